@@ -6,17 +6,20 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"time"
 	"trade-bot/configs"
 	"trade-bot/internal/app"
 	"trade-bot/internal/pkg/handler"
 	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/repository/encryption"
 	"trade-bot/internal/pkg/repository/postgresRepo"
 	"trade-bot/internal/pkg/repository/redisRepo"
 	"trade-bot/internal/pkg/service"
 	"trade-bot/internal/pkg/tradeAlgorithm"
 	"trade-bot/internal/pkg/web"
-	"trade-bot/pkg/krakenFuturesSDK"
 	"trade-bot/pkg/krakenFuturesWSSDK"
+	"trade-bot/schema"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/websocket"
@@ -36,11 +39,26 @@ var (
 	ErrCouldNotShutdownServer       = errors.New("could not shut down server normally")
 	ErrCouldNotCloseDBConnection    = errors.New("could not close db connection normally")
 	ErrCouldNotCloseRedisConnection = errors.New("could not close redis connection normally")
+	ErrUnableToCreateKeyRing        = errors.New("unable to create api keys key ring")
+	ErrUnableToRotateAPIKeys        = errors.New("unable to rotate api keys")
+	ErrUnableToRotateSecrets        = errors.New("unable to rotate secrets of signal webhooks")
+	ErrUnableToDecryptAPIKeys       = errors.New("unable to decrypt api keys")
+	ErrResumeTradingSessions        = errors.New("unable to resume trading sessions")
+	ErrUnableToMigrate              = errors.New("unable to migrate database")
+	ErrInvalidMigrateCommand        = errors.New("usage: migrate up|down [steps]|status")
+	ErrEmptyJWTSigningKey           = errors.New("jwt signing key is not set")
+	ErrUnableToStartCandlesRecorder = errors.New("unable to start candles recorder")
+	ErrUnableToWatchOpenOrders      = errors.New("unable to watch open orders")
 )
 
 const (
-	publicAPIKey  = "PUBLIC_API_KEY"
-	privateAPIKey = "PRIVATE_API_KEY"
+	masterKeyEnvPrefix = "API_KEYS_MASTER_KEY_V"
+	jwtSigningKeyEnv   = "JWT_ACCESS_SIGNING_KEY"
+	smtpPasswordEnv    = "SMTP_PASSWORD"
+	webhookSigningEnv  = "WEBHOOK_SIGNING_KEY"
+	rotateKeysCommand  = "rotate-keys"
+	decryptKeysCommand = "decrypt-keys"
+	migrateCommand     = "migrate"
 )
 
 // @title Trade-bot API
@@ -70,6 +88,55 @@ func main() {
 		}
 	}()
 
+	migrator, err := postgresRepo.NewMigrator(db, schema.Migrations)
+	if err != nil {
+		log.Panicf("%s: %s", ErrUnableToMigrate, err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == migrateCommand {
+		if err := runMigrateCommand(migrator, os.Args[2:]); err != nil {
+			log.Panicf("%s: %s", ErrUnableToMigrate, err)
+		}
+		return
+	}
+
+	if config.PostgreDatabase.MigrateOnStart {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Panicf("%s: %s", ErrUnableToMigrate, err)
+		}
+		logMigrations("applied", applied)
+	}
+
+	keyRing, err := encryption.NewKeyRing(config.Encryption.MasterKeys, config.Encryption.CurrentKeyVersion)
+	if err != nil {
+		log.Panicf("%s: %s", ErrUnableToCreateKeyRing, err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == rotateKeysCommand {
+		rotated, err := postgresRepo.NewAuthPostgres(db, keyRing).RotateAPIKeys()
+		if err != nil {
+			log.Panicf("%s: %s", ErrUnableToRotateAPIKeys, err)
+		}
+		log.Infof("api keys of %d users rotated to master key version %d", rotated, keyRing.CurrentVersion())
+
+		rotated, err = postgresRepo.NewSignalsPostgres(db, keyRing).RotateSecrets()
+		if err != nil {
+			log.Panicf("%s: %s", ErrUnableToRotateSecrets, err)
+		}
+		log.Infof("secrets of %d signal webhooks rotated to master key version %d", rotated, keyRing.CurrentVersion())
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == decryptKeysCommand {
+		decrypted, err := postgresRepo.NewAuthPostgres(db, keyRing).DecryptAPIKeys()
+		if err != nil {
+			log.Panicf("%s: %s", ErrUnableToDecryptAPIKeys, err)
+		}
+		log.Infof("api keys of %d users decrypted", decrypted)
+		return
+	}
+
 	redisClient, err := redisRepo.NewRedisClient(config.RedisDatabase)
 	if err != nil {
 		log.Panicf("%s: %s", ErrUnableToConnectToJWTDB, err)
@@ -80,11 +147,13 @@ func main() {
 		}
 	}()
 
-	krakenAPI := krakenFuturesSDK.NewAPI(os.Getenv(publicAPIKey), os.Getenv(privateAPIKey), config.Kraken.APIURL)
 	krakenWSAPI := krakenFuturesWSSDK.NewWSAPI(config.KrakenWS)
+	connectionEventsCtx, stopConnectionEvents := context.WithCancel(context.Background())
+	defer stopConnectionEvents()
+	go logConnectionEvents(krakenWSAPI.ConnectionEvents(connectionEventsCtx))
 
-	repo := repository.NewRepository(db, redisClient)
-	newWeb := web.NewWeb(krakenAPI, krakenWSAPI)
+	repo := repository.NewRepository(db, redisClient, keyRing)
+	newWeb := web.NewWeb(config.Kraken.APIURL, krakenWSAPI, config.PaperTrading, config.Telegram.APIToken, config.Notifications)
 	newTrader := tradeAlgorithm.NewTradeAlgorithm(newWeb)
 
 	validate := validator.New()
@@ -96,7 +165,21 @@ func main() {
 		},
 	}
 
-	services := service.NewService(repo, newWeb, newTrader)
+	if config.Auth.SigningKey == "" {
+		log.Panicf("%s: %s", ErrEmptyJWTSigningKey, jwtSigningKeyEnv)
+	}
+	services := service.NewService(repo, newWeb, newTrader, config.Auth, config.CandlesRecorder, config.Notifications)
+	if err := services.KrakenOrdersManager.WatchOpenOrders(); err != nil {
+		log.Warnf("%s: %s", ErrUnableToWatchOpenOrders, err)
+	}
+	if err := services.TradingSessions.ResumeSessions(); err != nil {
+		log.Panicf("%s: %s", ErrResumeTradingSessions, err)
+	}
+	services.Portfolio.StartReconciler(time.Duration(config.Portfolio.ReconcileIntervalInSeconds) * time.Second)
+	if err := services.Candles.StartRecorder(); err != nil {
+		log.Panicf("%s: %s", ErrUnableToStartCandlesRecorder, err)
+	}
+	services.Notifications.StartDispatcher()
 	handlers := handler.NewHandler(services, validate, &upgrader)
 
 	interrupt := make(chan os.Signal, 1)
@@ -120,9 +203,81 @@ func main() {
 		log.Panicf("%s: %s", ErrCouldNotShutdownServer, err)
 	}
 
+	services.Portfolio.StopReconciler()
+	services.Candles.StopRecorder()
+	services.TradingSessions.StopSessions()
+	services.KrakenOrdersManager.StopWatchingOrders()
+	newWeb.KrakenOrdersManagerFactory.Close()
+	services.Notifications.StopDispatcher()
+
 	log.Info("Trade bot server shut down")
 }
 
+// runMigrateCommand applies or rolls back migrations by arguments of migrate command,
+// down rolls back one migration unless count of steps is given
+func runMigrateCommand(migrator *postgresRepo.Migrator, args []string) error {
+	if len(args) == 0 {
+		return ErrInvalidMigrateCommand
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		logMigrations("applied", applied)
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return ErrInvalidMigrateCommand
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		logMigrations("rolled back", rolledBack)
+		return err
+
+	case "status":
+		version, statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		log.Infof("database version: %d", version)
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			log.Infof("%06d_%s: %s", status.Version, status.Name, state)
+		}
+		return nil
+	}
+	return ErrInvalidMigrateCommand
+}
+
+func logMigrations(action string, migrations []postgresRepo.Migration) {
+	if len(migrations) == 0 {
+		log.Infof("no migrations %s", action)
+	}
+	for _, migration := range migrations {
+		log.Infof("migration %06d_%s %s", migration.Version, migration.Name, action)
+	}
+}
+
+// logConnectionEvents logs state changes of kraken websocket connection shared by public feeds
+func logConnectionEvents(events <-chan krakenFuturesWSSDK.ConnectionEvent) {
+	for event := range events {
+		switch event.State {
+		case krakenFuturesWSSDK.StateDisconnected, krakenFuturesWSSDK.StateFailed:
+			log.Warnf("kraken websocket %s: attempt %d: %v", event.State, event.Attempt, event.Err)
+		default:
+			log.Infof("kraken websocket %s: attempt %d", event.State, event.Attempt)
+		}
+	}
+}
+
 func initConfig() (configs.Configuration, error) {
 	viper.SetConfigName("config")
 	viper.AddConfigPath("configs")
@@ -142,5 +297,21 @@ func initConfig() (configs.Configuration, error) {
 	var c configs.Configuration
 	err := viper.Unmarshal(&c)
 	c.PostgreDatabase.Password = os.Getenv("DB_PASSWORD")
+	c.Auth.SigningKey = os.Getenv(jwtSigningKeyEnv)
+	c.Notifications.SMTP.Password = os.Getenv(smtpPasswordEnv)
+	c.Notifications.WebhookSigningKey = os.Getenv(webhookSigningEnv)
+	c.Encryption.MasterKeys = loadMasterKeys(c.Encryption.CurrentKeyVersion)
 	return c, err
 }
+
+// loadMasterKeys reads master keys of every version up to current from environment.
+// Keys of old versions may be removed from environment after rotation
+func loadMasterKeys(currentVersion int) map[int]string {
+	keys := make(map[int]string)
+	for version := 1; version <= currentVersion; version++ {
+		if key := os.Getenv(fmt.Sprintf("%s%d", masterKeyEnvPrefix, version)); key != "" {
+			keys[version] = key
+		}
+	}
+	return keys
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository/postgresRepo"
+	"trade-bot/internal/pkg/service"
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/internal/pkg/web/webKraken"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrReadConfig               = errors.New("read config")
+	ErrUnableToConnectToDB      = errors.New("unable to connect to database")
+	ErrUnableToReadCandlesFile  = errors.New("unable to read candles file")
+	ErrUnableToParseParameters  = errors.New("unable to parse strategy parameters")
+	ErrUnableToParsePeriod      = errors.New("unable to parse candles period")
+	ErrUnableToRunBacktest      = errors.New("unable to run backtest")
+	ErrUnableToWriteReport      = errors.New("unable to write report")
+	ErrCandlesSourceNotProvided = errors.New("one of -file or -from with -to must be provided")
+)
+
+// Runs backtest of trading strategy over candles from csv/json file or from candles table:
+//
+//	go run cmd/backtest/main.go -file candles_1m.csv -symbol PI_XBTUSD -side buy -size 1 \
+//		-strategy trailing_stop -params trail_border=50
+//
+//	go run cmd/backtest/main.go -from 2021-12-01T00:00:00Z -to 2021-12-02T00:00:00Z -symbol PI_XBTUSD \
+//		-side sell -size 1 -strategy rsi_threshold -interval 15m -fee 0.0005
+func main() {
+	file := flag.String("file", "", "csv or json file with candles")
+	from := flag.String("from", "", "start of candles period in RFC3339 to read from candles table")
+	to := flag.String("to", "", "end of candles period in RFC3339 to read from candles table")
+	symbol := flag.String("symbol", "", "symbol of candles")
+	side := flag.String("side", krakenFuturesSDK.BuySide, "side of positions: buy or sell")
+	size := flag.Uint("size", 1, "size of positions")
+	strategy := flag.String("strategy", "", "trading strategy, stop loss & take profit by default")
+	params := flag.String("params", "", "strategy parameters in format name=value,name=value")
+	interval := flag.String("interval", "", "interval of candles analyzed by strategy, 1m by default")
+	fee := flag.Float64("fee", -1, "taker fee as a fraction of notional, fee schedule of kraken is used when negative")
+	warmup := flag.Int("warmup", 0, "count of candles to warm up strategy indicators")
+	flag.Parse()
+
+	parameters, err := parseParameters(*params)
+	if err != nil {
+		log.Fatalf("%s: %s", ErrUnableToParseParameters, err)
+	}
+
+	input := models.BacktestInput{
+		TradingDetails: types.TradingDetails{
+			OrderType:       "mkt",
+			Symbol:          *symbol,
+			Side:            *side,
+			Size:            *size,
+			Strategy:        *strategy,
+			Parameters:      parameters,
+			CandlesInterval: *interval,
+		},
+		WarmupCandles: *warmup,
+	}
+	if *fee >= 0 {
+		input.TakerFee = fee
+	}
+
+	config, err := initConfig()
+	if err != nil {
+		log.Fatalf("%s: %s", ErrReadConfig, err)
+	}
+
+	var backtestService *service.BacktestService
+	market := webKraken.NewKrakenMarketDataWebSDK(krakenFuturesSDK.NewAPI("", "", config.Kraken.APIURL))
+	strategies := tradeAlgorithm.NewTradeAlgorithm(&web.Web{}).Strategies
+
+	switch {
+	case *file != "":
+		data, err := ioutil.ReadFile(*file)
+		if err != nil {
+			log.Fatalf("%s: %s", ErrUnableToReadCandlesFile, err)
+		}
+		input.Candles = models.BacktestCandles{
+			Source: strings.TrimPrefix(filepath.Ext(*file), "."),
+			Data:   string(data),
+		}
+		backtestService = service.NewBacktestService(nil, market, strategies)
+
+	case *from != "" && *to != "":
+		input.Candles.Source = models.BacktestDBSource
+		if input.Candles.From, err = time.Parse(time.RFC3339, *from); err != nil {
+			log.Fatalf("%s: %s", ErrUnableToParsePeriod, err)
+		}
+		if input.Candles.To, err = time.Parse(time.RFC3339, *to); err != nil {
+			log.Fatalf("%s: %s", ErrUnableToParsePeriod, err)
+		}
+
+		_ = godotenv.Load()
+		config.PostgreDatabase.Password = os.Getenv("DB_PASSWORD")
+		db, err := postgresRepo.NewPostgresDB(config.PostgreDatabase)
+		if err != nil {
+			log.Fatalf("%s: %s", ErrUnableToConnectToDB, err)
+		}
+		defer db.Close()
+		candles := service.NewCandlesService(nil, postgresRepo.NewCandlesPostgres(db), config.CandlesRecorder)
+		backtestService = service.NewBacktestService(candles, market, strategies)
+
+	default:
+		log.Fatal(ErrCandlesSourceNotProvided)
+	}
+
+	report, err := backtestService.RunBacktest(context.Background(), input)
+	if err != nil {
+		log.Fatalf("%s: %s", ErrUnableToRunBacktest, err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("%s: %s", ErrUnableToWriteReport, err)
+	}
+}
+
+func parseParameters(params string) (types.StrategyParameters, error) {
+	parameters := make(types.StrategyParameters)
+	if params == "" {
+		return parameters, nil
+	}
+
+	for _, param := range strings.Split(params, ",") {
+		parts := strings.SplitN(param, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid parameter: %s", param)
+		}
+		value, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter: %s: %w", param, err)
+		}
+		parameters[parts[0]] = value
+	}
+	return parameters, nil
+}
+
+func initConfig() (configs.Configuration, error) {
+	viper.SetConfigName("config")
+	viper.AddConfigPath("configs")
+	viper.AddConfigPath(".")
+	viper.SetConfigType("yml")
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return configs.Configuration{}, err
+		}
+	}
+
+	var c configs.Configuration
+	err := viper.Unmarshal(&c)
+	return c, err
+}
@@ -8,6 +8,12 @@ type Configuration struct {
 	RedisDatabase   RedisDatabaseConfiguration
 	Kraken          KrakenConfiguration
 	KrakenWS        KrakenWSConfiguration
+	Encryption      EncryptionConfiguration
+	Auth            AuthConfiguration
+	PaperTrading    PaperTradingConfiguration
+	Portfolio       PortfolioConfiguration
+	CandlesRecorder CandlesRecorderConfiguration
+	Notifications   NotificationsConfiguration
 }
 
 type ServerConfiguration struct {
@@ -37,6 +43,8 @@ type PostgreDatabaseConfiguration struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// MigrateOnStart applies embedded migrations before server starts
+	MigrateOnStart bool
 }
 
 type RedisDatabaseConfiguration struct {
@@ -63,3 +71,63 @@ type KrakenWSAPIRequestsConfiguration struct {
 	PingPeriodInSeconds int
 	MaxMessageSize      int
 }
+
+type EncryptionConfiguration struct {
+	CurrentKeyVersion int
+	MasterKeys        map[int]string `mapstructure:"-"`
+}
+
+// AuthConfiguration sets up lifetime of access and refresh tokens, signing key is loaded from environment
+type AuthConfiguration struct {
+	AccessTokenTTLInMinutes int
+	RefreshTokenTTLInHours  int
+	SigningKey              string `mapstructure:"-"`
+}
+
+// PaperTradingConfiguration sets up simulated exchange used by users with enabled paper trading.
+// Fees and margin rate are fractions of order notional
+type PaperTradingConfiguration struct {
+	InitialBalance        float64
+	TakerFee              float64
+	MakerFee              float64
+	MarginRate            float64
+	PriceTimeoutInSeconds int
+}
+
+// PortfolioConfiguration sets up reconciler which syncs fills, positions and orders of users with exchange
+type PortfolioConfiguration struct {
+	ReconcileIntervalInSeconds int
+}
+
+// CandlesRecorderConfiguration sets up recorder which saves candles of symbols from websocket feeds of intervals
+// like 1m or 1h, recorder is off without symbols
+type CandlesRecorderConfiguration struct {
+	Symbols                []string
+	Intervals              []string
+	FlushIntervalInSeconds int
+}
+
+// NotificationsConfiguration sets up dispatcher which sends notifications of outbox by telegram, email and webhooks.
+// Failed notification is retried with doubling delay until it runs out of attempts, daily summary of PnL is sent
+// after hour of day in UTC. Webhook notifications are signed by secrets of users derived from signing key,
+// which is loaded from environment
+type NotificationsConfiguration struct {
+	DispatchIntervalInSeconds int
+	MaxAttempts               int
+	RetryDelayInSeconds       int
+	DailySummaryHour          int
+	TelegramAPIURL            string
+	SMTP                      SMTPConfiguration
+	WebhookTimeoutInSeconds   int
+	WebhookSigningKey         string `mapstructure:"-"`
+}
+
+// SMTPConfiguration sets up server which sends emails, email channel is off without host.
+// Password is loaded from environment
+type SMTPConfiguration struct {
+	Host     string
+	Port     int
+	Username string
+	From     string
+	Password string `mapstructure:"-"`
+}
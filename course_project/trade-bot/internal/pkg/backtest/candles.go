@@ -0,0 +1,145 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrReadCSVCandles    = errors.New("read csv candles")
+	ErrReadJSONCandles   = errors.New("read json candles")
+	ErrInvalidCSVRecord  = errors.New("invalid csv record")
+	ErrUnknownFormat     = errors.New("unknown candles format")
+	ErrNoCandlesOfSymbol = errors.New("no candles of symbol")
+)
+
+const (
+	CSVFormat  = "csv"
+	JSONFormat = "json"
+)
+
+// FileCandle is a candle in format of candles pipeline from hw3:
+// csv records "ticker,time in RFC3339,open,high,low,close" with optional volume column,
+// or json array of objects with the same fields
+type FileCandle struct {
+	Ticker string    `json:"ticker"`
+	TS     time.Time `json:"ts"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume int       `json:"volume,omitempty"`
+}
+
+func (c FileCandle) toCandle() krakenFuturesWSSDK.Candle {
+	return krakenFuturesWSSDK.Candle{
+		Time:   int(c.TS.Unix()),
+		Open:   strconv.FormatFloat(c.Open, 'f', -1, 64),
+		High:   strconv.FormatFloat(c.High, 'f', -1, 64),
+		Low:    strconv.FormatFloat(c.Low, 'f', -1, 64),
+		Close:  strconv.FormatFloat(c.Close, 'f', -1, 64),
+		Volume: c.Volume,
+	}
+}
+
+// ReadCandles reads candles of symbol in csv or json format sorted by time
+func ReadCandles(r io.Reader, format, symbol string) ([]krakenFuturesWSSDK.Candle, error) {
+	var (
+		fileCandles []FileCandle
+		err         error
+	)
+
+	switch strings.ToLower(format) {
+	case CSVFormat:
+		fileCandles, err = readCSV(r)
+	case JSONFormat:
+		fileCandles, err = readJSON(r)
+	default:
+		return nil, fmt.Errorf("%s: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]krakenFuturesWSSDK.Candle, 0, len(fileCandles))
+	for _, c := range fileCandles {
+		if strings.EqualFold(c.Ticker, symbol) {
+			candles = append(candles, c.toCandle())
+		}
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("%s: %s", ErrNoCandlesOfSymbol, symbol)
+	}
+
+	sort.SliceStable(candles, func(i, j int) bool {
+		return candles[i].Time < candles[j].Time
+	})
+	return candles, nil
+}
+
+func readCSV(r io.Reader) ([]FileCandle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrReadCSVCandles, err)
+	}
+
+	candles := make([]FileCandle, 0, len(records))
+	for i, record := range records {
+		candle, err := parseCSVRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: %w", ErrReadCSVCandles, i+1, err)
+		}
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+func parseCSVRecord(record []string) (FileCandle, error) {
+	if len(record) != 6 && len(record) != 7 {
+		return FileCandle{}, fmt.Errorf("%s: expected 6 or 7 fields, got %d", ErrInvalidCSVRecord, len(record))
+	}
+
+	ts, err := time.Parse(time.RFC3339, record[1])
+	if err != nil {
+		return FileCandle{}, fmt.Errorf("%s: %w", ErrInvalidCSVRecord, err)
+	}
+
+	prices := make([]float64, 4)
+	for i := range prices {
+		prices[i], err = strconv.ParseFloat(record[i+2], 64)
+		if err != nil {
+			return FileCandle{}, fmt.Errorf("%s: %w", ErrInvalidCSVRecord, err)
+		}
+	}
+
+	candle := FileCandle{Ticker: record[0], TS: ts, Open: prices[0], High: prices[1], Low: prices[2], Close: prices[3]}
+	if len(record) == 7 {
+		candle.Volume, err = strconv.Atoi(record[6])
+		if err != nil {
+			return FileCandle{}, fmt.Errorf("%s: %w", ErrInvalidCSVRecord, err)
+		}
+	}
+	return candle, nil
+}
+
+func readJSON(r io.Reader) ([]FileCandle, error) {
+	var candles []FileCandle
+	if err := json.NewDecoder(r).Decode(&candles); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrReadJSONCandles, err)
+	}
+	return candles, nil
+}
@@ -0,0 +1,208 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrRunBacktest      = errors.New("run backtest")
+	ErrNotEnoughCandles = errors.New("not enough candles")
+	ErrParseClosePrice  = errors.New("parse close price")
+)
+
+const (
+	DefaultWarmupCandles = 100
+	minCandlesCount      = 2
+	percents             = 100
+)
+
+// TraderFactory creates trader which looks for candles with given analyzer
+type TraderFactory func(analyzer web.KrakenAnalyzer) tradeAlgorithm.Trader
+
+type Config struct {
+	Details types.TradingDetails
+	// TakerFee is a fee of market order as a fraction of its notional
+	TakerFee float64
+	// WarmupCandles is a count of candles before entry passed to trader to warm up its indicators
+	WarmupCandles int
+}
+
+type Trade struct {
+	Side       string    `json:"side"`
+	Size       uint      `json:"size"`
+	EntryTime  time.Time `json:"entry_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitTime   time.Time `json:"exit_time"`
+	ExitPrice  float64   `json:"exit_price"`
+	Fees       float64   `json:"fees"`
+	PnL        float64   `json:"pnl"`
+	// ClosedByEnd is set when strategy has not exited before candles are over
+	ClosedByEnd bool `json:"closed_by_end,omitempty"`
+}
+
+type Report struct {
+	Candles     int     `json:"candles"`
+	Trades      []Trade `json:"trades"`
+	GrossPnL    float64 `json:"gross_pnl"`
+	Fees        float64 `json:"fees"`
+	PnL         float64 `json:"pnl"`
+	WinRate     float64 `json:"win_rate"`
+	MaxDrawdown float64 `json:"max_drawdown"`
+}
+
+// Engine replays historical candles through trader. Position of trading details side is opened
+// by market order on close of candle, kept until trader exits and then opened again on the next candle
+type Engine struct {
+	newTrader TraderFactory
+}
+
+func NewEngine(newTrader TraderFactory) *Engine {
+	return &Engine{newTrader: newTrader}
+}
+
+func (e *Engine) Run(ctx context.Context, candles []krakenFuturesWSSDK.Candle, cfg Config) (Report, error) {
+	if len(candles) < minCandlesCount {
+		return Report{}, fmt.Errorf("%s: %s: %d", ErrRunBacktest, ErrNotEnoughCandles, len(candles))
+	}
+
+	report := Report{Candles: len(candles), Trades: make([]Trade, 0)}
+	for entry := 0; entry < len(candles)-1; {
+		trade, exit, err := e.runTrade(ctx, candles, entry, cfg)
+		if err != nil {
+			return Report{}, fmt.Errorf("%s: %w", ErrRunBacktest, err)
+		}
+
+		report.Trades = append(report.Trades, trade)
+		entry = exit + 1
+	}
+
+	report.summarize()
+	return report, nil
+}
+
+// runTrade opens position on close of entry candle and returns trade with index of exit candle
+func (e *Engine) runTrade(ctx context.Context, candles []krakenFuturesWSSDK.Candle, entry int, cfg Config) (Trade, int, error) {
+	entryPrice, err := closePrice(candles[entry])
+	if err != nil {
+		return Trade{}, 0, err
+	}
+
+	start := entry + 1 - cfg.WarmupCandles
+	if start < 0 {
+		start = 0
+	}
+	replay := newReplayAnalyzer(candles[start:])
+
+	details := cfg.Details
+	details.BuyPrice = entryPrice
+	buyTime := time.Unix(int64(candles[entry+1].Time), 0)
+
+	tradeCtx, cancel := context.WithCancel(ctx)
+	err = e.newTrader(replay).StartAnalyzing(tradeCtx, buyTime, details)
+	cancel()
+
+	exit := start + replay.lastDelivered()
+	closedByEnd := false
+	if err != nil {
+		if ctx.Err() != nil {
+			return Trade{}, 0, ctx.Err()
+		}
+		if exit != len(candles)-1 {
+			return Trade{}, 0, err
+		}
+		closedByEnd = true
+	}
+	if exit <= entry {
+		// trader exited without looking at candles after buy, so position is closed on the first of them
+		exit = entry + 1
+	}
+
+	exitPrice, err := closePrice(candles[exit])
+	if err != nil {
+		return Trade{}, 0, err
+	}
+
+	trade := Trade{
+		Side:        details.Side,
+		Size:        details.Size,
+		EntryTime:   time.Unix(int64(candles[entry].Time), 0).UTC(),
+		EntryPrice:  entryPrice,
+		ExitTime:    time.Unix(int64(candles[exit].Time), 0).UTC(),
+		ExitPrice:   exitPrice,
+		ClosedByEnd: closedByEnd,
+	}
+	trade.calculatePnL(cfg.TakerFee)
+
+	return trade, exit, nil
+}
+
+func (t *Trade) calculatePnL(takerFee float64) {
+	direction := 1.0
+	if t.Side == krakenFuturesSDK.SellSide {
+		direction = -1
+	}
+
+	size := float64(t.Size)
+	t.Fees = takerFee * (t.EntryPrice + t.ExitPrice) * size
+	t.PnL = direction*(t.ExitPrice-t.EntryPrice)*size - t.Fees
+}
+
+func (r *Report) summarize() {
+	var wins int
+	var equity, peak float64
+
+	for _, trade := range r.Trades {
+		r.Fees += trade.Fees
+		r.PnL += trade.PnL
+		if trade.PnL > 0 {
+			wins++
+		}
+
+		equity += trade.PnL
+		peak = math.Max(peak, equity)
+		r.MaxDrawdown = math.Max(r.MaxDrawdown, peak-equity)
+	}
+
+	r.GrossPnL = r.PnL + r.Fees
+	if len(r.Trades) != 0 {
+		r.WinRate = float64(wins) / float64(len(r.Trades))
+	}
+}
+
+func closePrice(candle krakenFuturesWSSDK.Candle) (float64, error) {
+	price, err := strconv.ParseFloat(candle.Close, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrParseClosePrice, err)
+	}
+	return price, nil
+}
+
+// TakerFee returns taker fee of the lowest volume tier of fee schedule as a fraction of notional
+func TakerFee(schedules []krakenFuturesSDK.FeeSchedules) float64 {
+	for _, schedule := range schedules {
+		if len(schedule.Tiers) == 0 {
+			continue
+		}
+
+		lowest := schedule.Tiers[0]
+		for _, tier := range schedule.Tiers {
+			if tier.UsdVolume < lowest.UsdVolume {
+				lowest = tier
+			}
+		}
+		return lowest.TakerFee / percents
+	}
+	return 0
+}
@@ -0,0 +1,147 @@
+package backtest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+const candlesCSV = `PI_XBTUSD,2021-12-01T00:00:00Z,100,100,100,100
+PI_XBTUSD,2021-12-01T00:01:00Z,100,100,100,105
+OTHER,2021-12-01T00:01:00Z,1,1,1,1
+PI_XBTUSD,2021-12-01T00:02:00Z,100,100,100,111
+PI_XBTUSD,2021-12-01T00:03:00Z,100,100,100,108
+PI_XBTUSD,2021-12-01T00:04:00Z,100,100,100,100
+PI_XBTUSD,2021-12-01T00:05:00Z,100,100,100,96
+PI_XBTUSD,2021-12-01T00:06:00Z,100,100,100,97
+`
+
+const candlesJSON = `[
+	{"ticker": "PI_XBTUSD", "ts": "2021-12-01T00:01:00Z", "open": 1, "high": 1, "low": 1, "close": 2},
+	{"ticker": "PI_XBTUSD", "ts": "2021-12-01T00:00:00Z", "open": 1, "high": 1, "low": 1, "close": 1}
+]`
+
+func newTestEngine() *Engine {
+	return NewEngine(func(analyzer web.KrakenAnalyzer) tradeAlgorithm.Trader {
+		return tradeAlgorithm.NewTradeAlgorithm(&web.Web{KrakenAnalyzer: analyzer})
+	})
+}
+
+func TestReadCandles(t *testing.T) {
+	csvCandles, err := ReadCandles(strings.NewReader(candlesCSV), CSVFormat, "PI_XBTUSD")
+	assert.NoError(t, err)
+	assert.Len(t, csvCandles, 7)
+	assert.Equal(t, "105", csvCandles[1].Close)
+
+	jsonCandles, err := ReadCandles(strings.NewReader(candlesJSON), JSONFormat, "PI_XBTUSD")
+	assert.NoError(t, err)
+	assert.Len(t, jsonCandles, 2)
+	assert.Equal(t, "1", jsonCandles[0].Close)
+
+	_, err = ReadCandles(strings.NewReader(candlesCSV), CSVFormat, "UNKNOWN")
+	assert.Error(t, err)
+
+	_, err = ReadCandles(strings.NewReader("PI_XBTUSD,time,1,1,1,1"), CSVFormat, "PI_XBTUSD")
+	assert.Error(t, err)
+}
+
+func TestEngine_Run(t *testing.T) {
+	candles, err := ReadCandles(strings.NewReader(candlesCSV), CSVFormat, "PI_XBTUSD")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    Report
+		wantErr bool
+	}{
+		{
+			name: "Stop loss take profit",
+			cfg: Config{
+				Details: types.TradingDetails{Symbol: "PI_XBTUSD", Side: krakenFuturesSDK.BuySide, Size: 2,
+					Strategy: "stop_loss_take_profit", Parameters: types.StrategyParameters{"stop_loss_border": 5, "take_profit_border": 10}},
+				TakerFee: 0.001,
+			},
+			want: Report{
+				Candles: 7,
+				Trades: []Trade{
+					// entry 100, exit 111 on take profit
+					{EntryPrice: 100, ExitPrice: 111, Fees: 0.422, PnL: 21.578},
+					// entry 108, exit 100 on stop loss
+					{EntryPrice: 108, ExitPrice: 100, Fees: 0.416, PnL: -16.416},
+					// entry 96, candles are over
+					{EntryPrice: 96, ExitPrice: 97, Fees: 0.386, PnL: 1.614, ClosedByEnd: true},
+				},
+				Fees:        1.224,
+				PnL:         6.776,
+				GrossPnL:    8,
+				WinRate:     2.0 / 3,
+				MaxDrawdown: 16.416,
+			},
+		},
+		{
+			name: "Trailing stop short",
+			cfg: Config{
+				Details: types.TradingDetails{Symbol: "PI_XBTUSD", Side: krakenFuturesSDK.SellSide, Size: 1,
+					Strategy: "trailing_stop", Parameters: types.StrategyParameters{"trail_border": 3}},
+			},
+			want: Report{
+				Candles: 7,
+				Trades: []Trade{
+					// entry 100, exit 105 on retrace from 100
+					{EntryPrice: 100, ExitPrice: 105, PnL: -5},
+					// entry 111, best 100, exit 96 is not retrace, candles are over
+					{EntryPrice: 111, ExitPrice: 97, PnL: 14, ClosedByEnd: true},
+				},
+				PnL:         9,
+				GrossPnL:    9,
+				WinRate:     0.5,
+				MaxDrawdown: 5,
+			},
+		},
+		{
+			name:    "Not enough candles",
+			cfg:     Config{Details: types.TradingDetails{Strategy: "trailing_stop"}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := candles
+			if test.wantErr {
+				input = candles[:1]
+			}
+
+			got, err := newTestEngine().Run(context.Background(), input, test.cfg)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want.Candles, got.Candles)
+			assert.InDelta(t, test.want.PnL, got.PnL, 1e-9)
+			assert.InDelta(t, test.want.GrossPnL, got.GrossPnL, 1e-9)
+			assert.InDelta(t, test.want.Fees, got.Fees, 1e-9)
+			assert.InDelta(t, test.want.WinRate, got.WinRate, 1e-9)
+			assert.InDelta(t, test.want.MaxDrawdown, got.MaxDrawdown, 1e-9)
+
+			assert.Len(t, got.Trades, len(test.want.Trades))
+			for i, trade := range got.Trades {
+				assert.Equal(t, test.want.Trades[i].EntryPrice, trade.EntryPrice)
+				assert.Equal(t, test.want.Trades[i].ExitPrice, trade.ExitPrice)
+				assert.InDelta(t, test.want.Trades[i].Fees, trade.Fees, 1e-9)
+				assert.InDelta(t, test.want.Trades[i].PnL, trade.PnL, 1e-9)
+				assert.Equal(t, test.want.Trades[i].ClosedByEnd, trade.ClosedByEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package backtest
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// ErrNotReplayed is returned for market data which is not stored, so backtest can not replay it
+var ErrNotReplayed = errors.New("market data is not replayed by backtest")
+
+// replayAnalyzer is a web.KrakenAnalyzer which streams historical candles instead of live ones
+// and remembers the last candle received by trader
+type replayAnalyzer struct {
+	candles   []krakenFuturesWSSDK.Candle
+	delivered int
+	done      chan struct{}
+}
+
+func newReplayAnalyzer(candles []krakenFuturesWSSDK.Candle) *replayAnalyzer {
+	return &replayAnalyzer{candles: candles, delivered: -1}
+}
+
+func (r *replayAnalyzer) LookForCandles(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.Candle, error) {
+	candlesCh := make(chan krakenFuturesWSSDK.Candle)
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+		defer close(candlesCh)
+
+		for i, candle := range r.candles {
+			select {
+			case candlesCh <- candle:
+				r.delivered = i
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return candlesCh, nil
+}
+
+func (r *replayAnalyzer) LookForCandlesUpdates(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.CandlesTradeData, error) {
+	return nil, ErrNotReplayed
+}
+
+// CandlesHistory returns no candles, replay starts from its first candle
+func (r *replayAnalyzer) CandlesHistory(feed, productID string, count int) ([]krakenFuturesWSSDK.Candle, error) {
+	return nil, nil
+}
+
+func (r *replayAnalyzer) LookForTicker(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TickerData, error) {
+	return nil, ErrNotReplayed
+}
+
+func (r *replayAnalyzer) LookForTickerLite(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TickerLiteData, error) {
+	return nil, ErrNotReplayed
+}
+
+func (r *replayAnalyzer) LookForTrades(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TradeData, error) {
+	return nil, ErrNotReplayed
+}
+
+func (r *replayAnalyzer) LookForOrderBook(ctx context.Context, productsIDs []string, depth int) (<-chan krakenFuturesWSSDK.OrderBookDepth, error) {
+	return nil, ErrNotReplayed
+}
+
+// lastDelivered waits for replay to stop and returns index of the last candle received by trader
+func (r *replayAnalyzer) lastDelivered() int {
+	if r.done != nil {
+		<-r.done
+	}
+	return r.delivered
+}
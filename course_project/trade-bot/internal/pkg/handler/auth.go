@@ -3,9 +3,11 @@ package handler
 import (
 	"net/http"
 	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
 	"trade-bot/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -17,6 +19,10 @@ type signInInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type refreshInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 // @Summary SignIn
 // @Tags auth
 // @Description login
@@ -24,7 +30,7 @@ type signInInput struct {
 // @Accept  json
 // @Produce  json
 // @Param input body signInInput true "credentials"
-// @Success 200 {string} string "access_token"
+// @Success 200 {object} models.Tokens
 // @Failure 400,404 {object} errResponse
 // @Failure 500 {object} errResponse
 // @Failure default {object} errResponse
@@ -37,15 +43,42 @@ func (h *Handler) signIn(c *gin.Context) {
 		return
 	}
 
-	accessToken, err := h.services.Authorization.GenerateJWT(input.Username, input.Password)
+	tokens, err := h.services.Authorization.GenerateJWT(input.Username, input.Password, sessionClient(c))
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"access_token": accessToken,
-	})
+	c.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Refresh
+// @Tags auth
+// @Description get new access token, refresh token is rotated and can be used only once
+// @ID refresh
+// @Accept  json
+// @Produce  json
+// @Param input body refreshInput true "refresh token"
+// @Success 200 {object} models.Tokens
+// @Failure 400,401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /auth/refresh [post]
+func (h *Handler) refresh(c *gin.Context) {
+	var input refreshInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, ErrInvalidInputBody)
+		return
+	}
+
+	tokens, err := h.services.Authorization.RefreshJWT(input.RefreshToken, sessionClient(c))
+	if err != nil {
+		newErrorResponse(c, authErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
 }
 
 // @Summary SignUp
@@ -106,3 +139,79 @@ func (h *Handler) logout(c *gin.Context) {
 		"message": "successfully logged out",
 	})
 }
+
+// @Summary AuthSessions
+// @Security ApiKeyAuth
+// @Tags auth
+// @Description get signed in devices of user, the latest used first
+// @ID auth-sessions
+// @Produce  json
+// @Success 200 {object} []models.AuthSession
+// @Failure 401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /auth/sessions [get]
+func (h *Handler) authSessions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	sessions, err := h.services.Authorization.GetUserSessions(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// @Summary RevokeAuthSession
+// @Security ApiKeyAuth
+// @Tags auth
+// @Description sign out device, its access and refresh tokens stop working
+// @ID revoke-auth-session
+// @Produce  json
+// @Param id path string true "session id"
+// @Success 200 {string} string "message"
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /auth/sessions/{id} [delete]
+func (h *Handler) revokeAuthSession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err := h.services.Authorization.RevokeSession(userID, c.Param("id")); err != nil {
+		newErrorResponse(c, authErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "session is revoked",
+	})
+}
+
+func sessionClient(c *gin.Context) models.SessionClient {
+	return models.SessionClient{
+		Device: c.Request.UserAgent(),
+		IP:     c.ClientIP(),
+	}
+}
+
+func authErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRefreshToken), errors.Is(err, service.ErrRefreshTokenReused):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrAuthSessionNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -129,10 +130,11 @@ func TestHandler_signIn(t *testing.T) {
 			username:  "username",
 			password:  "qwerty",
 			mockBehaviour: func(s *mockService.MockAuthorization, username, password string) {
-				s.EXPECT().GenerateJWT(username, password).Return("token", nil)
+				s.EXPECT().GenerateJWT(username, password, models.SessionClient{IP: "192.0.2.1"}).
+					Return(models.Tokens{AccessToken: "token", RefreshToken: "refresh", ExpiresIn: 900}, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"access_token":"token"}`,
+			expectedRequestBody: `{"access_token":"token","refresh_token":"refresh","expires_in":900}`,
 		},
 		{
 			name:                "Wrong Input",
@@ -147,7 +149,8 @@ func TestHandler_signIn(t *testing.T) {
 			username:  "username",
 			password:  "qwerty",
 			mockBehaviour: func(s *mockService.MockAuthorization, username, password string) {
-				s.EXPECT().GenerateJWT(username, password).Return("", errors.New("something went wrong"))
+				s.EXPECT().GenerateJWT(username, password, models.SessionClient{IP: "192.0.2.1"}).
+					Return(models.Tokens{}, errors.New("something went wrong"))
 			},
 			expectedStatusCode:  500,
 			expectedRequestBody: `{"message":"something went wrong"}`,
@@ -268,3 +271,194 @@ func TestHandler_logout(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_refresh(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockAuthorization)
+
+	client := models.SessionClient{Device: "telegram", IP: "192.0.2.1"}
+
+	tests := []struct {
+		name                string
+		inputBody           string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"refresh_token":"refresh"}`,
+			mockBehaviour: func(s *mockService.MockAuthorization) {
+				s.EXPECT().RefreshJWT("refresh", client).
+					Return(models.Tokens{AccessToken: "token", RefreshToken: "new refresh", ExpiresIn: 900}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"access_token":"token","refresh_token":"new refresh","expires_in":900}`,
+		},
+		{
+			name:                "Wrong Input",
+			inputBody:           `{}`,
+			mockBehaviour:       func(s *mockService.MockAuthorization) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s"}`, ErrInvalidInputBody),
+		},
+		{
+			name:      "Reused token",
+			inputBody: `{"refresh_token":"refresh"}`,
+			mockBehaviour: func(s *mockService.MockAuthorization) {
+				s.EXPECT().RefreshJWT("refresh", client).
+					Return(models.Tokens{}, fmt.Errorf("%s: %w", service.ErrRefreshJWT, service.ErrRefreshTokenReused))
+			},
+			expectedStatusCode:  401,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: %s"}`, service.ErrRefreshJWT, service.ErrRefreshTokenReused),
+		},
+		{
+			name:      "Service error",
+			inputBody: `{"refresh_token":"refresh"}`,
+			mockBehaviour: func(s *mockService.MockAuthorization) {
+				s.EXPECT().RefreshJWT("refresh", client).Return(models.Tokens{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mockService.NewMockAuthorization(c)
+			test.mockBehaviour(repo)
+
+			services := &service.Service{Authorization: repo}
+			handler := Handler{services, nil, nil}
+
+			r := gin.New()
+			r.POST("/refresh", handler.refresh)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/refresh",
+				bytes.NewBufferString(test.inputBody))
+			req.Header.Set("User-Agent", "telegram")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_authSessions(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockAuthorization)
+
+	created := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockAuthorization) {
+				s.EXPECT().GetUserSessions(1).Return([]models.AuthSession{{ID: "id", UserID: 1, Device: "telegram",
+					IP: "192.0.2.1", CreatedAt: created, LastUsedAt: created, ExpiresAt: created.Add(time.Hour),
+					AccessUUID: "access", RefreshUUID: "refresh"}}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"sessions":[{"id":"id","device":"telegram","ip":"192.0.2.1",` +
+				`"created_at":"2022-01-10T12:00:00Z","last_used_at":"2022-01-10T12:00:00Z","expires_at":"2022-01-10T13:00:00Z"}]}`,
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockAuthorization) {
+				s.EXPECT().GetUserSessions(1).Return(nil, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mockService.NewMockAuthorization(c)
+			test.mockBehaviour(repo)
+
+			services := &service.Service{Authorization: repo}
+			handler := Handler{services, nil, nil}
+
+			r := gin.New()
+			r.GET("/sessions", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.authSessions)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_revokeAuthSession(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockAuthorization)
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockAuthorization) {
+				s.EXPECT().RevokeSession(1, "id").Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"message":"session is revoked"}`,
+		},
+		{
+			name: "Not found",
+			mockBehaviour: func(s *mockService.MockAuthorization) {
+				s.EXPECT().RevokeSession(1, "id").
+					Return(fmt.Errorf("%s: %w", service.ErrRevokeSession, service.ErrAuthSessionNotFound))
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: %s"}`, service.ErrRevokeSession, service.ErrAuthSessionNotFound),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			repo := mockService.NewMockAuthorization(c)
+			test.mockBehaviour(repo)
+
+			services := &service.Service{Authorization: repo}
+			handler := Handler{services, nil, nil}
+
+			r := gin.New()
+			r.DELETE("/sessions/:id", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.revokeAuthSession)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/sessions/id", nil)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
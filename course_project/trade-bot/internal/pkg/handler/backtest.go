@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+)
+
+// @Summary RunBacktest
+// @Security ApiKeyAuth
+// @Tags backtest
+// @Description replay historical candles through trading strategy and report its results
+// @ID runBacktest
+// @Accept  json
+// @Produce  json
+// @Param input body models.BacktestInput true "backtest info"
+// @Success 200 {object} backtest.Report
+// @Failure 400,401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /backtest [post]
+func (h *Handler) runBacktest(c *gin.Context) {
+	var input models.BacktestInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.services.Backtest.RunBacktest(c.Request.Context(), input)
+	if err != nil {
+		newErrorResponse(c, backtestErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func backtestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidBacktestDetails), errors.Is(err, service.ErrInvalidBacktestCandles),
+		errors.Is(err, service.ErrUnknownCandlesSource), errors.Is(err, service.ErrEmptyCandlesPeriod),
+		errors.Is(err, service.ErrInvalidCandlesRequest), errors.Is(err, service.ErrIntervalIsNotResampled):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,11 +1,20 @@
 package handler
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/service"
 )
 
+// riskRejectedCode is a code of error response when order is rejected by risk check
+const riskRejectedCode = "risk_rejected"
+
 type errResponse struct {
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -13,3 +22,13 @@ func newErrorResponse(c *gin.Context, statusCode int, message string) {
 	log.Error(message)
 	c.AbortWithStatusJSON(statusCode, errResponse{Message: message})
 }
+
+// newOrderErrorResponse responds with distinct code and status to orders rejected by risk check
+func newOrderErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrRiskRejected) {
+		log.Warn(err.Error())
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, errResponse{Code: riskRejectedCode, Message: err.Error()})
+		return
+	}
+	newErrorResponse(c, http.StatusInternalServerError, err.Error())
+}
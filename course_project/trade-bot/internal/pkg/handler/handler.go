@@ -32,7 +32,10 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	{
 		auth.POST("sign-in", h.signIn)
 		auth.POST("sign-up", h.signUp)
+		auth.POST("refresh", h.refresh)
 		auth.DELETE("logout", h.userIdentity, h.logout)
+		auth.GET("sessions", h.userIdentity, h.authSessions)
+		auth.DELETE("sessions/:id", h.userIdentity, h.revokeAuthSession)
 	}
 
 	orderManager := router.Group("/orderManager", h.userIdentity)
@@ -40,6 +43,59 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		orderManager.POST("send-order", h.sendOrder)
 		orderManager.GET("ws/start-trade", h.startTrade)
 		orderManager.GET("my-orders", h.myOrders)
+		orderManager.PUT("orders/:id", h.editOrder)
+		orderManager.DELETE("orders/:id", h.cancelOrder)
+		orderManager.DELETE("orders", h.cancelAllOrders)
+		orderManager.GET("strategies", h.strategies)
+		orderManager.GET("sessions", h.sessions)
+		orderManager.GET("sessions/:id", h.session)
+		orderManager.DELETE("sessions/:id", h.cancelSession)
+	}
+
+	router.POST("/backtest", h.userIdentity, h.runBacktest)
+
+	settings := router.Group("/settings", h.userIdentity)
+	{
+		settings.GET("", h.getSettings)
+		settings.PUT("", h.updateSettings)
+		settings.GET("risk", h.getRiskLimits)
+		settings.PUT("risk", h.updateRiskLimits)
+		settings.POST("signals", h.createSignalWebhook)
+		settings.GET("notifications", h.getNotificationPreferences)
+		settings.PUT("notifications", h.updateNotificationPreferences)
+	}
+
+	signals := router.Group("/signals")
+	{
+		signals.POST(":token", h.handleSignal)
+		signals.GET("", h.userIdentity, h.signals)
+	}
+
+	router.GET("/notifications", h.userIdentity, h.notifications)
+
+	portfolio := router.Group("/portfolio", h.userIdentity)
+	{
+		portfolio.GET("open-orders", h.openOrders)
+		portfolio.GET("positions", h.positions)
+		portfolio.GET("fills", h.fills)
+		portfolio.GET("accounts", h.accounts)
+		portfolio.GET("history", h.historicalOrders)
+		portfolio.GET("drifts", h.drifts)
+		portfolio.POST("sync", h.syncPortfolio)
+	}
+
+	reports := router.Group("/reports", h.userIdentity)
+	{
+		reports.GET("pnl", h.pnl)
+	}
+
+	market := router.Group("/market")
+	{
+		market.GET("tickers", h.tickers)
+		market.GET("orderbook/:symbol", h.orderBook)
+		market.GET("instruments", h.instruments)
+		market.GET("fees", h.fees)
+		market.GET("candles", h.candles)
 	}
 
 	return router
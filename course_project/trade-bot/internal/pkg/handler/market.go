@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/service"
+)
+
+var ErrInvalidCandlesTime = errors.New("from and to must be in RFC3339 format")
+
+// @Summary Tickers
+// @Tags market
+// @Description get tickers of all kraken futures contracts, tickers are cached for a few seconds
+// @ID tickers
+// @Produce  json
+// @Success 200 {object} []krakenFuturesSDK.Ticker
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /market/tickers [get]
+func (h *Handler) tickers(c *gin.Context) {
+	tickers, err := h.services.Market.GetTickers()
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"tickers": tickers,
+	})
+}
+
+// @Summary OrderBook
+// @Tags market
+// @Description get order book of contract, order book is cached for a second
+// @ID orderBook
+// @Produce  json
+// @Param symbol path string true "contract symbol"
+// @Success 200 {object} krakenFuturesSDK.OrderBook
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /market/orderbook/{symbol} [get]
+func (h *Handler) orderBook(c *gin.Context) {
+	orderBook, err := h.services.Market.GetOrderBook(c.Param("symbol"))
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"order_book": orderBook,
+	})
+}
+
+// @Summary Instruments
+// @Tags market
+// @Description get specifications of kraken futures contracts, instruments are cached for minutes
+// @ID instruments
+// @Produce  json
+// @Success 200 {object} []krakenFuturesSDK.Instrument
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /market/instruments [get]
+func (h *Handler) instruments(c *gin.Context) {
+	instruments, err := h.services.Market.GetInstruments()
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"instruments": instruments,
+	})
+}
+
+// @Summary Fees
+// @Tags market
+// @Description get fee schedules of kraken futures, fee schedules are cached for minutes
+// @ID fees
+// @Produce  json
+// @Success 200 {object} []krakenFuturesSDK.FeeSchedules
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /market/fees [get]
+func (h *Handler) fees(c *gin.Context) {
+	schedules, err := h.services.Market.GetFeeSchedules()
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"fee_schedules": schedules,
+	})
+}
+
+// @Summary Candles
+// @Tags market
+// @Description get recorded candles of contract, candles of larger intervals are resampled from recorded ones
+// @ID candles
+// @Produce  json
+// @Param symbol query string true "contract symbol"
+// @Param interval query string true "1m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d or 1w"
+// @Param from query string false "RFC3339 time, 500 intervals before to by default"
+// @Param to query string false "RFC3339 time, now by default"
+// @Success 200 {object} []models.Candle
+// @Failure 400 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /market/candles [get]
+func (h *Handler) candles(c *gin.Context) {
+	var from, to time.Time
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: %s", ErrInvalidCandlesTime, value))
+			return
+		}
+		*param.value = t
+	}
+
+	candles, err := h.services.Candles.GetCandles(c.Query("symbol"), c.Query("interval"), from, to)
+	if err != nil {
+		newErrorResponse(c, candlesErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"candles": candles,
+	})
+}
+
+func candlesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCandlesRequest), errors.Is(err, service.ErrTooManyCandles),
+		errors.Is(err, service.ErrIntervalIsNotResampled):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestHandler_tickers(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockMarket)
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockMarket) {
+				s.EXPECT().GetTickers().Return([]krakenFuturesSDK.Ticker{{Symbol: "PI_XBTUSD", Last: 28000.5}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"tickers":[{"symbol":"PI_XBTUSD","last":28000.5}]}`,
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockMarket) {
+				s.EXPECT().GetTickers().Return(nil, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			market := mockService.NewMockMarket(c)
+			test.mockBehaviour(market)
+
+			services := &service.Service{Market: market}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/market/tickers", handler.tickers)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/market/tickers", nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_orderBook(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockMarket, symbol string)
+
+	tests := []struct {
+		name                string
+		symbol              string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:   "OK",
+			symbol: "PI_XBTUSD",
+			mockBehaviour: func(s *mockService.MockMarket, symbol string) {
+				s.EXPECT().GetOrderBook(symbol).Return(krakenFuturesSDK.OrderBook{
+					Bids: [][2]float64{{28000, 10}},
+					Asks: [][2]float64{{28001, 5}},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"order_book":{"bids":[[28000,10]],"asks":[[28001,5]]}}`,
+		},
+		{
+			name:   "Service error",
+			symbol: "PI_UNKNOWN",
+			mockBehaviour: func(s *mockService.MockMarket, symbol string) {
+				s.EXPECT().GetOrderBook(symbol).Return(krakenFuturesSDK.OrderBook{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			market := mockService.NewMockMarket(c)
+			test.mockBehaviour(market, test.symbol)
+
+			services := &service.Service{Market: market}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/market/orderbook/:symbol", handler.orderBook)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/market/orderbook/"+test.symbol, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_candles(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockCandles)
+
+	from := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?symbol=PI_XBTUSD&interval=1h&from=2022-01-10T00:00:00Z&to=2022-01-11T00:00:00Z",
+			mockBehaviour: func(s *mockService.MockCandles) {
+				s.EXPECT().GetCandles("PI_XBTUSD", "1h", from, to).Return([]models.Candle{
+					{Time: from, Open: 1, High: 3, Low: 0.5, Close: 2, Volume: 10},
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"candles":[{"time":"2022-01-10T00:00:00Z","open":1,"high":3,"low":0.5,"close":2,"volume":10}]}`,
+		},
+		{
+			name:                "Invalid time",
+			query:               "?symbol=PI_XBTUSD&interval=1h&from=yesterday",
+			mockBehaviour:       func(s *mockService.MockCandles) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: yesterday"}`, ErrInvalidCandlesTime),
+		},
+		{
+			name:  "Invalid interval",
+			query: "?symbol=PI_XBTUSD&interval=3m",
+			mockBehaviour: func(s *mockService.MockCandles) {
+				s.EXPECT().GetCandles("PI_XBTUSD", "3m", time.Time{}, time.Time{}).
+					Return(nil, fmt.Errorf("%s: %w", service.ErrGetCandles, service.ErrInvalidCandlesRequest))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: %s"}`, service.ErrGetCandles, service.ErrInvalidCandlesRequest),
+		},
+		{
+			name:  "Service error",
+			query: "?symbol=PI_XBTUSD&interval=1h",
+			mockBehaviour: func(s *mockService.MockCandles) {
+				s.EXPECT().GetCandles("PI_XBTUSD", "1h", time.Time{}, time.Time{}).
+					Return(nil, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			candles := mockService.NewMockCandles(c)
+			test.mockBehaviour(candles)
+
+			services := &service.Service{Candles: candles}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/market/candles", handler.candles)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/market/candles"+test.query, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+)
+
+// @Summary GetNotificationPreferences
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description get channels of notifications of user and events which user is notified about, webhook_secret
+// @Description signs notifications sent to webhook_url in X-Signature header: hex encoded HMAC-SHA256 of body prefixed by sha256=
+// @ID getNotificationPreferences
+// @Produce  json
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /settings/notifications [get]
+func (h *Handler) getNotificationPreferences(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	preferences, err := h.services.Notifications.GetNotificationPreferences(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// @Summary UpdateNotificationPreferences
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description update notification preferences of user: channel is on when its address is set (telegram chat id,
+// @Description email or https webhook url of public host). Events are order_filled, order_rejected, stop_loss_hit,
+// @Description take_profit_hit, session_closed, session_error and daily_summary, empty events mean all of them
+// @ID updateNotificationPreferences
+// @Accept  json
+// @Produce  json
+// @Param input body models.NotificationPreferences true "notification preferences"
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 400,401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /settings/notifications [put]
+func (h *Handler) updateNotificationPreferences(c *gin.Context) {
+	var input models.NotificationPreferences
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// webhook secret is set by server
+	input.WebhookSecret = ""
+	if err := h.validate.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err := h.services.Notifications.UpdateNotificationPreferences(userID, input); err != nil {
+		if errors.Is(err, service.ErrInvalidNotificationPreferences) {
+			newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, input)
+}
+
+// @Summary Notifications
+// @Security ApiKeyAuth
+// @Tags notifications
+// @Description get the last 100 notifications of user with their delivery statuses, the newest first
+// @ID notifications
+// @Produce  json
+// @Success 200 {object} []models.Notification
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /notifications [get]
+func (h *Handler) notifications(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	notifications, err := h.services.Notifications.GetUserNotifications(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"notifications": notifications,
+	})
+}
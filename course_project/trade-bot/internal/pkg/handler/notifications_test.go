@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+)
+
+func TestHandler_updateNotificationPreferences(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockNotifications, preferences models.NotificationPreferences)
+
+	tests := []struct {
+		name                string
+		inputBody           string
+		inputPreferences    models.NotificationPreferences
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"telegram_chat_id":42,"email":"user@mail.com","events":["order_filled","daily_summary"]}`,
+			inputPreferences: models.NotificationPreferences{TelegramChatID: 42, Email: "user@mail.com",
+				Events: []string{"order_filled", "daily_summary"}},
+			mockBehaviour: func(s *mockService.MockNotifications, preferences models.NotificationPreferences) {
+				s.EXPECT().UpdateNotificationPreferences(1, preferences).Return(nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"telegram_chat_id":42,"email":"user@mail.com","webhook_url":"",` +
+				`"events":["order_filled","daily_summary"]}`,
+		},
+		{
+			name:                "Invalid email",
+			inputBody:           `{"email":"user"}`,
+			mockBehaviour:       func(s *mockService.MockNotifications, preferences models.NotificationPreferences) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"message":"Key: 'NotificationPreferences.Email' Error:Field validation for 'Email' failed on the 'email' tag"}`,
+		},
+		{
+			name:                "Plain http webhook",
+			inputBody:           `{"webhook_url":"http://hooks.example.com/trade-bot"}`,
+			mockBehaviour:       func(s *mockService.MockNotifications, preferences models.NotificationPreferences) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"message":"Key: 'NotificationPreferences.WebhookURL' Error:Field validation for 'WebhookURL' failed on the 'startswith' tag"}`,
+		},
+		{
+			name:             "Webhook secret is ignored",
+			inputBody:        `{"webhook_url":"https://hooks.example.com/trade-bot","webhook_secret":"secret"}`,
+			inputPreferences: models.NotificationPreferences{WebhookURL: "https://hooks.example.com/trade-bot"},
+			mockBehaviour: func(s *mockService.MockNotifications, preferences models.NotificationPreferences) {
+				s.EXPECT().UpdateNotificationPreferences(1, preferences).Return(nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"telegram_chat_id":0,"email":"","webhook_url":"https://hooks.example.com/trade-bot",` +
+				`"events":null}`,
+		},
+		{
+			name:             "Unknown event",
+			inputBody:        `{"events":["moon"]}`,
+			inputPreferences: models.NotificationPreferences{Events: []string{"moon"}},
+			mockBehaviour: func(s *mockService.MockNotifications, preferences models.NotificationPreferences) {
+				s.EXPECT().UpdateNotificationPreferences(1, preferences).
+					Return(fmt.Errorf("%w: unknown event %q", service.ErrInvalidNotificationPreferences, "moon"))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"message":"invalid notification preferences: unknown event \"moon\""}`,
+		},
+		{
+			name:             "Service error",
+			inputBody:        `{"webhook_url":"https://hooks.example.com/trade-bot"}`,
+			inputPreferences: models.NotificationPreferences{WebhookURL: "https://hooks.example.com/trade-bot"},
+			mockBehaviour: func(s *mockService.MockNotifications, preferences models.NotificationPreferences) {
+				s.EXPECT().UpdateNotificationPreferences(1, preferences).Return(errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			notifications := mockService.NewMockNotifications(c)
+			test.mockBehaviour(notifications, test.inputPreferences)
+
+			services := &service.Service{Notifications: notifications}
+			handler := Handler{services, validator.New(), nil}
+
+			// test server
+			r := gin.New()
+			r.PUT("/settings/notifications", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.updateNotificationPreferences)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/settings/notifications",
+				bytes.NewBufferString(test.inputBody))
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_notifications(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockNotifications)
+
+	createdAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockNotifications) {
+				s.EXPECT().GetUserNotifications(1).Return([]models.Notification{{ID: 1, UserID: 1,
+					Event: models.EventOrderFilled, Channel: models.ChannelTelegram, Address: "42", DedupKey: "order_filled:1",
+					Subject: "Order filled", Body: "buy", Status: models.NotificationFailed, Attempts: 5,
+					LastError: "chat not found", NextAttemptAt: createdAt, CreatedAt: createdAt}}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"notifications":[{"id":1,"user_id":1,"event":"order_filled","channel":"telegram",` +
+				`"address":"42","subject":"Order filled","body":"buy","status":"failed","attempts":5,` +
+				`"last_error":"chat not found","next_attempt_at":"2021-12-01T00:00:00Z","created_at":"2021-12-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockNotifications) {
+				s.EXPECT().GetUserNotifications(1).Return(nil, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			notifications := mockService.NewMockNotifications(c)
+			test.mockBehaviour(notifications)
+
+			services := &service.Service{Notifications: notifications}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/notifications", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.notifications)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/notifications", nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
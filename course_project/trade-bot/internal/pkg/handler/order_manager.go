@@ -2,10 +2,17 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
 	"trade-bot/internal/pkg/tradeAlgorithm/types"
 	"trade-bot/pkg/krakenFuturesSDK"
 )
@@ -13,13 +20,14 @@ import (
 // @Summary SendOrder
 // @Security ApiKeyAuth
 // @Tags orderManager
-// @Description sendOrder to kraken futures API
+// @Description sendOrder to kraken futures API, order rejected by risk check gets 422 with code risk_rejected
 // @ID sendOrder
 // @Accept  json
 // @Produce  json
 // @Param input body krakenFuturesSDK.SendOrderArguments true "send order info"
 // @Success 200 {string} string "order_id"
 // @Failure 400,401,404 {object} errResponse
+// @Failure 422 {object} errResponse
 // @Failure 500 {object} errResponse
 // @Failure default {object} errResponse
 // @Router /orderManager/send-order [post]
@@ -39,13 +47,17 @@ func (h *Handler) sendOrder(c *gin.Context) {
 
 	order, err := h.services.KrakenOrdersManager.SendOrder(userID, input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newOrderErrorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, order)
 }
 
+var ErrInvalidEvent = errors.New("invalid event")
+
+// tradingDetails is a message of start-trade. Rules of TradingDetails are YAML or JSON document in string
+// or JSON object, they replace the other fields of TradingDetails and are validated with them by trading session
 type tradingDetails struct {
 	Event          string               `json:"event"`
 	TradingDetails types.TradingDetails `json:"trading_details,omitempty"`
@@ -70,20 +82,32 @@ func (h *Handler) startTrade(c *gin.Context) {
 
 	var input tradingDetails
 	if err := conn.ReadJSON(&input); err != nil {
-		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
-		return
-	}
-	if err := h.validate.Struct(input); err != nil {
 		newWebsocketErrResponse(c, http.StatusBadRequest, conn, err.Error())
 		return
 	}
+	if input.TradingDetails.Rules == nil {
+		if err := h.validate.Struct(input); err != nil {
+			newWebsocketErrResponse(c, http.StatusBadRequest, conn, err.Error())
+			return
+		}
+	}
 	if input.Event != startTrading {
-		newWebsocketErrResponse(c, http.StatusBadRequest, conn, err.Error())
+		newWebsocketErrResponse(c, http.StatusBadRequest, conn, fmt.Sprintf("%s: %s", ErrInvalidEvent, input.Event))
+		return
+	}
+
+	session, err := h.services.TradingSessions.StartSession(userID, input.TradingDetails)
+	if err != nil {
+		newWebsocketOrderErrResponse(c, conn, err)
+		return
+	}
+	if err := conn.WriteJSON(session); err != nil {
+		log.Error(err)
 		return
 	}
 
+	// session is run by supervisor, so disconnect of client only stops waiting for its result
 	ctx, cancel := context.WithCancel(context.Background())
-	var isCancelled bool
 	defer cancel()
 
 	go func() {
@@ -95,61 +119,139 @@ func (h *Handler) startTrade(c *gin.Context) {
 				return
 			}
 			if tradingDetails.Event == cancelEvent {
-				isCancelled = true
-				return
+				if _, err := h.services.TradingSessions.CancelSession(userID, session.ID); err != nil {
+					log.Warn(err)
+				}
 			}
 		}
 	}()
 
-	order, err := h.services.KrakenOrdersManager.StartTrading(ctx, userID, input.TradingDetails)
-	if err != nil && !isCancelled {
-		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
+	session, err = h.services.TradingSessions.WaitSession(ctx, userID, session.ID)
+	if ctx.Err() != nil {
 		return
 	}
-
-	if isCancelled {
-		err := conn.WriteJSON(struct {
-			Message string `json:"message"`
-		}{Message: "trading have been canceled"})
-
-		if err != nil {
-			newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
-			return
-		}
+	if err != nil {
+		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
 		return
 	}
 
-	if err := conn.WriteJSON(order); err != nil {
+	if err := conn.WriteJSON(session); err != nil {
 		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
 		return
 	}
 }
 
+var (
+	ErrInvalidOrdersTime  = errors.New("from and to must be in RFC3339 format")
+	ErrInvalidOrdersLimit = errors.New("limit must be a number")
+	ErrInvalidOrdersOrder = errors.New("order must be asc or desc")
+)
+
+const (
+	ascendingOrder  = "asc"
+	descendingOrder = "desc"
+)
+
 // @Summary MyOrders
 // @Security ApiKeyAuth
 // @Tags orderManager
-// @Description get all orders of user
+// @Description get page of orders of user, the latest orders go first by default. next_cursor of response is
+// @Description the cursor of the next page, it is empty on the last page
 // @ID myOrders
 // @Produce  json
-// @Success 200 {object} []models.Order
-// @Failure 401,404 {object} errResponse
+// @Param symbol query string false "symbol of orders"
+// @Param side query string false "buy or sell"
+// @Param status query string false "open, filled or cancelled"
+// @Param from query string false "the earliest time of orders, RFC3339"
+// @Param to query string false "time before the latest orders, RFC3339"
+// @Param sort query string false "timestamp (default), price or quantity"
+// @Param order query string false "desc (default) or asc"
+// @Param limit query int false "page size, 50 by default and 500 at most"
+// @Param cursor query string false "next_cursor of previous page"
+// @Success 200 {object} models.OrdersPage
+// @Failure 400,401,404 {object} errResponse
 // @Failure 500 {object} errResponse
 // @Failure default {object} errResponse
 // @Router /orderManager/my-orders [get]
 func (h *Handler) myOrders(c *gin.Context) {
+	filter, err := parseOrdersFilter(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	userID, err := getUserID(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	orders, err := h.services.KrakenOrdersManager.GetUserOrders(userID)
+	page, err := h.services.KrakenOrdersManager.GetUserOrders(userID, filter, c.Query("cursor"))
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidOrdersFilter) {
+			status = http.StatusBadRequest
+		}
+		newErrorResponse(c, status, err.Error())
 		return
 	}
 
+	c.JSON(http.StatusOK, page)
+}
+
+func parseOrdersFilter(c *gin.Context) (models.OrdersFilter, error) {
+	filter := models.OrdersFilter{
+		Symbol: c.Query("symbol"),
+		Side:   c.Query("side"),
+		Status: c.Query("status"),
+		SortBy: c.Query("sort"),
+	}
+
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return models.OrdersFilter{}, fmt.Errorf("%s: %s", ErrInvalidOrdersTime, value)
+		}
+		*param.value = t
+	}
+
+	switch order := c.DefaultQuery("order", descendingOrder); order {
+	case descendingOrder:
+		filter.Descending = true
+	case ascendingOrder:
+	default:
+		return models.OrdersFilter{}, fmt.Errorf("%s: %s", ErrInvalidOrdersOrder, order)
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return models.OrdersFilter{}, fmt.Errorf("%s: %s", ErrInvalidOrdersLimit, value)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// @Summary Strategies
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description get trading strategies with their parameters, which can be used in start-trade
+// @ID strategies
+// @Produce  json
+// @Success 200 {object} []types.StrategySchema
+// @Failure 401,404 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/strategies [get]
+func (h *Handler) strategies(c *gin.Context) {
 	c.JSON(http.StatusOK, map[string]interface{}{
-		"orders": orders,
+		"strategies": h.services.KrakenOrdersManager.GetStrategies(),
 	})
 }
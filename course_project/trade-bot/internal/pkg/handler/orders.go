@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/service"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var ErrEmptyEditOrder = errors.New("nothing to edit: size, limit_price or stop_price is required")
+
+type editOrderInput struct {
+	Size       uint    `json:"size"`
+	LimitPrice float64 `json:"limit_price" validate:"gte=0"`
+	StopPrice  float64 `json:"stop_price" validate:"gte=0"`
+	CliOrdID   string  `json:"cli_order_id"`
+}
+
+// @Summary EditOrder
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description change size or prices of resting order on kraken futures
+// @ID editOrder
+// @Accept  json
+// @Produce  json
+// @Param id path string true "order id"
+// @Param input body editOrderInput true "new size and prices of order"
+// @Success 200 {object} models.Order
+// @Failure 400,401,404,409 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/orders/{id} [put]
+func (h *Handler) editOrder(c *gin.Context) {
+	var input editOrderInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.validate.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.Size == 0 && input.LimitPrice == 0 && input.StopPrice == 0 {
+		newErrorResponse(c, http.StatusBadRequest, ErrEmptyEditOrder.Error())
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	order, err := h.services.KrakenOrdersManager.EditOrder(userID, krakenFuturesSDK.EditOrderArguments{
+		OrderID:    c.Param("id"),
+		Size:       input.Size,
+		LimitPrice: input.LimitPrice,
+		StopPrice:  input.StopPrice,
+		CliOrdID:   input.CliOrdID,
+	})
+	if err != nil {
+		newErrorResponse(c, orderErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// @Summary CancelOrder
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description cancel resting order on kraken futures
+// @ID cancelOrder
+// @Produce  json
+// @Param id path string true "order id"
+// @Success 200 {object} krakenFuturesSDK.CancelStatus
+// @Failure 401,404,409 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/orders/{id} [delete]
+func (h *Handler) cancelOrder(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	status, err := h.services.KrakenOrdersManager.CancelOrder(userID, krakenFuturesSDK.CancelOrderArguments{OrderID: c.Param("id")})
+	if err != nil {
+		newErrorResponse(c, orderErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// @Summary CancelAllOrders
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description cancel resting orders on kraken futures by symbol or of all symbols when it is not set
+// @ID cancelAllOrders
+// @Produce  json
+// @Param symbol query string false "symbol of orders"
+// @Success 200 {object} krakenFuturesSDK.CancelAllStatus
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/orders [delete]
+func (h *Handler) cancelAllOrders(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	status, err := h.services.KrakenOrdersManager.CancelAllOrders(userID, c.Query("symbol"))
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrOrderNotActive):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestHandler_editOrder(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockKrakenOrdersManager)
+
+	tests := []struct {
+		name                string
+		inputBody           string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"limit_price":95}`,
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager) {
+				s.EXPECT().EditOrder(1, krakenFuturesSDK.EditOrderArguments{OrderID: "order", LimitPrice: 95}).
+					Return(models.Order{ID: "order", UserID: 1, Price: 95, Status: models.OrderOpen}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"id":"order","user_id":1,"client_order_id":"","type":"","symbol":"","quantity":0,"side":"",` +
+				`"filled":0,"timestamp":"0001-01-01T00:00:00Z","last_update_timestamp":"0001-01-01T00:00:00Z","price":95,"status":"open","status_updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:                "Nothing to edit",
+			inputBody:           `{"cli_order_id":"id"}`,
+			mockBehaviour:       func(s *mockService.MockKrakenOrdersManager) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s"}`, ErrEmptyEditOrder),
+		},
+		{
+			name:      "Not found",
+			inputBody: `{"size":2}`,
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager) {
+				err := fmt.Errorf("%s: %w", service.ErrEditOrderServiceMethod, service.ErrOrderNotFound)
+				s.EXPECT().EditOrder(1, krakenFuturesSDK.EditOrderArguments{OrderID: "order", Size: 2}).
+					Return(models.Order{}, err)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: %s"}`, service.ErrEditOrderServiceMethod, service.ErrOrderNotFound),
+		},
+		{
+			name:      "Filled",
+			inputBody: `{"size":2}`,
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager) {
+				err := fmt.Errorf("%s: %w", service.ErrEditOrderServiceMethod, service.ErrOrderNotActive)
+				s.EXPECT().EditOrder(1, krakenFuturesSDK.EditOrderArguments{OrderID: "order", Size: 2}).
+					Return(models.Order{}, err)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: %s"}`, service.ErrEditOrderServiceMethod, service.ErrOrderNotActive),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			orders := mockService.NewMockKrakenOrdersManager(c)
+			test.mockBehaviour(orders)
+
+			services := &service.Service{KrakenOrdersManager: orders}
+			handler := Handler{services, validator.New(), nil}
+
+			// test server
+			r := gin.New()
+			r.PUT("/orders/:id", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.editOrder)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/orders/order", bytes.NewBufferString(test.inputBody))
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_cancelAllOrders(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	orders := mockService.NewMockKrakenOrdersManager(c)
+	orders.EXPECT().CancelAllOrders(1, "pi_xbtusd").Return(krakenFuturesSDK.CancelAllStatus{
+		Status:          "cancelled",
+		CancelOnly:      "pi_xbtusd",
+		CancelledOrders: []krakenFuturesSDK.CanceledOrder{{OrderID: "order"}},
+	}, nil)
+
+	services := &service.Service{KrakenOrdersManager: orders}
+	handler := Handler{services, nil, nil}
+
+	r := gin.New()
+	r.DELETE("/orders", func(c *gin.Context) {
+		c.Set(userIDCtx, 1)
+	}, handler.cancelAllOrders)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/orders?symbol=pi_xbtusd", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"cancelOnly":"pi_xbtusd","status":"cancelled","cancelledOrders":[{"order_id":"order"}]}`, w.Body.String())
+}
+
+func TestHandler_sendOrder(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockKrakenOrdersManager, args krakenFuturesSDK.SendOrderArguments)
+
+	args := krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "buy", Size: 20}
+	rejection := &service.RiskRejection{Rule: service.RiskRuleMaxOrderSize, Reason: "size 20 is larger than 10"}
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "Rejected by risk check",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, args krakenFuturesSDK.SendOrderArguments) {
+				s.EXPECT().SendOrder(1, args).Return(models.Order{}, fmt.Errorf("%s: %w", service.ErrSendOrderServiceMethod, rejection))
+			},
+			expectedStatusCode: 422,
+			expectedRequestBody: fmt.Sprintf(`{"code":"risk_rejected","message":"%s: %s"}`,
+				service.ErrSendOrderServiceMethod, rejection),
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, args krakenFuturesSDK.SendOrderArguments) {
+				s.EXPECT().SendOrder(1, args).Return(models.Order{}, fmt.Errorf("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			ordersManager := mockService.NewMockKrakenOrdersManager(c)
+			test.mockBehaviour(ordersManager, args)
+
+			services := &service.Service{KrakenOrdersManager: ordersManager}
+			handler := Handler{services, validator.New(), nil}
+
+			// test server
+			r := gin.New()
+			r.POST("/orderManager/send-order", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.sendOrder)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/orderManager/send-order",
+				bytes.NewBufferString(`{"order_type":"mkt","symbol":"PI_XBTUSD","side":"buy","size":20}`))
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_myOrders(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockKrakenOrdersManager)
+
+	from := time.Date(2021, 12, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?symbol=pi_xbtusd&side=buy&from=2021-12-20T00:00:00Z&sort=price&order=asc&limit=1&cursor=order-1",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager) {
+				filter := models.OrdersFilter{Symbol: "pi_xbtusd", Side: "buy", From: from, SortBy: "price", Limit: 1}
+				s.EXPECT().GetUserOrders(1, filter, "order-1").
+					Return(models.OrdersPage{Orders: []models.Order{{ID: "order-2"}}, NextCursor: "order-2"}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"orders":[{"id":"order-2","user_id":0,"client_order_id":"","type":"","symbol":"","quantity":0,` +
+				`"side":"","filled":0,"timestamp":"0001-01-01T00:00:00Z","last_update_timestamp":"0001-01-01T00:00:00Z",` +
+				`"price":0,"status":"","status_updated_at":"0001-01-01T00:00:00Z"}],"next_cursor":"order-2"}`,
+		},
+		{
+			name: "Latest orders by default",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager) {
+				s.EXPECT().GetUserOrders(1, models.OrdersFilter{Descending: true}, "").
+					Return(models.OrdersPage{Orders: []models.Order{}}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"orders":[]}`,
+		},
+		{
+			name:                "Invalid time",
+			query:               "?to=yesterday",
+			mockBehaviour:       func(s *mockService.MockKrakenOrdersManager) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: yesterday"}`, ErrInvalidOrdersTime),
+		},
+		{
+			name:                "Invalid order",
+			query:               "?order=up",
+			mockBehaviour:       func(s *mockService.MockKrakenOrdersManager) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: up"}`, ErrInvalidOrdersOrder),
+		},
+		{
+			name:  "Invalid filter",
+			query: "?side=long",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager) {
+				s.EXPECT().GetUserOrders(1, models.OrdersFilter{Side: "long", Descending: true}, "").
+					Return(models.OrdersPage{}, fmt.Errorf("%s: %w", service.ErrGetUserOrders, service.ErrInvalidOrdersFilter))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: %s"}`, service.ErrGetUserOrders, service.ErrInvalidOrdersFilter),
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager) {
+				s.EXPECT().GetUserOrders(1, models.OrdersFilter{Descending: true}, "").
+					Return(models.OrdersPage{}, fmt.Errorf("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			ordersManager := mockService.NewMockKrakenOrdersManager(c)
+			test.mockBehaviour(ordersManager)
+
+			services := &service.Service{KrakenOrdersManager: ordersManager}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/orderManager/my-orders", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.myOrders)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/orderManager/my-orders"+test.query, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
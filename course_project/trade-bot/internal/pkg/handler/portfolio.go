@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidSince = errors.New("since must be timestamp in milliseconds")
+
+// @Summary OpenOrders
+// @Security ApiKeyAuth
+// @Tags portfolio
+// @Description get resting orders of user on exchange including orders which were not sent through bot
+// @ID openOrders
+// @Produce  json
+// @Success 200 {object} []krakenFuturesSDK.OpenOrder
+// @Failure 401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /portfolio/open-orders [get]
+func (h *Handler) openOrders(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	openOrders, err := h.services.Portfolio.GetOpenOrders(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"open_orders": openOrders,
+	})
+}
+
+// @Summary Positions
+// @Security ApiKeyAuth
+// @Tags portfolio
+// @Description get open positions of user saved on the last sync with exchange
+// @ID positions
+// @Produce  json
+// @Success 200 {object} []models.Position
+// @Failure 401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /portfolio/positions [get]
+func (h *Handler) positions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	positions, err := h.services.Portfolio.GetPositions(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"positions": positions,
+	})
+}
+
+// @Summary Fills
+// @Security ApiKeyAuth
+// @Tags portfolio
+// @Description get fills of user saved by syncs with exchange, the newest first
+// @ID fills
+// @Produce  json
+// @Success 200 {object} []models.Fill
+// @Failure 401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /portfolio/fills [get]
+func (h *Handler) fills(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	fills, err := h.services.Portfolio.GetFills(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"fills": fills,
+	})
+}
+
+// @Summary Accounts
+// @Security ApiKeyAuth
+// @Tags portfolio
+// @Description get balances and margin of user accounts on exchange
+// @ID accounts
+// @Produce  json
+// @Success 200 {object} map[string]krakenFuturesSDK.Account
+// @Failure 401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /portfolio/accounts [get]
+func (h *Handler) accounts(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	accounts, err := h.services.Portfolio.GetAccounts(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"accounts": accounts,
+	})
+}
+
+// @Summary HistoricalOrders
+// @Security ApiKeyAuth
+// @Tags portfolio
+// @Description get order events of user on exchange
+// @ID historicalOrders
+// @Produce  json
+// @Param since query int false "timestamp in milliseconds"
+// @Success 200 {object} []krakenFuturesSDK.HistoricalOrderElement
+// @Failure 400,401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /portfolio/history [get]
+func (h *Handler) historicalOrders(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var since int64
+	if value := c.Query("since"); value != "" {
+		since, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || since < 0 {
+			newErrorResponse(c, http.StatusBadRequest, ErrInvalidSince.Error())
+			return
+		}
+	}
+
+	elements, err := h.services.Portfolio.GetHistoricalOrders(userID, since)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"elements": elements,
+	})
+}
+
+// @Summary Drifts
+// @Security ApiKeyAuth
+// @Tags portfolio
+// @Description get differences between orders and positions of bot and exchange found on the last sync
+// @ID drifts
+// @Produce  json
+// @Success 200 {object} []models.PortfolioDrift
+// @Failure 401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /portfolio/drifts [get]
+func (h *Handler) drifts(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	drifts, err := h.services.Portfolio.GetDrifts(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"drifts": drifts,
+	})
+}
+
+// @Summary SyncPortfolio
+// @Security ApiKeyAuth
+// @Tags portfolio
+// @Description sync fills, positions and orders of user with exchange right away without waiting for reconciler
+// @ID syncPortfolio
+// @Produce  json
+// @Success 200 {object} models.PortfolioSync
+// @Failure 401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /portfolio/sync [post]
+func (h *Handler) syncPortfolio(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	result, err := h.services.Portfolio.SyncPortfolio(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestHandler_historicalOrders(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockPortfolio)
+
+	tests := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:  "OK",
+			query: "?since=1640000000000",
+			mockBehaviour: func(s *mockService.MockPortfolio) {
+				s.EXPECT().GetHistoricalOrders(1, int64(1640000000000)).Return([]krakenFuturesSDK.HistoricalOrderElement{{
+					UID:       "event",
+					Timestamp: 1640000000001,
+					Event: map[string]krakenFuturesSDK.HistoricalOrderEvent{
+						"OrderCancelled": {Order: krakenFuturesSDK.HistoricalOrder{UID: "order"}, Reason: "cancelled_by_user"},
+					},
+				}}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"elements":[{"uid":"event","timestamp":1640000000001,"event":{"OrderCancelled":{"order":` +
+				`{"uid":"order","tradeable":"","direction":"","quantity":"0","filled":"0","limitPrice":"0","orderType":"",` +
+				`"reduceOnly":false,"timestamp":0,"lastUpdateTimestamp":0},"reason":"cancelled_by_user"}}}]}`,
+		},
+		{
+			name: "Without since",
+			mockBehaviour: func(s *mockService.MockPortfolio) {
+				s.EXPECT().GetHistoricalOrders(1, int64(0)).Return(nil, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"elements":null}`,
+		},
+		{
+			name:                "Invalid since",
+			query:               "?since=yesterday",
+			mockBehaviour:       func(s *mockService.MockPortfolio) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s"}`, ErrInvalidSince),
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockPortfolio) {
+				s.EXPECT().GetHistoricalOrders(1, int64(0)).Return(nil, errors.New("service error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"service error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			portfolio := mockService.NewMockPortfolio(c)
+			test.mockBehaviour(portfolio)
+
+			services := &service.Service{Portfolio: portfolio}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/history", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.historicalOrders)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/history"+test.query, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_syncPortfolio(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockPortfolio)
+
+	syncedAt := time.Date(2021, 12, 20, 11, 33, 20, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockPortfolio) {
+				s.EXPECT().SyncPortfolio(1).Return(models.PortfolioSync{
+					NewFills: 2,
+					Drifts: []models.PortfolioDrift{{UserID: 1, Kind: models.DriftPositionMismatch, Reference: "pi_xbtusd",
+						LocalState: "1", ExchangeState: "2", DetectedAt: syncedAt}},
+					SyncedAt: syncedAt,
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"new_fills":2,"updated_orders":0,"positions":null,"drifts":[{"id":0,"user_id":1,` +
+				`"kind":"position_mismatch","reference":"pi_xbtusd","local_state":"1","exchange_state":"2",` +
+				`"detected_at":"2021-12-20T11:33:20Z"}],"synced_at":"2021-12-20T11:33:20Z"}`,
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockPortfolio) {
+				s.EXPECT().SyncPortfolio(1).Return(models.PortfolioSync{}, errors.New("service error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"service error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			portfolio := mockService.NewMockPortfolio(c)
+			test.mockBehaviour(portfolio)
+
+			services := &service.Service{Portfolio: portfolio}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.POST("/sync", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.syncPortfolio)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/sync", nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrInvalidReportDay    = errors.New("day must be in format YYYY-MM-DD")
+	ErrInvalidReportFormat = errors.New("format must be json or csv")
+)
+
+const (
+	reportDayLayout  = "2006-01-02"
+	jsonReportFormat = "json"
+	csvReportFormat  = "csv"
+)
+
+var pnlCSVHeader = []string{"day", "symbol", "strategy", "realized_pnl", "unrealized_pnl", "fees", "closed_size", "open_size"}
+
+// @Summary PnL
+// @Security ApiKeyAuth
+// @Tags reports
+// @Description get realized and unrealized PnL of user in USD by day, symbol and strategy. Fills are matched FIFO
+// @Description per symbol, open positions are marked to mark price, fees are estimated by fee schedule
+// @ID pnl
+// @Produce  json,text/csv
+// @Param from query string false "first day, YYYY-MM-DD"
+// @Param to query string false "last day, YYYY-MM-DD"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} models.PnLReport
+// @Failure 400,401 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /reports/pnl [get]
+func (h *Handler) pnl(c *gin.Context) {
+	from, err := parseReportDay(c.Query("from"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := parseReportDay(c.Query("to"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := c.DefaultQuery("format", jsonReportFormat)
+	if format != jsonReportFormat && format != csvReportFormat {
+		newErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("%s: %s", ErrInvalidReportFormat, format))
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	report, err := h.services.Reports.GetPnLReport(userID, from, to)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if format == jsonReportFormat {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	data, err := pnlCSV(report)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="pnl.csv"`)
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+func parseReportDay(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	day, err := time.Parse(reportDayLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %s", ErrInvalidReportDay, value)
+	}
+	return day, nil
+}
+
+func pnlCSV(report models.PnLReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := make([][]string, 0, len(report.Rows)+1)
+	records = append(records, pnlCSVHeader)
+	for _, row := range report.Rows {
+		records = append(records, []string{
+			row.Day,
+			row.Symbol,
+			row.Strategy,
+			formatReportFloat(row.RealizedPnL),
+			formatReportFloat(row.UnrealizedPnL),
+			formatReportFloat(row.Fees),
+			formatReportFloat(row.ClosedSize),
+			formatReportFloat(row.OpenSize),
+		})
+	}
+
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func formatReportFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
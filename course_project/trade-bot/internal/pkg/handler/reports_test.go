@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+)
+
+func TestHandler_pnl(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockReports)
+
+	report := models.PnLReport{
+		Rows: []models.PnLRow{{Day: "2022-01-10", Symbol: "PF_ETHUSD", Strategy: "manual", RealizedPnL: 99.5,
+			UnrealizedPnL: -10, Fees: 0.5, ClosedSize: 1, OpenSize: 2}},
+		RealizedPnL:   99.5,
+		UnrealizedPnL: -10,
+		Fees:          0.5,
+	}
+	from := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		query               string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedContentType string
+		expectedRequestBody string
+	}{
+		{
+			name:  "JSON",
+			query: "?from=2022-01-10&to=2022-01-11",
+			mockBehaviour: func(s *mockService.MockReports) {
+				s.EXPECT().GetPnLReport(1, from, to).Return(report, nil)
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedRequestBody: `{"rows":[{"day":"2022-01-10","symbol":"PF_ETHUSD","strategy":"manual","realized_pnl":99.5,` +
+				`"unrealized_pnl":-10,"fees":0.5,"closed_size":1,"open_size":2}],"realized_pnl":99.5,"unrealized_pnl":-10,"fees":0.5}`,
+		},
+		{
+			name:  "CSV",
+			query: "?format=csv",
+			mockBehaviour: func(s *mockService.MockReports) {
+				s.EXPECT().GetPnLReport(1, time.Time{}, time.Time{}).Return(report, nil)
+			},
+			expectedStatusCode:  200,
+			expectedContentType: "text/csv",
+			expectedRequestBody: "day,symbol,strategy,realized_pnl,unrealized_pnl,fees,closed_size,open_size\n" +
+				"2022-01-10,PF_ETHUSD,manual,99.5,-10,0.5,1,2\n",
+		},
+		{
+			name:                "Invalid day",
+			query:               "?from=10.01.2022",
+			mockBehaviour:       func(s *mockService.MockReports) {},
+			expectedStatusCode:  400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: 10.01.2022"}`, ErrInvalidReportDay),
+		},
+		{
+			name:                "Invalid format",
+			query:               "?format=xml",
+			mockBehaviour:       func(s *mockService.MockReports) {},
+			expectedStatusCode:  400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: xml"}`, ErrInvalidReportFormat),
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockReports) {
+				s.EXPECT().GetPnLReport(1, time.Time{}, time.Time{}).Return(models.PnLReport{}, errors.New("service error"))
+			},
+			expectedStatusCode:  500,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedRequestBody: `{"message":"service error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			reports := mockService.NewMockReports(c)
+			test.mockBehaviour(reports)
+
+			services := &service.Service{Reports: reports}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/reports/pnl", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.pnl)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/reports/pnl"+test.query, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
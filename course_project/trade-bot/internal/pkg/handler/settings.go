@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"trade-bot/internal/pkg/models"
+)
+
+// @Summary GetSettings
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description get trading settings of user
+// @ID getSettings
+// @Produce  json
+// @Success 200 {object} models.UserSettings
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /settings [get]
+func (h *Handler) getSettings(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	settings, err := h.services.Settings.GetSettings(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// @Summary UpdateSettings
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description update trading settings of user, paper_trading switches orders to simulated exchange
+// @ID updateSettings
+// @Accept  json
+// @Produce  json
+// @Param input body models.UserSettings true "user settings"
+// @Success 200 {object} models.UserSettings
+// @Failure 400,401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /settings [put]
+func (h *Handler) updateSettings(c *gin.Context) {
+	var input models.UserSettings
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err := h.services.Settings.UpdateSettings(userID, input); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, input)
+}
+
+// @Summary GetRiskLimits
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description get risk limits which are checked before every order of user, zero limit is turned off
+// @ID getRiskLimits
+// @Produce  json
+// @Success 200 {object} models.RiskLimits
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /settings/risk [get]
+func (h *Handler) getRiskLimits(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	limits, err := h.services.Risk.GetRiskLimits(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// @Summary UpdateRiskLimits
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description update risk limits of user: max order size, max notional of position per symbol in USD,
+// @Description max open trading sessions and daily loss limit in USD, zero limit is turned off
+// @ID updateRiskLimits
+// @Accept  json
+// @Produce  json
+// @Param input body models.RiskLimits true "risk limits"
+// @Success 200 {object} models.RiskLimits
+// @Failure 400,401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /settings/risk [put]
+func (h *Handler) updateRiskLimits(c *gin.Context) {
+	var input models.RiskLimits
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.validate.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if err := h.services.Risk.UpdateRiskLimits(userID, input); err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, input)
+}
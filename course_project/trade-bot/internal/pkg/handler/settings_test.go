@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+)
+
+func TestHandler_updateSettings(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockSettings, settings models.UserSettings)
+
+	tests := []struct {
+		name                string
+		inputBody           string
+		inputSettings       models.UserSettings
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:          "OK",
+			inputBody:     `{"paper_trading":true}`,
+			inputSettings: models.UserSettings{PaperTrading: true},
+			mockBehaviour: func(s *mockService.MockSettings, settings models.UserSettings) {
+				s.EXPECT().UpdateSettings(1, settings).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"paper_trading":true}`,
+		},
+		{
+			name:                "Wrong Input",
+			inputBody:           `{"paper_trading":"yes"}`,
+			mockBehaviour:       func(s *mockService.MockSettings, settings models.UserSettings) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"message":"json: cannot unmarshal string into Go struct field UserSettings.paper_trading of type bool"}`,
+		},
+		{
+			name:          "Service error",
+			inputBody:     `{"paper_trading":false}`,
+			inputSettings: models.UserSettings{},
+			mockBehaviour: func(s *mockService.MockSettings, settings models.UserSettings) {
+				s.EXPECT().UpdateSettings(1, settings).Return(errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			settings := mockService.NewMockSettings(c)
+			test.mockBehaviour(settings, test.inputSettings)
+
+			services := &service.Service{Settings: settings}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.PUT("/settings", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.updateSettings)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/settings",
+				bytes.NewBufferString(test.inputBody))
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_updateRiskLimits(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockRisk, limits models.RiskLimits)
+
+	tests := []struct {
+		name                string
+		inputBody           string
+		inputLimits         models.RiskLimits
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:        "OK",
+			inputBody:   `{"max_order_size":10,"max_notional":50000,"max_open_sessions":2,"daily_loss_limit":300}`,
+			inputLimits: models.RiskLimits{MaxOrderSize: 10, MaxNotional: 50000, MaxOpenSessions: 2, DailyLossLimit: 300},
+			mockBehaviour: func(s *mockService.MockRisk, limits models.RiskLimits) {
+				s.EXPECT().UpdateRiskLimits(1, limits).Return(nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"max_order_size":10,"max_notional":50000,"max_open_sessions":2,"daily_loss_limit":300}`,
+		},
+		{
+			name:                "Negative limit",
+			inputBody:           `{"daily_loss_limit":-1}`,
+			mockBehaviour:       func(s *mockService.MockRisk, limits models.RiskLimits) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"message":"Key: 'RiskLimits.DailyLossLimit' Error:Field validation for 'DailyLossLimit' failed on the 'gte' tag"}`,
+		},
+		{
+			name:        "Service error",
+			inputBody:   `{"max_order_size":10}`,
+			inputLimits: models.RiskLimits{MaxOrderSize: 10},
+			mockBehaviour: func(s *mockService.MockRisk, limits models.RiskLimits) {
+				s.EXPECT().UpdateRiskLimits(1, limits).Return(errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			risk := mockService.NewMockRisk(c)
+			test.mockBehaviour(risk, test.inputLimits)
+
+			services := &service.Service{Risk: risk}
+			handler := Handler{services, validator.New(), nil}
+
+			// test server
+			r := gin.New()
+			r.PUT("/settings/risk", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.updateRiskLimits)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/settings/risk",
+				bytes.NewBufferString(test.inputBody))
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
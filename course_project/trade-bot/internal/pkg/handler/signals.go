@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/service"
+)
+
+const (
+	// signatureHeader keeps hex encoded HMAC-SHA256 of body of signal, it may be prefixed by sha256=
+	signatureHeader = "X-Signature"
+	// maxSignalSize is the largest size of body of signal in bytes
+	maxSignalSize = 64 << 10
+)
+
+// @Summary HandleSignal
+// @Tags signals
+// @Description trading signal of external tool sent to webhook of user, it must be signed by secret of webhook
+// @Description in X-Signature header: hex encoded HMAC-SHA256 of body, optionally prefixed by sha256=.
+// @Description Market order is sent by default, signal with stop_loss or take_profit price delta starts trading session
+// @Description which closes position by them. Signal with id of accepted signal is rejected with 409,
+// @Description signals with invalid signature are rejected with 429 when there are too many of them for token
+// @ID handleSignal
+// @Accept  json
+// @Produce  json
+// @Param token path string true "token of webhook"
+// @Param X-Signature header string true "HMAC-SHA256 of body"
+// @Param input body models.SignalPayload true "signal"
+// @Success 200 {object} models.Signal
+// @Failure 400,401,404,409 {object} errResponse
+// @Failure 422,429 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /signals/{token} [post]
+func (h *Handler) handleSignal(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignalSize))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	signal, err := h.services.Signals.HandleSignal(c.Param("token"), c.GetHeader(signatureHeader), body)
+	if err != nil {
+		newSignalErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, signal)
+}
+
+// newSignalErrorResponse responds to rejected signal, the ones rejected by risk check get distinct code like orders
+func newSignalErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSignalWebhookNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidSignature):
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrTooManySignatures):
+		newErrorResponse(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrInvalidSignal):
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrDuplicateSignal):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		newOrderErrorResponse(c, err)
+	}
+}
+
+// @Summary Signals
+// @Security ApiKeyAuth
+// @Tags signals
+// @Description get the last 100 signals received by webhook of user with reasons of rejected ones, the newest first
+// @ID signals
+// @Produce  json
+// @Success 200 {object} []models.Signal
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /signals [get]
+func (h *Handler) signals(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	signals, err := h.services.Signals.GetUserSignals(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"signals": signals,
+	})
+}
+
+// @Summary CreateSignalWebhook
+// @Security ApiKeyAuth
+// @Tags settings
+// @Description generate token and secret of webhook for signals of user, signals are sent to /signals/{token}.
+// @Description Secret is shown only once, the previous webhook of user stops working
+// @ID createSignalWebhook
+// @Produce  json
+// @Success 200 {object} models.SignalWebhook
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /settings/signals [post]
+func (h *Handler) createSignalWebhook(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	webhook, err := h.services.Signals.CreateSignalWebhook(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
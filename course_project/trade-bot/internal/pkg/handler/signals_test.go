@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+)
+
+func TestHandler_handleSignal(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockSignals, body []byte)
+
+	const body = `{"id":"s1","symbol":"PI_XBTUSD","side":"buy","size":1}`
+	receivedAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	rejection := &service.RiskRejection{Rule: service.RiskRuleMaxOrderSize, Reason: "size 20 is larger than 10"}
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).Return(models.Signal{ID: 1, UserID: 1,
+					SignalID: "s1", Status: models.SignalAccepted, Payload: string(body), OrderID: "order",
+					ReceivedAt: receivedAt}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: fmt.Sprintf(`{"id":1,"user_id":1,"signal_id":"s1","status":"accepted","payload":%q,`+
+				`"order_id":"order","received_at":"2021-12-01T00:00:00Z"}`, body),
+		},
+		{
+			name: "Unknown token",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).
+					Return(models.Signal{}, fmt.Errorf("%s: %w", service.ErrHandleSignal, service.ErrSignalWebhookNotFound))
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: `{"message":"handle signal: signal webhook not found"}`,
+		},
+		{
+			name: "Invalid signature",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).
+					Return(models.Signal{}, fmt.Errorf("%s: %w", service.ErrHandleSignal, service.ErrInvalidSignature))
+			},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"message":"handle signal: invalid signature of signal"}`,
+		},
+		{
+			name: "Too many invalid signatures",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).
+					Return(models.Signal{}, fmt.Errorf("%s: %w", service.ErrHandleSignal, service.ErrTooManySignatures))
+			},
+			expectedStatusCode:  429,
+			expectedRequestBody: `{"message":"handle signal: too many signals with invalid signature, try again later"}`,
+		},
+		{
+			name: "Invalid signal",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).
+					Return(models.Signal{}, fmt.Errorf("%w: size must be greater than 0", service.ErrInvalidSignal))
+			},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"message":"invalid signal: size must be greater than 0"}`,
+		},
+		{
+			name: "Duplicate",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).
+					Return(models.Signal{}, fmt.Errorf("%s: %w", service.ErrHandleSignal, service.ErrDuplicateSignal))
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: `{"message":"handle signal: signal with the same id is accepted already"}`,
+		},
+		{
+			name: "Rejected by risk check",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).Return(models.Signal{}, rejection)
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"risk_rejected","message":"rejected by risk check: max_order_size: size 20 is larger than 10"}`,
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockSignals, body []byte) {
+				s.EXPECT().HandleSignal("token", "sha256=abc", body).Return(models.Signal{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			signals := mockService.NewMockSignals(c)
+			test.mockBehaviour(signals, []byte(body))
+
+			services := &service.Service{Signals: signals}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.POST("/signals/:token", handler.handleSignal)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/signals/token", bytes.NewBufferString(body))
+			req.Header.Set(signatureHeader, "sha256=abc")
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_handleSignal_TooLarge(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	services := &service.Service{Signals: mockService.NewMockSignals(c)}
+	handler := Handler{services, nil, nil}
+
+	r := gin.New()
+	r.POST("/signals/:token", handler.handleSignal)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/signals/token", bytes.NewReader(make([]byte, maxSignalSize+1)))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"http: request body too large"}`, w.Body.String())
+}
+
+func TestHandler_createSignalWebhook(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockSignals)
+
+	createdAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockSignals) {
+				s.EXPECT().CreateSignalWebhook(1).Return(models.SignalWebhook{UserID: 1, Token: "token", Secret: "secret",
+					CreatedAt: createdAt}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"user_id":1,"token":"token","secret":"secret","created_at":"2021-12-01T00:00:00Z"}`,
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockSignals) {
+				s.EXPECT().CreateSignalWebhook(1).Return(models.SignalWebhook{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			signals := mockService.NewMockSignals(c)
+			test.mockBehaviour(signals)
+
+			services := &service.Service{Signals: signals}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.POST("/settings/signals", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.createSignalWebhook)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/settings/signals", nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/service"
+)
+
+var ErrInvalidSessionID = errors.New("invalid trading session id")
+
+// @Summary Sessions
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description get trading sessions of user, the newest first
+// @ID sessions
+// @Produce  json
+// @Success 200 {object} []models.TradingSession
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/sessions [get]
+func (h *Handler) sessions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	sessions, err := h.services.TradingSessions.GetUserSessions(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// @Summary Session
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description get trading session of user by id
+// @ID session
+// @Produce  json
+// @Param id path int true "session id"
+// @Success 200 {object} models.TradingSession
+// @Failure 400,401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/sessions/{id} [get]
+func (h *Handler) session(c *gin.Context) {
+	userID, sessionID, ok := h.sessionParams(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.services.TradingSessions.GetSession(userID, sessionID)
+	if err != nil {
+		newErrorResponse(c, sessionErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary CancelSession
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description stop trading session and close its position
+// @ID cancelSession
+// @Produce  json
+// @Param id path int true "session id"
+// @Success 200 {object} models.TradingSession
+// @Failure 400,401,404,409 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/sessions/{id} [delete]
+func (h *Handler) cancelSession(c *gin.Context) {
+	userID, sessionID, ok := h.sessionParams(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.services.TradingSessions.CancelSession(userID, sessionID)
+	if err != nil {
+		newErrorResponse(c, sessionErrorStatus(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *Handler) sessionParams(c *gin.Context) (int, int, bool) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return 0, 0, false
+	}
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, ErrInvalidSessionID.Error())
+		return 0, 0, false
+	}
+
+	return userID, sessionID, true
+}
+
+func sessionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSessionNotActive), errors.Is(err, service.ErrSessionNotRunning):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+)
+
+func TestHandler_cancelSession(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockTradingSessions)
+
+	tests := []struct {
+		name                string
+		sessionID           string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			sessionID: "1",
+			mockBehaviour: func(s *mockService.MockTradingSessions) {
+				s.EXPECT().CancelSession(1, 1).Return(models.TradingSession{ID: 1, UserID: 1, Status: models.SessionCancelling}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedRequestBody: `{"id":1,"user_id":1,"status":"cancelling","details":{"order_type":"","symbol":"","side":"","size":0,"BuyPrice":0},` +
+				`"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:                "Invalid id",
+			sessionID:           "first",
+			mockBehaviour:       func(s *mockService.MockTradingSessions) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s"}`, ErrInvalidSessionID),
+		},
+		{
+			name:      "Not found",
+			sessionID: "2",
+			mockBehaviour: func(s *mockService.MockTradingSessions) {
+				s.EXPECT().CancelSession(1, 2).Return(models.TradingSession{}, service.ErrSessionNotFound)
+			},
+			expectedStatusCode:  404,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s"}`, service.ErrSessionNotFound),
+		},
+		{
+			name:      "Not active",
+			sessionID: "3",
+			mockBehaviour: func(s *mockService.MockTradingSessions) {
+				err := fmt.Errorf("%s: %w", service.ErrCancelSession, service.ErrSessionNotActive)
+				s.EXPECT().CancelSession(1, 3).Return(models.TradingSession{}, err)
+			},
+			expectedStatusCode:  409,
+			expectedRequestBody: fmt.Sprintf(`{"message":"%s: %s"}`, service.ErrCancelSession, service.ErrSessionNotActive),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			sessions := mockService.NewMockTradingSessions(c)
+			test.mockBehaviour(sessions)
+
+			services := &service.Service{TradingSessions: sessions}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.DELETE("/sessions/:id", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.cancelSession)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/sessions/"+test.sessionID, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/service"
 )
 
 type websocketErrResponse struct {
+	Code    string `json:"code,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -17,3 +23,22 @@ func newWebsocketErrResponse(c *gin.Context, code int, ws *websocket.Conn, messa
 	c.AbortWithStatus(code)
 	log.Error(message)
 }
+
+// newWebsocketOrderErrResponse responds with distinct code and status to orders rejected by risk check,
+// invalid trading details get bad request status
+func newWebsocketOrderErrResponse(c *gin.Context, ws *websocket.Conn, err error) {
+	if errors.Is(err, service.ErrInvalidTradingDetails) {
+		newWebsocketErrResponse(c, http.StatusBadRequest, ws, err.Error())
+		return
+	}
+	if !errors.Is(err, service.ErrRiskRejected) {
+		newWebsocketErrResponse(c, http.StatusInternalServerError, ws, err.Error())
+		return
+	}
+
+	if err := ws.WriteJSON(websocketErrResponse{Code: riskRejectedCode, Message: err.Error()}); err != nil {
+		log.Error(err.Error())
+	}
+	c.AbortWithStatus(http.StatusUnprocessableEntity)
+	log.Warn(err.Error())
+}
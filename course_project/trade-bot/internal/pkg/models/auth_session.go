@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// AuthSession is a sign in of user from one device, it lives until refresh token expires or session is revoked.
+// Refresh token of session is rotated on every refresh, so only the latest one is valid
+type AuthSession struct {
+	ID          string    `json:"id"`
+	UserID      int       `json:"-"`
+	Device      string    `json:"device"`
+	IP          string    `json:"ip"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	AccessUUID  string    `json:"-"`
+	RefreshUUID string    `json:"-"`
+}
+
+// SessionClient describes client which signs in or refreshes tokens
+type SessionClient struct {
+	Device string
+	IP     string
+}
+
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is a lifetime of access token in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+const (
+	BacktestCSVSource  = "csv"
+	BacktestJSONSource = "json"
+	BacktestDBSource   = "db"
+)
+
+type BacktestInput struct {
+	TradingDetails types.TradingDetails `json:"trading_details" binding:"required"`
+	Candles        BacktestCandles      `json:"candles" binding:"required"`
+	// TakerFee is a fee of market order as a fraction of notional, fee schedule of kraken is used when omitted
+	TakerFee      *float64 `json:"taker_fee,omitempty"`
+	WarmupCandles int      `json:"warmup_candles,omitempty"`
+}
+
+type BacktestCandles struct {
+	// Source is one of csv, json or db
+	Source string `json:"source" binding:"required"`
+	// Data is a content of csv or json file with candles
+	Data string    `json:"data,omitempty"`
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Candle is OHLCV candle which starts at Time
+type Candle struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume int       `json:"volume"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Events which users are notified about
+const (
+	EventOrderFilled   = "order_filled"
+	EventOrderRejected = "order_rejected"
+	EventStopLossHit   = "stop_loss_hit"
+	EventTakeProfitHit = "take_profit_hit"
+	EventSessionClosed = "session_closed"
+	EventSessionError  = "session_error"
+	EventDailySummary  = "daily_summary"
+)
+
+// NotificationEvents are all events which users are notified about
+var NotificationEvents = []string{EventOrderFilled, EventOrderRejected, EventStopLossHit, EventTakeProfitHit,
+	EventSessionClosed, EventSessionError, EventDailySummary}
+
+// Channels of notifications
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook"
+)
+
+// Statuses of notification in outbox. Pending notification is sent until it succeeds or runs out of attempts
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+)
+
+// NotificationPreferences tell where user is notified, channel is on when its address is set.
+// User is notified about Events only, empty Events mean all of them
+type NotificationPreferences struct {
+	// TelegramChatID is an id of chat of user with telegram bot
+	TelegramChatID int64  `json:"telegram_chat_id"`
+	Email          string `json:"email" validate:"omitempty,email"`
+	WebhookURL     string `json:"webhook_url" validate:"omitempty,url,startswith=https://"`
+	// WebhookSecret signs notifications sent to webhook, it is set by server and returned along with webhook url
+	WebhookSecret string   `json:"webhook_secret,omitempty"`
+	Events        []string `json:"events"`
+}
+
+// Addresses returns addresses of user by channels which are on
+func (p NotificationPreferences) Addresses() map[string]string {
+	addresses := make(map[string]string)
+	if p.TelegramChatID != 0 {
+		addresses[ChannelTelegram] = strconv.FormatInt(p.TelegramChatID, 10)
+	}
+	if p.Email != "" {
+		addresses[ChannelEmail] = p.Email
+	}
+	if p.WebhookURL != "" {
+		addresses[ChannelWebhook] = p.WebhookURL
+	}
+	return addresses
+}
+
+// Subscribed reports whether user is notified about event
+func (p NotificationPreferences) Subscribed(event string) bool {
+	if len(p.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range p.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// TradingEvent is an event of user which is sent by every channel of user subscribed to it.
+// Event with the same non-empty Key is sent only once
+type TradingEvent struct {
+	UserID  int
+	Event   string
+	Key     string
+	Subject string
+	Body    string
+}
+
+// Notification is a message of outbox sent to Address of Channel. It is retried at NextAttemptAt after
+// failed attempt, LastError is the error of the last one
+type Notification struct {
+	ID            int        `json:"id" db:"id"`
+	UserID        int        `json:"user_id" db:"user_id"`
+	Event         string     `json:"event" db:"event"`
+	Channel       string     `json:"channel" db:"channel"`
+	Address       string     `json:"address" db:"address"`
+	DedupKey      string     `json:"-" db:"dedup_key"`
+	Subject       string     `json:"subject" db:"subject"`
+	Body          string     `json:"body" db:"body"`
+	Status        string     `json:"status" db:"status"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     string     `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+}
@@ -1,15 +1,63 @@
 package models
 
+import "time"
+
+// Statuses of order. Order is open while it rests in order book of exchange
+const (
+	OrderOpen      = "open"
+	OrderFilled    = "filled"
+	OrderCancelled = "cancelled"
+)
+
+// Fields which order history can be sorted by
+const (
+	OrdersSortTimestamp = "timestamp"
+	OrdersSortPrice     = "price"
+	OrdersSortQuantity  = "quantity"
+)
+
 type Order struct {
-	ID                  string  `json:"id" db:"order_id"`
-	UserID              int     `json:"user_id" db:"user_id"`
-	ClientOrderID       string  `json:"client_order_id" db:"cli_order_id"`
-	Type                string  `json:"type" db:"type"`
-	Symbol              string  `json:"symbol" db:"symbol"`
-	Quantity            float64 `json:"quantity" db:"quantity"`
-	Side                string  `json:"side" db:"side"`
-	Filled              float64 `json:"filled" db:"filled"`
-	Timestamp           string  `json:"timestamp" db:"timestamp"`
-	LastUpdateTimestamp string  `json:"last_update_timestamp" db:"last_update_timestamp"`
-	Price               float64 `json:"price" db:"price"`
+	ID                  string    `json:"id" db:"order_id"`
+	UserID              int       `json:"user_id" db:"user_id"`
+	ClientOrderID       string    `json:"client_order_id" db:"cli_order_id"`
+	Type                string    `json:"type" db:"type"`
+	Symbol              string    `json:"symbol" db:"symbol"`
+	Quantity            float64   `json:"quantity" db:"quantity"`
+	Side                string    `json:"side" db:"side"`
+	Filled              float64   `json:"filled" db:"filled"`
+	Timestamp           time.Time `json:"timestamp" db:"timestamp"`
+	LastUpdateTimestamp time.Time `json:"last_update_timestamp" db:"last_update_timestamp"`
+	Price               float64   `json:"price" db:"price"`
+	Status              string    `json:"status" db:"status"`
+	StatusUpdatedAt     time.Time `json:"status_updated_at" db:"status_updated_at"`
+}
+
+// ParseOrderTime parses RFC3339 time of order reported by exchange, fallback is returned when time is invalid
+func ParseOrderTime(value string, fallback time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fallback
+	}
+	return t.UTC()
+}
+
+// OrdersFilter selects page of order history of user, empty fields do not filter orders.
+// Orders are sorted by SortBy and then by id, After is the last order of previous page
+type OrdersFilter struct {
+	Symbol     string
+	Side       string
+	Status     string
+	From       time.Time
+	To         time.Time
+	SortBy     string
+	Descending bool
+	After      *Order
+	// Limit is a maximum count of orders, zero does not limit them
+	Limit int
+}
+
+// OrdersPage is a page of order history, NextCursor is empty on the last page
+type OrdersPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,25 @@
+package models
+
+// ManualStrategy is a strategy of fills of orders which were not sent by trading sessions
+const ManualStrategy = "manual"
+
+// PnLRow is PnL of symbol traded by strategy on UTC day in USD. Realized PnL of positions closed on the day
+// and unrealized PnL of positions opened on the day are net of fees, which are included in Fees
+type PnLRow struct {
+	Day           string  `json:"day"`
+	Symbol        string  `json:"symbol"`
+	Strategy      string  `json:"strategy"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Fees          float64 `json:"fees"`
+	ClosedSize    float64 `json:"closed_size"`
+	OpenSize      float64 `json:"open_size"`
+}
+
+// PnLReport is PnL of fills of user matched FIFO per symbol with totals of its rows
+type PnLReport struct {
+	Rows          []PnLRow `json:"rows"`
+	RealizedPnL   float64  `json:"realized_pnl"`
+	UnrealizedPnL float64  `json:"unrealized_pnl"`
+	Fees          float64  `json:"fees"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Kinds of drift between state saved by bot and state of exchange
+const (
+	// DriftUnknownOrder is an order resting on exchange which is not open in orders of bot
+	DriftUnknownOrder = "unknown_order"
+	// DriftMissingOrder is an open order of bot which is gone from exchange without fill
+	DriftMissingOrder = "missing_order"
+	// DriftPositionMismatch is a position on exchange which differs from positions of active trading sessions
+	DriftPositionMismatch = "position_mismatch"
+)
+
+// Fill is an execution of order of user on exchange
+type Fill struct {
+	ID            string    `json:"fill_id" db:"fill_id"`
+	UserID        int       `json:"user_id" db:"user_id"`
+	OrderID       string    `json:"order_id" db:"order_id"`
+	ClientOrderID string    `json:"client_order_id,omitempty" db:"cli_order_id"`
+	Symbol        string    `json:"symbol" db:"symbol"`
+	Side          string    `json:"side" db:"side"`
+	Size          float64   `json:"size" db:"size"`
+	Price         float64   `json:"price" db:"price"`
+	FillType      string    `json:"fill_type" db:"fill_type"`
+	FillTime      time.Time `json:"fill_time" db:"fill_time"`
+}
+
+// Position is an open position of user on exchange, side is long or short
+type Position struct {
+	UserID    int       `json:"user_id" db:"user_id"`
+	Symbol    string    `json:"symbol" db:"symbol"`
+	Side      string    `json:"side" db:"side"`
+	Size      float64   `json:"size" db:"size"`
+	Price     float64   `json:"price" db:"price"`
+	FillTime  time.Time `json:"fill_time" db:"fill_time"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PortfolioDrift is a difference between state saved by bot and state of exchange found on the last sync.
+// Reference is id of order or symbol of position
+type PortfolioDrift struct {
+	ID            int       `json:"id" db:"id"`
+	UserID        int       `json:"user_id" db:"user_id"`
+	Kind          string    `json:"kind" db:"kind"`
+	Reference     string    `json:"reference" db:"reference"`
+	LocalState    string    `json:"local_state" db:"local_state"`
+	ExchangeState string    `json:"exchange_state" db:"exchange_state"`
+	DetectedAt    time.Time `json:"detected_at" db:"detected_at"`
+}
+
+// PortfolioSync is a result of sync of user portfolio with exchange
+type PortfolioSync struct {
+	NewFills      int              `json:"new_fills"`
+	UpdatedOrders int              `json:"updated_orders"`
+	Positions     []Position       `json:"positions"`
+	Drifts        []PortfolioDrift `json:"drifts"`
+	SyncedAt      time.Time        `json:"synced_at"`
+}
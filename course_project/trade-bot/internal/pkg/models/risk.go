@@ -0,0 +1,14 @@
+package models
+
+// RiskLimits are checked before every order of user which may open or increase position,
+// zero value of limit turns it off
+type RiskLimits struct {
+	// MaxOrderSize is the largest size of order in contracts
+	MaxOrderSize uint `json:"max_order_size" db:"max_order_size"`
+	// MaxNotional is the largest value in USD of position of one symbol including the order
+	MaxNotional float64 `json:"max_notional" db:"max_notional" validate:"gte=0"`
+	// MaxOpenSessions is the largest number of active trading sessions
+	MaxOpenSessions int `json:"max_open_sessions" db:"max_open_sessions" validate:"gte=0"`
+	// DailyLossLimit is the largest loss in USD realized by orders of the current UTC day
+	DailyLossLimit float64 `json:"daily_loss_limit" db:"daily_loss_limit" validate:"gte=0"`
+}
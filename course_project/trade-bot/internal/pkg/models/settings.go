@@ -0,0 +1,7 @@
+package models
+
+// UserSettings are trading preferences of user
+type UserSettings struct {
+	// PaperTrading switches orders of user from kraken to simulated exchange
+	PaperTrading bool `json:"paper_trading" db:"paper_trading"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Statuses of signal received by webhook. Signal is pending while its order or trading session is being started
+const (
+	SignalPending  = "pending"
+	SignalAccepted = "accepted"
+	SignalRejected = "rejected"
+)
+
+// SignalWebhook is a webhook of user for signals of external tools. Signals are sent to path with Token
+// and signed by HMAC-SHA256 with Secret, which is never sent along with them
+type SignalWebhook struct {
+	UserID    int       `json:"user_id" db:"user_id"`
+	Token     string    `json:"token" db:"token"`
+	Secret    string    `json:"secret" db:"secret"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// SignalPayload is a trading signal of external tool, market order is sent by default. Signal with
+// StopLoss or TakeProfit starts trading session which closes position when price moves away from
+// entry price by one of them
+type SignalPayload struct {
+	ID         string  `json:"id"`
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"`
+	Size       uint    `json:"size"`
+	OrderType  string  `json:"order_type,omitempty"`
+	LimitPrice float64 `json:"limit_price,omitempty"`
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+}
+
+// Signal is a log entry of signal received by webhook, Reason tells why it is rejected
+type Signal struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	SignalID   string    `json:"signal_id" db:"signal_id"`
+	Status     string    `json:"status" db:"status"`
+	Reason     string    `json:"reason,omitempty" db:"reason"`
+	Payload    string    `json:"payload" db:"payload"`
+	OrderID    string    `json:"order_id,omitempty" db:"order_id"`
+	SessionID  int       `json:"session_id,omitempty" db:"session_id"`
+	ReceivedAt time.Time `json:"received_at" db:"received_at"`
+}
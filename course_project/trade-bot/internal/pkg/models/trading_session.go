@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+// Statuses of trading session. Session is active until it is closed, cancelled or failed.
+// Session of rules with entry conditions is waiting for entry until they are met
+const (
+	SessionWaitingEntry = "waiting_entry"
+	SessionStarting     = "starting"
+	SessionMonitoring   = "monitoring"
+	SessionCancelling   = "cancelling"
+	SessionClosing      = "closing"
+	SessionClosed       = "closed"
+	SessionCancelled    = "cancelled"
+	SessionFailed       = "failed"
+)
+
+// TradingSession is a position opened by entry order which is watched by trading strategy
+// and closed by exit order. In bracket mode position is also protected by stop loss and
+// take profit orders resting on exchange, one of them becomes exit order when it is filled
+type TradingSession struct {
+	ID      int                  `json:"id" db:"id"`
+	UserID  int                  `json:"user_id" db:"user_id"`
+	Status  string               `json:"status" db:"status"`
+	Details types.TradingDetails `json:"details" db:"details"`
+	// PaperTrading is set when session is started on simulated exchange of user
+	PaperTrading      bool       `json:"paper_trading,omitempty" db:"paper_trading"`
+	EntryOrderID      string     `json:"entry_order_id,omitempty" db:"entry_order_id"`
+	EntryPrice        float64    `json:"entry_price,omitempty" db:"entry_price"`
+	EntryTime         *time.Time `json:"entry_time,omitempty" db:"entry_time"`
+	StopLossOrderID   string     `json:"stop_loss_order_id,omitempty" db:"stop_loss_order_id"`
+	TakeProfitOrderID string     `json:"take_profit_order_id,omitempty" db:"take_profit_order_id"`
+	ExitOrderID       string     `json:"exit_order_id,omitempty" db:"exit_order_id"`
+	ExitPrice         float64    `json:"exit_price,omitempty" db:"exit_price"`
+	Error             string     `json:"error,omitempty" db:"error"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+func (s TradingSession) IsActive() bool {
+	switch s.Status {
+	case SessionClosed, SessionCancelled, SessionFailed:
+		return false
+	default:
+		return true
+	}
+}
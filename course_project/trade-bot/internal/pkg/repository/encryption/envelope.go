@@ -0,0 +1,173 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNewKeyRing            = errors.New("new key ring")
+	ErrSeal                  = errors.New("seal")
+	ErrOpen                  = errors.New("open")
+	ErrUnknownKeyVersion     = errors.New("unknown master key version")
+	ErrInvalidMasterKey      = errors.New("invalid master key")
+	ErrCiphertextTooShort    = errors.New("ciphertext too short")
+	ErrCurrentKeyNotProvided = errors.New("current master key not provided")
+)
+
+// PlaintextKeyVersion marks values which have been stored before encryption was enabled
+const PlaintextKeyVersion = 0
+
+const dataKeySize = 32
+
+// SealedValues is a set of values encrypted with one data key.
+// Data key itself is encrypted with master key of KeyVersion
+type SealedValues struct {
+	DataKey    string
+	KeyVersion int
+	Values     []string
+}
+
+// KeyRing makes envelope encryption with AES-GCM. Every sealed set of values gets its own
+// random data key, which is wrapped with current master key
+type KeyRing struct {
+	masterKeys     map[int][]byte
+	currentVersion int
+}
+
+// NewKeyRing creates key ring from base64 encoded master keys of size 16, 24 or 32 bytes
+func NewKeyRing(masterKeys map[int]string, currentVersion int) (*KeyRing, error) {
+	keys := make(map[int][]byte, len(masterKeys))
+	for version, encodedKey := range masterKeys {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: version %d: %w", ErrNewKeyRing, ErrInvalidMasterKey, version, err)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("%s: %s: version %d: %w", ErrNewKeyRing, ErrInvalidMasterKey, version, err)
+		}
+		keys[version] = key
+	}
+
+	if _, ok := keys[currentVersion]; !ok {
+		return nil, fmt.Errorf("%s: %s: version %d", ErrNewKeyRing, ErrCurrentKeyNotProvided, currentVersion)
+	}
+
+	return &KeyRing{masterKeys: keys, currentVersion: currentVersion}, nil
+}
+
+func (k *KeyRing) CurrentVersion() int {
+	return k.currentVersion
+}
+
+// Seal encrypts values with new data key wrapped by current master key
+func (k *KeyRing) Seal(values ...string) (SealedValues, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return SealedValues{}, fmt.Errorf("%s: %w", ErrSeal, err)
+	}
+
+	wrappedDataKey, err := encrypt(k.masterKeys[k.currentVersion], dataKey)
+	if err != nil {
+		return SealedValues{}, fmt.Errorf("%s: %w", ErrSeal, err)
+	}
+
+	sealed := SealedValues{
+		DataKey:    wrappedDataKey,
+		KeyVersion: k.currentVersion,
+		Values:     make([]string, 0, len(values)),
+	}
+	for _, value := range values {
+		encrypted, err := encrypt(dataKey, []byte(value))
+		if err != nil {
+			return SealedValues{}, fmt.Errorf("%s: %w", ErrSeal, err)
+		}
+		sealed.Values = append(sealed.Values, encrypted)
+	}
+
+	return sealed, nil
+}
+
+// Open decrypts values. Values of PlaintextKeyVersion are returned as is
+func (k *KeyRing) Open(sealed SealedValues) ([]string, error) {
+	if sealed.KeyVersion == PlaintextKeyVersion {
+		return sealed.Values, nil
+	}
+
+	masterKey, ok := k.masterKeys[sealed.KeyVersion]
+	if !ok {
+		return nil, fmt.Errorf("%s: %s: version %d", ErrOpen, ErrUnknownKeyVersion, sealed.KeyVersion)
+	}
+
+	dataKey, err := decrypt(masterKey, sealed.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrOpen, err)
+	}
+
+	values := make([]string, 0, len(sealed.Values))
+	for _, value := range sealed.Values {
+		decrypted, err := decrypt(dataKey, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrOpen, err)
+		}
+		values = append(values, string(decrypted))
+	}
+
+	return values, nil
+}
+
+// Rotate re-encrypts values with new data key wrapped by current master key
+func (k *KeyRing) Rotate(sealed SealedValues) (SealedValues, error) {
+	values, err := k.Open(sealed)
+	if err != nil {
+		return SealedValues{}, err
+	}
+	return k.Seal(values...)
+}
+
+// encrypt returns base64 encoded nonce with ciphertext
+func encrypt(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func decrypt(key []byte, encoded string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrCiphertextTooShort
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
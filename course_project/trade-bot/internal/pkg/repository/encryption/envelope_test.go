@@ -0,0 +1,90 @@
+package encryption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	masterKeyV1 = "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+	masterKeyV2 = "YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXphYmNkZWY="
+)
+
+func TestKeyRing_SealOpenRotate(t *testing.T) {
+	oldKeyRing, err := NewKeyRing(map[int]string{1: masterKeyV1}, 1)
+	assert.NoError(t, err)
+
+	sealed, err := oldKeyRing.Seal("public", "private")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sealed.KeyVersion)
+	assert.NotContains(t, sealed.Values, "public")
+	assert.NotContains(t, sealed.Values, "private")
+
+	values, err := oldKeyRing.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"public", "private"}, values)
+
+	newKeyRing, err := NewKeyRing(map[int]string{1: masterKeyV1, 2: masterKeyV2}, 2)
+	assert.NoError(t, err)
+
+	rotated, err := newKeyRing.Rotate(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rotated.KeyVersion)
+
+	values, err = newKeyRing.Open(rotated)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"public", "private"}, values)
+
+	_, err = oldKeyRing.Open(rotated)
+	assert.Error(t, err)
+}
+
+func TestKeyRing_OpenPlaintext(t *testing.T) {
+	keyRing, err := NewKeyRing(map[int]string{1: masterKeyV1}, 1)
+	assert.NoError(t, err)
+
+	values, err := keyRing.Open(SealedValues{KeyVersion: PlaintextKeyVersion, Values: []string{"public", "private"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"public", "private"}, values)
+}
+
+func TestKeyRing_OpenTampered(t *testing.T) {
+	keyRing, err := NewKeyRing(map[int]string{1: masterKeyV1}, 1)
+	assert.NoError(t, err)
+
+	sealed, err := keyRing.Seal("public")
+	assert.NoError(t, err)
+
+	other, err := keyRing.Seal("other")
+	assert.NoError(t, err)
+	sealed.Values = other.Values
+
+	_, err = keyRing.Open(sealed)
+	assert.Error(t, err)
+}
+
+func TestNewKeyRing(t *testing.T) {
+	tests := []struct {
+		name       string
+		masterKeys map[int]string
+		current    int
+		wantErr    bool
+	}{
+		{name: "OK", masterKeys: map[int]string{1: masterKeyV1}, current: 1},
+		{name: "Current key not provided", masterKeys: map[int]string{1: masterKeyV1}, current: 2, wantErr: true},
+		{name: "Invalid base64", masterKeys: map[int]string{1: "???"}, current: 1, wantErr: true},
+		{name: "Invalid key size", masterKeys: map[int]string{1: "a2V5"}, current: 1, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewKeyRing(test.masterKeys, test.current)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,43 +1,176 @@
 package postgresRepo
 
 import (
+	"fmt"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 
 	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository/encryption"
+)
+
+var (
+	ErrEncryptAPIKeys = errors.New("encrypt api keys")
+	ErrDecryptAPIKeys = errors.New("decrypt api keys")
+	ErrRotateAPIKeys  = errors.New("rotate api keys")
 )
 
 type AuthPostgres struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	keyRing *encryption.KeyRing
+}
+
+func NewAuthPostgres(db *sqlx.DB, keyRing *encryption.KeyRing) *AuthPostgres {
+	return &AuthPostgres{db: db, keyRing: keyRing}
+}
+
+// userRow is a users table row with api keys in encrypted form
+type userRow struct {
+	models.User
+	DataKey    string `db:"api_keys_data_key"`
+	KeyVersion int    `db:"api_keys_key_version"`
 }
 
-func NewAuthPostgres(db *sqlx.DB) *AuthPostgres {
-	return &AuthPostgres{db: db}
+func (u *userRow) sealedAPIKeys() encryption.SealedValues {
+	return encryption.SealedValues{
+		DataKey:    u.DataKey,
+		KeyVersion: u.KeyVersion,
+		Values:     []string{u.PublicAPIKey, u.PrivateAPIKey},
+	}
+}
+
+func (r *AuthPostgres) decryptUser(row userRow) (models.User, error) {
+	keys, err := r.keyRing.Open(row.sealedAPIKeys())
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", ErrDecryptAPIKeys, err)
+	}
+
+	user := row.User
+	user.PublicAPIKey, user.PrivateAPIKey = keys[0], keys[1]
+	return user, nil
 }
 
 const insertUserQuery = `
 	INSERT INTO users
-    (name, username, password_hash, public_api_key, private_api_key) values ($1, $2, $3, $4, $5)
+    (name, username, password_hash, public_api_key, private_api_key, api_keys_data_key, api_keys_key_version)
+    values ($1, $2, $3, $4, $5, $6, $7)
     RETURNING id`
 
 func (r *AuthPostgres) CreateUser(user models.User) (int, error) {
+	sealed, err := r.keyRing.Seal(user.PublicAPIKey, user.PrivateAPIKey)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrEncryptAPIKeys, err)
+	}
+
 	var id int
-	row := r.db.QueryRow(insertUserQuery, user.Name, user.Username, user.Password, user.PublicAPIKey, user.PrivateAPIKey)
-	err := row.Scan(&id)
+	row := r.db.QueryRow(insertUserQuery, user.Name, user.Username, user.Password,
+		sealed.Values[0], sealed.Values[1], sealed.DataKey, sealed.KeyVersion)
+	err = row.Scan(&id)
 	return id, err
 }
 
 const getUserQuery = "SELECT * FROM users WHERE username=$1"
 
 func (r *AuthPostgres) GetUser(username string) (models.User, error) {
-	var user models.User
-	err := r.db.Get(&user, getUserQuery, username)
-	return user, err
+	var row userRow
+	if err := r.db.Get(&row, getUserQuery, username); err != nil {
+		return models.User{}, err
+	}
+	return r.decryptUser(row)
 }
 
 const getUserAPIKeysQuery = "SELECT * FROM users WHERE id=$1"
 
 func (r *AuthPostgres) GetUserAPIKeys(userID int) (string, string, error) {
-	var user models.User
-	err := r.db.Get(&user, getUserAPIKeysQuery, userID)
-	return user.PublicAPIKey, user.PrivateAPIKey, err
+	var row userRow
+	if err := r.db.Get(&row, getUserAPIKeysQuery, userID); err != nil {
+		return "", "", err
+	}
+
+	user, err := r.decryptUser(row)
+	if err != nil {
+		return "", "", err
+	}
+	return user.PublicAPIKey, user.PrivateAPIKey, nil
+}
+
+const getUserIDsQuery = "SELECT id FROM users ORDER BY id"
+
+// GetUserIDs returns ids of all users
+func (r *AuthPostgres) GetUserIDs() ([]int, error) {
+	var ids []int
+	if err := r.db.Select(&ids, getUserIDsQuery); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+const getUsersToRotateQuery = `
+	SELECT * FROM users WHERE api_keys_key_version <> $1 FOR UPDATE`
+
+const updateUserAPIKeysQuery = `
+	UPDATE users
+	SET public_api_key=$1, private_api_key=$2, api_keys_data_key=$3, api_keys_key_version=$4
+	WHERE id=$5`
+
+// RotateAPIKeys re-encrypts api keys of every user which are not encrypted with current master key.
+// Returns count of rotated users
+func (r *AuthPostgres) RotateAPIKeys() (int, error) {
+	rotated, err := r.resealAPIKeys(r.keyRing.CurrentVersion(), r.keyRing.Rotate)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrRotateAPIKeys, err)
+	}
+	return rotated, nil
+}
+
+// DecryptAPIKeys stores api keys of every user in plaintext, so migration which encrypted them
+// can be rolled back. Returns count of decrypted users
+func (r *AuthPostgres) DecryptAPIKeys() (int, error) {
+	decrypted, err := r.resealAPIKeys(encryption.PlaintextKeyVersion, func(sealed encryption.SealedValues) (encryption.SealedValues, error) {
+		values, err := r.keyRing.Open(sealed)
+		if err != nil {
+			return encryption.SealedValues{}, err
+		}
+		return encryption.SealedValues{KeyVersion: encryption.PlaintextKeyVersion, Values: values}, nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrDecryptAPIKeys, err)
+	}
+	return decrypted, nil
+}
+
+// resealAPIKeys replaces api keys of every user which are not sealed with key version by resealed ones
+// in one transaction. Returns count of resealed users
+func (r *AuthPostgres) resealAPIKeys(keyVersion int, reseal func(encryption.SealedValues) (encryption.SealedValues, error)) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	var rows []userRow
+	if err := tx.Select(&rows, getUsersToRotateQuery, keyVersion); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return 0, ErrCouldNotRollbackTransaction
+		}
+		return 0, err
+	}
+
+	for _, row := range rows {
+		sealed, err := reseal(row.sealedAPIKeys())
+		if err == nil {
+			_, err = tx.Exec(updateUserAPIKeysQuery, sealed.Values[0], sealed.Values[1], sealed.DataKey, sealed.KeyVersion, row.ID)
+		}
+		if err != nil {
+			if err := tx.Rollback(); err != nil {
+				return 0, ErrCouldNotRollbackTransaction
+			}
+			return 0, fmt.Errorf("user %d: %w", row.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
 }
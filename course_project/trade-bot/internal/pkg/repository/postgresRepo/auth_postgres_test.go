@@ -1,14 +1,31 @@
 package postgresRepo
 
 import (
+	"database/sql"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 
 	"testing"
 	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository/encryption"
 )
 
+// testMasterKey is base64 encoded 32 bytes key
+const testMasterKey = "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+
+var userColumns = []string{"id", "name", "username", "password_hash",
+	"public_api_key", "private_api_key", "api_keys_data_key", "api_keys_key_version"}
+
+func newTestKeyRing(t *testing.T) *encryption.KeyRing {
+	keyRing, err := encryption.NewKeyRing(map[int]string{1: testMasterKey}, 1)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when creating key ring", err)
+	}
+	return keyRing
+}
+
 func TestAuthPostgres_CreateUser(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
@@ -17,7 +34,8 @@ func TestAuthPostgres_CreateUser(t *testing.T) {
 	defer mockDB.Close()
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
-	r := NewAuthPostgres(sqlxDB)
+	keyRing := newTestKeyRing(t)
+	r := NewAuthPostgres(sqlxDB, keyRing)
 
 	tests := []struct {
 		name    string
@@ -31,7 +49,7 @@ func TestAuthPostgres_CreateUser(t *testing.T) {
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
 				mock.ExpectQuery("INSERT INTO users").
-					WithArgs("name", "username", "password", "key", "key").WillReturnRows(rows)
+					WithArgs("name", "username", "password", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 1).WillReturnRows(rows)
 			},
 			input: models.User{
 				Name:          "name",
@@ -47,7 +65,7 @@ func TestAuthPostgres_CreateUser(t *testing.T) {
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id"})
 				mock.ExpectQuery("INSERT INTO users").
-					WithArgs("name", "username", "", "key", "key").WillReturnRows(rows)
+					WithArgs("name", "username", "", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 1).WillReturnRows(rows)
 			},
 			input: models.User{
 				Name:          "name",
@@ -84,7 +102,8 @@ func TestAuthPostgres_GetUser(t *testing.T) {
 	defer mockDB.Close()
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
-	r := NewAuthPostgres(sqlxDB)
+	keyRing := newTestKeyRing(t)
+	r := NewAuthPostgres(sqlxDB, keyRing)
 
 	tests := []struct {
 		name     string
@@ -96,9 +115,8 @@ func TestAuthPostgres_GetUser(t *testing.T) {
 		{
 			name: "OK",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "name", "username", "password_hash",
-					"public_api_key", "private_api_key"}).
-					AddRow(1, "name", "username", "password", "key", "key")
+				rows := sqlmock.NewRows(userColumns).
+					AddRow(1, "name", "username", "password", "key", "key", "", encryption.PlaintextKeyVersion)
 				mock.ExpectQuery("SELECT (.+) FROM users").
 					WithArgs("username").WillReturnRows(rows)
 			},
@@ -115,8 +133,7 @@ func TestAuthPostgres_GetUser(t *testing.T) {
 		{
 			name: "Not Found",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "name", "username", "password_hash",
-					"public_api_key", "private_api_key"})
+				rows := sqlmock.NewRows(userColumns)
 				mock.ExpectQuery("SELECT (.+) FROM users").
 					WithArgs("username").WillReturnRows(rows)
 			},
@@ -149,7 +166,8 @@ func TestAuthPostgres_GetUserAPIKeys(t *testing.T) {
 	defer mockDB.Close()
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
-	r := NewAuthPostgres(sqlxDB)
+	keyRing := newTestKeyRing(t)
+	r := NewAuthPostgres(sqlxDB, keyRing)
 
 	type wantArgs struct {
 		publicAPIKey  string
@@ -166,9 +184,8 @@ func TestAuthPostgres_GetUserAPIKeys(t *testing.T) {
 		{
 			name: "OK",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "name", "username", "password_hash",
-					"public_api_key", "private_api_key"}).
-					AddRow(1, "name", "username", "password", "key", "key")
+				rows := sqlmock.NewRows(userColumns).
+					AddRow(1, "name", "username", "password", "key", "key", "", encryption.PlaintextKeyVersion)
 				mock.ExpectQuery("SELECT (.+) FROM users").
 					WithArgs(1).WillReturnRows(rows)
 			},
@@ -178,11 +195,39 @@ func TestAuthPostgres_GetUserAPIKeys(t *testing.T) {
 				privateAPIKey: "key",
 			},
 		},
+		{
+			name: "Encrypted",
+			mock: func() {
+				sealed, err := keyRing.Seal("public", "private")
+				assert.NoError(t, err)
+
+				rows := sqlmock.NewRows(userColumns).
+					AddRow(1, "name", "username", "password", sealed.Values[0], sealed.Values[1],
+						sealed.DataKey, sealed.KeyVersion)
+				mock.ExpectQuery("SELECT (.+) FROM users").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			userID: 1,
+			want: wantArgs{
+				publicAPIKey:  "public",
+				privateAPIKey: "private",
+			},
+		},
+		{
+			name: "Unknown key version",
+			mock: func() {
+				rows := sqlmock.NewRows(userColumns).
+					AddRow(1, "name", "username", "password", "key", "key", "key", 2)
+				mock.ExpectQuery("SELECT (.+) FROM users").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			userID:  1,
+			wantErr: true,
+		},
 		{
 			name: "Not Found",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "name", "username", "password_hash",
-					"public_api_key", "private_api_key"})
+				rows := sqlmock.NewRows(userColumns)
 				mock.ExpectQuery("SELECT (.+) FROM users").
 					WithArgs(1).WillReturnRows(rows)
 			},
@@ -200,10 +245,152 @@ func TestAuthPostgres_GetUserAPIKeys(t *testing.T) {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, test.want.privateAPIKey, publicAPIKeyGot)
+				assert.Equal(t, test.want.publicAPIKey, publicAPIKeyGot)
 				assert.Equal(t, test.want.privateAPIKey, privateAPIKeyGot)
 			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestAuthPostgres_GetUserIDs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewAuthPostgres(sqlxDB, newTestKeyRing(t))
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2)
+				mock.ExpectQuery("SELECT id FROM users").WillReturnRows(rows)
+			},
+			want: []int{1, 2},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectQuery("SELECT id FROM users").WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetUserIDs()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthPostgres_RotateAPIKeys(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	keyRing := newTestKeyRing(t)
+	r := NewAuthPostgres(sqlxDB, keyRing)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows(userColumns).
+					AddRow(1, "name", "username", "password", "public", "private", "", encryption.PlaintextKeyVersion)
+				mock.ExpectQuery("SELECT (.+) FROM users").
+					WithArgs(keyRing.CurrentVersion()).WillReturnRows(rows)
+				mock.ExpectExec("UPDATE users").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), keyRing.CurrentVersion(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 1,
+		},
+		{
+			name: "Unknown key version",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows(userColumns).
+					AddRow(1, "name", "username", "password", "public", "private", "key", 5)
+				mock.ExpectQuery("SELECT (.+) FROM users").
+					WithArgs(keyRing.CurrentVersion()).WillReturnRows(rows)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.RotateAPIKeys()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAuthPostgres_DecryptAPIKeys(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	keyRing := newTestKeyRing(t)
+	r := NewAuthPostgres(sqlxDB, keyRing)
+
+	sealed, err := keyRing.Seal("public", "private")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when sealing api keys", err)
+	}
+
+	mock.ExpectBegin()
+	rows := sqlmock.NewRows(userColumns).
+		AddRow(1, "name", "username", "password", sealed.Values[0], sealed.Values[1], sealed.DataKey, sealed.KeyVersion)
+	mock.ExpectQuery("SELECT (.+) FROM users").WithArgs(encryption.PlaintextKeyVersion).WillReturnRows(rows)
+	mock.ExpectExec("UPDATE users").
+		WithArgs("public", "private", "", encryption.PlaintextKeyVersion, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	decrypted, err := r.DecryptAPIKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, decrypted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
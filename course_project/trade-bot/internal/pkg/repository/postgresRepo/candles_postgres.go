@@ -0,0 +1,156 @@
+package postgresRepo
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrGetCandles             = errors.New("get candles")
+	ErrSaveCandles            = errors.New("save candles")
+	ErrCreateCandlesPartition = errors.New("create candles partition")
+)
+
+// CandlesPostgres keeps candles in table partitioned by months, partition of month is created
+// before the first candle of month is saved
+type CandlesPostgres struct {
+	db *sqlx.DB
+
+	mu         sync.Mutex
+	partitions map[string]bool
+}
+
+func NewCandlesPostgres(db *sqlx.DB) *CandlesPostgres {
+	return &CandlesPostgres{db: db, partitions: make(map[string]bool)}
+}
+
+const getCandlesQuery = `
+	SELECT time, open, high, low, close, volume FROM candles
+	WHERE symbol=$1 AND interval=$2 AND time >= $3 AND time < $4
+	ORDER BY time`
+
+const (
+	// bounds of partition can't be parameters of query, they are formatted by time.RFC3339
+	createCandlesPartitionQuery = `CREATE TABLE IF NOT EXISTS %s PARTITION OF candles FOR VALUES FROM ('%s') TO ('%s')`
+	candlesPartitionLayout      = "candles_2006_01"
+)
+
+// upsertCandleQuery replaces candle of the same time, so candles can be saved again while they are updated
+const upsertCandleQuery = `
+	INSERT INTO candles (symbol, interval, time, open, high, low, close, volume)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (symbol, interval, time) DO UPDATE
+	SET open=EXCLUDED.open, high=EXCLUDED.high, low=EXCLUDED.low, close=EXCLUDED.close, volume=EXCLUDED.volume`
+
+// SaveCandles inserts candles of symbol and interval or updates saved ones, time of candles is in unix seconds
+func (c *CandlesPostgres) SaveCandles(symbol, interval string, candles []krakenFuturesWSSDK.Candle) error {
+	for _, candle := range candles {
+		if err := c.createPartition(time.Unix(int64(candle.Time), 0)); err != nil {
+			return fmt.Errorf("%s: %w", ErrSaveCandles, err)
+		}
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrSaveCandles, err)
+	}
+
+	for _, candle := range candles {
+		open, high, low, close, err := candlePrices(candle)
+		if err != nil {
+			if err := tx.Rollback(); err != nil {
+				return ErrCouldNotRollbackTransaction
+			}
+			return fmt.Errorf("%s: %w", ErrSaveCandles, err)
+		}
+
+		_, err = tx.Exec(upsertCandleQuery, symbol, interval, time.Unix(int64(candle.Time), 0).UTC(),
+			open, high, low, close, candle.Volume)
+		if err != nil {
+			if err := tx.Rollback(); err != nil {
+				return ErrCouldNotRollbackTransaction
+			}
+			return fmt.Errorf("%s: %w", ErrSaveCandles, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", ErrSaveCandles, err)
+	}
+	return nil
+}
+
+// createPartition creates partition of month of t unless it is created already
+func (c *CandlesPostgres) createPartition(t time.Time) error {
+	from := time.Date(t.UTC().Year(), t.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	name := from.Format(candlesPartitionLayout)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.partitions[name] {
+		return nil
+	}
+
+	query := fmt.Sprintf(createCandlesPartitionQuery, name, from.Format(time.RFC3339), from.AddDate(0, 1, 0).Format(time.RFC3339))
+	if _, err := c.db.Exec(query); err != nil {
+		return fmt.Errorf("%s %s: %w", ErrCreateCandlesPartition, name, err)
+	}
+	c.partitions[name] = true
+	return nil
+}
+
+func candlePrices(candle krakenFuturesWSSDK.Candle) (open, high, low, close float64, err error) {
+	if open, err = strconv.ParseFloat(candle.Open, 64); err != nil {
+		return
+	}
+	if high, err = strconv.ParseFloat(candle.High, 64); err != nil {
+		return
+	}
+	if low, err = strconv.ParseFloat(candle.Low, 64); err != nil {
+		return
+	}
+	close, err = strconv.ParseFloat(candle.Close, 64)
+	return
+}
+
+// GetCandles returns candles of symbol and interval in [from, to) sorted by time
+func (c *CandlesPostgres) GetCandles(symbol, interval string, from, to time.Time) ([]krakenFuturesWSSDK.Candle, error) {
+	rows, err := c.db.Query(getCandlesQuery, symbol, interval, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetCandles, err)
+	}
+	defer rows.Close()
+
+	var candles []krakenFuturesWSSDK.Candle
+	for rows.Next() {
+		var (
+			candleTime             time.Time
+			open, high, low, close float64
+			volume                 int
+		)
+
+		if err := rows.Scan(&candleTime, &open, &high, &low, &close, &volume); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrGetCandles, err)
+		}
+		candles = append(candles, krakenFuturesWSSDK.Candle{
+			Time:   int(candleTime.Unix()),
+			Open:   strconv.FormatFloat(open, 'f', -1, 64),
+			High:   strconv.FormatFloat(high, 'f', -1, 64),
+			Low:    strconv.FormatFloat(low, 'f', -1, 64),
+			Close:  strconv.FormatFloat(close, 'f', -1, 64),
+			Volume: volume,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetCandles, err)
+	}
+	return candles, nil
+}
@@ -0,0 +1,103 @@
+package postgresRepo
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+func TestCandlesPostgres_SaveCandles(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewCandlesPostgres(sqlxDB)
+
+	january := time.Date(2022, 1, 31, 23, 59, 0, 0, time.UTC)
+	february := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	candle := func(t time.Time) krakenFuturesWSSDK.Candle {
+		return krakenFuturesWSSDK.Candle{Time: int(t.Unix()), Open: "1", High: "3", Low: "0.5", Close: "2", Volume: 10}
+	}
+
+	tests := []struct {
+		name    string
+		candles []krakenFuturesWSSDK.Candle
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "OK",
+			candles: []krakenFuturesWSSDK.Candle{candle(january), candle(february)},
+			mock: func() {
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS candles_2022_01 PARTITION OF candles " +
+					"FOR VALUES FROM ('2022-01-01T00:00:00Z') TO ('2022-02-01T00:00:00Z')")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS candles_2022_02 PARTITION OF candles " +
+					"FOR VALUES FROM ('2022-02-01T00:00:00Z') TO ('2022-03-01T00:00:00Z')")).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO candles (.+) ON CONFLICT").
+					WithArgs("PI_XBTUSD", "1m", january, 1.0, 3.0, 0.5, 2.0, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO candles (.+) ON CONFLICT").
+					WithArgs("PI_XBTUSD", "1m", february, 1.0, 3.0, 0.5, 2.0, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "Partition is created once",
+			candles: []krakenFuturesWSSDK.Candle{candle(january)},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO candles (.+) ON CONFLICT").
+					WithArgs("PI_XBTUSD", "1m", january, 1.0, 3.0, 0.5, 2.0, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:    "Invalid price",
+			candles: []krakenFuturesWSSDK.Candle{{Time: int(january.Unix()), Open: "one"}},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name:    "DB error",
+			candles: []krakenFuturesWSSDK.Candle{candle(january)},
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO candles (.+) ON CONFLICT").WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.SaveCandles("PI_XBTUSD", "1m", test.candles)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
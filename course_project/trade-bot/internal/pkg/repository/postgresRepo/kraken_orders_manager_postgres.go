@@ -1,7 +1,9 @@
 package postgresRepo
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -11,7 +13,12 @@ import (
 
 var (
 	ErrCouldNotRollbackTransaction = errors.New("could not rollback transaction")
-	ErrGetUsersOrder               = errors.New("get user orders")
+	ErrFindUserOrders              = errors.New("find user orders")
+	ErrInvalidOrdersSort           = errors.New("invalid sort of orders")
+	ErrUpdateOrder                 = errors.New("update order")
+	ErrUpdateOrderStatus           = errors.New("update order status")
+	ErrOrderNotFound               = errors.New("order not found")
+	ErrGetUsersWithOpenOrders      = errors.New("get users with open orders")
 )
 
 type KrakenOrdersManagerPostgres struct {
@@ -24,9 +31,9 @@ func NewKrakenOrdersManagerPostgres(db *sqlx.DB) *KrakenOrdersManagerPostgres {
 
 const createOrderQuery = `
 	INSERT INTO orders(order_id, user_id, cli_order_id, type, symbol, quantity, side, filled,
-	                  timestamp, last_update_timestamp, price)
+	                  timestamp, last_update_timestamp, price, status)
 	VALUES($1, $2, $3, $4, $5, $6, $7, $8,
-	                  $9, $10, $11)`
+	                  $9, $10, $11, $12)`
 
 const createUsersOrdersQuery = `
 	INSERT INTO users_orders(user_id, order_id) VALUES ($1, $2)
@@ -39,7 +46,7 @@ func (k *KrakenOrdersManagerPostgres) CreateOrder(userID int, order models.Order
 	}
 
 	_, err = tx.Exec(createOrderQuery, order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-		order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price)
+		order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Status)
 	if err != nil {
 		if err := tx.Rollback(); err != nil {
 			return ErrCouldNotRollbackTransaction
@@ -58,7 +65,7 @@ func (k *KrakenOrdersManagerPostgres) CreateOrder(userID int, order models.Order
 }
 
 const getOrderByIDQuery = `
-SELECT * FROM orders WHERE order_id like $1
+SELECT * FROM orders WHERE order_id=$1
 `
 
 func (k *KrakenOrdersManagerPostgres) GetOrder(orderID string) (models.Order, error) {
@@ -67,25 +74,130 @@ func (k *KrakenOrdersManagerPostgres) GetOrder(orderID string) (models.Order, er
 	return order, err
 }
 
-const getUserOrdersQuery = `SELECT * FROM orders WHERE user_id=$1`
+// ordersSortColumns are columns of orders which order history can be sorted by
+var ordersSortColumns = map[string]string{
+	models.OrdersSortTimestamp: "timestamp",
+	models.OrdersSortPrice:     "price",
+	models.OrdersSortQuantity:  "quantity",
+}
 
-func (k *KrakenOrdersManagerPostgres) GetUserOrders(userID int) ([]models.Order, error) {
-	rows, err := k.db.Query(getUserOrdersQuery, userID)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", ErrGetUsersOrder, err)
+// FindUserOrders returns orders of user selected by filter. Pages are split by keyset of sort column
+// and order id, so orders which are saved meanwhile do not shift next pages
+func (k *KrakenOrdersManagerPostgres) FindUserOrders(userID int, filter models.OrdersFilter) ([]models.Order, error) {
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = models.OrdersSortTimestamp
+	}
+	column, ok := ordersSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w: %s", ErrFindUserOrders, ErrInvalidOrdersSort, sortBy)
 	}
-	defer rows.Close()
-
-	var orders []models.Order
-	for rows.Next() {
-		var order models.Order
 
-		if err := rows.Scan(&order.ID, &order.UserID, &order.ClientOrderID, &order.Type, &order.Symbol, &order.Quantity,
-			&order.Side, &order.Filled, &order.Timestamp, &order.LastUpdateTimestamp, &order.Price); err != nil {
-			return nil, fmt.Errorf("%s: %w", ErrGetUsersOrder, err)
+	var query strings.Builder
+	args := []interface{}{userID}
+	query.WriteString("SELECT * FROM orders WHERE user_id=$1")
+	where := func(condition string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = len(args)
 		}
-		orders = append(orders, order)
+		query.WriteString(" AND ")
+		query.WriteString(fmt.Sprintf(condition, placeholders...))
+	}
+
+	if filter.Symbol != "" {
+		where("lower(symbol)=lower($%d)", filter.Symbol)
+	}
+	if filter.Side != "" {
+		where("side=$%d", filter.Side)
+	}
+	if filter.Status != "" {
+		where("status=$%d", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		where("timestamp>=$%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("timestamp<$%d", filter.To)
+	}
+
+	direction, compare := "ASC", ">"
+	if filter.Descending {
+		direction, compare = "DESC", "<"
+	}
+	if filter.After != nil {
+		where("("+column+", order_id)"+compare+"($%d, $%d)", orderSortValue(*filter.After, sortBy), filter.After.ID)
+	}
+
+	query.WriteString(fmt.Sprintf(" ORDER BY %s %s, order_id %s", column, direction, direction))
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
 	}
 
+	var orders []models.Order
+	if err := k.db.Select(&orders, query.String(), args...); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrFindUserOrders, err)
+	}
 	return orders, nil
 }
+
+func orderSortValue(order models.Order, sortBy string) interface{} {
+	switch sortBy {
+	case models.OrdersSortPrice:
+		return order.Price
+	case models.OrdersSortQuantity:
+		return order.Quantity
+	default:
+		return order.Timestamp
+	}
+}
+
+const updateOrderQuery = `
+	UPDATE orders
+	SET quantity=$1, filled=$2, price=$3, last_update_timestamp=$4, status=$5, status_updated_at=now()
+	WHERE order_id=$6 AND user_id=$7`
+
+// UpdateOrder saves quantity, price and status of order changed on exchange
+func (k *KrakenOrdersManagerPostgres) UpdateOrder(userID int, order models.Order) error {
+	result, err := k.db.Exec(updateOrderQuery, order.Quantity, order.Filled, order.Price, order.LastUpdateTimestamp,
+		order.Status, order.ID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateOrder, err)
+	}
+	return checkOrderUpdated(result, ErrUpdateOrder)
+}
+
+const updateOrderStatusQuery = `
+	UPDATE orders SET status=$1, status_updated_at=now() WHERE order_id=$2 AND user_id=$3`
+
+func (k *KrakenOrdersManagerPostgres) UpdateOrderStatus(userID int, orderID, status string) error {
+	result, err := k.db.Exec(updateOrderStatusQuery, status, orderID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateOrderStatus, err)
+	}
+	return checkOrderUpdated(result, ErrUpdateOrderStatus)
+}
+
+const getUsersWithOpenOrdersQuery = `SELECT DISTINCT user_id FROM orders WHERE status=$1 ORDER BY user_id`
+
+// GetUsersWithOpenOrders returns ids of users which have orders resting on exchange
+func (k *KrakenOrdersManagerPostgres) GetUsersWithOpenOrders() ([]int, error) {
+	var userIDs []int
+	if err := k.db.Select(&userIDs, getUsersWithOpenOrdersQuery, models.OrderOpen); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUsersWithOpenOrders, err)
+	}
+	return userIDs, nil
+}
+
+func checkOrderUpdated(result sql.Result, errUpdate error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", errUpdate, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", errUpdate, ErrOrderNotFound)
+	}
+	return nil
+}
@@ -1,7 +1,10 @@
 package postgresRepo
 
 import (
+	"database/sql/driver"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
@@ -11,6 +14,11 @@ import (
 	"trade-bot/internal/pkg/models"
 )
 
+var orderColumns = []string{"order_id", "user_id", "cli_order_id", "type", "symbol", "quantity",
+	"side", "filled", "timestamp", "last_update_timestamp", "price", "status", "status_updated_at"}
+
+var orderTime = time.Date(2021, 12, 20, 11, 33, 20, 0, time.UTC)
+
 func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
@@ -45,8 +53,8 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 					Quantity:            10,
 					Side:                "buy",
 					Filled:              2,
-					Timestamp:           "timestamp",
-					LastUpdateTimestamp: "timestamp",
+					Timestamp:           orderTime,
+					LastUpdateTimestamp: orderTime,
 					Price:               10,
 				},
 				userID: 1,
@@ -56,7 +64,7 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 
 				mock.ExpectExec("INSERT INTO orders").
 					WithArgs(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price).
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Status).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectExec("INSERT INTO users_orders").WithArgs(userID, order.ID).
@@ -80,7 +88,7 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 
 				mock.ExpectExec("INSERT INTO orders").
 					WithArgs(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price).
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Status).
 					WillReturnError(errors.New("insert error"))
 
 				mock.ExpectRollback()
@@ -99,8 +107,8 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 					Quantity:            10,
 					Side:                "buy",
 					Filled:              2,
-					Timestamp:           "timestamp",
-					LastUpdateTimestamp: "timestamp",
+					Timestamp:           orderTime,
+					LastUpdateTimestamp: orderTime,
 					Price:               10,
 				},
 			},
@@ -109,7 +117,7 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 
 				mock.ExpectExec("INSERT INTO orders").
 					WithArgs(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price).
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Status).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectExec("INSERT INTO users_orders").WithArgs(userID, order.ID).
@@ -171,11 +179,11 @@ func TestKrakenOrdersManagerPostgres_GetOrder(t *testing.T) {
 				ID: "1",
 			},
 			mock: func(orderID string, order models.Order) {
-				rows := sqlmock.NewRows([]string{"order_id", "user_id", "cli_order_id", "type", "symbol", "quantity",
-					"side", "filled", "timestamp", "last_update_timestamp", "price"}).
+				rows := sqlmock.NewRows(orderColumns).
 					AddRow(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price)
-				mock.ExpectQuery("SELECT (.+) FROM orders").
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Status,
+						order.StatusUpdatedAt)
+				mock.ExpectQuery("SELECT (.+) FROM orders WHERE order_id=\\$1").
 					WithArgs(orderID).WillReturnRows(rows)
 			},
 			wantErr: false,
@@ -192,9 +200,8 @@ func TestKrakenOrdersManagerPostgres_GetOrder(t *testing.T) {
 				ID: "1",
 			},
 			mock: func(orderID string, order models.Order) {
-				rows := sqlmock.NewRows([]string{"order_id", "user_id", "cli_order_id", "type", "symbol", "quantity",
-					"side", "filled", "timestamp", "last_update_timestamp", "price"})
-				mock.ExpectQuery("SELECT (.+) FROM orders").
+				rows := sqlmock.NewRows(orderColumns)
+				mock.ExpectQuery("SELECT (.+) FROM orders WHERE order_id=\\$1").
 					WithArgs(orderID).WillReturnRows(rows)
 			},
 			wantErr: true,
@@ -217,7 +224,77 @@ func TestKrakenOrdersManagerPostgres_GetOrder(t *testing.T) {
 	}
 }
 
-func TestKrakenOrdersManagerPostgres_GetUserOrders(t *testing.T) {
+func TestKrakenOrdersManagerPostgres_FindUserOrders(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewKrakenOrdersManagerPostgres(sqlxDB)
+	order := models.Order{ID: "2", UserID: 1, Symbol: "PI_XBTUSD", Quantity: 10, Side: "buy", Timestamp: orderTime,
+		LastUpdateTimestamp: orderTime, Price: 100, Status: models.OrderFilled, StatusUpdatedAt: orderTime}
+	after := models.Order{ID: "1", Price: 110, Timestamp: orderTime.Add(time.Hour)}
+
+	tests := []struct {
+		name      string
+		filter    models.OrdersFilter
+		wantQuery string
+		wantArgs  []driver.Value
+		wantErr   bool
+	}{
+		{
+			name:      "All orders",
+			wantQuery: "SELECT * FROM orders WHERE user_id=$1 ORDER BY timestamp ASC, order_id ASC",
+			wantArgs:  []driver.Value{1},
+		},
+		{
+			name: "Filtered page of latest orders",
+			filter: models.OrdersFilter{Symbol: "pi_xbtusd", Side: "buy", Status: models.OrderFilled, From: orderTime,
+				To: orderTime.Add(24 * time.Hour), Descending: true, After: &after, Limit: 2},
+			wantQuery: "SELECT * FROM orders WHERE user_id=$1 AND lower(symbol)=lower($2) AND side=$3 AND status=$4 " +
+				"AND timestamp>=$5 AND timestamp<$6 AND (timestamp, order_id)<($7, $8) " +
+				"ORDER BY timestamp DESC, order_id DESC LIMIT $9",
+			wantArgs: []driver.Value{1, "pi_xbtusd", "buy", models.OrderFilled, orderTime, orderTime.Add(24 * time.Hour),
+				after.Timestamp, after.ID, 2},
+		},
+		{
+			name:      "Page sorted by price",
+			filter:    models.OrdersFilter{SortBy: models.OrdersSortPrice, After: &after},
+			wantQuery: "SELECT * FROM orders WHERE user_id=$1 AND (price, order_id)>($2, $3) ORDER BY price ASC, order_id ASC",
+			wantArgs:  []driver.Value{1, after.Price, after.ID},
+		},
+		{
+			name:    "Invalid sort",
+			filter:  models.OrdersFilter{SortBy: "symbol"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.wantQuery != "" {
+				rows := sqlmock.NewRows(orderColumns).
+					AddRow(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Status,
+						order.StatusUpdatedAt)
+				mock.ExpectQuery("^" + regexp.QuoteMeta(test.wantQuery) + "$").WithArgs(test.wantArgs...).WillReturnRows(rows)
+			}
+
+			orders, err := r.FindUserOrders(1, test.filter)
+			if test.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidOrdersSort)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, []models.Order{order}, orders)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestKrakenOrdersManagerPostgres_UpdateOrder(t *testing.T) {
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -226,66 +303,144 @@ func TestKrakenOrdersManagerPostgres_GetUserOrders(t *testing.T) {
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 
 	r := NewKrakenOrdersManagerPostgres(sqlxDB)
+	order := models.Order{ID: "1", Quantity: 5, Price: 100, LastUpdateTimestamp: orderTime, Status: models.OrderOpen}
 
 	tests := []struct {
 		name    string
-		userID  int
-		order   models.Order
-		mock    func(userID int, order models.Order)
-		want    []models.Order
+		mock    func()
 		wantErr bool
 	}{
 		{
-			name:   "OK",
-			userID: 1,
-			order: models.Order{
-				ID:                  "1",
-				UserID:              1,
-				ClientOrderID:       "1",
-				Type:                "type",
-				Symbol:              "symbol",
-				Quantity:            10,
-				Side:                "buy",
-				Filled:              10,
-				Timestamp:           "time",
-				LastUpdateTimestamp: "time",
-				Price:               100,
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("UPDATE orders").
+					WithArgs(order.Quantity, order.Filled, order.Price, order.LastUpdateTimestamp, order.Status, order.ID, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
-			mock: func(userID int, order models.Order) {
-				rows := sqlmock.NewRows([]string{"order_id", "user_id", "cli_order_id", "type", "symbol", "quantity",
-					"side", "filled", "timestamp", "last_update_timestamp", "price"}).
-					AddRow(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price)
-				mock.ExpectQuery("SELECT (.+) FROM orders").
-					WithArgs(userID).WillReturnRows(rows)
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectExec("UPDATE orders").WillReturnResult(sqlmock.NewResult(0, 0))
 			},
-			want: []models.Order{{
-				ID:                  "1",
-				UserID:              1,
-				ClientOrderID:       "1",
-				Type:                "type",
-				Symbol:              "symbol",
-				Quantity:            10,
-				Side:                "buy",
-				Filled:              10,
-				Timestamp:           "time",
-				LastUpdateTimestamp: "time",
-				Price:               100,
-			}},
-			wantErr: false,
+			wantErr: true,
+		},
+		{
+			name: "Error",
+			mock: func() {
+				mock.ExpectExec("UPDATE orders").WillReturnError(errors.New("update error"))
+			},
+			wantErr: true,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			test.mock(test.userID, test.order)
+			test.mock()
 
-			gorOrders, err := r.GetUserOrders(test.userID)
+			err := r.UpdateOrder(1, order)
 			if test.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, test.want, gorOrders)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestKrakenOrdersManagerPostgres_UpdateOrderStatus(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewKrakenOrdersManagerPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("UPDATE orders SET status").WithArgs(models.OrderCancelled, "1", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectExec("UPDATE orders SET status").WithArgs(models.OrderCancelled, "1", 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrOrderNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.UpdateOrderStatus(1, "1", models.OrderCancelled)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestKrakenOrdersManagerPostgres_GetUsersWithOpenOrders(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewKrakenOrdersManagerPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []int
+		wantErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"user_id"}).AddRow(1).AddRow(3)
+				mock.ExpectQuery("SELECT DISTINCT user_id FROM orders WHERE status=\\$1").
+					WithArgs(models.OrderOpen).WillReturnRows(rows)
+			},
+			want: []int{1, 3},
+		},
+		{
+			name: "Select error",
+			mock: func() {
+				mock.ExpectQuery("SELECT DISTINCT user_id FROM orders WHERE status=\\$1").
+					WithArgs(models.OrderOpen).WillReturnError(errors.New("select error"))
+			},
+			wantErr: ErrGetUsersWithOpenOrders,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetUsersWithOpenOrders()
+			if test.wantErr != nil {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
 			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
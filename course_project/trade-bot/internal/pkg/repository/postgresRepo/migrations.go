@@ -0,0 +1,304 @@
+package postgresRepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrMigrate             = errors.New("migrate")
+	ErrInvalidMigrations   = errors.New("invalid migrations")
+	ErrDirtyDatabase       = errors.New("database is dirty, fix it and force version of schema_migrations manually")
+	ErrUnknownVersion      = errors.New("version of database is not found in migrations")
+	ErrNoDownMigration     = errors.New("no down migration")
+	ErrLockMigrations      = errors.New("lock migrations")
+	ErrUnlockMigrations    = errors.New("unlock migrations")
+	ErrGetMigrationVersion = errors.New("get migration version")
+)
+
+// migrationsLockID is a key of postgres advisory lock held while migrations are applied,
+// so replicas of server started together apply every migration once
+const migrationsLockID = 72522021
+
+// schema_migrations has the same format as in golang-migrate, so databases migrated by its cli keep their version
+const (
+	createMigrationsTableQuery = `
+	CREATE TABLE IF NOT EXISTS schema_migrations
+	(
+	    version bigint  not null primary key,
+	    dirty   boolean not null
+	)`
+	getMigrationVersionQuery    = `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	deleteMigrationVersionQuery = `DELETE FROM schema_migrations`
+	setMigrationVersionQuery    = `INSERT INTO schema_migrations(version, dirty) VALUES ($1, false)`
+	lockMigrationsQuery         = `SELECT pg_advisory_lock($1)`
+	unlockMigrationsQuery       = `SELECT pg_advisory_unlock($1)`
+)
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a version of schema of database, Down is empty when it can not be rolled back
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether migration is applied to database
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Migrator applies migrations in order of versions. Every migration runs in its own transaction
+// together with update of version, so failed migration leaves database at the previous version
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// NewMigrator reads migrations from files {version}_{name}.up.sql and {version}_{name}.down.sql of fsys
+func NewMigrator(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrInvalidMigrations, err)
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all migrations newer than version of database and returns them
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := getMigrationVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if _, err := m.index(version); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= version {
+				continue
+			}
+			if err := applyMigration(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return applied, fmt.Errorf("%s: up: %w", ErrMigrate, err)
+	}
+	return applied, nil
+}
+
+// Down rolls back steps latest applied migrations and returns them
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		version, err := getMigrationVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		i, err := m.index(version)
+		if err != nil {
+			return err
+		}
+
+		for ; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := m.migrations[i]
+			if migration.Down == "" {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, ErrNoDownMigration)
+			}
+
+			var previous uint
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if err := applyMigration(ctx, conn, migration.Down, previous); err != nil {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+	if err != nil {
+		return rolledBack, fmt.Errorf("%s: down: %w", ErrMigrate, err)
+	}
+	return rolledBack, nil
+}
+
+// Status returns version of database and all migrations with their state
+func (m *Migrator) Status(ctx context.Context) (uint, []MigrationStatus, error) {
+	var version uint
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+		version, err = getMigrationVersion(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: status: %w", ErrMigrate, err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Version <= version,
+		})
+	}
+	return version, statuses, nil
+}
+
+// index returns index of migration of version, it is -1 for empty database
+func (m *Migrator) index(version uint) (int, error) {
+	if version == 0 {
+		return -1, nil
+	}
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+}
+
+// withLock runs fn on one connection while it holds advisory lock of migrations,
+// other servers wait for the lock until migrations are applied
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, lockMigrationsQuery, migrationsLockID); err != nil {
+		return fmt.Errorf("%s: %w", ErrLockMigrations, err)
+	}
+	defer func() {
+		// lock must be released even when ctx is done, it is held by connection which returns to pool
+		if _, unlockErr := conn.ExecContext(context.Background(), unlockMigrationsQuery, migrationsLockID); unlockErr != nil && err == nil {
+			err = fmt.Errorf("%s: %w", ErrUnlockMigrations, unlockErr)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTableQuery); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func getMigrationVersion(ctx context.Context, conn *sql.Conn) (uint, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := conn.QueryRowContext(ctx, getMigrationVersionQuery).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrGetMigrationVersion, err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w: version %d", ErrDirtyDatabase, version)
+	}
+	return uint(version), nil
+}
+
+// applyMigration runs query of migration and sets version of database in one transaction,
+// zero version removes version of database
+func applyMigration(ctx context.Context, conn *sql.Conn, query string, version uint) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return ErrCouldNotRollbackTransaction
+		}
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, deleteMigrationVersionQuery); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return ErrCouldNotRollbackTransaction
+		}
+		return err
+	}
+	if version != 0 {
+		if _, err := tx.ExecContext(ctx, setMigrationVersionQuery, int64(version)); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return ErrCouldNotRollbackTransaction
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, file := range files {
+		match := migrationFileName.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("unexpected file name %s", file)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid version of %s", file)
+		}
+
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", migration.Name, match[2])
+		}
+
+		query, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(query)
+		} else {
+			migration.Down = string(query)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
@@ -0,0 +1,174 @@
+package postgresRepo
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrGetNotificationPreferences    = errors.New("get notification preferences")
+	ErrUpdateNotificationPreferences = errors.New("update notification preferences")
+	ErrCreateNotifications           = errors.New("create notifications")
+	ErrLeaseNotifications            = errors.New("lease notifications")
+	ErrUpdateNotification            = errors.New("update notification")
+	ErrGetUserNotifications          = errors.New("get user notifications")
+	ErrNotificationNotFound          = errors.New("notification not found")
+)
+
+// eventsSeparator joins events of preferences in one column
+const eventsSeparator = ","
+
+type NotificationsPostgres struct {
+	db *sqlx.DB
+}
+
+func NewNotificationsPostgres(db *sqlx.DB) *NotificationsPostgres {
+	return &NotificationsPostgres{db: db}
+}
+
+// preferencesRow is a notification_preferences table row with events joined by comma
+type preferencesRow struct {
+	UserID         int    `db:"user_id"`
+	TelegramChatID int64  `db:"telegram_chat_id"`
+	Email          string `db:"email"`
+	WebhookURL     string `db:"webhook_url"`
+	Events         string `db:"events"`
+}
+
+func (r preferencesRow) toPreferences() models.NotificationPreferences {
+	preferences := models.NotificationPreferences{
+		TelegramChatID: r.TelegramChatID,
+		Email:          r.Email,
+		WebhookURL:     r.WebhookURL,
+	}
+	if r.Events != "" {
+		preferences.Events = strings.Split(r.Events, eventsSeparator)
+	}
+	return preferences
+}
+
+const getNotificationPreferencesQuery = `SELECT * FROM notification_preferences WHERE user_id=$1`
+
+// GetNotificationPreferences returns preferences without channels when user has not set them yet
+func (r *NotificationsPostgres) GetNotificationPreferences(userID int) (models.NotificationPreferences, error) {
+	var row preferencesRow
+	err := r.db.Get(&row, getNotificationPreferencesQuery, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.NotificationPreferences{}, nil
+	}
+	if err != nil {
+		return models.NotificationPreferences{}, fmt.Errorf("%s: %w", ErrGetNotificationPreferences, err)
+	}
+	return row.toPreferences(), nil
+}
+
+const getAllNotificationPreferencesQuery = `SELECT * FROM notification_preferences ORDER BY user_id`
+
+// GetAllNotificationPreferences returns preferences of users who have set them by ids of users
+func (r *NotificationsPostgres) GetAllNotificationPreferences() (map[int]models.NotificationPreferences, error) {
+	var rows []preferencesRow
+	if err := r.db.Select(&rows, getAllNotificationPreferencesQuery); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetNotificationPreferences, err)
+	}
+
+	preferences := make(map[int]models.NotificationPreferences, len(rows))
+	for _, row := range rows {
+		preferences[row.UserID] = row.toPreferences()
+	}
+	return preferences, nil
+}
+
+const upsertNotificationPreferencesQuery = `
+	INSERT INTO notification_preferences(user_id, telegram_chat_id, email, webhook_url, events)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id) DO UPDATE SET telegram_chat_id=excluded.telegram_chat_id, email=excluded.email,
+		webhook_url=excluded.webhook_url, events=excluded.events`
+
+func (r *NotificationsPostgres) UpdateNotificationPreferences(userID int, preferences models.NotificationPreferences) error {
+	_, err := r.db.Exec(upsertNotificationPreferencesQuery, userID, preferences.TelegramChatID, preferences.Email,
+		preferences.WebhookURL, strings.Join(preferences.Events, eventsSeparator))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateNotificationPreferences, err)
+	}
+	return nil
+}
+
+const createNotificationQuery = `
+	INSERT INTO notifications(user_id, event, channel, address, dedup_key, subject, body, status, next_attempt_at, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (user_id, channel, dedup_key) WHERE dedup_key <> '' DO NOTHING`
+
+// CreateNotifications adds notifications to outbox in one transaction, notification with dedup key
+// which is in outbox already is skipped
+func (r *NotificationsPostgres) CreateNotifications(notifications []models.Notification) error {
+	err := inTransaction(r.db, func(tx *sqlx.Tx) error {
+		for _, n := range notifications {
+			if _, err := tx.Exec(createNotificationQuery, n.UserID, n.Event, n.Channel, n.Address, n.DedupKey,
+				n.Subject, n.Body, n.Status, n.NextAttemptAt, n.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCreateNotifications, err)
+	}
+	return nil
+}
+
+// leaseNotificationsQuery moves next attempt of due pending notifications to the end of lease,
+// so other dispatchers skip them while they are sent
+const leaseNotificationsQuery = `
+	UPDATE notifications SET next_attempt_at=$2
+	WHERE id IN (
+		SELECT id FROM notifications WHERE status='pending' AND next_attempt_at <= $1
+		ORDER BY next_attempt_at, id LIMIT $3 FOR UPDATE SKIP LOCKED
+	)
+	RETURNING *`
+
+// LeaseNotifications returns up to limit pending notifications due at now and leases them until leaseUntil
+func (r *NotificationsPostgres) LeaseNotifications(now, leaseUntil time.Time, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	if err := r.db.Select(&notifications, leaseNotificationsQuery, now, leaseUntil, limit); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLeaseNotifications, err)
+	}
+	return notifications, nil
+}
+
+const updateNotificationQuery = `
+	UPDATE notifications SET status=$1, attempts=$2, last_error=$3, next_attempt_at=$4, sent_at=$5 WHERE id=$6`
+
+func (r *NotificationsPostgres) UpdateNotification(notification models.Notification) error {
+	result, err := r.db.Exec(updateNotificationQuery, notification.Status, notification.Attempts, notification.LastError,
+		notification.NextAttemptAt, notification.SentAt, notification.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateNotification, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateNotification, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", ErrUpdateNotification, ErrNotificationNotFound)
+	}
+	return nil
+}
+
+const getUserNotificationsQuery = `SELECT * FROM notifications WHERE user_id=$1 ORDER BY id DESC LIMIT $2`
+
+// GetUserNotifications returns up to limit notifications of user, the newest first
+func (r *NotificationsPostgres) GetUserNotifications(userID, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	if err := r.db.Select(&notifications, getUserNotificationsQuery, userID, limit); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserNotifications, err)
+	}
+	return notifications, nil
+}
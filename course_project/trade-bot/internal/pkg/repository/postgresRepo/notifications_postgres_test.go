@@ -0,0 +1,246 @@
+package postgresRepo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	preferencesColumns  = []string{"user_id", "telegram_chat_id", "email", "webhook_url", "events"}
+	notificationColumns = []string{"id", "user_id", "event", "channel", "address", "dedup_key", "subject", "body",
+		"status", "attempts", "last_error", "next_attempt_at", "created_at", "sent_at"}
+)
+
+func TestNotificationsPostgres_GetNotificationPreferences(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewNotificationsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    models.NotificationPreferences
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(preferencesColumns).AddRow(1, 42, "user@mail.com", "", "order_filled,daily_summary")
+				mock.ExpectQuery("SELECT (.+) FROM notification_preferences").WithArgs(1).WillReturnRows(rows)
+			},
+			want: models.NotificationPreferences{TelegramChatID: 42, Email: "user@mail.com",
+				Events: []string{"order_filled", "daily_summary"}},
+		},
+		{
+			name: "All events",
+			mock: func() {
+				rows := sqlmock.NewRows(preferencesColumns).AddRow(1, 0, "", "http://hook", "")
+				mock.ExpectQuery("SELECT (.+) FROM notification_preferences").WithArgs(1).WillReturnRows(rows)
+			},
+			want: models.NotificationPreferences{WebhookURL: "http://hook"},
+		},
+		{
+			name: "Not set",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM notification_preferences").WithArgs(1).
+					WillReturnRows(sqlmock.NewRows(preferencesColumns))
+			},
+			want: models.NotificationPreferences{},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM notification_preferences").WithArgs(1).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetNotificationPreferences(1)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestNotificationsPostgres_UpdateNotificationPreferences(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewNotificationsPostgres(sqlxDB)
+	preferences := models.NotificationPreferences{TelegramChatID: 42, Events: []string{"order_filled", "session_error"}}
+
+	mock.ExpectExec("INSERT INTO notification_preferences").
+		WithArgs(1, int64(42), "", "", "order_filled,session_error").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, r.UpdateNotificationPreferences(1, preferences))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationsPostgres_CreateNotifications(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewNotificationsPostgres(sqlxDB)
+	now := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	notifications := []models.Notification{
+		{UserID: 1, Event: "order_filled", Channel: "email", Address: "user@mail.com", DedupKey: "order_filled:1",
+			Subject: "Order filled", Body: "buy", Status: "pending", NextAttemptAt: now, CreatedAt: now},
+		{UserID: 1, Event: "order_filled", Channel: "telegram", Address: "42", DedupKey: "order_filled:1",
+			Subject: "Order filled", Body: "buy", Status: "pending", NextAttemptAt: now, CreatedAt: now},
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO notifications").
+					WithArgs(1, "order_filled", "email", "user@mail.com", "order_filled:1", "Order filled", "buy",
+						"pending", now, now).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				// duplicate is skipped
+				mock.ExpectExec("INSERT INTO notifications").
+					WithArgs(1, "order_filled", "telegram", "42", "order_filled:1", "Order filled", "buy",
+						"pending", now, now).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO notifications").WillReturnError(errors.New("db error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.CreateNotifications(notifications)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestNotificationsPostgres_LeaseNotifications(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewNotificationsPostgres(sqlxDB)
+	now := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	leaseUntil := now.Add(5 * time.Minute)
+
+	rows := sqlmock.NewRows(notificationColumns).
+		AddRow(1, 1, "order_filled", "telegram", "42", "order_filled:1", "Order filled", "buy", "pending", 1,
+			"timeout", leaseUntil, now, nil)
+	mock.ExpectQuery("UPDATE notifications SET next_attempt_at(.+)FOR UPDATE SKIP LOCKED").
+		WithArgs(now, leaseUntil, 10).WillReturnRows(rows)
+
+	got, err := r.LeaseNotifications(now, leaseUntil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Notification{{ID: 1, UserID: 1, Event: "order_filled", Channel: "telegram", Address: "42",
+		DedupKey: "order_filled:1", Subject: "Order filled", Body: "buy", Status: "pending", Attempts: 1,
+		LastError: "timeout", NextAttemptAt: leaseUntil, CreatedAt: now}}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationsPostgres_UpdateNotification(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewNotificationsPostgres(sqlxDB)
+	now := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	notification := models.Notification{ID: 1, Status: "sent", Attempts: 1, NextAttemptAt: now, SentAt: &now}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		wantErr     bool
+		wantErrorIs error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("UPDATE notifications").WithArgs("sent", 1, "", now, &now, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectExec("UPDATE notifications").WithArgs("sent", 1, "", now, &now, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:     true,
+			wantErrorIs: ErrNotificationNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.UpdateNotification(notification)
+			if test.wantErr {
+				assert.ErrorIs(t, err, test.wantErrorIs)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
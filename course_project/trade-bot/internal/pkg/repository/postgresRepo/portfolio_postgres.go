@@ -0,0 +1,156 @@
+package postgresRepo
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrSaveFills     = errors.New("save fills")
+	ErrGetFills      = errors.New("get fills")
+	ErrSavePositions = errors.New("save positions")
+	ErrGetPositions  = errors.New("get positions")
+	ErrSaveDrifts    = errors.New("save portfolio drifts")
+	ErrGetDrifts     = errors.New("get portfolio drifts")
+)
+
+type PortfolioPostgres struct {
+	db *sqlx.DB
+}
+
+func NewPortfolioPostgres(db *sqlx.DB) *PortfolioPostgres {
+	return &PortfolioPostgres{db: db}
+}
+
+// inTransaction runs fn in transaction which is rolled back when fn fails
+func inTransaction(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return ErrCouldNotRollbackTransaction
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const saveFillQuery = `
+	INSERT INTO fills(fill_id, user_id, order_id, cli_order_id, symbol, side, size, price, fill_type, fill_time)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (fill_id) DO NOTHING`
+
+// SaveFills saves fills of user which are not saved yet and returns count of new fills
+func (p *PortfolioPostgres) SaveFills(userID int, fills []models.Fill) (int, error) {
+	var saved int64
+	err := inTransaction(p.db, func(tx *sqlx.Tx) error {
+		for _, fill := range fills {
+			result, err := tx.Exec(saveFillQuery, fill.ID, userID, fill.OrderID, fill.ClientOrderID, fill.Symbol,
+				fill.Side, fill.Size, fill.Price, fill.FillType, fill.FillTime)
+			if err != nil {
+				return err
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			saved += affected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrSaveFills, err)
+	}
+	return int(saved), nil
+}
+
+const getFillsQuery = `SELECT * FROM fills WHERE user_id=$1 ORDER BY fill_time DESC`
+
+func (p *PortfolioPostgres) GetFills(userID int) ([]models.Fill, error) {
+	var fills []models.Fill
+	if err := p.db.Select(&fills, getFillsQuery, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetFills, err)
+	}
+	return fills, nil
+}
+
+const deletePositionsQuery = `DELETE FROM positions WHERE user_id=$1`
+
+const savePositionQuery = `
+	INSERT INTO positions(user_id, symbol, side, size, price, fill_time)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+
+// SavePositions replaces positions of user by positions of exchange, closed positions are removed
+func (p *PortfolioPostgres) SavePositions(userID int, positions []models.Position) error {
+	err := inTransaction(p.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(deletePositionsQuery, userID); err != nil {
+			return err
+		}
+		for _, position := range positions {
+			if _, err := tx.Exec(savePositionQuery, userID, position.Symbol, position.Side, position.Size,
+				position.Price, position.FillTime); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrSavePositions, err)
+	}
+	return nil
+}
+
+const getPositionsQuery = `SELECT * FROM positions WHERE user_id=$1 ORDER BY symbol`
+
+func (p *PortfolioPostgres) GetPositions(userID int) ([]models.Position, error) {
+	var positions []models.Position
+	if err := p.db.Select(&positions, getPositionsQuery, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetPositions, err)
+	}
+	return positions, nil
+}
+
+const deleteDriftsQuery = `DELETE FROM portfolio_drifts WHERE user_id=$1`
+
+const saveDriftQuery = `
+	INSERT INTO portfolio_drifts(user_id, kind, reference, local_state, exchange_state, detected_at)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+
+// SaveDrifts replaces drifts of user by drifts found on the last sync
+func (p *PortfolioPostgres) SaveDrifts(userID int, drifts []models.PortfolioDrift) error {
+	err := inTransaction(p.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(deleteDriftsQuery, userID); err != nil {
+			return err
+		}
+		for _, drift := range drifts {
+			if _, err := tx.Exec(saveDriftQuery, userID, drift.Kind, drift.Reference, drift.LocalState,
+				drift.ExchangeState, drift.DetectedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrSaveDrifts, err)
+	}
+	return nil
+}
+
+const getDriftsQuery = `SELECT * FROM portfolio_drifts WHERE user_id=$1 ORDER BY id`
+
+func (p *PortfolioPostgres) GetDrifts(userID int) ([]models.PortfolioDrift, error) {
+	var drifts []models.PortfolioDrift
+	if err := p.db.Select(&drifts, getDriftsQuery, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetDrifts, err)
+	}
+	return drifts, nil
+}
@@ -0,0 +1,252 @@
+package postgresRepo
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var fillColumns = []string{"fill_id", "user_id", "order_id", "cli_order_id", "symbol", "side", "size", "price",
+	"fill_type", "fill_time"}
+
+func TestPortfolioPostgres_SaveFills(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewPortfolioPostgres(sqlxDB)
+
+	fillTime := time.Unix(1640000000, 0)
+	fills := []models.Fill{
+		{ID: "fill-1", OrderID: "order-1", Symbol: "pi_xbtusd", Side: "buy", Size: 1, Price: 100, FillType: "taker", FillTime: fillTime},
+		{ID: "fill-2", OrderID: "order-2", Symbol: "pi_xbtusd", Side: "sell", Size: 1, Price: 110, FillType: "maker", FillTime: fillTime},
+	}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO fills").
+					WithArgs("fill-1", 1, "order-1", "", "pi_xbtusd", "buy", 1.0, 100.0, "taker", fillTime).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO fills").
+					WithArgs("fill-2", 1, "order-2", "", "pi_xbtusd", "sell", 1.0, 110.0, "maker", fillTime).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			want: 1,
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO fills").WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.SaveFills(1, fills)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPortfolioPostgres_GetFills(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewPortfolioPostgres(sqlxDB)
+	fillTime := time.Unix(1640000000, 0)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    []models.Fill
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(fillColumns).
+					AddRow("fill-1", 1, "order-1", "", "pi_xbtusd", "buy", 1.0, 100.0, "taker", fillTime)
+				mock.ExpectQuery("SELECT (.+) FROM fills").WithArgs(1).WillReturnRows(rows)
+			},
+			want: []models.Fill{{ID: "fill-1", UserID: 1, OrderID: "order-1", Symbol: "pi_xbtusd", Side: "buy",
+				Size: 1, Price: 100, FillType: "taker", FillTime: fillTime}},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM fills").WithArgs(1).WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetFills(1)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPortfolioPostgres_SavePositions(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewPortfolioPostgres(sqlxDB)
+	fillTime := time.Unix(1640000000, 0)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM positions").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("INSERT INTO positions").
+					WithArgs(1, "pi_xbtusd", "long", 2.0, 100.0, fillTime).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM positions").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO positions").WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.SavePositions(1, []models.Position{
+				{Symbol: "pi_xbtusd", Side: "long", Size: 2, Price: 100, FillTime: fillTime},
+			})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPortfolioPostgres_SaveDrifts(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewPortfolioPostgres(sqlxDB)
+	detectedAt := time.Unix(1640000000, 0)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		drifts  []models.PortfolioDrift
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM portfolio_drifts").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO portfolio_drifts").
+					WithArgs(1, models.DriftUnknownOrder, "order-1", "", "open", detectedAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			drifts: []models.PortfolioDrift{
+				{Kind: models.DriftUnknownOrder, Reference: "order-1", ExchangeState: "open", DetectedAt: detectedAt},
+			},
+		},
+		{
+			name: "No drifts",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM portfolio_drifts").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM portfolio_drifts").WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.SaveDrifts(1, test.drifts)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package postgresRepo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrGetRiskLimits    = errors.New("get risk limits")
+	ErrUpdateRiskLimits = errors.New("update risk limits")
+)
+
+type RiskLimitsPostgres struct {
+	db *sqlx.DB
+}
+
+func NewRiskLimitsPostgres(db *sqlx.DB) *RiskLimitsPostgres {
+	return &RiskLimitsPostgres{db: db}
+}
+
+const getRiskLimitsQuery = `
+	SELECT max_order_size, max_notional, max_open_sessions, daily_loss_limit FROM risk_limits WHERE user_id=$1`
+
+// GetRiskLimits returns no limits when user has not set them yet
+func (r *RiskLimitsPostgres) GetRiskLimits(userID int) (models.RiskLimits, error) {
+	var limits models.RiskLimits
+	err := r.db.Get(&limits, getRiskLimitsQuery, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.RiskLimits{}, fmt.Errorf("%s: %w", ErrGetRiskLimits, err)
+	}
+	return limits, nil
+}
+
+const upsertRiskLimitsQuery = `
+	INSERT INTO risk_limits(user_id, max_order_size, max_notional, max_open_sessions, daily_loss_limit)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id) DO UPDATE SET max_order_size=excluded.max_order_size, max_notional=excluded.max_notional,
+		max_open_sessions=excluded.max_open_sessions, daily_loss_limit=excluded.daily_loss_limit`
+
+func (r *RiskLimitsPostgres) UpdateRiskLimits(userID int, limits models.RiskLimits) error {
+	_, err := r.db.Exec(upsertRiskLimitsQuery, userID, limits.MaxOrderSize, limits.MaxNotional,
+		limits.MaxOpenSessions, limits.DailyLossLimit)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateRiskLimits, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package postgresRepo
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var riskLimitsColumns = []string{"max_order_size", "max_notional", "max_open_sessions", "daily_loss_limit"}
+
+func TestRiskLimitsPostgres_GetRiskLimits(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewRiskLimitsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    models.RiskLimits
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(riskLimitsColumns).AddRow(10, 50000.0, 2, 300.0)
+				mock.ExpectQuery("SELECT (.+) FROM risk_limits").WithArgs(1).WillReturnRows(rows)
+			},
+			want: models.RiskLimits{MaxOrderSize: 10, MaxNotional: 50000, MaxOpenSessions: 2, DailyLossLimit: 300},
+		},
+		{
+			name: "No limits",
+			mock: func() {
+				rows := sqlmock.NewRows(riskLimitsColumns)
+				mock.ExpectQuery("SELECT (.+) FROM risk_limits").WithArgs(1).WillReturnRows(rows)
+			},
+			want: models.RiskLimits{},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM risk_limits").WithArgs(1).WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetRiskLimits(1)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRiskLimitsPostgres_UpdateRiskLimits(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewRiskLimitsPostgres(sqlxDB)
+	limits := models.RiskLimits{MaxOrderSize: 10, MaxNotional: 50000, MaxOpenSessions: 2, DailyLossLimit: 300}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO risk_limits").WithArgs(1, uint(10), 50000.0, 2, 300.0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO risk_limits").WithArgs(1, uint(10), 50000.0, 2, 300.0).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.UpdateRiskLimits(1, limits)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package postgresRepo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrGetUserSettings    = errors.New("get user settings")
+	ErrUpdateUserSettings = errors.New("update user settings")
+)
+
+type SettingsPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSettingsPostgres(db *sqlx.DB) *SettingsPostgres {
+	return &SettingsPostgres{db: db}
+}
+
+const getUserSettingsQuery = `SELECT paper_trading FROM user_settings WHERE user_id=$1`
+
+// GetUserSettings returns default settings when user has not changed them yet
+func (s *SettingsPostgres) GetUserSettings(userID int) (models.UserSettings, error) {
+	var settings models.UserSettings
+	err := s.db.Get(&settings, getUserSettingsQuery, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.UserSettings{}, fmt.Errorf("%s: %w", ErrGetUserSettings, err)
+	}
+	return settings, nil
+}
+
+const upsertUserSettingsQuery = `
+	INSERT INTO user_settings(user_id, paper_trading) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET paper_trading=excluded.paper_trading`
+
+func (s *SettingsPostgres) UpdateUserSettings(userID int, settings models.UserSettings) error {
+	if _, err := s.db.Exec(upsertUserSettingsQuery, userID, settings.PaperTrading); err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateUserSettings, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package postgresRepo
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+)
+
+func TestSettingsPostgres_GetUserSettings(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewSettingsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    models.UserSettings
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"paper_trading"}).AddRow(true)
+				mock.ExpectQuery("SELECT (.+) FROM user_settings").WithArgs(1).WillReturnRows(rows)
+			},
+			want: models.UserSettings{PaperTrading: true},
+		},
+		{
+			name: "Default settings",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"paper_trading"})
+				mock.ExpectQuery("SELECT (.+) FROM user_settings").WithArgs(1).WillReturnRows(rows)
+			},
+			want: models.UserSettings{},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM user_settings").WithArgs(1).WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetUserSettings(1)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSettingsPostgres_UpdateUserSettings(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewSettingsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO user_settings").WithArgs(1, true).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO user_settings").WithArgs(1, true).WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.UpdateUserSettings(1, models.UserSettings{PaperTrading: true})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package postgresRepo
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository/encryption"
+)
+
+var (
+	ErrSaveSignalWebhook = errors.New("save signal webhook")
+	ErrGetSignalWebhook  = errors.New("get signal webhook")
+	ErrCreateSignal      = errors.New("create signal")
+	ErrUpdateSignal      = errors.New("update signal")
+	ErrGetUserSignals    = errors.New("get user signals")
+	ErrSignalNotFound    = errors.New("signal not found")
+	ErrRotateSecrets     = errors.New("rotate signal webhook secrets")
+)
+
+type SignalsPostgres struct {
+	db      *sqlx.DB
+	keyRing *encryption.KeyRing
+}
+
+func NewSignalsPostgres(db *sqlx.DB, keyRing *encryption.KeyRing) *SignalsPostgres {
+	return &SignalsPostgres{db: db, keyRing: keyRing}
+}
+
+// webhookRow is a signal_webhooks table row with secret in encrypted form
+type webhookRow struct {
+	models.SignalWebhook
+	DataKey    string `db:"secret_data_key"`
+	KeyVersion int    `db:"secret_key_version"`
+}
+
+func (w *webhookRow) sealedSecret() encryption.SealedValues {
+	return encryption.SealedValues{
+		DataKey:    w.DataKey,
+		KeyVersion: w.KeyVersion,
+		Values:     []string{w.Secret},
+	}
+}
+
+const saveSignalWebhookQuery = `
+	INSERT INTO signal_webhooks(user_id, token, secret, secret_data_key, secret_key_version, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (user_id) DO UPDATE SET token=excluded.token, secret=excluded.secret,
+		secret_data_key=excluded.secret_data_key, secret_key_version=excluded.secret_key_version,
+		created_at=excluded.created_at`
+
+// SaveSignalWebhook replaces webhook of user, so signals sent to the old token are not accepted anymore
+func (r *SignalsPostgres) SaveSignalWebhook(webhook models.SignalWebhook) error {
+	sealed, err := r.keyRing.Seal(webhook.Secret)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrSaveSignalWebhook, err)
+	}
+
+	_, err = r.db.Exec(saveSignalWebhookQuery, webhook.UserID, webhook.Token, sealed.Values[0], sealed.DataKey,
+		sealed.KeyVersion, webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrSaveSignalWebhook, err)
+	}
+	return nil
+}
+
+const getSignalWebhookQuery = `SELECT * FROM signal_webhooks WHERE token=$1`
+
+// GetSignalWebhook returns webhook by its token with decrypted secret, error wraps sql.ErrNoRows when it is not found
+func (r *SignalsPostgres) GetSignalWebhook(token string) (models.SignalWebhook, error) {
+	var row webhookRow
+	if err := r.db.Get(&row, getSignalWebhookQuery, token); err != nil {
+		return models.SignalWebhook{}, fmt.Errorf("%s: %w", ErrGetSignalWebhook, err)
+	}
+
+	secret, err := r.keyRing.Open(row.sealedSecret())
+	if err != nil {
+		return models.SignalWebhook{}, fmt.Errorf("%s: %w", ErrGetSignalWebhook, err)
+	}
+
+	webhook := row.SignalWebhook
+	webhook.Secret = secret[0]
+	return webhook, nil
+}
+
+const getWebhooksToRotateQuery = `
+	SELECT * FROM signal_webhooks WHERE secret_key_version <> $1 FOR UPDATE`
+
+const updateWebhookSecretQuery = `
+	UPDATE signal_webhooks SET secret=$1, secret_data_key=$2, secret_key_version=$3 WHERE user_id=$4`
+
+// RotateSecrets re-encrypts secrets of webhooks which are not encrypted with current master key.
+// Returns count of rotated webhooks
+func (r *SignalsPostgres) RotateSecrets() (int, error) {
+	var rows []webhookRow
+	err := inTransaction(r.db, func(tx *sqlx.Tx) error {
+		if err := tx.Select(&rows, getWebhooksToRotateQuery, r.keyRing.CurrentVersion()); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			sealed, err := r.keyRing.Rotate(row.sealedSecret())
+			if err == nil {
+				_, err = tx.Exec(updateWebhookSecretQuery, sealed.Values[0], sealed.DataKey, sealed.KeyVersion, row.UserID)
+			}
+			if err != nil {
+				return fmt.Errorf("user %d: %w", row.UserID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrRotateSecrets, err)
+	}
+	return len(rows), nil
+}
+
+const createSignalQuery = `
+	INSERT INTO signals(user_id, signal_id, status, reason, payload, received_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (user_id, signal_id) WHERE status <> 'rejected' DO NOTHING
+	RETURNING id`
+
+// CreateSignal logs signal, created is false when pending or accepted signal of user with the same id
+// is logged already. Rejected signals never conflict
+func (r *SignalsPostgres) CreateSignal(signal models.Signal) (id int, created bool, err error) {
+	err = r.db.QueryRow(createSignalQuery, signal.UserID, signal.SignalID, signal.Status, signal.Reason,
+		signal.Payload, signal.ReceivedAt).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", ErrCreateSignal, err)
+	}
+	return id, true, nil
+}
+
+const updateSignalQuery = `UPDATE signals SET status=$1, reason=$2, order_id=$3, session_id=$4 WHERE id=$5`
+
+func (r *SignalsPostgres) UpdateSignal(signal models.Signal) error {
+	result, err := r.db.Exec(updateSignalQuery, signal.Status, signal.Reason, signal.OrderID, signal.SessionID, signal.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateSignal, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateSignal, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", ErrUpdateSignal, ErrSignalNotFound)
+	}
+	return nil
+}
+
+const getUserSignalsQuery = `SELECT * FROM signals WHERE user_id=$1 ORDER BY id DESC LIMIT $2`
+
+// GetUserSignals returns up to limit signals of user, the newest first
+func (r *SignalsPostgres) GetUserSignals(userID, limit int) ([]models.Signal, error) {
+	var signals []models.Signal
+	if err := r.db.Select(&signals, getUserSignalsQuery, userID, limit); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserSignals, err)
+	}
+	return signals, nil
+}
@@ -0,0 +1,313 @@
+package postgresRepo
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository/encryption"
+)
+
+var webhookColumns = []string{"user_id", "token", "secret", "secret_data_key", "secret_key_version", "created_at"}
+
+func TestSignalsPostgres_SaveSignalWebhook(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewSignalsPostgres(sqlxDB, newTestKeyRing(t))
+	createdAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	webhook := models.SignalWebhook{UserID: 1, Token: "token", Secret: "secret", CreatedAt: createdAt}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO signal_webhooks").
+					WithArgs(1, "token", sqlmock.AnyArg(), sqlmock.AnyArg(), 1, createdAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO signal_webhooks").
+					WithArgs(1, "token", sqlmock.AnyArg(), sqlmock.AnyArg(), 1, createdAt).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.SaveSignalWebhook(webhook)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSignalsPostgres_GetSignalWebhook(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	keyRing := newTestKeyRing(t)
+	r := NewSignalsPostgres(sqlxDB, keyRing)
+	createdAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	sealed, err := keyRing.Seal("secret")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when sealing secret", err)
+	}
+
+	tests := []struct {
+		name       string
+		mock       func()
+		want       models.SignalWebhook
+		wantErr    bool
+		wantNoRows bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(webhookColumns).
+					AddRow(1, "token", sealed.Values[0], sealed.DataKey, sealed.KeyVersion, createdAt)
+				mock.ExpectQuery("SELECT (.+) FROM signal_webhooks").WithArgs("token").WillReturnRows(rows)
+			},
+			want: models.SignalWebhook{UserID: 1, Token: "token", Secret: "secret", CreatedAt: createdAt},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM signal_webhooks").WithArgs("token").
+					WillReturnRows(sqlmock.NewRows(webhookColumns))
+			},
+			wantErr:    true,
+			wantNoRows: true,
+		},
+		{
+			name: "Tampered secret",
+			mock: func() {
+				rows := sqlmock.NewRows(webhookColumns).
+					AddRow(1, "token", "dGFtcGVyZWQ=", sealed.DataKey, sealed.KeyVersion, createdAt)
+				mock.ExpectQuery("SELECT (.+) FROM signal_webhooks").WithArgs("token").WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetSignalWebhook("token")
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, test.wantNoRows, errors.Is(err, sql.ErrNoRows))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSignalsPostgres_CreateSignal(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewSignalsPostgres(sqlxDB, newTestKeyRing(t))
+	receivedAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	signal := models.Signal{UserID: 1, SignalID: "s1", Status: models.SignalPending, Payload: "{}", ReceivedAt: receivedAt}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		wantID      int
+		wantCreated bool
+		wantErr     bool
+	}{
+		{
+			name: "Created",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO signals").
+					WithArgs(1, "s1", models.SignalPending, "", "{}", receivedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+			},
+			wantID:      7,
+			wantCreated: true,
+		},
+		{
+			name: "Duplicate",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO signals").
+					WithArgs(1, "s1", models.SignalPending, "", "{}", receivedAt).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO signals").
+					WithArgs(1, "s1", models.SignalPending, "", "{}", receivedAt).
+					WillReturnError(errors.New("db error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			id, created, err := r.CreateSignal(signal)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantID, id)
+				assert.Equal(t, test.wantCreated, created)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSignalsPostgres_UpdateSignal(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewSignalsPostgres(sqlxDB, newTestKeyRing(t))
+	signal := models.Signal{ID: 7, Status: models.SignalAccepted, OrderID: "order"}
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr error
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("UPDATE signals").WithArgs(models.SignalAccepted, "", "order", 0, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not found",
+			mock: func() {
+				mock.ExpectExec("UPDATE signals").WithArgs(models.SignalAccepted, "", "order", 0, 7).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrSignalNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.UpdateSignal(signal)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSignalsPostgres_RotateSecrets(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	keyRing := newTestKeyRing(t)
+	r := NewSignalsPostgres(sqlxDB, keyRing)
+	createdAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows(webhookColumns).
+					AddRow(1, "token", "secret", "", encryption.PlaintextKeyVersion, createdAt)
+				mock.ExpectQuery("SELECT (.+) FROM signal_webhooks").
+					WithArgs(keyRing.CurrentVersion()).WillReturnRows(rows)
+				mock.ExpectExec("UPDATE signal_webhooks").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), keyRing.CurrentVersion(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			want: 1,
+		},
+		{
+			name: "Unknown key version",
+			mock: func() {
+				mock.ExpectBegin()
+				rows := sqlmock.NewRows(webhookColumns).AddRow(1, "token", "secret", "key", 5, createdAt)
+				mock.ExpectQuery("SELECT (.+) FROM signal_webhooks").
+					WithArgs(keyRing.CurrentVersion()).WillReturnRows(rows)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.RotateSecrets()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
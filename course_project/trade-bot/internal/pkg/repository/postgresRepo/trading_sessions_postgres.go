@@ -0,0 +1,202 @@
+package postgresRepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrCreateSession     = errors.New("create trading session")
+	ErrUpdateSession     = errors.New("update trading session")
+	ErrGetSession        = errors.New("get trading session")
+	ErrGetUserSessions   = errors.New("get user trading sessions")
+	ErrGetActiveSessions = errors.New("get active trading sessions")
+	ErrClaimSession      = errors.New("claim trading session lease")
+	ErrRenewSession      = errors.New("renew trading session lease")
+	ErrReleaseSession    = errors.New("release trading session lease")
+	ErrSessionNotFound   = errors.New("trading session not found")
+	ErrMarshalSession    = errors.New("marshal trading session details")
+	ErrUnmarshalSession  = errors.New("unmarshal trading session details")
+)
+
+var inactiveSessionStatuses = []interface{}{models.SessionClosed, models.SessionCancelled, models.SessionFailed}
+
+type TradingSessionsPostgres struct {
+	db *sqlx.DB
+}
+
+func NewTradingSessionsPostgres(db *sqlx.DB) *TradingSessionsPostgres {
+	return &TradingSessionsPostgres{db: db}
+}
+
+// sessionRow is a trading_sessions table row with details in json form
+type sessionRow struct {
+	models.TradingSession
+	Details []byte `db:"details"`
+}
+
+func (r sessionRow) toSession() (models.TradingSession, error) {
+	session := r.TradingSession
+	if err := json.Unmarshal(r.Details, &session.Details); err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrUnmarshalSession, err)
+	}
+	return session, nil
+}
+
+func rowsToSessions(rows []sessionRow) ([]models.TradingSession, error) {
+	sessions := make([]models.TradingSession, 0, len(rows))
+	for _, row := range rows {
+		session, err := row.toSession()
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+const createSessionQuery = `
+	WITH session AS (
+		INSERT INTO trading_sessions(user_id, status, details, paper_trading)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	)
+	INSERT INTO trading_session_leases(session_id, owner, lease_until)
+	SELECT id, $5, now() + make_interval(secs => $6) FROM session
+	RETURNING session_id`
+
+// CreateSession saves session together with its lease held by owner, so other replicas don't take it over
+// before owner starts watching it
+func (t *TradingSessionsPostgres) CreateSession(session models.TradingSession, owner string, lease time.Duration) (int, error) {
+	details, err := json.Marshal(session.Details)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s: %w", ErrCreateSession, ErrMarshalSession, err)
+	}
+
+	var id int
+	err = t.db.QueryRow(createSessionQuery, session.UserID, session.Status, details, session.PaperTrading, owner,
+		lease.Seconds()).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrCreateSession, err)
+	}
+	return id, nil
+}
+
+const claimSessionQuery = `
+	INSERT INTO trading_session_leases(session_id, owner, lease_until)
+	VALUES ($1, $2, now() + make_interval(secs => $3))
+	ON CONFLICT (session_id) DO UPDATE
+	SET owner=EXCLUDED.owner, lease_until=EXCLUDED.lease_until
+	WHERE trading_session_leases.lease_until < now()`
+
+// ClaimSession takes lease of session for owner when session has no lease or its lease has expired.
+// False is returned when lease of session is held already
+func (t *TradingSessionsPostgres) ClaimSession(sessionID int, owner string, lease time.Duration) (bool, error) {
+	result, err := t.db.Exec(claimSessionQuery, sessionID, owner, lease.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", ErrClaimSession, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", ErrClaimSession, err)
+	}
+	return affected > 0, nil
+}
+
+const renewSessionQuery = `
+	UPDATE trading_session_leases
+	SET lease_until=now() + make_interval(secs => $3)
+	WHERE session_id=$1 AND owner=$2`
+
+// RenewSession prolongs lease of session held by owner. False is returned when lease has been taken over
+func (t *TradingSessionsPostgres) RenewSession(sessionID int, owner string, lease time.Duration) (bool, error) {
+	result, err := t.db.Exec(renewSessionQuery, sessionID, owner, lease.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", ErrRenewSession, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", ErrRenewSession, err)
+	}
+	return affected > 0, nil
+}
+
+const releaseSessionQuery = `DELETE FROM trading_session_leases WHERE session_id=$1 AND owner=$2`
+
+// ReleaseSession drops lease of session held by owner, so other replicas may take session over right away
+func (t *TradingSessionsPostgres) ReleaseSession(sessionID int, owner string) error {
+	if _, err := t.db.Exec(releaseSessionQuery, sessionID, owner); err != nil {
+		return fmt.Errorf("%s: %w", ErrReleaseSession, err)
+	}
+	return nil
+}
+
+const updateSessionQuery = `
+	UPDATE trading_sessions
+	SET status=$1, details=$2, entry_order_id=$3, entry_price=$4, entry_time=$5,
+	    stop_loss_order_id=$6, take_profit_order_id=$7, exit_order_id=$8, exit_price=$9, error=$10,
+	    updated_at=now()
+	WHERE id=$11`
+
+func (t *TradingSessionsPostgres) UpdateSession(session models.TradingSession) error {
+	details, err := json.Marshal(session.Details)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", ErrUpdateSession, ErrMarshalSession, err)
+	}
+
+	result, err := t.db.Exec(updateSessionQuery, session.Status, details, session.EntryOrderID, session.EntryPrice,
+		session.EntryTime, session.StopLossOrderID, session.TakeProfitOrderID, session.ExitOrderID, session.ExitPrice,
+		session.Error, session.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateSession, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateSession, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s: %w", ErrUpdateSession, ErrSessionNotFound)
+	}
+	return nil
+}
+
+const getSessionQuery = `SELECT * FROM trading_sessions WHERE id=$1`
+
+func (t *TradingSessionsPostgres) GetSession(sessionID int) (models.TradingSession, error) {
+	var row sessionRow
+	if err := t.db.Get(&row, getSessionQuery, sessionID); err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrGetSession, err)
+	}
+	return row.toSession()
+}
+
+const getUserSessionsQuery = `SELECT * FROM trading_sessions WHERE user_id=$1 ORDER BY id DESC`
+
+func (t *TradingSessionsPostgres) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	var rows []sessionRow
+	if err := t.db.Select(&rows, getUserSessionsQuery, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserSessions, err)
+	}
+	return rowsToSessions(rows)
+}
+
+const getActiveSessionsQuery = `
+	SELECT * FROM trading_sessions WHERE status NOT IN ($1, $2, $3) ORDER BY id`
+
+// GetActiveSessions returns sessions of all users which are not closed, cancelled or failed yet
+func (t *TradingSessionsPostgres) GetActiveSessions() ([]models.TradingSession, error) {
+	var rows []sessionRow
+	if err := t.db.Select(&rows, getActiveSessionsQuery, inactiveSessionStatuses...); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetActiveSessions, err)
+	}
+	return rowsToSessions(rows)
+}
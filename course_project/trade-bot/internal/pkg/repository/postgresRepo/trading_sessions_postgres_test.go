@@ -0,0 +1,350 @@
+package postgresRepo
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+var sessionColumns = []string{"id", "user_id", "status", "details", "entry_order_id", "entry_price", "entry_time",
+	"exit_order_id", "exit_price", "error", "created_at", "updated_at", "stop_loss_order_id", "take_profit_order_id",
+	"paper_trading"}
+
+func TestTradingSessionsPostgres_CreateSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+				mock.ExpectQuery("INSERT INTO trading_sessions").
+					WithArgs(1, models.SessionStarting, []byte(`{"order_type":"mkt","symbol":"pi_xbtusd","side":"buy","size":1,"BuyPrice":0}`),
+						true, "replica", 60.0).
+					WillReturnRows(rows)
+			},
+			want: 1,
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectQuery("INSERT INTO trading_sessions").WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.CreateSession(models.TradingSession{
+				UserID:       1,
+				Status:       models.SessionStarting,
+				Details:      types.TradingDetails{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: 1},
+				PaperTrading: true,
+			}, "replica", time.Minute)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTradingSessionsPostgres_ClaimSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Claimed",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO trading_session_leases").WithArgs(1, "replica", 60.0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want: true,
+		},
+		{
+			name: "Lease is held",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO trading_session_leases").WithArgs(1, "replica", 60.0).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectExec("INSERT INTO trading_session_leases").WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.ClaimSession(1, "replica", time.Minute)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTradingSessionsPostgres_RenewSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Renewed",
+			mock: func() {
+				mock.ExpectExec("UPDATE trading_session_leases").WithArgs(1, "replica", 60.0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want: true,
+		},
+		{
+			name: "Taken over",
+			mock: func() {
+				mock.ExpectExec("UPDATE trading_session_leases").WithArgs(1, "replica", 60.0).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		{
+			name: "DB error",
+			mock: func() {
+				mock.ExpectExec("UPDATE trading_session_leases").WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.RenewSession(1, "replica", time.Minute)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTradingSessionsPostgres_ReleaseSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+
+	mock.ExpectExec("DELETE FROM trading_session_leases").WithArgs(1, "replica").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, r.ReleaseSession(1, "replica"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTradingSessionsPostgres_UpdateSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+	entryTime := time.Unix(1640000000, 0)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				mock.ExpectExec("UPDATE trading_sessions").
+					WithArgs(models.SessionMonitoring, sqlmock.AnyArg(), "order", 100.0, &entryTime, "stop", "profit", "", 0.0, "", 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectExec("UPDATE trading_sessions").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			err := r.UpdateSession(models.TradingSession{
+				ID:                1,
+				Status:            models.SessionMonitoring,
+				EntryOrderID:      "order",
+				EntryPrice:        100,
+				EntryTime:         &entryTime,
+				StopLossOrderID:   "stop",
+				TakeProfitOrderID: "profit",
+			})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTradingSessionsPostgres_GetSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+	createdAt := time.Unix(1640000000, 0)
+
+	tests := []struct {
+		name    string
+		mock    func()
+		want    models.TradingSession
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			mock: func() {
+				rows := sqlmock.NewRows(sessionColumns).
+					AddRow(1, 2, models.SessionClosed, []byte(`{"symbol":"pi_xbtusd","strategy":"trailing_stop"}`),
+						"entry", 100.0, createdAt, "exit", 110.0, "", createdAt, createdAt, "stop", "profit", false)
+				mock.ExpectQuery("SELECT (.+) FROM trading_sessions").WithArgs(1).WillReturnRows(rows)
+			},
+			want: models.TradingSession{
+				ID:                1,
+				UserID:            2,
+				Status:            models.SessionClosed,
+				Details:           types.TradingDetails{Symbol: "pi_xbtusd", Strategy: "trailing_stop"},
+				EntryOrderID:      "entry",
+				EntryPrice:        100,
+				EntryTime:         &createdAt,
+				StopLossOrderID:   "stop",
+				TakeProfitOrderID: "profit",
+				ExitOrderID:       "exit",
+				ExitPrice:         110,
+				CreatedAt:         createdAt,
+				UpdatedAt:         createdAt,
+			},
+		},
+		{
+			name: "Not Found",
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM trading_sessions").WithArgs(1).WillReturnRows(sqlmock.NewRows(sessionColumns))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock()
+
+			got, err := r.GetSession(1)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTradingSessionsPostgres_GetActiveSessions(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+	createdAt := time.Unix(1640000000, 0)
+
+	rows := sqlmock.NewRows(sessionColumns).
+		AddRow(1, 1, models.SessionMonitoring, []byte(`{}`), "entry", 100.0, createdAt, "", 0.0, "", createdAt, createdAt, "", "", false).
+		AddRow(2, 1, models.SessionClosing, []byte(`{}`), "entry", 100.0, createdAt, "", 0.0, "", createdAt, createdAt, "", "", true)
+	mock.ExpectQuery("SELECT (.+) FROM trading_sessions WHERE status NOT IN").
+		WithArgs(models.SessionClosed, models.SessionCancelled, models.SessionFailed).WillReturnRows(rows)
+
+	got, err := r.GetActiveSessions()
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, models.SessionClosing, got[1].Status)
+	assert.True(t, got[1].PaperTrading)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,14 +2,33 @@ package redisRepo
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
 
+	"trade-bot/internal/pkg/models"
 	"trade-bot/pkg/utils"
 )
 
+var (
+	ErrCreateSession    = errors.New("create session")
+	ErrGetSession       = errors.New("get session")
+	ErrGetUserSessions  = errors.New("get user sessions")
+	ErrRotateSession    = errors.New("rotate session")
+	ErrDeleteSession    = errors.New("delete session")
+	ErrUnmarshalSession = errors.New("unmarshal session")
+	ErrMarshalSession   = errors.New("marshal session")
+)
+
+const (
+	sessionKeyPrefix      = "auth_session:"
+	userSessionsKeyPrefix = "auth_sessions:"
+)
+
 type JWTRedis struct {
 	client *redis.Client
 }
@@ -18,16 +37,17 @@ func NewJWTRedis(client *redis.Client) *JWTRedis {
 	return &JWTRedis{client: client}
 }
 
-func (r *JWTRedis) CreateJWT(userID int, td utils.TokenDetails) (string, error) {
-	at := time.Unix(td.AtExpires, 0)
-	now := time.Now()
-
-	errAccess := r.client.Set(context.Background(), td.AccessUUID, strconv.Itoa(userID), at.Sub(now)).Err()
-	if errAccess != nil {
-		return "", errAccess
-	}
-
-	return td.AccessToken, nil
+// sessionRecord is a value of session key, it keeps uuids of current tokens hidden from json of models.AuthSession
+type sessionRecord struct {
+	ID          string    `json:"id"`
+	UserID      int       `json:"user_id"`
+	Device      string    `json:"device"`
+	IP          string    `json:"ip"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	AccessUUID  string    `json:"access_uuid"`
+	RefreshUUID string    `json:"refresh_uuid"`
 }
 
 func (r *JWTRedis) GetJWTUserID(ad utils.AccessDetails) (int, error) {
@@ -46,3 +66,165 @@ func (r *JWTRedis) DeleteJWT(ad utils.AccessDetails) error {
 	_, err := r.client.Del(context.Background(), ad.AccessUUID).Result()
 	return err
 }
+
+// CreateSession saves session with access token of td, session expires together with refresh token
+func (r *JWTRedis) CreateSession(session models.AuthSession, td utils.TokenDetails) error {
+	ctx := context.Background()
+	data, err := marshalSession(session, td)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCreateSession, err)
+	}
+
+	now := time.Now()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, td.AccessUUID, strconv.Itoa(session.UserID), time.Unix(td.AtExpires, 0).Sub(now))
+		pipe.Set(ctx, sessionKey(session.ID), data, time.Unix(td.RtExpires, 0).Sub(now))
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCreateSession, err)
+	}
+	return nil
+}
+
+// GetSession returns false when session is expired or revoked
+func (r *JWTRedis) GetSession(sessionID string) (models.AuthSession, bool, error) {
+	session, ok, err := getSession(context.Background(), r.client, sessionID)
+	if err != nil {
+		return models.AuthSession{}, false, fmt.Errorf("%s: %w", ErrGetSession, err)
+	}
+	return session, ok, nil
+}
+
+// GetUserSessions returns active sessions of user, expired ones are removed from set of user sessions
+func (r *JWTRedis) GetUserSessions(userID int) ([]models.AuthSession, error) {
+	ctx := context.Background()
+	ids, err := r.client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserSessions, err)
+	}
+
+	sessions := make([]models.AuthSession, 0, len(ids))
+	for _, id := range ids {
+		session, ok, err := getSession(ctx, r.client, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrGetUserSessions, err)
+		}
+		if !ok {
+			if err := r.client.SRem(ctx, userSessionsKey(userID), id).Err(); err != nil {
+				return nil, fmt.Errorf("%s: %w", ErrGetUserSessions, err)
+			}
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// RotateSession replaces tokens of session by td if refresh token with refreshUUID is still current one.
+// False is returned when session is gone or its refresh token has been already rotated
+func (r *JWTRedis) RotateSession(session models.AuthSession, refreshUUID string, td utils.TokenDetails) (bool, error) {
+	ctx := context.Background()
+	key := sessionKey(session.ID)
+
+	rotated := false
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, ok, err := getSession(ctx, tx, session.ID)
+		if err != nil {
+			return err
+		}
+		if !ok || current.RefreshUUID != refreshUUID {
+			return nil
+		}
+
+		data, err := marshalSession(session, td)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, current.AccessUUID)
+			pipe.Set(ctx, td.AccessUUID, strconv.Itoa(session.UserID), time.Unix(td.AtExpires, 0).Sub(now))
+			pipe.Set(ctx, key, data, time.Unix(td.RtExpires, 0).Sub(now))
+			return nil
+		})
+		rotated = err == nil
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		// session was changed by concurrent refresh, so refresh token of this request is not current anymore
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", ErrRotateSession, err)
+	}
+	return rotated, nil
+}
+
+// DeleteSession revokes session with its current access token
+func (r *JWTRedis) DeleteSession(session models.AuthSession) error {
+	ctx := context.Background()
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, session.AccessUUID, sessionKey(session.ID))
+		pipe.SRem(ctx, userSessionsKey(session.UserID), session.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrDeleteSession, err)
+	}
+	return nil
+}
+
+func getSession(ctx context.Context, client redis.Cmdable, sessionID string) (models.AuthSession, bool, error) {
+	data, err := client.Get(ctx, sessionKey(sessionID)).Bytes()
+	if err == redis.Nil {
+		return models.AuthSession{}, false, nil
+	}
+	if err != nil {
+		return models.AuthSession{}, false, err
+	}
+
+	var record sessionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return models.AuthSession{}, false, fmt.Errorf("%s: %w", ErrUnmarshalSession, err)
+	}
+	return models.AuthSession{
+		ID:          record.ID,
+		UserID:      record.UserID,
+		Device:      record.Device,
+		IP:          record.IP,
+		CreatedAt:   record.CreatedAt,
+		LastUsedAt:  record.LastUsedAt,
+		ExpiresAt:   record.ExpiresAt,
+		AccessUUID:  record.AccessUUID,
+		RefreshUUID: record.RefreshUUID,
+	}, true, nil
+}
+
+// marshalSession encodes session with uuids and expiration of tokens of td
+func marshalSession(session models.AuthSession, td utils.TokenDetails) ([]byte, error) {
+	data, err := json.Marshal(sessionRecord{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		Device:      session.Device,
+		IP:          session.IP,
+		CreatedAt:   session.CreatedAt,
+		LastUsedAt:  session.LastUsedAt,
+		ExpiresAt:   time.Unix(td.RtExpires, 0).UTC(),
+		AccessUUID:  td.AccessUUID,
+		RefreshUUID: td.RefreshUUID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrMarshalSession, err)
+	}
+	return data, nil
+}
+
+func sessionKey(sessionID string) string {
+	return sessionKeyPrefix + sessionID
+}
+
+func userSessionsKey(userID int) string {
+	return userSessionsKeyPrefix + strconv.Itoa(userID)
+}
@@ -3,6 +3,8 @@ package redisRepo
 import (
 	"strconv"
 	"testing"
+	"time"
+	"trade-bot/internal/pkg/models"
 	"trade-bot/pkg/utils"
 
 	"github.com/alicebob/miniredis/v2"
@@ -12,58 +14,6 @@ import (
 	"golang.org/x/net/context"
 )
 
-func TestJWTRedis_CreateJWT(t *testing.T) {
-	mr, err := miniredis.Run()
-	if err != nil {
-		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer mr.Close()
-
-	c := redis.NewClient(&redis.Options{
-		Addr: mr.Addr(),
-	})
-
-	r := NewJWTRedis(c)
-
-	type args struct {
-		userID int
-		td     utils.TokenDetails
-	}
-
-	tests := []struct {
-		name    string
-		args    args
-		want    string
-		wantErr bool
-	}{
-		{
-			name: "OK",
-			args: args{
-				userID: 1,
-				td: utils.TokenDetails{
-					AccessToken: "token",
-					AccessUUID:  "accessUUID",
-					AtExpires:   1,
-				},
-			},
-			want: "token",
-		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			got, err := r.CreateJWT(test.args.userID, test.args.td)
-			if test.wantErr {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, test.want, got)
-			}
-			mr.FlushAll()
-		})
-	}
-}
-
 func TestJWTRedis_GetJWTUserID(t *testing.T) {
 	mr, err := miniredis.Run()
 	if err != nil {
@@ -184,3 +134,74 @@ func TestJWTRedis_DeleteJWT(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTRedis_Sessions(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mr.Close()
+
+	c := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	r := NewJWTRedis(c)
+
+	now := time.Now()
+	session := models.AuthSession{ID: "session", UserID: 1, Device: "telegram", IP: "192.0.2.1",
+		CreatedAt: now.UTC().Truncate(time.Second), LastUsedAt: now.UTC().Truncate(time.Second)}
+	td := utils.TokenDetails{AccessUUID: "access", AtExpires: now.Add(time.Minute).Unix(),
+		RefreshUUID: "refresh", RtExpires: now.Add(time.Hour).Unix(), SessionID: "session"}
+
+	if err := r.CreateSession(session, td); err != nil {
+		t.Fatalf("unexpected error of create session: %s", err)
+	}
+
+	userID, err := r.GetJWTUserID(utils.AccessDetails{AccessUUID: "access"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, userID)
+
+	got, ok, err := r.GetSession("session")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "access", got.AccessUUID)
+	assert.Equal(t, "refresh", got.RefreshUUID)
+	assert.Equal(t, time.Unix(td.RtExpires, 0).UTC(), got.ExpiresAt)
+	assert.Equal(t, session.Device, got.Device)
+
+	next := utils.TokenDetails{AccessUUID: "next access", AtExpires: now.Add(time.Minute).Unix(),
+		RefreshUUID: "next refresh", RtExpires: now.Add(time.Hour).Unix(), SessionID: "session"}
+
+	rotated, err := r.RotateSession(session, "stale refresh", next)
+	assert.NoError(t, err)
+	assert.False(t, rotated)
+
+	rotated, err = r.RotateSession(session, "refresh", next)
+	assert.NoError(t, err)
+	assert.True(t, rotated)
+	assert.False(t, mr.Exists("access"))
+	got, _, err = r.GetSession("session")
+	assert.NoError(t, err)
+	assert.Equal(t, "next refresh", got.RefreshUUID)
+
+	sessions, err := r.GetUserSessions(1)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	assert.NoError(t, r.DeleteSession(got))
+	assert.False(t, mr.Exists("next access"))
+	_, ok, err = r.GetSession("session")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// expired sessions are removed from set of user sessions
+	if err := r.CreateSession(session, td); err != nil {
+		t.Fatalf("unexpected error of create session: %s", err)
+	}
+	mr.FastForward(2 * time.Hour)
+	sessions, err = r.GetUserSessions(1)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+	assert.False(t, mr.Exists(userSessionsKey(1)))
+}
@@ -0,0 +1,53 @@
+package redisRepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrGetMarketData = errors.New("get market data")
+	ErrSetMarketData = errors.New("set market data")
+)
+
+// MarketCacheRedis keeps public market data as json values which expire after their ttl
+type MarketCacheRedis struct {
+	client *redis.Client
+}
+
+func NewMarketCacheRedis(client *redis.Client) *MarketCacheRedis {
+	return &MarketCacheRedis{client: client}
+}
+
+// GetMarketData decodes cached value of key into value, false is returned when key is missed or expired
+func (r *MarketCacheRedis) GetMarketData(key string, value interface{}) (bool, error) {
+	data, err := r.client.Get(context.Background(), key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", ErrGetMarketData, err)
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("%s: %w", ErrGetMarketData, err)
+	}
+	return true, nil
+}
+
+func (r *MarketCacheRedis) SetMarketData(key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrSetMarketData, err)
+	}
+
+	if err := r.client.Set(context.Background(), key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("%s: %w", ErrSetMarketData, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package redisRepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestMarketCacheRedis_GetMarketData(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mr.Close()
+
+	r := NewMarketCacheRedis(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	tickers := []krakenFuturesSDK.Ticker{{Symbol: "PI_XBTUSD", Last: 28000.5}}
+
+	tests := []struct {
+		name    string
+		prepare func()
+		want    []krakenFuturesSDK.Ticker
+		wantHit bool
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			prepare: func() {
+				if err := r.SetMarketData("market:tickers", tickers, time.Second); err != nil {
+					t.Fatalf("an error '%s' was not expected when caching tickers", err)
+				}
+			},
+			want:    tickers,
+			wantHit: true,
+		},
+		{
+			name: "Expired",
+			prepare: func() {
+				if err := r.SetMarketData("market:tickers", tickers, time.Second); err != nil {
+					t.Fatalf("an error '%s' was not expected when caching tickers", err)
+				}
+				mr.FastForward(2 * time.Second)
+			},
+		},
+		{
+			name:    "Missed",
+			prepare: func() {},
+		},
+		{
+			name: "Invalid value",
+			prepare: func() {
+				if err := r.client.Set(context.Background(), "market:tickers", "{", 0).Err(); err != nil {
+					t.Fatalf("an error '%s' was not expected when setting value", err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.prepare()
+
+			var got []krakenFuturesSDK.Ticker
+			hit, err := r.GetMarketData("market:tickers", &got)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantHit, hit)
+				assert.Equal(t, test.want, got)
+			}
+
+			mr.FlushAll()
+		})
+	}
+}
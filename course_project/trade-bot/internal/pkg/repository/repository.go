@@ -1,12 +1,16 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/go-redis/redis/v8"
 	"github.com/jmoiron/sqlx"
 
 	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository/encryption"
 	"trade-bot/internal/pkg/repository/postgresRepo"
 	"trade-bot/internal/pkg/repository/redisRepo"
+	"trade-bot/pkg/krakenFuturesWSSDK"
 	"trade-bot/pkg/utils"
 )
 
@@ -14,30 +18,116 @@ type Authorization interface {
 	CreateUser(models.User) (int, error)
 	GetUser(username string) (models.User, error)
 	GetUserAPIKeys(userID int) (string, string, error)
+	GetUserIDs() ([]int, error)
 }
 
 type JWT interface {
-	CreateJWT(userID int, td utils.TokenDetails) (string, error)
 	GetJWTUserID(ad utils.AccessDetails) (int, error)
 	DeleteJWT(ad utils.AccessDetails) error
+	CreateSession(session models.AuthSession, td utils.TokenDetails) error
+	GetSession(sessionID string) (models.AuthSession, bool, error)
+	GetUserSessions(userID int) ([]models.AuthSession, error)
+	RotateSession(session models.AuthSession, refreshUUID string, td utils.TokenDetails) (bool, error)
+	DeleteSession(session models.AuthSession) error
 }
 
 type KrakenOrdersManager interface {
 	CreateOrder(userID int, order models.Order) error
-	GetUserOrders(userID int) ([]models.Order, error)
+	FindUserOrders(userID int, filter models.OrdersFilter) ([]models.Order, error)
 	GetOrder(orderID string) (models.Order, error)
+	UpdateOrder(userID int, order models.Order) error
+	UpdateOrderStatus(userID int, orderID, status string) error
+	GetUsersWithOpenOrders() ([]int, error)
+}
+
+type Candles interface {
+	GetCandles(symbol, interval string, from, to time.Time) ([]krakenFuturesWSSDK.Candle, error)
+	SaveCandles(symbol, interval string, candles []krakenFuturesWSSDK.Candle) error
+}
+
+type Settings interface {
+	GetUserSettings(userID int) (models.UserSettings, error)
+	UpdateUserSettings(userID int, settings models.UserSettings) error
+}
+
+type RiskLimits interface {
+	GetRiskLimits(userID int) (models.RiskLimits, error)
+	UpdateRiskLimits(userID int, limits models.RiskLimits) error
+}
+
+// TradingSessions keeps trading sessions and leases of replicas which watch them
+type TradingSessions interface {
+	CreateSession(session models.TradingSession, owner string, lease time.Duration) (int, error)
+	ClaimSession(sessionID int, owner string, lease time.Duration) (bool, error)
+	RenewSession(sessionID int, owner string, lease time.Duration) (bool, error)
+	ReleaseSession(sessionID int, owner string) error
+	UpdateSession(session models.TradingSession) error
+	GetSession(sessionID int) (models.TradingSession, error)
+	GetUserSessions(userID int) ([]models.TradingSession, error)
+	GetActiveSessions() ([]models.TradingSession, error)
+}
+
+type Portfolio interface {
+	SaveFills(userID int, fills []models.Fill) (int, error)
+	GetFills(userID int) ([]models.Fill, error)
+	SavePositions(userID int, positions []models.Position) error
+	GetPositions(userID int) ([]models.Position, error)
+	SaveDrifts(userID int, drifts []models.PortfolioDrift) error
+	GetDrifts(userID int) ([]models.PortfolioDrift, error)
+}
+
+// Signals keeps webhooks of users for signals of external tools and log of received signals
+type Signals interface {
+	SaveSignalWebhook(webhook models.SignalWebhook) error
+	GetSignalWebhook(token string) (models.SignalWebhook, error)
+	CreateSignal(signal models.Signal) (int, bool, error)
+	UpdateSignal(signal models.Signal) error
+	GetUserSignals(userID, limit int) ([]models.Signal, error)
+}
+
+// Notifications keeps preferences of users and outbox of notifications
+type Notifications interface {
+	GetNotificationPreferences(userID int) (models.NotificationPreferences, error)
+	GetAllNotificationPreferences() (map[int]models.NotificationPreferences, error)
+	UpdateNotificationPreferences(userID int, preferences models.NotificationPreferences) error
+	CreateNotifications(notifications []models.Notification) error
+	LeaseNotifications(now, leaseUntil time.Time, limit int) ([]models.Notification, error)
+	UpdateNotification(notification models.Notification) error
+	GetUserNotifications(userID, limit int) ([]models.Notification, error)
+}
+
+// MarketCache keeps public market data of exchange for a short time
+type MarketCache interface {
+	GetMarketData(key string, value interface{}) (bool, error)
+	SetMarketData(key string, value interface{}, ttl time.Duration) error
 }
 
 type Repository struct {
 	Authorization
 	JWT
 	KrakenOrdersManager
+	Candles
+	Settings
+	RiskLimits
+	TradingSessions
+	Portfolio
+	Signals
+	Notifications
+	MarketCache
 }
 
-func NewRepository(db *sqlx.DB, jwtDB *redis.Client) *Repository {
+func NewRepository(db *sqlx.DB, jwtDB *redis.Client, keyRing *encryption.KeyRing) *Repository {
 	return &Repository{
-		Authorization:       postgresRepo.NewAuthPostgres(db),
+		Authorization:       postgresRepo.NewAuthPostgres(db, keyRing),
 		JWT:                 redisRepo.NewJWTRedis(jwtDB),
 		KrakenOrdersManager: postgresRepo.NewKrakenOrdersManagerPostgres(db),
+		Candles:             postgresRepo.NewCandlesPostgres(db),
+		Settings:            postgresRepo.NewSettingsPostgres(db),
+		RiskLimits:          postgresRepo.NewRiskLimitsPostgres(db),
+		TradingSessions:     postgresRepo.NewTradingSessionsPostgres(db),
+		Portfolio:           postgresRepo.NewPortfolioPostgres(db),
+		Signals:             postgresRepo.NewSignalsPostgres(db, keyRing),
+		Notifications:       postgresRepo.NewNotificationsPostgres(db),
+		MarketCache:         redisRepo.NewMarketCacheRedis(jwtDB),
 	}
 }
@@ -2,31 +2,65 @@ package service
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
 
+	"trade-bot/configs"
 	"trade-bot/internal/pkg/models"
 	"trade-bot/internal/pkg/repository"
 	"trade-bot/pkg/utils"
 )
 
 var (
-	ErrCreateUser         = errors.New("create user")
-	ErrGenerateJWT        = errors.New("generate jwt")
-	ErrGetUserIDByJWT     = errors.New("get user id by jwt")
-	ErrLogoutUser         = errors.New("logout user")
-	ErrGetUserAPIKeys     = errors.New("get user api keys")
-	ErrMismatchedPassword = errors.New("mismatched password")
+	ErrCreateUser          = errors.New("create user")
+	ErrGenerateJWT         = errors.New("generate jwt")
+	ErrRefreshJWT          = errors.New("refresh jwt")
+	ErrGetUserIDByJWT      = errors.New("get user id by jwt")
+	ErrLogoutUser          = errors.New("logout user")
+	ErrGetUserAPIKeys      = errors.New("get user api keys")
+	ErrGetUserSessions     = errors.New("get user sessions")
+	ErrRevokeSession       = errors.New("revoke session")
+	ErrMismatchedPassword  = errors.New("mismatched password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token is reused, session is revoked")
+	ErrAuthSessionNotFound = errors.New("session not found")
 )
 
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// AuthService signs in users with sessions of short-lived access tokens and rotating refresh tokens.
+// Refresh token used second time means it was stolen, so whole session is revoked
 type AuthService struct {
-	repo    repository.Authorization
-	jwtRepo repository.JWT
+	repo       repository.Authorization
+	jwtRepo    repository.JWT
+	jwtManager *utils.JWTManager
+	accessTTL  time.Duration
+	now        func() time.Time
 }
 
-func NewAuthService(repo repository.Authorization, jwtRepo repository.JWT) *AuthService {
-	return &AuthService{repo: repo, jwtRepo: jwtRepo}
+func NewAuthService(repo repository.Authorization, jwtRepo repository.JWT, cfg configs.AuthConfiguration) *AuthService {
+	accessTTL := time.Duration(cfg.AccessTokenTTLInMinutes) * time.Minute
+	if accessTTL <= 0 {
+		accessTTL = defaultAccessTokenTTL
+	}
+	refreshTTL := time.Duration(cfg.RefreshTokenTTLInHours) * time.Hour
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+
+	return &AuthService{
+		repo:       repo,
+		jwtRepo:    jwtRepo,
+		jwtManager: utils.NewJWTManager(cfg.SigningKey, accessTTL, refreshTTL),
+		accessTTL:  accessTTL,
+		now:        time.Now,
+	}
 }
 
 func (s *AuthService) CreateUser(user models.User) (int, error) {
@@ -41,28 +75,80 @@ func (s *AuthService) CreateUser(user models.User) (int, error) {
 	return userID, nil
 }
 
-func (s *AuthService) GenerateJWT(username string, password string) (string, error) {
+// GenerateJWT checks credentials of user and starts new session of client
+func (s *AuthService) GenerateJWT(username string, password string, client models.SessionClient) (models.Tokens, error) {
 	user, err := s.repo.GetUser(username)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", ErrGenerateJWT, err)
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrGenerateJWT, err)
 	}
 	if ok := user.ComparePassword(password); !ok {
-		return "", fmt.Errorf("%s: %w", ErrGenerateJWT, ErrMismatchedPassword)
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrGenerateJWT, ErrMismatchedPassword)
+	}
+
+	sessionID, err := uuid.NewV4()
+	if err != nil {
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrGenerateJWT, err)
+	}
+	now := s.now().UTC()
+	td, err := s.jwtManager.GenerateTokens(user.ID, sessionID.String(), now)
+	if err != nil {
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrGenerateJWT, err)
+	}
+
+	session := models.AuthSession{
+		ID:         td.SessionID,
+		UserID:     user.ID,
+		Device:     client.Device,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
 	}
+	if err := s.jwtRepo.CreateSession(session, td); err != nil {
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrGenerateJWT, err)
+	}
+	return s.tokens(td), nil
+}
 
-	td, err := utils.GenerateJWTToken(user.ID, time.Hour*12)
+// RefreshJWT rotates tokens of session by its current refresh token
+func (s *AuthService) RefreshJWT(refreshToken string, client models.SessionClient) (models.Tokens, error) {
+	rd, err := s.jwtManager.ExtractRefreshTokenMetadata(refreshToken)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", ErrGenerateJWT, err)
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrRefreshJWT, ErrInvalidRefreshToken)
 	}
-	token, err := s.jwtRepo.CreateJWT(user.ID, td)
+
+	session, ok, err := s.jwtRepo.GetSession(rd.SessionID)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", ErrGenerateJWT, err)
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrRefreshJWT, err)
+	}
+	if !ok || session.UserID != int(rd.UserID) {
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrRefreshJWT, ErrInvalidRefreshToken)
 	}
-	return token, nil
+	if session.RefreshUUID != rd.RefreshUUID {
+		return models.Tokens{}, s.revokeReusedSession(session.ID)
+	}
+
+	now := s.now().UTC()
+	td, err := s.jwtManager.GenerateTokens(session.UserID, session.ID, now)
+	if err != nil {
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrRefreshJWT, err)
+	}
+
+	session.Device = client.Device
+	session.IP = client.IP
+	session.LastUsedAt = now
+	rotated, err := s.jwtRepo.RotateSession(session, rd.RefreshUUID, td)
+	if err != nil {
+		return models.Tokens{}, fmt.Errorf("%s: %w", ErrRefreshJWT, err)
+	}
+	if !rotated {
+		// the same refresh token was used concurrently
+		return models.Tokens{}, s.revokeReusedSession(session.ID)
+	}
+	return s.tokens(td), nil
 }
 
 func (s *AuthService) GetUserIDByJWT(token string) (int, error) {
-	ad, err := utils.ExtractTokenMetadata(token)
+	ad, err := s.jwtManager.ExtractTokenMetadata(token)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", ErrGetUserIDByJWT, err)
 	}
@@ -73,14 +159,56 @@ func (s *AuthService) GetUserIDByJWT(token string) (int, error) {
 	return userID, nil
 }
 
+// LogoutUser ends session of access token
 func (s *AuthService) LogoutUser(token string) error {
-	ad, err := utils.ExtractTokenMetadata(token)
+	ad, err := s.jwtManager.ExtractTokenMetadata(token)
 	if err != nil {
 		return fmt.Errorf("%s: %w", ErrLogoutUser, err)
 	}
 	if err := s.jwtRepo.DeleteJWT(ad); err != nil {
 		return fmt.Errorf("%s: %w", ErrLogoutUser, err)
 	}
+	if ad.SessionID == "" {
+		return nil
+	}
+
+	session, ok, err := s.jwtRepo.GetSession(ad.SessionID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrLogoutUser, err)
+	}
+	if !ok {
+		return nil
+	}
+	if err := s.jwtRepo.DeleteSession(session); err != nil {
+		return fmt.Errorf("%s: %w", ErrLogoutUser, err)
+	}
+	return nil
+}
+
+// GetUserSessions returns active sessions of user from the latest used one
+func (s *AuthService) GetUserSessions(userID int) ([]models.AuthSession, error) {
+	sessions, err := s.jwtRepo.GetUserSessions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserSessions, err)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession ends session of user, its access and refresh tokens stop working
+func (s *AuthService) RevokeSession(userID int, sessionID string) error {
+	session, ok, err := s.jwtRepo.GetSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrRevokeSession, err)
+	}
+	if !ok || session.UserID != userID {
+		return fmt.Errorf("%s: %w", ErrRevokeSession, ErrAuthSessionNotFound)
+	}
+	if err := s.jwtRepo.DeleteSession(session); err != nil {
+		return fmt.Errorf("%s: %w", ErrRevokeSession, err)
+	}
 	return nil
 }
 
@@ -91,3 +219,23 @@ func (s *AuthService) GetUserAPIKeys(userID int) (string, string, error) {
 	}
 	return public, private, nil
 }
+
+// revokeReusedSession deletes session with the latest access token issued by it
+func (s *AuthService) revokeReusedSession(sessionID string) error {
+	session, ok, err := s.jwtRepo.GetSession(sessionID)
+	if err == nil && ok {
+		err = s.jwtRepo.DeleteSession(session)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", ErrRefreshJWT, ErrRefreshTokenReused, err)
+	}
+	return fmt.Errorf("%s: %w", ErrRefreshJWT, ErrRefreshTokenReused)
+}
+
+func (s *AuthService) tokens(td utils.TokenDetails) models.Tokens {
+	return models.Tokens{
+		AccessToken:  td.AccessToken,
+		RefreshToken: td.RefreshToken,
+		ExpiresIn:    int64(s.accessTTL / time.Second),
+	}
+}
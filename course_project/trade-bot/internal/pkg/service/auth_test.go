@@ -0,0 +1,226 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/pkg/utils"
+)
+
+// usersRepo keeps users by their usernames
+type usersRepo struct {
+	repository.Authorization
+	users map[string]models.User
+}
+
+func (r *usersRepo) GetUser(username string) (models.User, error) {
+	user, ok := r.users[username]
+	if !ok {
+		return models.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+// authSessionsRepo keeps access tokens and sessions in memory like redis does
+type authSessionsRepo struct {
+	access   map[string]int
+	sessions map[string]models.AuthSession
+}
+
+func newAuthSessionsRepo() *authSessionsRepo {
+	return &authSessionsRepo{access: map[string]int{}, sessions: map[string]models.AuthSession{}}
+}
+
+func (r *authSessionsRepo) GetJWTUserID(ad utils.AccessDetails) (int, error) {
+	userID, ok := r.access[ad.AccessUUID]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return userID, nil
+}
+
+func (r *authSessionsRepo) DeleteJWT(ad utils.AccessDetails) error {
+	delete(r.access, ad.AccessUUID)
+	return nil
+}
+
+func (r *authSessionsRepo) CreateSession(session models.AuthSession, td utils.TokenDetails) error {
+	r.access[td.AccessUUID] = session.UserID
+	r.sessions[session.ID] = withTokens(session, td)
+	return nil
+}
+
+func (r *authSessionsRepo) GetSession(sessionID string) (models.AuthSession, bool, error) {
+	session, ok := r.sessions[sessionID]
+	return session, ok, nil
+}
+
+func (r *authSessionsRepo) GetUserSessions(userID int) ([]models.AuthSession, error) {
+	var sessions []models.AuthSession
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *authSessionsRepo) RotateSession(session models.AuthSession, refreshUUID string, td utils.TokenDetails) (bool, error) {
+	current, ok := r.sessions[session.ID]
+	if !ok || current.RefreshUUID != refreshUUID {
+		return false, nil
+	}
+	delete(r.access, current.AccessUUID)
+	r.access[td.AccessUUID] = session.UserID
+	r.sessions[session.ID] = withTokens(session, td)
+	return true, nil
+}
+
+func (r *authSessionsRepo) DeleteSession(session models.AuthSession) error {
+	delete(r.access, session.AccessUUID)
+	delete(r.sessions, session.ID)
+	return nil
+}
+
+func withTokens(session models.AuthSession, td utils.TokenDetails) models.AuthSession {
+	session.AccessUUID = td.AccessUUID
+	session.RefreshUUID = td.RefreshUUID
+	session.ExpiresAt = time.Unix(td.RtExpires, 0).UTC()
+	return session
+}
+
+func newTestAuthService(t *testing.T, jwtRepo *authSessionsRepo) *AuthService {
+	user := models.User{ID: 1, Username: "username"}
+	if err := user.GeneratePasswordHash("qwerty"); err != nil {
+		t.Fatalf("unable to hash password: %s", err)
+	}
+	users := &usersRepo{users: map[string]models.User{"username": user}}
+
+	return NewAuthService(users, jwtRepo, configs.AuthConfiguration{SigningKey: "key"})
+}
+
+func TestAuthService_RefreshJWT(t *testing.T) {
+	client := models.SessionClient{Device: "telegram", IP: "192.0.2.1"}
+
+	t.Run("Rotation", func(t *testing.T) {
+		jwtRepo := newAuthSessionsRepo()
+		s := newTestAuthService(t, jwtRepo)
+
+		tokens, err := s.GenerateJWT("username", "qwerty", client)
+		if err != nil {
+			t.Fatalf("unexpected error of sign in: %s", err)
+		}
+		assert.Equal(t, int64(defaultAccessTokenTTL/time.Second), tokens.ExpiresIn)
+
+		refreshed, err := s.RefreshJWT(tokens.RefreshToken, models.SessionClient{Device: "browser", IP: "192.0.2.2"})
+		assert.NoError(t, err)
+		assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+		// access token of previous pair is revoked by rotation
+		_, err = s.GetUserIDByJWT(tokens.AccessToken)
+		assert.Error(t, err)
+		userID, err := s.GetUserIDByJWT(refreshed.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, userID)
+
+		sessions, err := s.GetUserSessions(1)
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 1) {
+			assert.Equal(t, "browser", sessions[0].Device)
+			assert.Equal(t, "192.0.2.2", sessions[0].IP)
+		}
+	})
+
+	t.Run("Reused refresh token revokes session", func(t *testing.T) {
+		jwtRepo := newAuthSessionsRepo()
+		s := newTestAuthService(t, jwtRepo)
+
+		tokens, err := s.GenerateJWT("username", "qwerty", client)
+		if err != nil {
+			t.Fatalf("unexpected error of sign in: %s", err)
+		}
+		refreshed, err := s.RefreshJWT(tokens.RefreshToken, client)
+		if err != nil {
+			t.Fatalf("unexpected error of refresh: %s", err)
+		}
+
+		_, err = s.RefreshJWT(tokens.RefreshToken, client)
+		assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+		_, err = s.RefreshJWT(refreshed.RefreshToken, client)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		_, err = s.GetUserIDByJWT(refreshed.AccessToken)
+		assert.Error(t, err)
+		assert.Empty(t, jwtRepo.sessions)
+	})
+
+	t.Run("Access token is not refresh token", func(t *testing.T) {
+		s := newTestAuthService(t, newAuthSessionsRepo())
+
+		tokens, err := s.GenerateJWT("username", "qwerty", client)
+		if err != nil {
+			t.Fatalf("unexpected error of sign in: %s", err)
+		}
+		_, err = s.RefreshJWT(tokens.AccessToken, client)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+		_, err = s.GetUserIDByJWT(tokens.RefreshToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("Token signed by other key", func(t *testing.T) {
+		s := newTestAuthService(t, newAuthSessionsRepo())
+
+		td, err := utils.NewJWTManager("other key", time.Minute, time.Hour).GenerateTokens(1, "session", time.Now())
+		if err != nil {
+			t.Fatalf("unexpected error of tokens: %s", err)
+		}
+		_, err = s.RefreshJWT(td.RefreshToken, client)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	jwtRepo := newAuthSessionsRepo()
+	s := newTestAuthService(t, jwtRepo)
+
+	tokens, err := s.GenerateJWT("username", "qwerty", models.SessionClient{Device: "telegram"})
+	if err != nil {
+		t.Fatalf("unexpected error of sign in: %s", err)
+	}
+	sessions, err := s.GetUserSessions(1)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("unexpected sessions: %v, %v", sessions, err)
+	}
+
+	err = s.RevokeSession(2, sessions[0].ID)
+	assert.ErrorIs(t, err, ErrAuthSessionNotFound)
+
+	assert.NoError(t, s.RevokeSession(1, sessions[0].ID))
+	_, err = s.GetUserIDByJWT(tokens.AccessToken)
+	assert.Error(t, err)
+	_, err = s.RefreshJWT(tokens.RefreshToken, models.SessionClient{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	err = s.RevokeSession(1, sessions[0].ID)
+	assert.ErrorIs(t, err, ErrAuthSessionNotFound)
+}
+
+func TestAuthService_LogoutUser(t *testing.T) {
+	jwtRepo := newAuthSessionsRepo()
+	s := newTestAuthService(t, jwtRepo)
+
+	tokens, err := s.GenerateJWT("username", "qwerty", models.SessionClient{})
+	if err != nil {
+		t.Fatalf("unexpected error of sign in: %s", err)
+	}
+
+	assert.NoError(t, s.LogoutUser(tokens.AccessToken))
+	assert.Empty(t, jwtRepo.access)
+	assert.Empty(t, jwtRepo.sessions)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/backtest"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrRunBacktestService     = errors.New("run backtest service")
+	ErrUnknownCandlesSource   = errors.New("unknown candles source")
+	ErrEmptyCandlesPeriod     = errors.New("empty candles period")
+	ErrInvalidBacktestDetails = errors.New("invalid trading details of backtest")
+	ErrInvalidBacktestCandles = errors.New("invalid candles of backtest")
+)
+
+// defaultBacktestCandlesInterval is an interval of candles which strategy analyzes when it is not set in details
+const defaultBacktestCandlesInterval = "1m"
+
+type BacktestService struct {
+	candles    *CandlesService
+	market     web.KrakenMarketData
+	strategies tradeAlgorithm.Strategies
+	engine     *backtest.Engine
+}
+
+func NewBacktestService(candles *CandlesService, market web.KrakenMarketData,
+	strategies tradeAlgorithm.Strategies) *BacktestService {
+	return &BacktestService{
+		candles:    candles,
+		market:     market,
+		strategies: strategies,
+		engine: backtest.NewEngine(func(analyzer web.KrakenAnalyzer) tradeAlgorithm.Trader {
+			return tradeAlgorithm.NewTradeAlgorithm(&web.Web{KrakenAnalyzer: analyzer})
+		}),
+	}
+}
+
+func (b *BacktestService) RunBacktest(ctx context.Context, input models.BacktestInput) (backtest.Report, error) {
+	details, err := b.strategies.ValidateDetails(input.TradingDetails)
+	if err != nil {
+		return backtest.Report{}, fmt.Errorf("%s: %w: %s", ErrRunBacktestService, ErrInvalidBacktestDetails, err)
+	}
+
+	candles, err := b.loadCandles(details, input.Candles)
+	if err != nil {
+		return backtest.Report{}, fmt.Errorf("%s: %w", ErrRunBacktestService, err)
+	}
+
+	cfg := backtest.Config{
+		Details:       details,
+		WarmupCandles: input.WarmupCandles,
+	}
+	if cfg.WarmupCandles == 0 {
+		cfg.WarmupCandles = backtest.DefaultWarmupCandles
+	}
+
+	if input.TakerFee != nil {
+		cfg.TakerFee = *input.TakerFee
+	} else {
+		schedules, err := b.market.FeeSchedules()
+		if err != nil {
+			return backtest.Report{}, fmt.Errorf("%s: %w", ErrRunBacktestService, err)
+		}
+		cfg.TakerFee = backtest.TakerFee(schedules)
+	}
+
+	report, err := b.engine.Run(ctx, candles, cfg)
+	if err != nil {
+		return backtest.Report{}, fmt.Errorf("%s: %w", ErrRunBacktestService, err)
+	}
+	return report, nil
+}
+
+// loadCandles reads candles from file or recorded candles of interval of strategy, which are resampled
+// from recorded intervals when the interval itself isn't recorded
+func (b *BacktestService) loadCandles(details types.TradingDetails, input models.BacktestCandles) ([]krakenFuturesWSSDK.Candle, error) {
+	switch input.Source {
+	case models.BacktestCSVSource, models.BacktestJSONSource:
+		candles, err := backtest.ReadCandles(strings.NewReader(input.Data), input.Source, details.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBacktestCandles, err)
+		}
+		return candles, nil
+	case models.BacktestDBSource:
+		if !input.From.Before(input.To) {
+			return nil, fmt.Errorf("%w: from %s, to %s", ErrEmptyCandlesPeriod, input.From, input.To)
+		}
+		interval := details.CandlesInterval
+		if interval == "" {
+			interval = defaultBacktestCandlesInterval
+		}
+		return b.candles.recordedCandles(details.Symbol, interval, input.From, input.To)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCandlesSource, input.Source)
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+func TestBacktestService_loadCandles(t *testing.T) {
+	start := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := newCandlesRepo()
+	var minutes []krakenFuturesWSSDK.Candle
+	for i, prices := range [][4]string{
+		{"10", "12", "9", "11"},
+		{"11", "15", "10", "14"},
+		{"14", "14", "8", "9.5"},
+		{"9.5", "10", "9", "10"},
+		{"10", "11", "10", "11"},
+		{"11", "13", "11", "12"},
+	} {
+		minutes = append(minutes, krakenFuturesWSSDK.Candle{Time: int(start.Add(time.Duration(i) * time.Minute).Unix()),
+			Open: prices[0], High: prices[1], Low: prices[2], Close: prices[3], Volume: i + 1})
+	}
+	if err := repo.SaveCandles("PI_XBTUSD", "1m", minutes); err != nil {
+		t.Fatalf("unexpected error of saving candles: %s", err)
+	}
+
+	b := NewBacktestService(NewCandlesService(nil, repo, configs.CandlesRecorderConfiguration{}), nil, nil)
+	period := models.BacktestCandles{Source: models.BacktestDBSource, From: start, To: start.Add(10 * time.Minute)}
+
+	tests := []struct {
+		name        string
+		interval    string
+		input       models.BacktestCandles
+		want        []krakenFuturesWSSDK.Candle
+		wantErrorIs error
+	}{
+		{
+			name:  "Default interval",
+			input: models.BacktestCandles{Source: models.BacktestDBSource, From: start, To: start.Add(2 * time.Minute)},
+			want:  minutes[:2],
+		},
+		{
+			name:     "Resampled interval",
+			interval: "5m",
+			input:    period,
+			want: []krakenFuturesWSSDK.Candle{
+				{Time: int(start.Unix()), Open: "10", High: "15", Low: "8", Close: "11", Volume: 15},
+				{Time: int(start.Add(5 * time.Minute).Unix()), Open: "11", High: "13", Low: "11", Close: "12", Volume: 6},
+			},
+		},
+		{
+			name:        "Unknown interval",
+			interval:    "3m",
+			input:       period,
+			wantErrorIs: ErrInvalidCandlesRequest,
+		},
+		{
+			name:        "Empty period",
+			input:       models.BacktestCandles{Source: models.BacktestDBSource, From: start, To: start},
+			wantErrorIs: ErrEmptyCandlesPeriod,
+		},
+		{
+			name:        "Invalid csv",
+			input:       models.BacktestCandles{Source: models.BacktestCSVSource, Data: "time,open\n1,2"},
+			wantErrorIs: ErrInvalidBacktestCandles,
+		},
+		{
+			name:        "Unknown source",
+			input:       models.BacktestCandles{Source: "xml"},
+			wantErrorIs: ErrUnknownCandlesSource,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			details := types.TradingDetails{Symbol: "PI_XBTUSD", CandlesInterval: test.interval}
+			got, err := b.loadCandles(details, test.input)
+			if test.wantErrorIs != nil {
+				assert.ErrorIs(t, err, test.wantErrorIs)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
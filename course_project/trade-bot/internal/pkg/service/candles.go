@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrGetCandles             = errors.New("get candles")
+	ErrInvalidCandlesRequest  = errors.New("invalid candles request")
+	ErrTooManyCandles         = errors.New("too many candles, narrow time range")
+	ErrRecordCandles          = errors.New("record candles")
+	ErrCandlesUpdatesStopped  = errors.New("candles updates stopped")
+	ErrInvalidRecorderConfig  = errors.New("invalid candles recorder config")
+	ErrUnableToParseCandle    = errors.New("unable to parse candle")
+	ErrIntervalIsNotResampled = errors.New("interval can't be resampled from recorded intervals")
+)
+
+const (
+	defaultRecorderFlushInterval = 10 * time.Second
+	recorderRetryDelay           = 10 * time.Second
+	defaultCandlesCount          = 500
+	maxCandlesCount              = 5000
+	maxSourceCandlesCount        = 100000
+	defaultRecordedInterval      = "1m"
+)
+
+// candlesIntervals are intervals which candles are served with, larger intervals are resampled from recorded ones
+var candlesIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// CandlesService records candles of configured symbols from websocket feeds and serves saved candles.
+// Candles are updated by every trade, so recorder keeps the last update of every candle and saves
+// them every flush interval, the last save of candle is its final state
+type CandlesService struct {
+	analyzer      web.KrakenAnalyzer
+	repo          repository.Candles
+	symbols       []string
+	intervals     []string
+	flushInterval time.Duration
+	now           func() time.Time
+
+	mu   sync.Mutex
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func NewCandlesService(analyzer web.KrakenAnalyzer, repo repository.Candles, cfg configs.CandlesRecorderConfiguration) *CandlesService {
+	intervals := cfg.Intervals
+	if len(intervals) == 0 {
+		intervals = []string{defaultRecordedInterval}
+	}
+	flushInterval := time.Duration(cfg.FlushIntervalInSeconds) * time.Second
+	if flushInterval <= 0 {
+		flushInterval = defaultRecorderFlushInterval
+	}
+
+	return &CandlesService{
+		analyzer:      analyzer,
+		repo:          repo,
+		symbols:       cfg.Symbols,
+		intervals:     intervals,
+		flushInterval: flushInterval,
+		now:           time.Now,
+	}
+}
+
+// GetCandles returns candles of symbol in [from, to) resampled to interval. Zero to is now and zero from
+// is defaultCandlesCount intervals before to, it is moved closer to to when there are too many recorded
+// candles in such range
+func (s *CandlesService) GetCandles(symbol, interval string, from, to time.Time) ([]models.Candle, error) {
+	target, ok := candlesIntervals[interval]
+	if symbol == "" || !ok {
+		return nil, fmt.Errorf("%s: %w: symbol %q, interval %q", ErrGetCandles, ErrInvalidCandlesRequest, symbol, interval)
+	}
+
+	source, ok := s.sourceInterval(target)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w: %s", ErrGetCandles, ErrIntervalIsNotResampled, interval)
+	}
+	sourceDuration := candlesIntervals[source]
+
+	if to.IsZero() {
+		to = s.now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultCandlesCount * target).Truncate(target)
+		earliest := to.Add(-maxSourceCandlesCount * sourceDuration)
+		for from.Before(earliest) {
+			from = from.Add(target)
+		}
+	}
+	from = from.Truncate(target)
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%s: %w: from must be before to", ErrGetCandles, ErrInvalidCandlesRequest)
+	}
+	// candles are resampled from recorded ones, so both counts are limited
+	if to.Sub(from)/target > maxCandlesCount || to.Sub(from)/sourceDuration > maxSourceCandlesCount {
+		return nil, fmt.Errorf("%s: %w", ErrGetCandles, ErrTooManyCandles)
+	}
+
+	saved, err := s.repo.GetCandles(symbol, source, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetCandles, err)
+	}
+	candles, err := parseCandles(saved)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetCandles, err)
+	}
+	return resampleCandles(candles, target), nil
+}
+
+// recordedCandles returns candles of interval between from and to in format of candles feeds, they are
+// resampled from recorded intervals like served candles but their count isn't limited
+func (s *CandlesService) recordedCandles(symbol, interval string, from, to time.Time) ([]krakenFuturesWSSDK.Candle, error) {
+	target, ok := candlesIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: interval %q", ErrInvalidCandlesRequest, interval)
+	}
+	source, ok := s.sourceInterval(target)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrIntervalIsNotResampled, interval)
+	}
+	if source == interval {
+		return s.repo.GetCandles(symbol, source, from, to)
+	}
+
+	saved, err := s.repo.GetCandles(symbol, source, from.Truncate(target), to)
+	if err != nil {
+		return nil, err
+	}
+	candles, err := parseCandles(saved)
+	if err != nil {
+		return nil, err
+	}
+	return formatCandles(resampleCandles(candles, target)), nil
+}
+
+// sourceInterval returns the largest recorded interval which candles of target interval consist of
+func (s *CandlesService) sourceInterval(target time.Duration) (string, bool) {
+	var (
+		source   string
+		duration time.Duration
+	)
+	for _, interval := range s.intervals {
+		d, ok := candlesIntervals[interval]
+		if !ok || d > target || target%d != 0 {
+			continue
+		}
+		if d > duration {
+			source, duration = interval, d
+		}
+	}
+	return source, source != ""
+}
+
+// StartRecorder subscribes to candles feeds of recorded intervals until StopRecorder is called,
+// recorder is not started without symbols
+func (s *CandlesService) StartRecorder() error {
+	if len(s.symbols) == 0 {
+		return nil
+	}
+
+	feeds := make(map[string]string, len(s.intervals))
+	for _, interval := range s.intervals {
+		feed, ok := krakenFuturesWSSDK.CandlesFeed(interval)
+		if !ok || interval == "" {
+			return fmt.Errorf("%s: unsupported interval %q", ErrInvalidRecorderConfig, interval)
+		}
+		feeds[interval] = feed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return nil
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	s.stop = stop
+	s.done = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for interval, feed := range feeds {
+		wg.Add(1)
+		go func(interval, feed string) {
+			defer wg.Done()
+			s.recordFeed(ctx, interval, feed)
+		}(interval, feed)
+	}
+
+	go func(done chan struct{}) {
+		wg.Wait()
+		close(done)
+	}(s.done)
+	return nil
+}
+
+// StopRecorder stops recorder and waits until the last updates of candles are saved
+func (s *CandlesService) StopRecorder() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	stop()
+	<-done
+}
+
+// recordFeed records candles of feed and subscribes to it again when updates stop
+func (s *CandlesService) recordFeed(ctx context.Context, interval, feed string) {
+	for {
+		err := s.record(ctx, interval, feed)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warnf("%s %s: %s", ErrRecordCandles, interval, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(recorderRetryDelay):
+		}
+	}
+}
+
+type candleKey struct {
+	symbol string
+	time   int
+}
+
+func (s *CandlesService) record(ctx context.Context, interval, feed string) error {
+	updates, err := s.analyzer.LookForCandlesUpdates(ctx, feed, s.symbols)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[candleKey]krakenFuturesWSSDK.Candle)
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush(interval, pending)
+			return ctx.Err()
+		case update, ok := <-updates:
+			if !ok {
+				s.flush(interval, pending)
+				return ErrCandlesUpdatesStopped
+			}
+			pending[candleKey{symbol: update.ProductID, time: update.Candle.Time}] = update.Candle
+		case <-ticker.C:
+			s.flush(interval, pending)
+		}
+	}
+}
+
+// flush saves pending candles by symbols, candles of symbol which are not saved stay pending
+func (s *CandlesService) flush(interval string, pending map[candleKey]krakenFuturesWSSDK.Candle) {
+	bySymbol := make(map[string][]krakenFuturesWSSDK.Candle)
+	for key, candle := range pending {
+		bySymbol[key.symbol] = append(bySymbol[key.symbol], candle)
+	}
+
+	for symbol, candles := range bySymbol {
+		sort.Slice(candles, func(i, j int) bool {
+			return candles[i].Time < candles[j].Time
+		})
+		if err := s.repo.SaveCandles(symbol, interval, candles); err != nil {
+			log.Warnf("%s %s %s: %s", ErrRecordCandles, symbol, interval, err)
+			continue
+		}
+		for _, candle := range candles {
+			delete(pending, candleKey{symbol: symbol, time: candle.Time})
+		}
+	}
+}
+
+func parseCandles(candles []krakenFuturesWSSDK.Candle) ([]models.Candle, error) {
+	parsed := make([]models.Candle, 0, len(candles))
+	for _, candle := range candles {
+		prices := make([]float64, 4)
+		for i, value := range []string{candle.Open, candle.High, candle.Low, candle.Close} {
+			price, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", ErrUnableToParseCandle, err)
+			}
+			prices[i] = price
+		}
+
+		parsed = append(parsed, models.Candle{
+			Time:   time.Unix(int64(candle.Time), 0).UTC(),
+			Open:   prices[0],
+			High:   prices[1],
+			Low:    prices[2],
+			Close:  prices[3],
+			Volume: candle.Volume,
+		})
+	}
+	return parsed, nil
+}
+
+// formatCandles returns candles in format of candles feeds
+func formatCandles(candles []models.Candle) []krakenFuturesWSSDK.Candle {
+	formatted := make([]krakenFuturesWSSDK.Candle, 0, len(candles))
+	for _, candle := range candles {
+		formatted = append(formatted, krakenFuturesWSSDK.Candle{
+			Time:   int(candle.Time.Unix()),
+			Open:   strconv.FormatFloat(candle.Open, 'f', -1, 64),
+			High:   strconv.FormatFloat(candle.High, 'f', -1, 64),
+			Low:    strconv.FormatFloat(candle.Low, 'f', -1, 64),
+			Close:  strconv.FormatFloat(candle.Close, 'f', -1, 64),
+			Volume: candle.Volume,
+		})
+	}
+	return formatted
+}
+
+// resampleCandles merges candles sorted by time into candles of interval which start at multiples of interval
+func resampleCandles(candles []models.Candle, interval time.Duration) []models.Candle {
+	resampled := make([]models.Candle, 0, len(candles))
+	for _, candle := range candles {
+		start := candle.Time.Truncate(interval)
+
+		last := len(resampled) - 1
+		if last < 0 || !resampled[last].Time.Equal(start) {
+			candle.Time = start
+			resampled = append(resampled, candle)
+			continue
+		}
+
+		merged := &resampled[last]
+		if candle.High > merged.High {
+			merged.High = candle.High
+		}
+		if candle.Low < merged.Low {
+			merged.Low = candle.Low
+		}
+		merged.Close = candle.Close
+		merged.Volume += candle.Volume
+	}
+	return resampled
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// candlesRepo keeps saved candles by symbol, interval and time
+type candlesRepo struct {
+	mu       sync.Mutex
+	candles  map[string]map[int]krakenFuturesWSSDK.Candle
+	requests []string
+}
+
+func newCandlesRepo() *candlesRepo {
+	return &candlesRepo{candles: make(map[string]map[int]krakenFuturesWSSDK.Candle)}
+}
+
+func (r *candlesRepo) GetCandles(symbol, interval string, from, to time.Time) ([]krakenFuturesWSSDK.Candle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, interval)
+
+	var candles []krakenFuturesWSSDK.Candle
+	for t := from.Unix(); t < to.Unix(); t += 60 {
+		if candle, ok := r.candles[symbol+interval][int(t)]; ok {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+func (r *candlesRepo) SaveCandles(symbol, interval string, candles []krakenFuturesWSSDK.Candle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.candles[symbol+interval] == nil {
+		r.candles[symbol+interval] = make(map[int]krakenFuturesWSSDK.Candle)
+	}
+	for _, candle := range candles {
+		r.candles[symbol+interval][candle.Time] = candle
+	}
+	return nil
+}
+
+// candlesUpdatesAnalyzer streams updates from channel until context is done
+type candlesUpdatesAnalyzer struct {
+	web.KrakenAnalyzer
+	updates chan krakenFuturesWSSDK.CandlesTradeData
+	feeds   chan string
+}
+
+func (a *candlesUpdatesAnalyzer) LookForCandlesUpdates(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.CandlesTradeData, error) {
+	a.feeds <- feed
+	return a.updates, nil
+}
+
+func TestCandlesService_GetCandles(t *testing.T) {
+	start := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := newCandlesRepo()
+	var minutes []krakenFuturesWSSDK.Candle
+	for i, prices := range [][4]string{
+		{"10", "12", "9", "11"},
+		{"11", "15", "10", "14"},
+		{"14", "14", "8", "9"},
+		{"9", "10", "9", "10"},
+		{"10", "11", "10", "11"},
+		{"11", "13", "11", "12"},
+	} {
+		minutes = append(minutes, krakenFuturesWSSDK.Candle{Time: int(start.Add(time.Duration(i) * time.Minute).Unix()),
+			Open: prices[0], High: prices[1], Low: prices[2], Close: prices[3], Volume: i + 1})
+	}
+	if err := repo.SaveCandles("PI_XBTUSD", "1m", minutes); err != nil {
+		t.Fatalf("unexpected error of saving candles: %s", err)
+	}
+
+	s := NewCandlesService(nil, repo, configs.CandlesRecorderConfiguration{Intervals: []string{"1m", "1h"}})
+
+	tests := []struct {
+		name        string
+		interval    string
+		from        time.Time
+		to          time.Time
+		want        []models.Candle
+		wantSource  string
+		wantErrorIs error
+	}{
+		{
+			name:     "Recorded interval",
+			interval: "1m",
+			from:     start,
+			to:       start.Add(2 * time.Minute),
+			want: []models.Candle{
+				{Time: start, Open: 10, High: 12, Low: 9, Close: 11, Volume: 1},
+				{Time: start.Add(time.Minute), Open: 11, High: 15, Low: 10, Close: 14, Volume: 2},
+			},
+			wantSource: "1m",
+		},
+		{
+			name:     "Resampled interval",
+			interval: "5m",
+			from:     start.Add(time.Minute),
+			to:       start.Add(10 * time.Minute),
+			want: []models.Candle{
+				{Time: start, Open: 10, High: 15, Low: 8, Close: 11, Volume: 15},
+				{Time: start.Add(5 * time.Minute), Open: 11, High: 13, Low: 11, Close: 12, Volume: 6},
+			},
+			wantSource: "1m",
+		},
+		{
+			name:        "Unknown interval",
+			interval:    "3m",
+			wantErrorIs: ErrInvalidCandlesRequest,
+		},
+		{
+			name:        "Too many candles",
+			interval:    "1m",
+			from:        start,
+			to:          start.Add(maxCandlesCount*time.Minute + time.Minute),
+			wantErrorIs: ErrTooManyCandles,
+		},
+		{
+			name:        "Too many recorded candles",
+			interval:    "1w",
+			from:        start,
+			to:          start.Add(maxSourceCandlesCount*time.Hour + time.Hour),
+			wantErrorIs: ErrTooManyCandles,
+		},
+		{
+			name:        "From after to",
+			interval:    "1m",
+			from:        start.Add(time.Hour),
+			to:          start,
+			wantErrorIs: ErrInvalidCandlesRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo.requests = nil
+
+			got, err := s.GetCandles("PI_XBTUSD", test.interval, test.from, test.to)
+			if test.wantErrorIs != nil {
+				assert.ErrorIs(t, err, test.wantErrorIs)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, []string{test.wantSource}, repo.requests)
+		})
+	}
+}
+
+func TestCandlesService_GetCandles_DefaultRange(t *testing.T) {
+	start := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := newCandlesRepo()
+	candles := []krakenFuturesWSSDK.Candle{
+		{Time: int(start.Unix()), Open: "10", High: "12", Low: "9", Close: "11", Volume: 1},
+		{Time: int(start.Add(time.Minute).Unix()), Open: "11", High: "15", Low: "10", Close: "14", Volume: 2},
+	}
+	if err := repo.SaveCandles("PI_XBTUSD", "1m", candles); err != nil {
+		t.Fatalf("unexpected error of saving candles: %s", err)
+	}
+
+	// default range of daily candles has too many minute candles, so it is shortened
+	s := NewCandlesService(nil, repo, configs.CandlesRecorderConfiguration{Intervals: []string{"1m"}})
+	got, err := s.GetCandles("PI_XBTUSD", "1d", time.Time{}, start.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Candle{{Time: start, Open: 10, High: 15, Low: 9, Close: 14, Volume: 3}}, got)
+}
+
+func TestCandlesService_sourceInterval(t *testing.T) {
+	s := NewCandlesService(nil, nil, configs.CandlesRecorderConfiguration{Intervals: []string{"1m", "15m", "1h"}})
+
+	for interval, want := range map[string]string{"1m": "1m", "5m": "1m", "30m": "15m", "4h": "1h", "1w": "1h"} {
+		source, ok := s.sourceInterval(candlesIntervals[interval])
+		assert.True(t, ok)
+		assert.Equal(t, want, source, interval)
+	}
+
+	s = NewCandlesService(nil, nil, configs.CandlesRecorderConfiguration{Intervals: []string{"5m"}})
+	_, ok := s.sourceInterval(time.Minute)
+	assert.False(t, ok)
+}
+
+func TestCandlesService_Recorder(t *testing.T) {
+	analyzer := &candlesUpdatesAnalyzer{
+		updates: make(chan krakenFuturesWSSDK.CandlesTradeData),
+		feeds:   make(chan string, 1),
+	}
+	repo := newCandlesRepo()
+	s := NewCandlesService(analyzer, repo, configs.CandlesRecorderConfiguration{
+		Symbols:                []string{"PI_XBTUSD", "PI_ETHUSD"},
+		FlushIntervalInSeconds: 3600,
+	})
+
+	if err := s.StartRecorder(); err != nil {
+		t.Fatalf("unexpected error of recorder: %s", err)
+	}
+	assert.Equal(t, krakenFuturesWSSDK.OneMinuteCandlesFeed, <-analyzer.feeds)
+
+	for _, update := range []krakenFuturesWSSDK.CandlesTradeData{
+		{ProductID: "PI_XBTUSD", Candle: krakenFuturesWSSDK.Candle{Time: 60, Open: "1", High: "1", Low: "1", Close: "1", Volume: 1}},
+		{ProductID: "PI_ETHUSD", Candle: krakenFuturesWSSDK.Candle{Time: 60, Open: "5", High: "5", Low: "5", Close: "5", Volume: 1}},
+		{ProductID: "PI_XBTUSD", Candle: krakenFuturesWSSDK.Candle{Time: 60, Open: "1", High: "2", Low: "1", Close: "2", Volume: 3}},
+		{ProductID: "PI_XBTUSD", Candle: krakenFuturesWSSDK.Candle{Time: 120, Open: "2", High: "2", Low: "2", Close: "2", Volume: 1}},
+	} {
+		analyzer.updates <- update
+	}
+
+	// pending candles are saved when recorder stops
+	s.StopRecorder()
+
+	assert.Equal(t, map[string]map[int]krakenFuturesWSSDK.Candle{
+		"PI_XBTUSD1m": {
+			60:  {Time: 60, Open: "1", High: "2", Low: "1", Close: "2", Volume: 3},
+			120: {Time: 120, Open: "2", High: "2", Low: "2", Close: "2", Volume: 1},
+		},
+		"PI_ETHUSD1m": {
+			60: {Time: 60, Open: "5", High: "5", Low: "5", Close: "5", Volume: 1},
+		},
+	}, repo.candles)
+}
+
+func TestCandlesService_StartRecorder(t *testing.T) {
+	s := NewCandlesService(nil, nil, configs.CandlesRecorderConfiguration{})
+	assert.NoError(t, s.StartRecorder())
+
+	s = NewCandlesService(nil, nil, configs.CandlesRecorderConfiguration{Symbols: []string{"PI_XBTUSD"},
+		Intervals: []string{"2m"}})
+	assert.Error(t, s.StartRecorder())
+}
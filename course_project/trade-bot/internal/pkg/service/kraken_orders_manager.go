@@ -2,43 +2,135 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 	"trade-bot/internal/pkg/models"
 
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
 	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/repository/postgresRepo"
 	"trade-bot/internal/pkg/tradeAlgorithm"
 	"trade-bot/internal/pkg/tradeAlgorithm/types"
 	"trade-bot/internal/pkg/web"
 	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
 )
 
 var (
-	ErrSendOrderServiceMethod    = errors.New("send order service method")
-	ErrStartTradingService       = errors.New("start trading service")
-	ErrUnableToParseBuyTimestamp = errors.New("unable to convert buy timestamp")
+	ErrSendOrderServiceMethod       = errors.New("send order service method")
+	ErrEditOrderServiceMethod       = errors.New("edit order service method")
+	ErrCancelOrderServiceMethod     = errors.New("cancel order service method")
+	ErrCancelAllOrdersServiceMethod = errors.New("cancel all orders service method")
+	ErrGetUserOrdersManager         = errors.New("get user orders manager")
+	ErrGetUserOrders                = errors.New("get user orders")
+	ErrInvalidOrdersFilter          = errors.New("invalid orders filter")
+	ErrOrderNotFound                = errors.New("order not found")
+	ErrOrderNotActive               = errors.New("order is filled or cancelled already")
+	ErrWatchOrders                  = errors.New("watch orders")
+	ErrWatchOpenOrders              = errors.New("watch open orders")
+	ErrGetOrderFillPrice            = errors.New("get order fill price")
+	ErrFindOrder                    = errors.New("find order")
 )
 
+// Page sizes of order history
+const (
+	defaultOrdersLimit = 50
+	maxOrdersLimit     = 500
+)
+
+// fullFillReason is a reason of removal of order from open orders feed when order is filled
+const fullFillReason = "full_fill"
+
+// KrakenOrdersManagerService sends orders of users to exchange and saves them. Statuses of orders sent
+// with api keys of user follow open orders feed of user, which is watched from the first sent order
+// or from start of server when user has open orders, until the feed is closed or watching is stopped
 type KrakenOrdersManagerService struct {
-	sdk    web.KrakenOrdersManager
-	repo   repository.KrakenOrdersManager
-	trader tradeAlgorithm.Trader
+	sdk          web.KrakenOrdersManagerFactory
+	feeds        web.KrakenPrivateFeeds
+	authRepo     repository.Authorization
+	settingsRepo repository.Settings
+	repo         repository.KrakenOrdersManager
+	risk         Risk
+	notifier     Notifier
+	trader       tradeAlgorithm.Strategies
+
+	watchCtx  context.Context
+	stopWatch context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	watching  map[int]struct{}
 }
 
-func NewKrakenOrdersManagerService(sdk web.KrakenOrdersManager, repo repository.KrakenOrdersManager,
-	trader tradeAlgorithm.Trader) *KrakenOrdersManagerService {
-	return &KrakenOrdersManagerService{sdk: sdk, repo: repo, trader: trader}
+func NewKrakenOrdersManagerService(sdk web.KrakenOrdersManagerFactory, feeds web.KrakenPrivateFeeds, authRepo repository.Authorization,
+	settingsRepo repository.Settings, repo repository.KrakenOrdersManager, risk Risk, notifier Notifier,
+	trader tradeAlgorithm.Strategies) *KrakenOrdersManagerService {
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	return &KrakenOrdersManagerService{
+		sdk:          sdk,
+		feeds:        feeds,
+		authRepo:     authRepo,
+		settingsRepo: settingsRepo,
+		repo:         repo,
+		risk:         risk,
+		notifier:     notifier,
+		trader:       trader,
+		watchCtx:     watchCtx,
+		stopWatch:    stopWatch,
+		watching:     make(map[int]struct{}),
+	}
 }
 
+// userOrdersManager returns orders manager which signs requests with api keys of user
+// or simulated exchange of user when paper trading is enabled in his settings
+func (k *KrakenOrdersManagerService) userOrdersManager(userID int) (web.KrakenOrdersManager, error) {
+	settings, err := k.settingsRepo.GetUserSettings(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserOrdersManager, err)
+	}
+	if settings.PaperTrading {
+		return k.sdk.GetPaperOrdersManager(userID), nil
+	}
+
+	publicAPIKey, privateAPIKey, err := k.authRepo.GetUserAPIKeys(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserOrdersManager, err)
+	}
+	return k.sdk.GetOrdersManager(userID, publicAPIKey, privateAPIKey), nil
+}
+
+// SendOrder sends order after risk check, error wraps *RiskRejection when order breaks one of risk rules.
+// User is notified about rejected order
 func (k *KrakenOrdersManagerService) SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
-	sendStatus, err := k.sdk.SendOrder(args)
+	if err := k.risk.CheckOrder(userID, args); err != nil {
+		k.notifier.Notify(orderRejectedEvent(userID, args, err))
+		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
+	}
+
+	order, err := k.SendExitOrder(userID, args)
+	if err != nil {
+		k.notifier.Notify(orderRejectedEvent(userID, args, err))
+	}
+	return order, err
+}
+
+// SendExitOrder sends order which closes or protects open position without risk check,
+// so position can be closed whatever limits of user are. User is notified about order filled on sending
+func (k *KrakenOrdersManagerService) SendExitOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	sdk, err := k.userOrdersManager(userID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
+	}
+
+	sendStatus, err := sdk.SendOrder(args)
 	if err != nil {
 		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
 	}
 
-	order, err := k.sdk.ParseSendStatusToExecutedOrder(userID, sendStatus)
+	order, err := sdk.ParseSendStatusToOrder(userID, sendStatus)
 	if err != nil {
 		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
 	}
@@ -47,43 +139,456 @@ func (k *KrakenOrdersManagerService) SendOrder(userID int, args krakenFuturesSDK
 		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
 	}
 
+	if order.Status == models.OrderFilled {
+		k.notifier.Notify(orderFilledEvent(order))
+	}
+	k.watchOrders(userID)
 	return order, nil
 }
 
-func (k *KrakenOrdersManagerService) StartTrading(ctx context.Context, userID int, details types.TradingDetails) (models.Order, error) {
-	sendArgs := krakenFuturesSDK.SendOrderArguments{
-		OrderType: details.OrderType,
-		Symbol:    details.Symbol,
-		Side:      details.Side,
-		Size:      details.Size,
+// WatchOpenOrders starts watching of open orders feeds of users which have open orders, e.g. resting
+// orders of bracket sent before restart, so their statuses are followed without new orders of users
+func (k *KrakenOrdersManagerService) WatchOpenOrders() error {
+	userIDs, err := k.repo.GetUsersWithOpenOrders()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrWatchOpenOrders, err)
+	}
+	for _, userID := range userIDs {
+		k.watchOrders(userID)
+	}
+	return nil
+}
+
+// watchOrders starts watching of open orders feed of user if it is not watched yet.
+// Orders of paper trading are filled by simulated exchange, so they are not watched
+func (k *KrakenOrdersManagerService) watchOrders(userID int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.watching[userID]; ok || k.watchCtx.Err() != nil {
+		return
+	}
+	k.watching[userID] = struct{}{}
+	k.wg.Add(1)
+
+	go func() {
+		defer k.wg.Done()
+		defer func() {
+			k.mu.Lock()
+			delete(k.watching, userID)
+			k.mu.Unlock()
+		}()
+
+		if err := k.followOrders(userID); err != nil {
+			log.Warnf("%s: user %d: %s", ErrWatchOrders, userID, err)
+		}
+	}()
+}
+
+func (k *KrakenOrdersManagerService) followOrders(userID int) error {
+	settings, err := k.settingsRepo.GetUserSettings(userID)
+	if err != nil {
+		return err
+	}
+	if settings.PaperTrading {
+		return nil
+	}
+
+	publicAPIKey, privateAPIKey, err := k.authRepo.GetUserAPIKeys(userID)
+	if err != nil {
+		return err
+	}
+
+	updates, err := k.feeds.LookForOrders(k.watchCtx, publicAPIKey, privateAPIKey)
+	if err != nil {
+		return err
+	}
+	for update := range updates {
+		if err := k.applyOrdersUpdate(userID, update); err != nil {
+			log.Warnf("%s: user %d: %s", ErrWatchOrders, userID, err)
+		}
+	}
+	return nil
+}
+
+// applyOrdersUpdate saves state of orders from open orders feed. Orders which were not sent through bot are skipped
+func (k *KrakenOrdersManagerService) applyOrdersUpdate(userID int, update krakenFuturesWSSDK.OpenOrdersData) error {
+	switch {
+	case update.Feed == krakenFuturesWSSDK.OpenOrdersSnapshotFeed:
+		for _, openOrder := range update.Orders {
+			if err := k.updateOpenOrder(userID, openOrder); err != nil {
+				return err
+			}
+		}
+	case update.Feed != krakenFuturesWSSDK.OpenOrdersFeed:
+		return nil
+	case update.IsCancel:
+		status := models.OrderCancelled
+		if update.Reason == fullFillReason {
+			status = models.OrderFilled
+		}
+
+		order, err := k.userOrder(userID, update.OrderID)
+		if err != nil {
+			if errors.Is(err, ErrOrderNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := k.repo.UpdateOrderStatus(userID, update.OrderID, status); err != nil {
+			return err
+		}
+		if status == models.OrderFilled {
+			k.notifier.Notify(orderFilledEvent(order))
+		}
+		return nil
+	case update.Order != nil:
+		return k.updateOpenOrder(userID, *update.Order)
 	}
+	return nil
+}
 
-	startOrder, err := k.SendOrder(userID, sendArgs)
+func (k *KrakenOrdersManagerService) updateOpenOrder(userID int, openOrder krakenFuturesWSSDK.OpenOrder) error {
+	order, err := k.userOrder(userID, openOrder.OrderID)
+	if errors.Is(err, ErrOrderNotFound) {
+		return nil
+	}
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrStartTradingService, err)
+		return err
 	}
 
-	details.BuyPrice = startOrder.Price
-	buyTime, err := time.Parse(time.RFC3339, startOrder.Timestamp)
+	order.Status = models.OrderOpen
+	order.Quantity = openOrder.Qty
+	order.Filled = openOrder.Filled
+	order.Price = openOrder.LimitPrice
+	if openOrder.StopPrice != 0 {
+		order.Price = openOrder.StopPrice
+	}
+	if openOrder.LastUpdateTime != 0 {
+		order.LastUpdateTimestamp = time.Unix(0, openOrder.LastUpdateTime*int64(time.Millisecond)).UTC()
+	}
+	return k.repo.UpdateOrder(userID, order)
+}
+
+// StopWatchingOrders stops watching of open orders feeds of all users
+func (k *KrakenOrdersManagerService) StopWatchingOrders() {
+	k.mu.Lock()
+	k.stopWatch()
+	k.mu.Unlock()
+
+	k.wg.Wait()
+}
+
+// userOrder returns order of user saved on sending
+func (k *KrakenOrdersManagerService) userOrder(userID int, orderID string) (models.Order, error) {
+	order, err := k.repo.GetOrder(orderID)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && order.UserID != userID {
+		return models.Order{}, ErrOrderNotFound
+	}
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrUnableToParseBuyTimestamp, err)
+		return models.Order{}, err
 	}
+	return order, nil
+}
 
-	if err := k.trader.StartAnalyzing(ctx, buyTime, details); err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrStartTradingService, err)
+// orderManagerError replaces error of exchange about missing order by ErrOrderNotActive
+func orderManagerError(method, err error) error {
+	if errors.Is(err, web.ErrOrderNotFound) {
+		return fmt.Errorf("%s: %w", method, ErrOrderNotActive)
 	}
+	return fmt.Errorf("%s: %w", method, err)
+}
 
-	opositeArgs := sendArgs
-	opositeArgs.ChangeToOpositeOrderSide()
+// EditOrder changes size or prices of resting order of user and returns its new state
+func (k *KrakenOrdersManagerService) EditOrder(userID int, args krakenFuturesSDK.EditOrderArguments) (models.Order, error) {
+	order, err := k.userOrder(userID, args.OrderID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrEditOrderServiceMethod, err)
+	}
+
+	sdk, err := k.userOrdersManager(userID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrEditOrderServiceMethod, err)
+	}
 
-	finishOrder, err := k.SendOrder(userID, opositeArgs)
+	editStatus, err := sdk.EditOrder(args)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrStartTradingService, err)
+		return models.Order{}, orderManagerError(ErrEditOrderServiceMethod, err)
+	}
+
+	applyEditStatus(&order, args, editStatus)
+	if err := k.repo.UpdateOrder(userID, order); err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrEditOrderServiceMethod, err)
 	}
+	return order, nil
+}
 
-	return finishOrder, nil
+// applyEditStatus updates order by edit event of exchange or by arguments of edit when there is no event
+func applyEditStatus(order *models.Order, args krakenFuturesSDK.EditOrderArguments, editStatus krakenFuturesSDK.EditStatus) {
+	order.Status = models.OrderOpen
+	order.LastUpdateTimestamp = models.ParseOrderTime(editStatus.ReceivedTime, time.Now().UTC())
+
+	for _, event := range editStatus.OrderEvents {
+		if event.Type != "EDIT" {
+			continue
+		}
+		order.Quantity = event.New.Quantity
+		order.Filled = event.New.Filled
+		order.Price = event.New.LimitPrice
+		if event.New.StopPrice != 0 {
+			order.Price = event.New.StopPrice
+		}
+		order.LastUpdateTimestamp = models.ParseOrderTime(event.New.LastUpdateTimestamp, order.LastUpdateTimestamp)
+		return
+	}
+
+	if args.Size != 0 {
+		order.Quantity = float64(args.Size)
+	}
+	if args.LimitPrice != 0 {
+		order.Price = args.LimitPrice
+	}
+	if args.StopPrice != 0 {
+		order.Price = args.StopPrice
+	}
+}
+
+// CancelOrder cancels resting order of user, error wraps ErrOrderNotActive when order is filled already
+func (k *KrakenOrdersManagerService) CancelOrder(userID int, args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error) {
+	order, err := k.userOrder(userID, args.OrderID)
+	if err != nil {
+		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrderServiceMethod, err)
+	}
+
+	sdk, err := k.userOrdersManager(userID)
+	if err != nil {
+		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrderServiceMethod, err)
+	}
+
+	cancelStatus, err := sdk.CancelOrder(args)
+	if err != nil {
+		return krakenFuturesSDK.CancelStatus{}, orderManagerError(ErrCancelOrderServiceMethod, err)
+	}
+
+	if err := k.repo.UpdateOrderStatus(userID, order.ID, models.OrderCancelled); err != nil {
+		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrderServiceMethod, err)
+	}
+	return cancelStatus, nil
+}
+
+// GetOrderFillPrice returns average price of fills of order, false is returned when order is not
+// among the last fills of user on exchange
+func (k *KrakenOrdersManagerService) GetOrderFillPrice(userID int, orderID string) (float64, bool, error) {
+	sdk, err := k.userOrdersManager(userID)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", ErrGetOrderFillPrice, err)
+	}
+
+	fills, err := sdk.Fills("")
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", ErrGetOrderFillPrice, err)
+	}
+
+	var size, notional float64
+	for _, fill := range fills {
+		if fill.OrderID == orderID {
+			size += fill.Size
+			notional += fill.Size * fill.Price
+		}
+	}
+	if size == 0 {
+		return 0, false, nil
+	}
+	return notional / size, true, nil
+}
+
+// FindOrder looks for order of user by client order id among open orders and the last fills of user on exchange.
+// It finds order which has been accepted by exchange while response to sending is lost, such order is saved
+// and followed like the sent one. False is returned when order is not found
+func (k *KrakenOrdersManagerService) FindOrder(userID int, cliOrderID string) (models.Order, bool, error) {
+	sdk, err := k.userOrdersManager(userID)
+	if err != nil {
+		return models.Order{}, false, fmt.Errorf("%s: %w", ErrFindOrder, err)
+	}
+
+	openOrders, err := sdk.OpenOrders()
+	if err != nil {
+		return models.Order{}, false, fmt.Errorf("%s: %w", ErrFindOrder, err)
+	}
+	order, ok := findOpenOrder(userID, cliOrderID, openOrders)
+	if !ok {
+		fills, err := sdk.Fills("")
+		if err != nil {
+			return models.Order{}, false, fmt.Errorf("%s: %w", ErrFindOrder, err)
+		}
+		order, ok = findFilledOrder(userID, cliOrderID, fills)
+	}
+	if !ok {
+		return models.Order{}, false, nil
+	}
+
+	_, err = k.userOrder(userID, order.ID)
+	if errors.Is(err, ErrOrderNotFound) {
+		err = k.repo.CreateOrder(userID, order)
+	}
+	if err != nil {
+		return models.Order{}, false, fmt.Errorf("%s: %w", ErrFindOrder, err)
+	}
+	k.watchOrders(userID)
+	return order, true, nil
+}
+
+func findOpenOrder(userID int, cliOrderID string, openOrders []krakenFuturesSDK.OpenOrder) (models.Order, bool) {
+	for _, openOrder := range openOrders {
+		if openOrder.CliOrdID != cliOrderID {
+			continue
+		}
+
+		receivedTime := models.ParseOrderTime(openOrder.ReceivedTime, time.Now().UTC())
+		order := models.Order{
+			ID:                  openOrder.OrderID,
+			UserID:              userID,
+			ClientOrderID:       openOrder.CliOrdID,
+			Type:                openOrder.OrderType,
+			Symbol:              openOrder.Symbol,
+			Quantity:            openOrder.FilledSize + openOrder.UnfilledSize,
+			Side:                openOrder.Side,
+			Filled:              openOrder.FilledSize,
+			Timestamp:           receivedTime,
+			LastUpdateTimestamp: models.ParseOrderTime(openOrder.LastUpdateTime, receivedTime),
+			Price:               openOrder.LimitPrice,
+			Status:              models.OrderOpen,
+		}
+		if openOrder.StopPrice != 0 {
+			order.Price = openOrder.StopPrice
+		}
+		return order, true
+	}
+	return models.Order{}, false
+}
+
+// findFilledOrder builds filled order from its fills, price of order is average price of fills
+func findFilledOrder(userID int, cliOrderID string, fills []krakenFuturesSDK.Fill) (models.Order, bool) {
+	var order models.Order
+	var notional float64
+	for _, fill := range fills {
+		if fill.CliOrdID != cliOrderID {
+			continue
+		}
+
+		fillTime := models.ParseOrderTime(fill.FillTime, time.Now().UTC())
+		if order.ID == "" {
+			order = models.Order{
+				ID:                  fill.OrderID,
+				UserID:              userID,
+				ClientOrderID:       fill.CliOrdID,
+				Symbol:              fill.Symbol,
+				Side:                fill.Side,
+				Timestamp:           fillTime,
+				LastUpdateTimestamp: fillTime,
+				Status:              models.OrderFilled,
+			}
+		}
+		if fillTime.Before(order.Timestamp) {
+			order.Timestamp = fillTime
+		}
+		if fillTime.After(order.LastUpdateTimestamp) {
+			order.LastUpdateTimestamp = fillTime
+		}
+		order.Quantity += fill.Size
+		notional += fill.Size * fill.Price
+	}
+	if order.ID == "" || order.Quantity == 0 {
+		return models.Order{}, false
+	}
+
+	order.Filled = order.Quantity
+	order.Price = notional / order.Quantity
+	return order, true
+}
+
+// CancelAllOrders cancels resting orders of user by symbol or of all symbols when it is empty.
+// Orders which were not sent through bot are cancelled on exchange too
+func (k *KrakenOrdersManagerService) CancelAllOrders(userID int, symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
+	sdk, err := k.userOrdersManager(userID)
+	if err != nil {
+		return krakenFuturesSDK.CancelAllStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrdersServiceMethod, err)
+	}
+
+	cancelStatus, err := sdk.CancelAllOrders(symbol)
+	if err != nil {
+		return krakenFuturesSDK.CancelAllStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrdersServiceMethod, err)
+	}
+
+	// orders placed outside of trade bot are not saved, so they are skipped
+	for _, cancelled := range cancelStatus.CancelledOrders {
+		err := k.repo.UpdateOrderStatus(userID, cancelled.OrderID, models.OrderCancelled)
+		if err != nil && !errors.Is(err, postgresRepo.ErrOrderNotFound) {
+			return krakenFuturesSDK.CancelAllStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrdersServiceMethod, err)
+		}
+	}
+
+	return cancelStatus, nil
+}
+
+// GetUserOrders returns page of order history of user selected by filter, the next page starts after order
+// with id of cursor. Zero limit of filter means default page size
+func (k *KrakenOrdersManagerService) GetUserOrders(userID int, filter models.OrdersFilter, cursor string) (models.OrdersPage, error) {
+	if err := validateOrdersFilter(&filter); err != nil {
+		return models.OrdersPage{}, fmt.Errorf("%s: %w", ErrGetUserOrders, err)
+	}
+
+	if cursor != "" {
+		after, err := k.userOrder(userID, cursor)
+		if errors.Is(err, ErrOrderNotFound) {
+			return models.OrdersPage{}, fmt.Errorf("%s: %w: unknown cursor %s", ErrGetUserOrders, ErrInvalidOrdersFilter, cursor)
+		}
+		if err != nil {
+			return models.OrdersPage{}, fmt.Errorf("%s: %w", ErrGetUserOrders, err)
+		}
+		filter.After = &after
+	}
+
+	// one more order tells whether there is the next page
+	limit := filter.Limit
+	filter.Limit++
+	orders, err := k.repo.FindUserOrders(userID, filter)
+	if err != nil {
+		return models.OrdersPage{}, fmt.Errorf("%s: %w", ErrGetUserOrders, err)
+	}
+
+	page := models.OrdersPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		page.NextCursor = orders[limit-1].ID
+	}
+	if page.Orders == nil {
+		page.Orders = []models.Order{}
+	}
+	return page, nil
+}
+
+func validateOrdersFilter(filter *models.OrdersFilter) error {
+	switch {
+	case filter.Limit < 0 || filter.Limit > maxOrdersLimit:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidOrdersFilter, maxOrdersLimit)
+	case filter.Side != "" && filter.Side != krakenFuturesSDK.BuySide && filter.Side != krakenFuturesSDK.SellSide:
+		return fmt.Errorf("%w: side must be buy or sell", ErrInvalidOrdersFilter)
+	case filter.Status != "" && filter.Status != models.OrderOpen && filter.Status != models.OrderFilled &&
+		filter.Status != models.OrderCancelled:
+		return fmt.Errorf("%w: status must be open, filled or cancelled", ErrInvalidOrdersFilter)
+	case filter.SortBy != "" && filter.SortBy != models.OrdersSortTimestamp && filter.SortBy != models.OrdersSortPrice &&
+		filter.SortBy != models.OrdersSortQuantity:
+		return fmt.Errorf("%w: orders can be sorted by timestamp, price or quantity", ErrInvalidOrdersFilter)
+	case !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To):
+		return fmt.Errorf("%w: from must be before to", ErrInvalidOrdersFilter)
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultOrdersLimit
+	}
+	return nil
 }
 
-func (k *KrakenOrdersManagerService) GetUserOrders(userID int) ([]models.Order, error) {
-	return k.repo.GetUserOrders(userID)
+func (k *KrakenOrdersManagerService) GetStrategies() []types.StrategySchema {
+	return k.trader.Schemas()
 }
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+func TestKrakenOrdersManagerService_applyOrdersUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		update       krakenFuturesWSSDK.OpenOrdersData
+		wantStatuses map[string]string
+		wantUpdated  []models.Order
+		wantEvents   []string
+	}{
+		{
+			name: "Snapshot",
+			update: krakenFuturesWSSDK.OpenOrdersData{Feed: "open_orders_snapshot", Orders: []krakenFuturesWSSDK.OpenOrder{
+				{OrderID: "limit", Qty: 2, Filled: 1, LimitPrice: 95, LastUpdateTime: 1640000000000},
+				{OrderID: "manual", Qty: 1, LimitPrice: 90},
+			}},
+			wantStatuses: map[string]string{},
+			wantUpdated: []models.Order{{ID: "limit", UserID: 1, Quantity: 2, Filled: 1, Price: 95,
+				LastUpdateTimestamp: time.Date(2021, 12, 20, 11, 33, 20, 0, time.UTC), Status: models.OrderOpen}},
+		},
+		{
+			name: "Stop price edited",
+			update: krakenFuturesWSSDK.OpenOrdersData{Feed: "open_orders", Reason: "edited_by_user",
+				Order: &krakenFuturesWSSDK.OpenOrder{OrderID: "stop", Qty: 1, StopPrice: 80}},
+			wantStatuses: map[string]string{},
+			wantUpdated:  []models.Order{{ID: "stop", UserID: 1, Quantity: 1, Price: 80, Status: models.OrderOpen}},
+		},
+		{
+			name:         "Filled",
+			update:       krakenFuturesWSSDK.OpenOrdersData{Feed: "open_orders", OrderID: "limit", IsCancel: true, Reason: "full_fill"},
+			wantStatuses: map[string]string{"limit": models.OrderFilled},
+			wantEvents:   []string{"order_filled:limit"},
+		},
+		{
+			name:         "Cancelled",
+			update:       krakenFuturesWSSDK.OpenOrdersData{Feed: "open_orders", OrderID: "stop", IsCancel: true, Reason: "cancelled_by_user"},
+			wantStatuses: map[string]string{"stop": models.OrderCancelled},
+		},
+		{
+			name:         "Order of other user",
+			update:       krakenFuturesWSSDK.OpenOrdersData{Feed: "open_orders", OrderID: "other", IsCancel: true, Reason: "full_fill"},
+			wantStatuses: map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &portfolioRepo{
+				orders: []models.Order{
+					{ID: "limit", UserID: 1, Status: models.OrderOpen},
+					{ID: "stop", UserID: 1, Status: models.OrderOpen},
+					{ID: "other", UserID: 2, Status: models.OrderOpen},
+				},
+				statuses: make(map[string]string),
+			}
+			notifier := &notifyRecorder{}
+			k := NewKrakenOrdersManagerService(nil, nil, nil, nil, repo, nil, notifier, nil)
+
+			assert.NoError(t, k.applyOrdersUpdate(1, test.update))
+			assert.Equal(t, test.wantStatuses, repo.statuses)
+			assert.Equal(t, test.wantUpdated, repo.updated)
+			assert.Equal(t, test.wantEvents, notifier.keys())
+		})
+	}
+}
+
+func TestKrakenOrdersManagerService_GetUserOrders(t *testing.T) {
+	start := time.Date(2021, 12, 20, 11, 0, 0, 0, time.UTC)
+	repo := &portfolioRepo{orders: []models.Order{
+		{ID: "1", UserID: 1, Symbol: "PI_XBTUSD", Side: "buy", Timestamp: start},
+		{ID: "2", UserID: 1, Symbol: "PI_XBTUSD", Side: "sell", Timestamp: start.Add(time.Minute)},
+		{ID: "3", UserID: 1, Symbol: "PF_ETHUSD", Side: "buy", Timestamp: start.Add(2 * time.Minute)},
+		{ID: "4", UserID: 1, Symbol: "PI_XBTUSD", Side: "buy", Timestamp: start.Add(3 * time.Minute)},
+		{ID: "other", UserID: 2, Symbol: "PI_XBTUSD", Side: "buy", Timestamp: start},
+	}}
+	k := NewKrakenOrdersManagerService(nil, nil, nil, nil, repo, nil, &notifyRecorder{}, nil)
+
+	tests := []struct {
+		name           string
+		filter         models.OrdersFilter
+		cursor         string
+		wantIDs        []string
+		wantNextCursor string
+		wantErrorIs    error
+	}{
+		{
+			name:           "First page of latest orders",
+			filter:         models.OrdersFilter{Symbol: "pi_xbtusd", Descending: true, Limit: 2},
+			wantIDs:        []string{"4", "2"},
+			wantNextCursor: "2",
+		},
+		{
+			name:    "Last page",
+			filter:  models.OrdersFilter{Symbol: "pi_xbtusd", Descending: true, Limit: 2},
+			cursor:  "2",
+			wantIDs: []string{"1"},
+		},
+		{
+			name:    "Side and time range",
+			filter:  models.OrdersFilter{Side: "buy", From: start.Add(time.Minute), To: start.Add(3 * time.Minute)},
+			wantIDs: []string{"3"},
+		},
+		{
+			name:    "Nothing found",
+			filter:  models.OrdersFilter{Symbol: "PI_ETHUSD"},
+			wantIDs: []string{},
+		},
+		{
+			name:        "Cursor of other user",
+			cursor:      "other",
+			wantErrorIs: ErrInvalidOrdersFilter,
+		},
+		{
+			name:        "Limit is too large",
+			filter:      models.OrdersFilter{Limit: maxOrdersLimit + 1},
+			wantErrorIs: ErrInvalidOrdersFilter,
+		},
+		{
+			name:        "Invalid side",
+			filter:      models.OrdersFilter{Side: "long"},
+			wantErrorIs: ErrInvalidOrdersFilter,
+		},
+		{
+			name:        "Empty time range",
+			filter:      models.OrdersFilter{From: start, To: start},
+			wantErrorIs: ErrInvalidOrdersFilter,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := k.GetUserOrders(1, test.filter, test.cursor)
+			if test.wantErrorIs != nil {
+				assert.ErrorIs(t, err, test.wantErrorIs)
+				return
+			}
+			assert.NoError(t, err)
+
+			ids := make([]string, 0, len(page.Orders))
+			for _, order := range page.Orders {
+				ids = append(ids, order.ID)
+			}
+			assert.Equal(t, test.wantIDs, ids)
+			assert.Equal(t, test.wantNextCursor, page.NextCursor)
+		})
+	}
+}
+
+func TestFindOpenOrder(t *testing.T) {
+	openOrders := []krakenFuturesSDK.OpenOrder{
+		{OrderID: "other", CliOrdID: "session-2-exit"},
+		{OrderID: "stop", CliOrdID: "session-1-exit", OrderType: "stp", Symbol: "pi_xbtusd", Side: "sell", StopPrice: 90,
+			LimitPrice: 89, FilledSize: 1, UnfilledSize: 2, ReceivedTime: "2021-12-20T11:00:00Z"},
+	}
+
+	got, ok := findOpenOrder(1, "session-1-exit", openOrders)
+	assert.True(t, ok)
+	receivedTime := time.Date(2021, 12, 20, 11, 0, 0, 0, time.UTC)
+	assert.Equal(t, models.Order{ID: "stop", UserID: 1, ClientOrderID: "session-1-exit", Type: "stp", Symbol: "pi_xbtusd",
+		Quantity: 3, Side: "sell", Filled: 1, Timestamp: receivedTime, LastUpdateTimestamp: receivedTime, Price: 90,
+		Status: models.OrderOpen}, got)
+
+	_, ok = findOpenOrder(1, "session-3-exit", openOrders)
+	assert.False(t, ok)
+}
+
+func TestFindFilledOrder(t *testing.T) {
+	fills := []krakenFuturesSDK.Fill{
+		{FillID: "3", OrderID: "exit", CliOrdID: "session-1-exit", Symbol: "pi_xbtusd", Side: "sell", Size: 3, Price: 110,
+			FillTime: "2021-12-20T11:00:02Z"},
+		{FillID: "2", OrderID: "other", CliOrdID: "session-2-exit", Size: 1, Price: 90, FillTime: "2021-12-20T11:00:01Z"},
+		{FillID: "1", OrderID: "exit", CliOrdID: "session-1-exit", Symbol: "pi_xbtusd", Side: "sell", Size: 1, Price: 100,
+			FillTime: "2021-12-20T11:00:00Z"},
+	}
+
+	got, ok := findFilledOrder(1, "session-1-exit", fills)
+	assert.True(t, ok)
+	assert.Equal(t, models.Order{ID: "exit", UserID: 1, ClientOrderID: "session-1-exit", Symbol: "pi_xbtusd", Quantity: 4,
+		Side: "sell", Filled: 4, Timestamp: time.Date(2021, 12, 20, 11, 0, 0, 0, time.UTC),
+		LastUpdateTimestamp: time.Date(2021, 12, 20, 11, 0, 2, 0, time.UTC), Price: 107.5, Status: models.OrderFilled}, got)
+
+	_, ok = findFilledOrder(1, "session-3-exit", fills)
+	assert.False(t, ok)
+}
+
+// ordersFeeds streams no updates and keeps public api keys of watched users
+type ordersFeeds struct {
+	web.KrakenPrivateFeeds
+	mu      sync.Mutex
+	watched []string
+}
+
+func (f *ordersFeeds) LookForOrders(ctx context.Context, publicAPIKey, privateAPIKey string) (<-chan krakenFuturesWSSDK.OpenOrdersData, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.watched = append(f.watched, publicAPIKey)
+
+	updates := make(chan krakenFuturesWSSDK.OpenOrdersData)
+	close(updates)
+	return updates, nil
+}
+
+// usersKeys returns api keys and live trading settings of any user
+type usersKeys struct {
+	repository.Authorization
+	repository.Settings
+}
+
+func (u usersKeys) GetUserAPIKeys(userID int) (string, string, error) {
+	return fmt.Sprintf("public-%d", userID), "private", nil
+}
+
+func (u usersKeys) GetUserSettings(userID int) (models.UserSettings, error) {
+	return models.UserSettings{}, nil
+}
+
+func TestKrakenOrdersManagerService_WatchOpenOrders(t *testing.T) {
+	repo := &portfolioRepo{orders: []models.Order{
+		{ID: "stop", UserID: 1, Status: models.OrderOpen},
+		{ID: "take", UserID: 1, Status: models.OrderOpen},
+		{ID: "filled", UserID: 2, Status: models.OrderFilled},
+		{ID: "limit", UserID: 3, Status: models.OrderOpen},
+	}}
+	feeds := &ordersFeeds{}
+	k := NewKrakenOrdersManagerService(nil, feeds, usersKeys{}, usersKeys{}, repo, nil, &notifyRecorder{}, nil)
+
+	assert.NoError(t, k.WatchOpenOrders())
+	k.StopWatchingOrders()
+
+	sort.Strings(feeds.watched)
+	assert.Equal(t, []string{"public-1", "public-3"}, feeds.watched)
+}
+
+// cancellingExchange cancels orders of every user by the same status
+type cancellingExchange struct {
+	web.KrakenOrdersManager
+	status krakenFuturesSDK.CancelAllStatus
+}
+
+func (e *cancellingExchange) GetOrdersManager(userID int, publicAPIKey, privateAPIKey string) web.KrakenOrdersManager {
+	return e
+}
+
+func (e *cancellingExchange) GetPaperOrdersManager(userID int) web.KrakenOrdersManager {
+	return e
+}
+
+func (e *cancellingExchange) ClosePaperOrdersManager(userID int) {}
+
+func (e *cancellingExchange) Close() {}
+
+func (e *cancellingExchange) CancelAllOrders(symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
+	return e.status, nil
+}
+
+func TestKrakenOrdersManagerService_CancelAllOrders(t *testing.T) {
+	repo := &portfolioRepo{
+		orders: []models.Order{
+			{ID: "limit", UserID: 1, Status: models.OrderOpen},
+			{ID: "other", UserID: 2, Status: models.OrderOpen},
+		},
+		statuses: make(map[string]string),
+	}
+	exchange := &cancellingExchange{status: krakenFuturesSDK.CancelAllStatus{
+		CancelledOrders: []krakenFuturesSDK.CanceledOrder{
+			{OrderID: "limit"}, {OrderID: "manual"}, {OrderID: "other"},
+		},
+	}}
+	k := NewKrakenOrdersManagerService(exchange, nil, usersKeys{}, usersKeys{}, repo, nil, &notifyRecorder{}, nil)
+
+	status, err := k.CancelAllOrders(1, "pi_xbtusd")
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.status, status)
+	assert.Equal(t, map[string]string{"limit": models.OrderCancelled}, repo.statuses)
+}
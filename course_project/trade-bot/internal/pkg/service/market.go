@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrGetMarketData = errors.New("get market data")
+	ErrMarketCache   = errors.New("market cache")
+)
+
+// Market data changes at different rates, so tickers and order books are cached only for a few seconds
+// while instruments and fee schedules are cached much longer
+const (
+	tickersCacheTTL      = 2 * time.Second
+	orderBookCacheTTL    = time.Second
+	instrumentsCacheTTL  = 10 * time.Minute
+	feeSchedulesCacheTTL = 10 * time.Minute
+
+	tickersCacheKey      = "market:tickers"
+	orderBookCacheKey    = "market:orderbook:"
+	instrumentsCacheKey  = "market:instruments"
+	feeSchedulesCacheKey = "market:fees"
+)
+
+// MarketService serves public market data of exchange from short-lived cache,
+// requests to exchange are sent only when cached data is missed or expired
+type MarketService struct {
+	market web.KrakenMarketData
+	cache  repository.MarketCache
+}
+
+func NewMarketService(market web.KrakenMarketData, cache repository.MarketCache) *MarketService {
+	return &MarketService{market: market, cache: cache}
+}
+
+func (m *MarketService) GetTickers() ([]krakenFuturesSDK.Ticker, error) {
+	var tickers []krakenFuturesSDK.Ticker
+	err := m.cached(tickersCacheKey, tickersCacheTTL, &tickers, func() (interface{}, error) {
+		return m.market.Tickers()
+	})
+	return tickers, err
+}
+
+func (m *MarketService) GetOrderBook(symbol string) (krakenFuturesSDK.OrderBook, error) {
+	symbol = strings.ToUpper(symbol)
+
+	var orderBook krakenFuturesSDK.OrderBook
+	err := m.cached(orderBookCacheKey+symbol, orderBookCacheTTL, &orderBook, func() (interface{}, error) {
+		return m.market.OrderBook(symbol)
+	})
+	return orderBook, err
+}
+
+func (m *MarketService) GetInstruments() ([]krakenFuturesSDK.Instrument, error) {
+	var instruments []krakenFuturesSDK.Instrument
+	err := m.cached(instrumentsCacheKey, instrumentsCacheTTL, &instruments, func() (interface{}, error) {
+		return m.market.Instruments()
+	})
+	return instruments, err
+}
+
+func (m *MarketService) GetFeeSchedules() ([]krakenFuturesSDK.FeeSchedules, error) {
+	var schedules []krakenFuturesSDK.FeeSchedules
+	err := m.cached(feeSchedulesCacheKey, feeSchedulesCacheTTL, &schedules, func() (interface{}, error) {
+		return m.market.FeeSchedules()
+	})
+	return schedules, err
+}
+
+// cached decodes cached data of key into value or loads data from exchange and caches it for ttl,
+// value must point to the type of loaded data.
+// Cache failures only are logged, so market data is still served by exchange when cache is unavailable
+func (m *MarketService) cached(key string, ttl time.Duration, value interface{}, load func() (interface{}, error)) error {
+	hit, err := m.cache.GetMarketData(key, value)
+	if err != nil {
+		log.Warnf("%s: %s", ErrMarketCache, err)
+	}
+	if hit {
+		return nil
+	}
+
+	data, err := load()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrGetMarketData, err)
+	}
+
+	if err := m.cache.SetMarketData(key, data, ttl); err != nil {
+		log.Warnf("%s: %s", ErrMarketCache, err)
+	}
+
+	reflect.ValueOf(value).Elem().Set(reflect.ValueOf(data))
+	return nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+// marketDataCounter serves the same market data and counts requests to exchange
+type marketDataCounter struct {
+	requests int
+	err      error
+}
+
+func (m *marketDataCounter) FeeSchedules() ([]krakenFuturesSDK.FeeSchedules, error) {
+	m.requests++
+	return []krakenFuturesSDK.FeeSchedules{{Name: "default"}}, m.err
+}
+
+func (m *marketDataCounter) Tickers() ([]krakenFuturesSDK.Ticker, error) {
+	m.requests++
+	return []krakenFuturesSDK.Ticker{{Symbol: "PI_XBTUSD", Last: 28000.5}}, m.err
+}
+
+func (m *marketDataCounter) OrderBook(symbol string) (krakenFuturesSDK.OrderBook, error) {
+	m.requests++
+	return krakenFuturesSDK.OrderBook{Bids: [][2]float64{{28000, 10}}, Asks: [][2]float64{{28001, 5}}}, m.err
+}
+
+func (m *marketDataCounter) Instruments() ([]krakenFuturesSDK.Instrument, error) {
+	m.requests++
+	return []krakenFuturesSDK.Instrument{{Symbol: "PI_XBTUSD", Tradeable: true}}, m.err
+}
+
+// memoryCache is a market cache without expiration, it fails every call when err is set
+type memoryCache struct {
+	values map[string][]byte
+	err    error
+}
+
+func (c *memoryCache) GetMarketData(key string, value interface{}) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+	data, ok := c.values[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+func (c *memoryCache) SetMarketData(key string, value interface{}, ttl time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = data
+	return nil
+}
+
+func TestMarketService_GetTickers(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheErr     error
+		marketErr    error
+		want         []krakenFuturesSDK.Ticker
+		wantRequests int
+		wantErr      bool
+	}{
+		{
+			name:         "Second call is served by cache",
+			want:         []krakenFuturesSDK.Ticker{{Symbol: "PI_XBTUSD", Last: 28000.5}},
+			wantRequests: 1,
+		},
+		{
+			name:         "Unavailable cache falls back to exchange",
+			cacheErr:     errors.New("connection refused"),
+			want:         []krakenFuturesSDK.Ticker{{Symbol: "PI_XBTUSD", Last: 28000.5}},
+			wantRequests: 2,
+		},
+		{
+			name:         "Exchange error",
+			marketErr:    errors.New("service unavailable"),
+			wantRequests: 2,
+			wantErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			market := &marketDataCounter{err: test.marketErr}
+			s := NewMarketService(market, &memoryCache{values: make(map[string][]byte), err: test.cacheErr})
+
+			for i := 0; i < 2; i++ {
+				got, err := s.GetTickers()
+				if test.wantErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, test.want, got)
+				}
+			}
+			assert.Equal(t, test.wantRequests, market.requests)
+		})
+	}
+}
+
+func TestMarketService_GetOrderBook(t *testing.T) {
+	market := &marketDataCounter{}
+	cache := &memoryCache{values: make(map[string][]byte)}
+	s := NewMarketService(market, cache)
+
+	for _, symbol := range []string{"pi_xbtusd", "PI_XBTUSD"} {
+		got, err := s.GetOrderBook(symbol)
+		assert.NoError(t, err)
+		assert.Equal(t, krakenFuturesSDK.OrderBook{Bids: [][2]float64{{28000, 10}}, Asks: [][2]float64{{28001, 5}}}, got)
+	}
+	assert.Equal(t, 1, market.requests)
+	assert.Contains(t, cache.values, "market:orderbook:PI_XBTUSD")
+}
@@ -7,6 +7,8 @@ package mock_service
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
+	backtest "trade-bot/internal/pkg/backtest"
 	models "trade-bot/internal/pkg/models"
 	types "trade-bot/internal/pkg/tradeAlgorithm/types"
 	krakenFuturesSDK "trade-bot/pkg/krakenFuturesSDK"
@@ -53,18 +55,18 @@ func (mr *MockAuthorizationMockRecorder) CreateUser(user interface{}) *gomock.Ca
 }
 
 // GenerateJWT mocks base method.
-func (m *MockAuthorization) GenerateJWT(username, password string) (string, error) {
+func (m *MockAuthorization) GenerateJWT(username, password string, client models.SessionClient) (models.Tokens, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateJWT", username, password)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GenerateJWT", username, password, client)
+	ret0, _ := ret[0].(models.Tokens)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateJWT indicates an expected call of GenerateJWT.
-func (mr *MockAuthorizationMockRecorder) GenerateJWT(username, password interface{}) *gomock.Call {
+func (mr *MockAuthorizationMockRecorder) GenerateJWT(username, password, client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateJWT", reflect.TypeOf((*MockAuthorization)(nil).GenerateJWT), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateJWT", reflect.TypeOf((*MockAuthorization)(nil).GenerateJWT), username, password, client)
 }
 
 // GetUserAPIKeys mocks base method.
@@ -98,6 +100,21 @@ func (mr *MockAuthorizationMockRecorder) GetUserIDByJWT(token interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByJWT", reflect.TypeOf((*MockAuthorization)(nil).GetUserIDByJWT), token)
 }
 
+// GetUserSessions mocks base method.
+func (m *MockAuthorization) GetUserSessions(userID int) ([]models.AuthSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", userID)
+	ret0, _ := ret[0].([]models.AuthSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockAuthorizationMockRecorder) GetUserSessions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockAuthorization)(nil).GetUserSessions), userID)
+}
+
 // LogoutUser mocks base method.
 func (m *MockAuthorization) LogoutUser(token string) error {
 	m.ctrl.T.Helper()
@@ -112,6 +129,35 @@ func (mr *MockAuthorizationMockRecorder) LogoutUser(token interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutUser", reflect.TypeOf((*MockAuthorization)(nil).LogoutUser), token)
 }
 
+// RefreshJWT mocks base method.
+func (m *MockAuthorization) RefreshJWT(refreshToken string, client models.SessionClient) (models.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshJWT", refreshToken, client)
+	ret0, _ := ret[0].(models.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshJWT indicates an expected call of RefreshJWT.
+func (mr *MockAuthorizationMockRecorder) RefreshJWT(refreshToken, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshJWT", reflect.TypeOf((*MockAuthorization)(nil).RefreshJWT), refreshToken, client)
+}
+
+// RevokeSession mocks base method.
+func (m *MockAuthorization) RevokeSession(userID int, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockAuthorizationMockRecorder) RevokeSession(userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockAuthorization)(nil).RevokeSession), userID, sessionID)
+}
+
 // MockKrakenOrdersManager is a mock of KrakenOrdersManager interface.
 type MockKrakenOrdersManager struct {
 	ctrl     *gomock.Controller
@@ -135,6 +181,127 @@ func (m *MockKrakenOrdersManager) EXPECT() *MockKrakenOrdersManagerMockRecorder
 	return m.recorder
 }
 
+// CancelAllOrders mocks base method.
+func (m *MockKrakenOrdersManager) CancelAllOrders(userID int, symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelAllOrders", userID, symbol)
+	ret0, _ := ret[0].(krakenFuturesSDK.CancelAllStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelAllOrders indicates an expected call of CancelAllOrders.
+func (mr *MockKrakenOrdersManagerMockRecorder) CancelAllOrders(userID, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelAllOrders", reflect.TypeOf((*MockKrakenOrdersManager)(nil).CancelAllOrders), userID, symbol)
+}
+
+// CancelOrder mocks base method.
+func (m *MockKrakenOrdersManager) CancelOrder(userID int, args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", userID, args)
+	ret0, _ := ret[0].(krakenFuturesSDK.CancelStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockKrakenOrdersManagerMockRecorder) CancelOrder(userID, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).CancelOrder), userID, args)
+}
+
+// EditOrder mocks base method.
+func (m *MockKrakenOrdersManager) EditOrder(userID int, args krakenFuturesSDK.EditOrderArguments) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditOrder", userID, args)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditOrder indicates an expected call of EditOrder.
+func (mr *MockKrakenOrdersManagerMockRecorder) EditOrder(userID, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).EditOrder), userID, args)
+}
+
+// FindOrder mocks base method.
+func (m *MockKrakenOrdersManager) FindOrder(userID int, cliOrderID string) (models.Order, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrder", userID, cliOrderID)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindOrder indicates an expected call of FindOrder.
+func (mr *MockKrakenOrdersManagerMockRecorder) FindOrder(userID, cliOrderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).FindOrder), userID, cliOrderID)
+}
+
+// GetOrderFillPrice mocks base method.
+func (m *MockKrakenOrdersManager) GetOrderFillPrice(userID int, orderID string) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderFillPrice", userID, orderID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOrderFillPrice indicates an expected call of GetOrderFillPrice.
+func (mr *MockKrakenOrdersManagerMockRecorder) GetOrderFillPrice(userID, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderFillPrice", reflect.TypeOf((*MockKrakenOrdersManager)(nil).GetOrderFillPrice), userID, orderID)
+}
+
+// GetStrategies mocks base method.
+func (m *MockKrakenOrdersManager) GetStrategies() []types.StrategySchema {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStrategies")
+	ret0, _ := ret[0].([]types.StrategySchema)
+	return ret0
+}
+
+// GetStrategies indicates an expected call of GetStrategies.
+func (mr *MockKrakenOrdersManagerMockRecorder) GetStrategies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStrategies", reflect.TypeOf((*MockKrakenOrdersManager)(nil).GetStrategies))
+}
+
+// GetUserOrders mocks base method.
+func (m *MockKrakenOrdersManager) GetUserOrders(userID int, filter models.OrdersFilter, cursor string) (models.OrdersPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserOrders", userID, filter, cursor)
+	ret0, _ := ret[0].(models.OrdersPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserOrders indicates an expected call of GetUserOrders.
+func (mr *MockKrakenOrdersManagerMockRecorder) GetUserOrders(userID, filter, cursor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockKrakenOrdersManager)(nil).GetUserOrders), userID, filter, cursor)
+}
+
+// SendExitOrder mocks base method.
+func (m *MockKrakenOrdersManager) SendExitOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExitOrder", userID, args)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExitOrder indicates an expected call of SendExitOrder.
+func (mr *MockKrakenOrdersManagerMockRecorder) SendExitOrder(userID, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExitOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).SendExitOrder), userID, args)
+}
+
 // SendOrder mocks base method.
 func (m *MockKrakenOrdersManager) SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
 	m.ctrl.T.Helper()
@@ -150,17 +317,865 @@ func (mr *MockKrakenOrdersManagerMockRecorder) SendOrder(userID, args interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).SendOrder), userID, args)
 }
 
-// StartTrading mocks base method.
-func (m *MockKrakenOrdersManager) StartTrading(ctx context.Context, userID int, details types.TradingDetails) (models.Order, error) {
+// StopWatchingOrders mocks base method.
+func (m *MockKrakenOrdersManager) StopWatchingOrders() {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StartTrading", ctx, userID, details)
-	ret0, _ := ret[0].(models.Order)
+	m.ctrl.Call(m, "StopWatchingOrders")
+}
+
+// StopWatchingOrders indicates an expected call of StopWatchingOrders.
+func (mr *MockKrakenOrdersManagerMockRecorder) StopWatchingOrders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopWatchingOrders", reflect.TypeOf((*MockKrakenOrdersManager)(nil).StopWatchingOrders))
+}
+
+// WatchOpenOrders mocks base method.
+func (m *MockKrakenOrdersManager) WatchOpenOrders() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchOpenOrders")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchOpenOrders indicates an expected call of WatchOpenOrders.
+func (mr *MockKrakenOrdersManagerMockRecorder) WatchOpenOrders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchOpenOrders", reflect.TypeOf((*MockKrakenOrdersManager)(nil).WatchOpenOrders))
+}
+
+// MockBacktest is a mock of Backtest interface.
+type MockBacktest struct {
+	ctrl     *gomock.Controller
+	recorder *MockBacktestMockRecorder
+}
+
+// MockBacktestMockRecorder is the mock recorder for MockBacktest.
+type MockBacktestMockRecorder struct {
+	mock *MockBacktest
+}
+
+// NewMockBacktest creates a new mock instance.
+func NewMockBacktest(ctrl *gomock.Controller) *MockBacktest {
+	mock := &MockBacktest{ctrl: ctrl}
+	mock.recorder = &MockBacktestMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBacktest) EXPECT() *MockBacktestMockRecorder {
+	return m.recorder
+}
+
+// RunBacktest mocks base method.
+func (m *MockBacktest) RunBacktest(ctx context.Context, input models.BacktestInput) (backtest.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunBacktest", ctx, input)
+	ret0, _ := ret[0].(backtest.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunBacktest indicates an expected call of RunBacktest.
+func (mr *MockBacktestMockRecorder) RunBacktest(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunBacktest", reflect.TypeOf((*MockBacktest)(nil).RunBacktest), ctx, input)
+}
+
+// MockSettings is a mock of Settings interface.
+type MockSettings struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsMockRecorder
+}
+
+// MockSettingsMockRecorder is the mock recorder for MockSettings.
+type MockSettingsMockRecorder struct {
+	mock *MockSettings
+}
+
+// NewMockSettings creates a new mock instance.
+func NewMockSettings(ctrl *gomock.Controller) *MockSettings {
+	mock := &MockSettings{ctrl: ctrl}
+	mock.recorder = &MockSettingsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettings) EXPECT() *MockSettingsMockRecorder {
+	return m.recorder
+}
+
+// GetSettings mocks base method.
+func (m *MockSettings) GetSettings(userID int) (models.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", userID)
+	ret0, _ := ret[0].(models.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockSettingsMockRecorder) GetSettings(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockSettings)(nil).GetSettings), userID)
+}
+
+// UpdateSettings mocks base method.
+func (m *MockSettings) UpdateSettings(userID int, settings models.UserSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", userID, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockSettingsMockRecorder) UpdateSettings(userID, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockSettings)(nil).UpdateSettings), userID, settings)
+}
+
+// MockRisk is a mock of Risk interface.
+type MockRisk struct {
+	ctrl     *gomock.Controller
+	recorder *MockRiskMockRecorder
+}
+
+// MockRiskMockRecorder is the mock recorder for MockRisk.
+type MockRiskMockRecorder struct {
+	mock *MockRisk
+}
+
+// NewMockRisk creates a new mock instance.
+func NewMockRisk(ctrl *gomock.Controller) *MockRisk {
+	mock := &MockRisk{ctrl: ctrl}
+	mock.recorder = &MockRiskMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRisk) EXPECT() *MockRiskMockRecorder {
+	return m.recorder
+}
+
+// CheckOrder mocks base method.
+func (m *MockRisk) CheckOrder(userID int, args krakenFuturesSDK.SendOrderArguments) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckOrder", userID, args)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckOrder indicates an expected call of CheckOrder.
+func (mr *MockRiskMockRecorder) CheckOrder(userID, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOrder", reflect.TypeOf((*MockRisk)(nil).CheckOrder), userID, args)
+}
+
+// CheckSession mocks base method.
+func (m *MockRisk) CheckSession(userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSession", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSession indicates an expected call of CheckSession.
+func (mr *MockRiskMockRecorder) CheckSession(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockRisk)(nil).CheckSession), userID)
+}
+
+// GetRiskLimits mocks base method.
+func (m *MockRisk) GetRiskLimits(userID int) (models.RiskLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRiskLimits", userID)
+	ret0, _ := ret[0].(models.RiskLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRiskLimits indicates an expected call of GetRiskLimits.
+func (mr *MockRiskMockRecorder) GetRiskLimits(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRiskLimits", reflect.TypeOf((*MockRisk)(nil).GetRiskLimits), userID)
+}
+
+// UpdateRiskLimits mocks base method.
+func (m *MockRisk) UpdateRiskLimits(userID int, limits models.RiskLimits) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRiskLimits", userID, limits)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRiskLimits indicates an expected call of UpdateRiskLimits.
+func (mr *MockRiskMockRecorder) UpdateRiskLimits(userID, limits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRiskLimits", reflect.TypeOf((*MockRisk)(nil).UpdateRiskLimits), userID, limits)
+}
+
+// MockTradingSessions is a mock of TradingSessions interface.
+type MockTradingSessions struct {
+	ctrl     *gomock.Controller
+	recorder *MockTradingSessionsMockRecorder
+}
+
+// MockTradingSessionsMockRecorder is the mock recorder for MockTradingSessions.
+type MockTradingSessionsMockRecorder struct {
+	mock *MockTradingSessions
+}
+
+// NewMockTradingSessions creates a new mock instance.
+func NewMockTradingSessions(ctrl *gomock.Controller) *MockTradingSessions {
+	mock := &MockTradingSessions{ctrl: ctrl}
+	mock.recorder = &MockTradingSessionsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTradingSessions) EXPECT() *MockTradingSessionsMockRecorder {
+	return m.recorder
+}
+
+// CancelSession mocks base method.
+func (m *MockTradingSessions) CancelSession(userID, sessionID int) (models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSession", userID, sessionID)
+	ret0, _ := ret[0].(models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelSession indicates an expected call of CancelSession.
+func (mr *MockTradingSessionsMockRecorder) CancelSession(userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSession", reflect.TypeOf((*MockTradingSessions)(nil).CancelSession), userID, sessionID)
+}
+
+// GetSession mocks base method.
+func (m *MockTradingSessions) GetSession(userID, sessionID int) (models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", userID, sessionID)
+	ret0, _ := ret[0].(models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockTradingSessionsMockRecorder) GetSession(userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockTradingSessions)(nil).GetSession), userID, sessionID)
+}
+
+// GetUserSessions mocks base method.
+func (m *MockTradingSessions) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", userID)
+	ret0, _ := ret[0].([]models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockTradingSessionsMockRecorder) GetUserSessions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockTradingSessions)(nil).GetUserSessions), userID)
+}
+
+// ResumeSessions mocks base method.
+func (m *MockTradingSessions) ResumeSessions() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSessions")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeSessions indicates an expected call of ResumeSessions.
+func (mr *MockTradingSessionsMockRecorder) ResumeSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSessions", reflect.TypeOf((*MockTradingSessions)(nil).ResumeSessions))
+}
+
+// StartSession mocks base method.
+func (m *MockTradingSessions) StartSession(userID int, details types.TradingDetails) (models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSession", userID, details)
+	ret0, _ := ret[0].(models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartSession indicates an expected call of StartSession.
+func (mr *MockTradingSessionsMockRecorder) StartSession(userID, details interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSession", reflect.TypeOf((*MockTradingSessions)(nil).StartSession), userID, details)
+}
+
+// StopSessions mocks base method.
+func (m *MockTradingSessions) StopSessions() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopSessions")
+}
+
+// StopSessions indicates an expected call of StopSessions.
+func (mr *MockTradingSessionsMockRecorder) StopSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSessions", reflect.TypeOf((*MockTradingSessions)(nil).StopSessions))
+}
+
+// WaitSession mocks base method.
+func (m *MockTradingSessions) WaitSession(ctx context.Context, userID, sessionID int) (models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitSession indicates an expected call of WaitSession.
+func (mr *MockTradingSessionsMockRecorder) WaitSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitSession", reflect.TypeOf((*MockTradingSessions)(nil).WaitSession), ctx, userID, sessionID)
+}
+
+// MockPortfolio is a mock of Portfolio interface.
+type MockPortfolio struct {
+	ctrl     *gomock.Controller
+	recorder *MockPortfolioMockRecorder
+}
+
+// MockPortfolioMockRecorder is the mock recorder for MockPortfolio.
+type MockPortfolioMockRecorder struct {
+	mock *MockPortfolio
+}
+
+// NewMockPortfolio creates a new mock instance.
+func NewMockPortfolio(ctrl *gomock.Controller) *MockPortfolio {
+	mock := &MockPortfolio{ctrl: ctrl}
+	mock.recorder = &MockPortfolioMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPortfolio) EXPECT() *MockPortfolioMockRecorder {
+	return m.recorder
+}
+
+// GetAccounts mocks base method.
+func (m *MockPortfolio) GetAccounts(userID int) (map[string]krakenFuturesSDK.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccounts", userID)
+	ret0, _ := ret[0].(map[string]krakenFuturesSDK.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccounts indicates an expected call of GetAccounts.
+func (mr *MockPortfolioMockRecorder) GetAccounts(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockPortfolio)(nil).GetAccounts), userID)
+}
+
+// GetDrifts mocks base method.
+func (m *MockPortfolio) GetDrifts(userID int) ([]models.PortfolioDrift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrifts", userID)
+	ret0, _ := ret[0].([]models.PortfolioDrift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDrifts indicates an expected call of GetDrifts.
+func (mr *MockPortfolioMockRecorder) GetDrifts(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrifts", reflect.TypeOf((*MockPortfolio)(nil).GetDrifts), userID)
+}
+
+// GetFills mocks base method.
+func (m *MockPortfolio) GetFills(userID int) ([]models.Fill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFills", userID)
+	ret0, _ := ret[0].([]models.Fill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFills indicates an expected call of GetFills.
+func (mr *MockPortfolioMockRecorder) GetFills(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFills", reflect.TypeOf((*MockPortfolio)(nil).GetFills), userID)
+}
+
+// GetHistoricalOrders mocks base method.
+func (m *MockPortfolio) GetHistoricalOrders(userID int, since int64) ([]krakenFuturesSDK.HistoricalOrderElement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoricalOrders", userID, since)
+	ret0, _ := ret[0].([]krakenFuturesSDK.HistoricalOrderElement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoricalOrders indicates an expected call of GetHistoricalOrders.
+func (mr *MockPortfolioMockRecorder) GetHistoricalOrders(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoricalOrders", reflect.TypeOf((*MockPortfolio)(nil).GetHistoricalOrders), userID, since)
+}
+
+// GetOpenOrders mocks base method.
+func (m *MockPortfolio) GetOpenOrders(userID int) ([]krakenFuturesSDK.OpenOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenOrders", userID)
+	ret0, _ := ret[0].([]krakenFuturesSDK.OpenOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenOrders indicates an expected call of GetOpenOrders.
+func (mr *MockPortfolioMockRecorder) GetOpenOrders(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenOrders", reflect.TypeOf((*MockPortfolio)(nil).GetOpenOrders), userID)
+}
+
+// GetPositions mocks base method.
+func (m *MockPortfolio) GetPositions(userID int) ([]models.Position, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPositions", userID)
+	ret0, _ := ret[0].([]models.Position)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPositions indicates an expected call of GetPositions.
+func (mr *MockPortfolioMockRecorder) GetPositions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPositions", reflect.TypeOf((*MockPortfolio)(nil).GetPositions), userID)
+}
+
+// StartReconciler mocks base method.
+func (m *MockPortfolio) StartReconciler(interval time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartReconciler", interval)
+}
+
+// StartReconciler indicates an expected call of StartReconciler.
+func (mr *MockPortfolioMockRecorder) StartReconciler(interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartReconciler", reflect.TypeOf((*MockPortfolio)(nil).StartReconciler), interval)
+}
+
+// StopReconciler mocks base method.
+func (m *MockPortfolio) StopReconciler() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopReconciler")
+}
+
+// StopReconciler indicates an expected call of StopReconciler.
+func (mr *MockPortfolioMockRecorder) StopReconciler() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopReconciler", reflect.TypeOf((*MockPortfolio)(nil).StopReconciler))
+}
+
+// SyncPortfolio mocks base method.
+func (m *MockPortfolio) SyncPortfolio(userID int) (models.PortfolioSync, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncPortfolio", userID)
+	ret0, _ := ret[0].(models.PortfolioSync)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncPortfolio indicates an expected call of SyncPortfolio.
+func (mr *MockPortfolioMockRecorder) SyncPortfolio(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncPortfolio", reflect.TypeOf((*MockPortfolio)(nil).SyncPortfolio), userID)
+}
+
+// MockReports is a mock of Reports interface.
+type MockReports struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportsMockRecorder
+}
+
+// MockReportsMockRecorder is the mock recorder for MockReports.
+type MockReportsMockRecorder struct {
+	mock *MockReports
+}
+
+// NewMockReports creates a new mock instance.
+func NewMockReports(ctrl *gomock.Controller) *MockReports {
+	mock := &MockReports{ctrl: ctrl}
+	mock.recorder = &MockReportsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReports) EXPECT() *MockReportsMockRecorder {
+	return m.recorder
+}
+
+// GetPnLReport mocks base method.
+func (m *MockReports) GetPnLReport(userID int, from, to time.Time) (models.PnLReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPnLReport", userID, from, to)
+	ret0, _ := ret[0].(models.PnLReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPnLReport indicates an expected call of GetPnLReport.
+func (mr *MockReportsMockRecorder) GetPnLReport(userID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPnLReport", reflect.TypeOf((*MockReports)(nil).GetPnLReport), userID, from, to)
+}
+
+// MockMarket is a mock of Market interface.
+type MockMarket struct {
+	ctrl     *gomock.Controller
+	recorder *MockMarketMockRecorder
+}
+
+// MockMarketMockRecorder is the mock recorder for MockMarket.
+type MockMarketMockRecorder struct {
+	mock *MockMarket
+}
+
+// NewMockMarket creates a new mock instance.
+func NewMockMarket(ctrl *gomock.Controller) *MockMarket {
+	mock := &MockMarket{ctrl: ctrl}
+	mock.recorder = &MockMarketMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMarket) EXPECT() *MockMarketMockRecorder {
+	return m.recorder
+}
+
+// GetFeeSchedules mocks base method.
+func (m *MockMarket) GetFeeSchedules() ([]krakenFuturesSDK.FeeSchedules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeSchedules")
+	ret0, _ := ret[0].([]krakenFuturesSDK.FeeSchedules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeSchedules indicates an expected call of GetFeeSchedules.
+func (mr *MockMarketMockRecorder) GetFeeSchedules() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeSchedules", reflect.TypeOf((*MockMarket)(nil).GetFeeSchedules))
+}
+
+// GetInstruments mocks base method.
+func (m *MockMarket) GetInstruments() ([]krakenFuturesSDK.Instrument, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstruments")
+	ret0, _ := ret[0].([]krakenFuturesSDK.Instrument)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstruments indicates an expected call of GetInstruments.
+func (mr *MockMarketMockRecorder) GetInstruments() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstruments", reflect.TypeOf((*MockMarket)(nil).GetInstruments))
+}
+
+// GetOrderBook mocks base method.
+func (m *MockMarket) GetOrderBook(symbol string) (krakenFuturesSDK.OrderBook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderBook", symbol)
+	ret0, _ := ret[0].(krakenFuturesSDK.OrderBook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderBook indicates an expected call of GetOrderBook.
+func (mr *MockMarketMockRecorder) GetOrderBook(symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderBook", reflect.TypeOf((*MockMarket)(nil).GetOrderBook), symbol)
+}
+
+// GetTickers mocks base method.
+func (m *MockMarket) GetTickers() ([]krakenFuturesSDK.Ticker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTickers")
+	ret0, _ := ret[0].([]krakenFuturesSDK.Ticker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTickers indicates an expected call of GetTickers.
+func (mr *MockMarketMockRecorder) GetTickers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTickers", reflect.TypeOf((*MockMarket)(nil).GetTickers))
+}
+
+// MockCandles is a mock of Candles interface.
+type MockCandles struct {
+	ctrl     *gomock.Controller
+	recorder *MockCandlesMockRecorder
+}
+
+// MockCandlesMockRecorder is the mock recorder for MockCandles.
+type MockCandlesMockRecorder struct {
+	mock *MockCandles
+}
+
+// NewMockCandles creates a new mock instance.
+func NewMockCandles(ctrl *gomock.Controller) *MockCandles {
+	mock := &MockCandles{ctrl: ctrl}
+	mock.recorder = &MockCandlesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCandles) EXPECT() *MockCandlesMockRecorder {
+	return m.recorder
+}
+
+// GetCandles mocks base method.
+func (m *MockCandles) GetCandles(symbol, interval string, from, to time.Time) ([]models.Candle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCandles", symbol, interval, from, to)
+	ret0, _ := ret[0].([]models.Candle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCandles indicates an expected call of GetCandles.
+func (mr *MockCandlesMockRecorder) GetCandles(symbol, interval, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCandles", reflect.TypeOf((*MockCandles)(nil).GetCandles), symbol, interval, from, to)
+}
+
+// StartRecorder mocks base method.
+func (m *MockCandles) StartRecorder() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartRecorder")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartRecorder indicates an expected call of StartRecorder.
+func (mr *MockCandlesMockRecorder) StartRecorder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartRecorder", reflect.TypeOf((*MockCandles)(nil).StartRecorder))
+}
+
+// StopRecorder mocks base method.
+func (m *MockCandles) StopRecorder() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopRecorder")
+}
+
+// StopRecorder indicates an expected call of StopRecorder.
+func (mr *MockCandlesMockRecorder) StopRecorder() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopRecorder", reflect.TypeOf((*MockCandles)(nil).StopRecorder))
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockNotifier) Notify(event models.TradingEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Notify", event)
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockNotifierMockRecorder) Notify(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), event)
+}
+
+// MockNotifications is a mock of Notifications interface.
+type MockNotifications struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationsMockRecorder
+}
+
+// MockNotificationsMockRecorder is the mock recorder for MockNotifications.
+type MockNotificationsMockRecorder struct {
+	mock *MockNotifications
+}
+
+// NewMockNotifications creates a new mock instance.
+func NewMockNotifications(ctrl *gomock.Controller) *MockNotifications {
+	mock := &MockNotifications{ctrl: ctrl}
+	mock.recorder = &MockNotificationsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifications) EXPECT() *MockNotificationsMockRecorder {
+	return m.recorder
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockNotifications) GetNotificationPreferences(userID int) (models.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", userID)
+	ret0, _ := ret[0].(models.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockNotificationsMockRecorder) GetNotificationPreferences(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockNotifications)(nil).GetNotificationPreferences), userID)
+}
+
+// GetUserNotifications mocks base method.
+func (m *MockNotifications) GetUserNotifications(userID int) ([]models.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserNotifications", userID)
+	ret0, _ := ret[0].([]models.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserNotifications indicates an expected call of GetUserNotifications.
+func (mr *MockNotificationsMockRecorder) GetUserNotifications(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserNotifications", reflect.TypeOf((*MockNotifications)(nil).GetUserNotifications), userID)
+}
+
+// Notify mocks base method.
+func (m *MockNotifications) Notify(event models.TradingEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Notify", event)
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockNotificationsMockRecorder) Notify(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifications)(nil).Notify), event)
+}
+
+// StartDispatcher mocks base method.
+func (m *MockNotifications) StartDispatcher() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartDispatcher")
+}
+
+// StartDispatcher indicates an expected call of StartDispatcher.
+func (mr *MockNotificationsMockRecorder) StartDispatcher() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartDispatcher", reflect.TypeOf((*MockNotifications)(nil).StartDispatcher))
+}
+
+// StopDispatcher mocks base method.
+func (m *MockNotifications) StopDispatcher() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopDispatcher")
+}
+
+// StopDispatcher indicates an expected call of StopDispatcher.
+func (mr *MockNotificationsMockRecorder) StopDispatcher() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopDispatcher", reflect.TypeOf((*MockNotifications)(nil).StopDispatcher))
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MockNotifications) UpdateNotificationPreferences(userID int, preferences models.NotificationPreferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", userID, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockNotificationsMockRecorder) UpdateNotificationPreferences(userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockNotifications)(nil).UpdateNotificationPreferences), userID, preferences)
+}
+
+// MockSignals is a mock of Signals interface.
+type MockSignals struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignalsMockRecorder
+}
+
+// MockSignalsMockRecorder is the mock recorder for MockSignals.
+type MockSignalsMockRecorder struct {
+	mock *MockSignals
+}
+
+// NewMockSignals creates a new mock instance.
+func NewMockSignals(ctrl *gomock.Controller) *MockSignals {
+	mock := &MockSignals{ctrl: ctrl}
+	mock.recorder = &MockSignalsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSignals) EXPECT() *MockSignalsMockRecorder {
+	return m.recorder
+}
+
+// CreateSignalWebhook mocks base method.
+func (m *MockSignals) CreateSignalWebhook(userID int) (models.SignalWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSignalWebhook", userID)
+	ret0, _ := ret[0].(models.SignalWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSignalWebhook indicates an expected call of CreateSignalWebhook.
+func (mr *MockSignalsMockRecorder) CreateSignalWebhook(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSignalWebhook", reflect.TypeOf((*MockSignals)(nil).CreateSignalWebhook), userID)
+}
+
+// GetUserSignals mocks base method.
+func (m *MockSignals) GetUserSignals(userID int) ([]models.Signal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSignals", userID)
+	ret0, _ := ret[0].([]models.Signal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSignals indicates an expected call of GetUserSignals.
+func (mr *MockSignalsMockRecorder) GetUserSignals(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSignals", reflect.TypeOf((*MockSignals)(nil).GetUserSignals), userID)
+}
+
+// HandleSignal mocks base method.
+func (m *MockSignals) HandleSignal(token, signature string, body []byte) (models.Signal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleSignal", token, signature, body)
+	ret0, _ := ret[0].(models.Signal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// StartTrading indicates an expected call of StartTrading.
-func (mr *MockKrakenOrdersManagerMockRecorder) StartTrading(ctx, userID, details interface{}) *gomock.Call {
+// HandleSignal indicates an expected call of HandleSignal.
+func (mr *MockSignalsMockRecorder) HandleSignal(token, signature, body interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTrading", reflect.TypeOf((*MockKrakenOrdersManager)(nil).StartTrading), ctx, userID, details)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleSignal", reflect.TypeOf((*MockSignals)(nil).HandleSignal), token, signature, body)
 }
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/internal/pkg/web/webNotify"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrNotify                         = errors.New("notify")
+	ErrGetNotificationPreferences     = errors.New("get notification preferences")
+	ErrUpdateNotificationPreferences  = errors.New("update notification preferences")
+	ErrInvalidNotificationPreferences = errors.New("invalid notification preferences")
+	ErrGetUserNotifications           = errors.New("get user notifications")
+	ErrDispatchNotifications          = errors.New("dispatch notifications")
+	ErrEnqueueDailySummaries          = errors.New("enqueue daily summaries")
+	ErrUnknownNotificationChannel     = errors.New("unknown notification channel")
+)
+
+const (
+	defaultDispatchInterval   = 10 * time.Second
+	defaultNotifyMaxAttempts  = 5
+	defaultNotifyRetryDelay   = 30 * time.Second
+	notificationsBatchSize    = 100
+	userNotificationsLimit    = 100
+	notificationsLease        = 5 * time.Minute
+	notificationSendTimeout   = 30 * time.Second
+	dailySummaryKeyDateLayout = "2006-01-02"
+)
+
+// NotificationsService enqueues notifications of trading events into outbox, one for every channel of user
+// subscribed to event, and dispatcher of service sends them. Failed notification is retried with doubling delay
+// until it runs out of attempts. Dispatcher also enqueues daily summary of PnL of the previous day after
+// summary hour of day in UTC
+type NotificationsService struct {
+	repo             repository.Notifications
+	notifiers        map[string]web.Notifier
+	reports          Reports
+	dispatchInterval time.Duration
+	maxAttempts      int
+	retryDelay       time.Duration
+	summaryHour      int
+	webhookKey       string
+	now              func() time.Time
+
+	// summaryDay is the day which daily summaries are enqueued for already
+	summaryDay time.Time
+
+	mu   sync.Mutex
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func NewNotificationsService(repo repository.Notifications, notifiers map[string]web.Notifier, reports Reports,
+	cfg configs.NotificationsConfiguration) *NotificationsService {
+	dispatchInterval := time.Duration(cfg.DispatchIntervalInSeconds) * time.Second
+	if dispatchInterval <= 0 {
+		dispatchInterval = defaultDispatchInterval
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultNotifyMaxAttempts
+	}
+	retryDelay := time.Duration(cfg.RetryDelayInSeconds) * time.Second
+	if retryDelay <= 0 {
+		retryDelay = defaultNotifyRetryDelay
+	}
+
+	return &NotificationsService{
+		repo:             repo,
+		notifiers:        notifiers,
+		reports:          reports,
+		dispatchInterval: dispatchInterval,
+		maxAttempts:      maxAttempts,
+		retryDelay:       retryDelay,
+		summaryHour:      cfg.DailySummaryHour,
+		webhookKey:       cfg.WebhookSigningKey,
+		now:              time.Now,
+	}
+}
+
+// Notify enqueues event for every channel of user subscribed to it. Notifications are sent by dispatcher,
+// so caller is not blocked by channels, errors are logged only
+func (n *NotificationsService) Notify(event models.TradingEvent) {
+	preferences, err := n.repo.GetNotificationPreferences(event.UserID)
+	if err != nil {
+		log.Errorf("%s: user %d: %s: %s", ErrNotify, event.UserID, event.Event, err)
+		return
+	}
+
+	if err := n.enqueue(event, preferences); err != nil {
+		log.Errorf("%s: user %d: %s: %s", ErrNotify, event.UserID, event.Event, err)
+	}
+}
+
+func (n *NotificationsService) enqueue(event models.TradingEvent, preferences models.NotificationPreferences) error {
+	if !preferences.Subscribed(event.Event) {
+		return nil
+	}
+
+	addresses := preferences.Addresses()
+	channels := make([]string, 0, len(addresses))
+	for channel := range addresses {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	now := n.now().UTC()
+	notifications := make([]models.Notification, 0, len(channels))
+	for _, channel := range channels {
+		notifications = append(notifications, models.Notification{
+			UserID:        event.UserID,
+			Event:         event.Event,
+			Channel:       channel,
+			Address:       addresses[channel],
+			DedupKey:      event.Key,
+			Subject:       event.Subject,
+			Body:          event.Body,
+			Status:        models.NotificationPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return n.repo.CreateNotifications(notifications)
+}
+
+func (n *NotificationsService) GetNotificationPreferences(userID int) (models.NotificationPreferences, error) {
+	preferences, err := n.repo.GetNotificationPreferences(userID)
+	if err != nil {
+		return models.NotificationPreferences{}, fmt.Errorf("%s: %w", ErrGetNotificationPreferences, err)
+	}
+	if preferences.WebhookURL != "" {
+		preferences.WebhookSecret = webNotify.WebhookSecret(n.webhookKey, userID)
+	}
+	return preferences, nil
+}
+
+// UpdateNotificationPreferences replaces preferences of user, events must be known ones
+func (n *NotificationsService) UpdateNotificationPreferences(userID int, preferences models.NotificationPreferences) error {
+	for _, event := range preferences.Events {
+		if !isNotificationEvent(event) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidNotificationPreferences, event)
+		}
+	}
+
+	if err := n.repo.UpdateNotificationPreferences(userID, preferences); err != nil {
+		return fmt.Errorf("%s: %w", ErrUpdateNotificationPreferences, err)
+	}
+	return nil
+}
+
+func isNotificationEvent(event string) bool {
+	for _, known := range models.NotificationEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserNotifications returns the last notifications of user, the newest first
+func (n *NotificationsService) GetUserNotifications(userID int) ([]models.Notification, error) {
+	notifications, err := n.repo.GetUserNotifications(userID, userNotificationsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserNotifications, err)
+	}
+	return notifications, nil
+}
+
+// StartDispatcher sends due notifications every dispatch interval until StopDispatcher is called
+func (n *NotificationsService) StartDispatcher() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.stop != nil {
+		return
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	n.stop = stop
+	n.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+
+		ticker := time.NewTicker(n.dispatchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n.dispatch(ctx)
+			}
+		}
+	}(n.done)
+}
+
+// StopDispatcher stops dispatcher and waits for the notifications being sent
+func (n *NotificationsService) StopDispatcher() {
+	n.mu.Lock()
+	stop, done := n.stop, n.done
+	n.stop, n.done = nil, nil
+	n.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	stop()
+	<-done
+}
+
+// dispatch enqueues daily summaries when they are due and sends due notifications batch by batch.
+// Notifications are leased, so replicas of server don't send them twice
+func (n *NotificationsService) dispatch(ctx context.Context) {
+	n.enqueueDailySummaries()
+
+	for ctx.Err() == nil {
+		now := n.now().UTC()
+		notifications, err := n.repo.LeaseNotifications(now, now.Add(notificationsLease), notificationsBatchSize)
+		if err != nil {
+			log.Errorf("%s: %s", ErrDispatchNotifications, err)
+			return
+		}
+
+		for _, notification := range notifications {
+			n.send(ctx, notification)
+		}
+		if len(notifications) < notificationsBatchSize {
+			return
+		}
+	}
+}
+
+// send sends notification by its channel and saves result of attempt
+func (n *NotificationsService) send(ctx context.Context, notification models.Notification) {
+	err := ErrUnknownNotificationChannel
+	if notifier, ok := n.notifiers[notification.Channel]; ok {
+		sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+		err = notifier.Send(sendCtx, notification)
+		cancel()
+	}
+
+	now := n.now().UTC()
+	notification.Attempts++
+	switch {
+	case err == nil:
+		notification.Status = models.NotificationSent
+		notification.LastError = ""
+		notification.SentAt = &now
+	case notification.Attempts >= n.maxAttempts:
+		notification.Status = models.NotificationFailed
+		notification.LastError = err.Error()
+	default:
+		notification.LastError = err.Error()
+		notification.NextAttemptAt = now.Add(n.retryDelay << (notification.Attempts - 1))
+	}
+
+	if err != nil {
+		log.Warnf("%s: notification %d: attempt %d: %s", ErrDispatchNotifications, notification.ID,
+			notification.Attempts, err)
+	}
+	if err := n.repo.UpdateNotification(notification); err != nil {
+		log.Errorf("%s: %s", ErrDispatchNotifications, err)
+	}
+}
+
+// enqueueDailySummaries enqueues PnL report of the previous day for every user subscribed to daily summary
+// once a day after summary hour. Summary of day has the same key, so restarted server does not send it twice
+func (n *NotificationsService) enqueueDailySummaries() {
+	now := n.now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	if now.Hour() < n.summaryHour || n.summaryDay.Equal(today) {
+		return
+	}
+
+	preferences, err := n.repo.GetAllNotificationPreferences()
+	if err != nil {
+		log.Errorf("%s: %s", ErrEnqueueDailySummaries, err)
+		return
+	}
+
+	from := today.AddDate(0, 0, -1)
+	userIDs := make([]int, 0, len(preferences))
+	for userID := range preferences {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Ints(userIDs)
+
+	failed := false
+	for _, userID := range userIDs {
+		userPreferences := preferences[userID]
+		if !userPreferences.Subscribed(models.EventDailySummary) || len(userPreferences.Addresses()) == 0 {
+			continue
+		}
+
+		// days of report are inclusive, so the previous day is both the first and the last one
+		report, err := n.reports.GetPnLReport(userID, from, from)
+		if err != nil {
+			log.Errorf("%s: user %d: %s", ErrEnqueueDailySummaries, userID, err)
+			failed = true
+			continue
+		}
+
+		if err := n.enqueue(dailySummaryEvent(userID, from, report), userPreferences); err != nil {
+			log.Errorf("%s: user %d: %s", ErrEnqueueDailySummaries, userID, err)
+			failed = true
+		}
+	}
+
+	// summaries are enqueued again on the next dispatch, enqueued ones are skipped by their keys
+	if !failed {
+		n.summaryDay = today
+	}
+}
+
+func dailySummaryEvent(userID int, day time.Time, report models.PnLReport) models.TradingEvent {
+	date := day.Format(dailySummaryKeyDateLayout)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Realized PnL: %.2f\n", report.RealizedPnL)
+	fmt.Fprintf(&body, "Unrealized PnL: %.2f\n", report.UnrealizedPnL)
+	fmt.Fprintf(&body, "Fees: %.2f", report.Fees)
+	for _, row := range report.Rows {
+		fmt.Fprintf(&body, "\n%s %s: realized %.2f, unrealized %.2f, fees %.2f", row.Symbol, row.Strategy,
+			row.RealizedPnL, row.UnrealizedPnL, row.Fees)
+	}
+
+	return models.TradingEvent{
+		UserID:  userID,
+		Event:   models.EventDailySummary,
+		Key:     models.EventDailySummary + ":" + date,
+		Subject: "Daily summary " + date,
+		Body:    body.String(),
+	}
+}
+
+func orderFilledEvent(order models.Order) models.TradingEvent {
+	return models.TradingEvent{
+		UserID:  order.UserID,
+		Event:   models.EventOrderFilled,
+		Key:     models.EventOrderFilled + ":" + order.ID,
+		Subject: fmt.Sprintf("Order filled: %s %s", order.Side, order.Symbol),
+		Body: fmt.Sprintf("Order %s %s %g %s is filled at %g", order.ID, order.Side, order.Quantity, order.Symbol,
+			order.Price),
+	}
+}
+
+func orderRejectedEvent(userID int, args krakenFuturesSDK.SendOrderArguments, err error) models.TradingEvent {
+	return models.TradingEvent{
+		UserID:  userID,
+		Event:   models.EventOrderRejected,
+		Subject: fmt.Sprintf("Order rejected: %s %s", args.Side, args.Symbol),
+		Body:    fmt.Sprintf("Order %s %s %d %s is rejected: %s", args.OrderType, args.Side, args.Size, args.Symbol, err),
+	}
+}
+
+var sessionEventSubjects = map[string]string{
+	models.EventSessionClosed: "Trading session closed",
+	models.EventSessionError:  "Trading session error",
+	models.EventStopLossHit:   "Stop loss hit",
+	models.EventTakeProfitHit: "Take profit hit",
+}
+
+// sessionEvent is an event of trading session, session is notified once about event in every status
+func sessionEvent(session models.TradingSession, event string) models.TradingEvent {
+	details := session.Details
+	var body strings.Builder
+	fmt.Fprintf(&body, "Session %d %s %s %d by %s is %s", session.ID, details.Side, details.Symbol, details.Size,
+		details.Strategy, session.Status)
+	if session.EntryPrice != 0 {
+		fmt.Fprintf(&body, "\nEntry price: %g", session.EntryPrice)
+	}
+	if session.ExitPrice != 0 {
+		fmt.Fprintf(&body, "\nExit price: %g", session.ExitPrice)
+	}
+	if session.Error != "" {
+		fmt.Fprintf(&body, "\nError: %s", session.Error)
+	}
+
+	return models.TradingEvent{
+		UserID:  session.UserID,
+		Event:   event,
+		Key:     fmt.Sprintf("%s:%d:%s", event, session.ID, session.Status),
+		Subject: fmt.Sprintf("%s: %s %s", sessionEventSubjects[event], details.Side, details.Symbol),
+		Body:    body.String(),
+	}
+}
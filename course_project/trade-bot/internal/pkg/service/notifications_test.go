@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/internal/pkg/web/webNotify"
+)
+
+// notifyRecorder records notified events
+type notifyRecorder struct {
+	mu     sync.Mutex
+	events []models.TradingEvent
+}
+
+func (r *notifyRecorder) Notify(event models.TradingEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// keys returns keys of notified events
+func (r *notifyRecorder) keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var keys []string
+	for _, event := range r.events {
+		keys = append(keys, event.Key)
+	}
+	return keys
+}
+
+// notificationsRepo keeps outbox in memory, notifications with the same dedup key are skipped like in postgres
+type notificationsRepo struct {
+	preferences   map[int]models.NotificationPreferences
+	notifications []models.Notification
+}
+
+func (r *notificationsRepo) GetNotificationPreferences(userID int) (models.NotificationPreferences, error) {
+	return r.preferences[userID], nil
+}
+
+func (r *notificationsRepo) GetAllNotificationPreferences() (map[int]models.NotificationPreferences, error) {
+	return r.preferences, nil
+}
+
+func (r *notificationsRepo) UpdateNotificationPreferences(userID int, preferences models.NotificationPreferences) error {
+	r.preferences[userID] = preferences
+	return nil
+}
+
+func (r *notificationsRepo) CreateNotifications(notifications []models.Notification) error {
+	for _, notification := range notifications {
+		duplicate := false
+		for _, saved := range r.notifications {
+			duplicate = duplicate || notification.DedupKey != "" && saved.DedupKey == notification.DedupKey &&
+				saved.UserID == notification.UserID && saved.Channel == notification.Channel
+		}
+		if !duplicate {
+			notification.ID = len(r.notifications) + 1
+			r.notifications = append(r.notifications, notification)
+		}
+	}
+	return nil
+}
+
+func (r *notificationsRepo) LeaseNotifications(now, leaseUntil time.Time, limit int) ([]models.Notification, error) {
+	var leased []models.Notification
+	for i, notification := range r.notifications {
+		if notification.Status == models.NotificationPending && !notification.NextAttemptAt.After(now) && len(leased) < limit {
+			r.notifications[i].NextAttemptAt = leaseUntil
+			leased = append(leased, r.notifications[i])
+		}
+	}
+	return leased, nil
+}
+
+func (r *notificationsRepo) UpdateNotification(notification models.Notification) error {
+	r.notifications[notification.ID-1] = notification
+	return nil
+}
+
+func (r *notificationsRepo) GetUserNotifications(userID, limit int) ([]models.Notification, error) {
+	return r.notifications, nil
+}
+
+// channelNotifier records addresses of sent notifications and fails with err when it is set
+type channelNotifier struct {
+	sent []string
+	err  error
+}
+
+func (n *channelNotifier) Send(ctx context.Context, notification models.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification.Address)
+	return nil
+}
+
+// summaryReports returns the same report to every user and records requested periods
+type summaryReports struct {
+	report  models.PnLReport
+	periods []string
+}
+
+func (r *summaryReports) GetPnLReport(userID int, from, to time.Time) (models.PnLReport, error) {
+	r.periods = append(r.periods, from.Format(time.RFC3339)+" "+to.Format(time.RFC3339))
+	return r.report, nil
+}
+
+func TestNotificationsService_Notify(t *testing.T) {
+	now := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	repo := &notificationsRepo{preferences: map[int]models.NotificationPreferences{
+		1: {TelegramChatID: 42, Email: "user@mail.com"},
+		2: {WebhookURL: "http://hook", Events: []string{models.EventSessionError}},
+	}}
+	n := NewNotificationsService(repo, nil, nil, configs.NotificationsConfiguration{})
+	n.now = func() time.Time { return now }
+
+	filled := models.TradingEvent{UserID: 1, Event: models.EventOrderFilled, Key: "order_filled:1", Subject: "Order filled",
+		Body: "buy 1 PI_XBTUSD"}
+	n.Notify(filled)
+	n.Notify(filled)
+	n.Notify(models.TradingEvent{UserID: 2, Event: models.EventOrderFilled, Key: "order_filled:2"})
+	n.Notify(models.TradingEvent{UserID: 2, Event: models.EventSessionError, Key: "session_error:1:failed"})
+	n.Notify(models.TradingEvent{UserID: 3, Event: models.EventSessionError})
+
+	notification := models.Notification{UserID: 1, Event: models.EventOrderFilled, DedupKey: "order_filled:1",
+		Subject: "Order filled", Body: "buy 1 PI_XBTUSD", Status: models.NotificationPending, NextAttemptAt: now, CreatedAt: now}
+	email, telegram := notification, notification
+	email.ID, email.Channel, email.Address = 1, models.ChannelEmail, "user@mail.com"
+	telegram.ID, telegram.Channel, telegram.Address = 2, models.ChannelTelegram, "42"
+	webhook := models.Notification{ID: 3, UserID: 2, Event: models.EventSessionError, Channel: models.ChannelWebhook,
+		Address: "http://hook", DedupKey: "session_error:1:failed", Status: models.NotificationPending,
+		NextAttemptAt: now, CreatedAt: now}
+
+	assert.Equal(t, []models.Notification{email, telegram, webhook}, repo.notifications)
+}
+
+func TestNotificationsService_dispatch(t *testing.T) {
+	now := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	sendErr := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		attempts      int
+		err           error
+		wantStatus    string
+		wantAttempts  int
+		wantLastError string
+		wantNext      time.Time
+		wantSent      []string
+	}{
+		{
+			name:         "Sent",
+			wantStatus:   models.NotificationSent,
+			wantAttempts: 1,
+			wantNext:     now,
+			wantSent:     []string{"42"},
+		},
+		{
+			name:          "Retried with doubling delay",
+			attempts:      2,
+			err:           sendErr,
+			wantStatus:    models.NotificationPending,
+			wantAttempts:  3,
+			wantLastError: "connection refused",
+			wantNext:      now.Add(4 * time.Minute),
+		},
+		{
+			name:          "Out of attempts",
+			attempts:      4,
+			err:           sendErr,
+			wantStatus:    models.NotificationFailed,
+			wantAttempts:  5,
+			wantLastError: "connection refused",
+			wantNext:      now.Add(notificationsLease),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &notificationsRepo{notifications: []models.Notification{
+				{ID: 1, UserID: 1, Channel: models.ChannelTelegram, Address: "42", Status: models.NotificationPending,
+					Attempts: test.attempts, NextAttemptAt: now},
+				{ID: 2, UserID: 1, Channel: models.ChannelTelegram, Address: "43", Status: models.NotificationPending,
+					NextAttemptAt: now.Add(time.Hour)},
+			}}
+			telegram := &channelNotifier{err: test.err}
+			n := NewNotificationsService(repo, map[string]web.Notifier{models.ChannelTelegram: telegram}, nil,
+				configs.NotificationsConfiguration{RetryDelayInSeconds: 60, DailySummaryHour: 24})
+			n.now = func() time.Time { return now }
+
+			n.dispatch(context.Background())
+
+			got := repo.notifications[0]
+			assert.Equal(t, test.wantStatus, got.Status)
+			assert.Equal(t, test.wantAttempts, got.Attempts)
+			assert.Equal(t, test.wantLastError, got.LastError)
+			assert.Equal(t, test.wantSent, telegram.sent)
+			if test.wantStatus == models.NotificationSent {
+				assert.Equal(t, &now, got.SentAt)
+			} else {
+				assert.Equal(t, test.wantNext, got.NextAttemptAt)
+				assert.Nil(t, got.SentAt)
+			}
+
+			// notification which is not due is left
+			assert.Equal(t, 0, repo.notifications[1].Attempts)
+		})
+	}
+}
+
+func TestNotificationsService_dispatch_UnknownChannel(t *testing.T) {
+	now := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	repo := &notificationsRepo{notifications: []models.Notification{
+		{ID: 1, UserID: 1, Channel: "pigeon", Status: models.NotificationPending, NextAttemptAt: now},
+	}}
+	n := NewNotificationsService(repo, nil, nil, configs.NotificationsConfiguration{MaxAttempts: 1, DailySummaryHour: 24})
+	n.now = func() time.Time { return now }
+
+	n.dispatch(context.Background())
+
+	assert.Equal(t, models.NotificationFailed, repo.notifications[0].Status)
+	assert.Equal(t, ErrUnknownNotificationChannel.Error(), repo.notifications[0].LastError)
+}
+
+func TestNotificationsService_enqueueDailySummaries(t *testing.T) {
+	repo := &notificationsRepo{preferences: map[int]models.NotificationPreferences{
+		1: {TelegramChatID: 42},
+		2: {Email: "user@mail.com", Events: []string{models.EventOrderFilled}},
+		3: {},
+	}}
+	reports := &summaryReports{report: models.PnLReport{RealizedPnL: 12.5, UnrealizedPnL: -2, Fees: 0.5,
+		Rows: []models.PnLRow{{Symbol: "PI_XBTUSD", Strategy: "manual", RealizedPnL: 12.5, UnrealizedPnL: -2, Fees: 0.5}}}}
+	n := NewNotificationsService(repo, nil, reports, configs.NotificationsConfiguration{DailySummaryHour: 8})
+
+	now := time.Date(2021, 12, 2, 7, 59, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+
+	// before summary hour
+	n.enqueueDailySummaries()
+	assert.Empty(t, repo.notifications)
+
+	now = now.Add(time.Minute)
+	n.enqueueDailySummaries()
+	n.enqueueDailySummaries()
+
+	// restarted service does not enqueue the same summary again
+	restarted := NewNotificationsService(repo, nil, reports, configs.NotificationsConfiguration{DailySummaryHour: 8})
+	restarted.now = n.now
+	restarted.enqueueDailySummaries()
+
+	assert.Equal(t, []string{"2021-12-01T00:00:00Z 2021-12-01T00:00:00Z", "2021-12-01T00:00:00Z 2021-12-01T00:00:00Z"},
+		reports.periods)
+	if assert.Len(t, repo.notifications, 1) {
+		got := repo.notifications[0]
+		assert.Equal(t, 1, got.UserID)
+		assert.Equal(t, models.ChannelTelegram, got.Channel)
+		assert.Equal(t, "daily_summary:2021-12-01", got.DedupKey)
+		assert.Equal(t, "Daily summary 2021-12-01", got.Subject)
+		assert.Equal(t, "Realized PnL: 12.50\nUnrealized PnL: -2.00\nFees: 0.50\n"+
+			"PI_XBTUSD manual: realized 12.50, unrealized -2.00, fees 0.50", got.Body)
+	}
+}
+
+func TestNotificationsService_UpdateNotificationPreferences(t *testing.T) {
+	repo := &notificationsRepo{preferences: map[int]models.NotificationPreferences{}}
+	n := NewNotificationsService(repo, nil, nil, configs.NotificationsConfiguration{})
+
+	err := n.UpdateNotificationPreferences(1, models.NotificationPreferences{Events: []string{"order_filled", "moon"}})
+	assert.ErrorIs(t, err, ErrInvalidNotificationPreferences)
+	assert.Empty(t, repo.preferences)
+
+	preferences := models.NotificationPreferences{TelegramChatID: 42, Events: append([]string(nil), models.NotificationEvents...)}
+	assert.NoError(t, n.UpdateNotificationPreferences(1, preferences))
+
+	got, err := n.GetNotificationPreferences(1)
+	assert.NoError(t, err)
+	sort.Strings(got.Events)
+	sort.Strings(preferences.Events)
+	assert.Equal(t, preferences, got)
+}
+
+func TestNotificationsService_GetNotificationPreferences_WebhookSecret(t *testing.T) {
+	repo := &notificationsRepo{preferences: map[int]models.NotificationPreferences{
+		1: {WebhookURL: "https://hooks.example.com/trade-bot"},
+		2: {TelegramChatID: 42},
+	}}
+	n := NewNotificationsService(repo, nil, nil, configs.NotificationsConfiguration{WebhookSigningKey: "key"})
+
+	got, err := n.GetNotificationPreferences(1)
+	assert.NoError(t, err)
+	assert.Equal(t, webNotify.WebhookSecret("key", 1), got.WebhookSecret)
+	assert.NotEmpty(t, got.WebhookSecret)
+
+	// secret is not returned without webhook
+	got, err = n.GetNotificationPreferences(2)
+	assert.NoError(t, err)
+	assert.Empty(t, got.WebhookSecret)
+}
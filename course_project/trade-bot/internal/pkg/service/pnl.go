@@ -0,0 +1,285 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var ErrGetPnLReport = errors.New("get pnl report")
+
+const (
+	pnlDayLayout = "2006-01-02"
+
+	makerFillType = "maker"
+	// feePercents converts fees of fee schedule from percents to fractions of notional
+	feePercents = 100
+	// sizeEpsilon is the largest size which is left from lot by float rounding
+	sizeEpsilon = 1e-9
+)
+
+// pnlLot is a part of open position opened by one fill, entry fee of lot is realized when it is closed
+type pnlLot struct {
+	long     bool
+	size     float64
+	price    float64
+	fee      float64
+	day      string
+	strategy string
+}
+
+type pnlKey struct {
+	day, symbol, strategy string
+}
+
+// PnLService reports PnL of fills of user saved by portfolio sync. Fills of every symbol are matched FIFO,
+// so exit fill closes the oldest lots first, and lots left open are marked to mark price of ticker.
+// Kraken does not report fees of fills, they are estimated by maker and taker fees of fee schedule.
+// Strategy of fill is a strategy of trading session which sent its order
+type PnLService struct {
+	portfolioRepo repository.Portfolio
+	sessionsRepo  repository.TradingSessions
+	market        Market
+}
+
+func NewPnLService(portfolioRepo repository.Portfolio, sessionsRepo repository.TradingSessions, market Market) *PnLService {
+	return &PnLService{portfolioRepo: portfolioRepo, sessionsRepo: sessionsRepo, market: market}
+}
+
+// GetPnLReport returns rows of days between from and to inclusive, zero time does not limit days
+func (p *PnLService) GetPnLReport(userID int, from, to time.Time) (models.PnLReport, error) {
+	fills, err := p.portfolioRepo.GetFills(userID)
+	if err != nil {
+		return models.PnLReport{}, fmt.Errorf("%s: %w", ErrGetPnLReport, err)
+	}
+	strategies, err := p.orderStrategies(userID)
+	if err != nil {
+		return models.PnLReport{}, fmt.Errorf("%s: %w", ErrGetPnLReport, err)
+	}
+	instruments, err := p.market.GetInstruments()
+	if err != nil {
+		return models.PnLReport{}, fmt.Errorf("%s: %w", ErrGetPnLReport, err)
+	}
+	schedules, err := p.market.GetFeeSchedules()
+	if err != nil {
+		return models.PnLReport{}, fmt.Errorf("%s: %w", ErrGetPnLReport, err)
+	}
+	makerFee, takerFee := feeRates(schedules)
+
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].FillTime.Before(fills[j].FillTime)
+	})
+
+	rows := make(map[pnlKey]*models.PnLRow)
+	row := func(day, symbol, strategy string) *models.PnLRow {
+		key := pnlKey{day: day, symbol: symbol, strategy: strategy}
+		r, ok := rows[key]
+		if !ok {
+			r = &models.PnLRow{Day: day, Symbol: symbol, Strategy: strategy}
+			rows[key] = r
+		}
+		return r
+	}
+
+	lots := make(map[string][]*pnlLot)
+	for _, fill := range fills {
+		symbol := strings.ToUpper(fill.Symbol)
+		instrument := instrumentOrDefault(instruments, symbol)
+
+		rate := takerFee
+		if fill.FillType == makerFillType {
+			rate = makerFee
+		}
+		fee := notionalValue(instrument, fill.Size, fill.Price) * rate
+		long := fill.Side == krakenFuturesSDK.BuySide
+		day := fill.FillTime.UTC().Format(pnlDayLayout)
+
+		size := fill.Size
+		for size > sizeEpsilon && len(lots[symbol]) > 0 && lots[symbol][0].long != long {
+			lot := lots[symbol][0]
+			matched := math.Min(size, lot.size)
+			entryFee := lot.fee * matched / lot.size
+			exitFee := fee * matched / fill.Size
+
+			r := row(day, symbol, lot.strategy)
+			r.RealizedPnL += positionPnL(instrument, lot.long, matched, lot.price, fill.Price) - entryFee - exitFee
+			r.Fees += entryFee + exitFee
+			r.ClosedSize += matched
+
+			lot.size -= matched
+			lot.fee -= entryFee
+			size -= matched
+			if lot.size <= sizeEpsilon {
+				lots[symbol] = lots[symbol][1:]
+			}
+		}
+
+		if size > sizeEpsilon {
+			strategy, ok := strategies[fill.OrderID]
+			if !ok {
+				strategy = models.ManualStrategy
+			}
+			lots[symbol] = append(lots[symbol], &pnlLot{
+				long:     long,
+				size:     size,
+				price:    fill.Price,
+				fee:      fee * size / fill.Size,
+				day:      day,
+				strategy: strategy,
+			})
+		}
+	}
+
+	if err := p.markLots(lots, instruments, row); err != nil {
+		return models.PnLReport{}, fmt.Errorf("%s: %w", ErrGetPnLReport, err)
+	}
+
+	return newPnLReport(rows, from, to), nil
+}
+
+// markLots adds unrealized PnL of open lots at mark price to rows of days when lots were opened
+func (p *PnLService) markLots(lots map[string][]*pnlLot, instruments []krakenFuturesSDK.Instrument,
+	row func(day, symbol, strategy string) *models.PnLRow) error {
+	var tickers []krakenFuturesSDK.Ticker
+	for symbol, symbolLots := range lots {
+		if len(symbolLots) == 0 {
+			continue
+		}
+		if tickers == nil {
+			var err error
+			if tickers, err = p.market.GetTickers(); err != nil {
+				return err
+			}
+		}
+
+		markPrice, ok := tickerPrice(tickers, symbol)
+		if !ok {
+			return fmt.Errorf("%s: %s", ErrNoPrice, symbol)
+		}
+
+		instrument := instrumentOrDefault(instruments, symbol)
+		for _, lot := range symbolLots {
+			r := row(lot.day, symbol, lot.strategy)
+			r.UnrealizedPnL += positionPnL(instrument, lot.long, lot.size, lot.price, markPrice) - lot.fee
+			r.Fees += lot.fee
+			r.OpenSize += lot.size
+		}
+	}
+	return nil
+}
+
+// orderStrategies returns strategies of trading sessions by ids of their orders
+func (p *PnLService) orderStrategies(userID int) (map[string]string, error) {
+	sessions, err := p.sessionsRepo.GetUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	strategies := make(map[string]string)
+	for _, session := range sessions {
+		for _, orderID := range []string{session.EntryOrderID, session.StopLossOrderID, session.TakeProfitOrderID, session.ExitOrderID} {
+			if orderID != "" {
+				strategies[orderID] = session.Details.Strategy
+			}
+		}
+	}
+	return strategies, nil
+}
+
+func newPnLReport(rows map[pnlKey]*models.PnLRow, from, to time.Time) models.PnLReport {
+	var fromDay, toDay string
+	if !from.IsZero() {
+		fromDay = from.UTC().Format(pnlDayLayout)
+	}
+	if !to.IsZero() {
+		toDay = to.UTC().Format(pnlDayLayout)
+	}
+
+	report := models.PnLReport{Rows: make([]models.PnLRow, 0, len(rows))}
+	for _, r := range rows {
+		if (fromDay != "" && r.Day < fromDay) || (toDay != "" && r.Day > toDay) {
+			continue
+		}
+		report.Rows = append(report.Rows, *r)
+		report.RealizedPnL += r.RealizedPnL
+		report.UnrealizedPnL += r.UnrealizedPnL
+		report.Fees += r.Fees
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Strategy < b.Strategy
+	})
+	return report
+}
+
+// positionPnL returns PnL in USD of size contracts of position opened at entry price and closed at exit price.
+// PnL of inverse contracts is in base currency, it is valued at exit price
+func positionPnL(instrument krakenFuturesSDK.Instrument, long bool, size, entryPrice, exitPrice float64) float64 {
+	pnl := float64(instrument.ContractSize) * size * (exitPrice - entryPrice)
+	if instrument.Type == inverseInstrumentType {
+		pnl /= entryPrice
+	}
+	if !long {
+		pnl = -pnl
+	}
+	return pnl
+}
+
+// feeRates returns maker and taker fees of the lowest volume tier of fee schedule as fractions of notional
+func feeRates(schedules []krakenFuturesSDK.FeeSchedules) (float64, float64) {
+	for _, schedule := range schedules {
+		if len(schedule.Tiers) == 0 {
+			continue
+		}
+
+		lowest := schedule.Tiers[0]
+		for _, tier := range schedule.Tiers {
+			if tier.UsdVolume < lowest.UsdVolume {
+				lowest = tier
+			}
+		}
+		return lowest.MakerFee / feePercents, lowest.TakerFee / feePercents
+	}
+	return 0, 0
+}
+
+// instrumentOrDefault returns instrument of symbol or linear contract of size 1 when it is not listed any more
+func instrumentOrDefault(instruments []krakenFuturesSDK.Instrument, symbol string) krakenFuturesSDK.Instrument {
+	instrument, ok := findInstrument(instruments, symbol)
+	if !ok || instrument.ContractSize == 0 {
+		instrument.Symbol = symbol
+		instrument.ContractSize = 1
+	}
+	return instrument
+}
+
+// tickerPrice returns mark price of symbol or its last price when mark price is not set
+func tickerPrice(tickers []krakenFuturesSDK.Ticker, symbol string) (float64, bool) {
+	for _, ticker := range tickers {
+		if !strings.EqualFold(ticker.Symbol, symbol) {
+			continue
+		}
+		if ticker.MarkPrice != 0 {
+			return ticker.MarkPrice, true
+		}
+		if ticker.Last != 0 {
+			return ticker.Last, true
+		}
+	}
+	return 0, false
+}
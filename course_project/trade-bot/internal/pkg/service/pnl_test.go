@@ -0,0 +1,129 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+// pnlMarket serves linear contract of size 1 with maker fee 0.02% and taker fee 0.05%
+type pnlMarket struct {
+	marketDataCounter
+	markPrice float64
+}
+
+func (m *pnlMarket) Tickers() ([]krakenFuturesSDK.Ticker, error) {
+	return []krakenFuturesSDK.Ticker{{Symbol: "PF_ETHUSD", MarkPrice: m.markPrice}}, nil
+}
+
+func (m *pnlMarket) Instruments() ([]krakenFuturesSDK.Instrument, error) {
+	return []krakenFuturesSDK.Instrument{{Symbol: "PF_ETHUSD", Type: "flexible_futures", Tradeable: true, ContractSize: 1}}, nil
+}
+
+func (m *pnlMarket) FeeSchedules() ([]krakenFuturesSDK.FeeSchedules, error) {
+	return []krakenFuturesSDK.FeeSchedules{{Tiers: []krakenFuturesSDK.Tier{
+		{MakerFee: 0.01, TakerFee: 0.03, UsdVolume: 1000000},
+		{MakerFee: 0.02, TakerFee: 0.05, UsdVolume: 0},
+	}}}, nil
+}
+
+func TestPnLService_GetPnLReport(t *testing.T) {
+	day1 := time.Date(2022, 1, 10, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	repo := &portfolioRepo{fills: map[string]models.Fill{
+		"f1": {ID: "f1", OrderID: "entry", Symbol: "pf_ethusd", Side: "buy", Size: 2, Price: 1000, FillType: "taker", FillTime: day1},
+		"f2": {ID: "f2", OrderID: "manual", Symbol: "PF_ETHUSD", Side: "buy", Size: 2, Price: 1100, FillType: "maker", FillTime: day1.Add(time.Hour)},
+		"f3": {ID: "f3", OrderID: "exit", Symbol: "PF_ETHUSD", Side: "sell", Size: 3, Price: 1200, FillType: "taker", FillTime: day2},
+	}}
+	sessions := newSessionsRepo(models.TradingSession{
+		ID: 1, UserID: 1, Status: models.SessionClosed, EntryOrderID: "entry", ExitOrderID: "exit",
+		Details: types.TradingDetails{Strategy: "sma_crossover"},
+	})
+	market := NewMarketService(&pnlMarket{markPrice: 1150}, &memoryCache{values: make(map[string][]byte)})
+	p := NewPnLService(repo, sessions, market)
+
+	// entry lot: 2 @ 1000 with fee 1, closed @ 1200 with 2/3 of exit fee 1.8 of 3 * 1200 * 0.05%
+	sessionRealized := 2*200 - 1 - 1.2
+	// manual lot: 1 of 2 @ 1100 with fee 0.44 closed @ 1200, the other one is marked to 1150
+	manualRealized := 100 - 0.22 - 0.6
+	manualUnrealized := 50 - 0.22
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []models.PnLRow
+	}{
+		{
+			name: "All days",
+			want: []models.PnLRow{
+				{Day: "2022-01-10", Symbol: "PF_ETHUSD", Strategy: "manual", UnrealizedPnL: manualUnrealized, Fees: 0.22, OpenSize: 1},
+				{Day: "2022-01-11", Symbol: "PF_ETHUSD", Strategy: "manual", RealizedPnL: manualRealized, Fees: 0.22 + 0.6, ClosedSize: 1},
+				{Day: "2022-01-11", Symbol: "PF_ETHUSD", Strategy: "sma_crossover", RealizedPnL: sessionRealized, Fees: 1 + 1.2, ClosedSize: 2},
+			},
+		},
+		{
+			name: "From the second day",
+			from: day2,
+			want: []models.PnLRow{
+				{Day: "2022-01-11", Symbol: "PF_ETHUSD", Strategy: "manual", RealizedPnL: manualRealized, Fees: 0.22 + 0.6, ClosedSize: 1},
+				{Day: "2022-01-11", Symbol: "PF_ETHUSD", Strategy: "sma_crossover", RealizedPnL: sessionRealized, Fees: 1 + 1.2, ClosedSize: 2},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := p.GetPnLReport(1, test.from, test.to)
+			assert.NoError(t, err)
+			if !assert.Len(t, report.Rows, len(test.want)) {
+				return
+			}
+
+			var realized, unrealized float64
+			for i, want := range test.want {
+				got := report.Rows[i]
+				assert.Equal(t, want.Day, got.Day)
+				assert.Equal(t, want.Symbol, got.Symbol)
+				assert.Equal(t, want.Strategy, got.Strategy)
+				assert.InDelta(t, want.RealizedPnL, got.RealizedPnL, 1e-9)
+				assert.InDelta(t, want.UnrealizedPnL, got.UnrealizedPnL, 1e-9)
+				assert.InDelta(t, want.Fees, got.Fees, 1e-9)
+				assert.InDelta(t, want.ClosedSize, got.ClosedSize, 1e-9)
+				assert.InDelta(t, want.OpenSize, got.OpenSize, 1e-9)
+				realized += want.RealizedPnL
+				unrealized += want.UnrealizedPnL
+			}
+			assert.InDelta(t, realized, report.RealizedPnL, 1e-9)
+			assert.InDelta(t, unrealized, report.UnrealizedPnL, 1e-9)
+		})
+	}
+}
+
+func TestPositionPnL(t *testing.T) {
+	linear := krakenFuturesSDK.Instrument{Type: "flexible_futures", ContractSize: 1}
+	inverse := krakenFuturesSDK.Instrument{Type: "futures_inverse", ContractSize: 1}
+
+	tests := []struct {
+		name       string
+		instrument krakenFuturesSDK.Instrument
+		long       bool
+		want       float64
+	}{
+		{name: "Linear long", instrument: linear, long: true, want: 2000},
+		{name: "Linear short", instrument: linear, want: -2000},
+		{name: "Inverse long", instrument: inverse, long: true, want: 0.1},
+		{name: "Inverse short", instrument: inverse, want: -0.1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.InDelta(t, test.want, positionPnL(test.instrument, test.long, 1, 20000, 22000), 1e-9)
+		})
+	}
+}
@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrSyncPortfolio         = errors.New("sync portfolio")
+	ErrGetOpenOrders         = errors.New("get open orders")
+	ErrGetPositions          = errors.New("get positions")
+	ErrGetFills              = errors.New("get fills")
+	ErrGetAccounts           = errors.New("get accounts")
+	ErrGetHistoricalOrders   = errors.New("get historical orders")
+	ErrGetDrifts             = errors.New("get portfolio drifts")
+	ErrReconcilePortfolios   = errors.New("reconcile portfolios")
+	ErrUnableToParseFillTime = errors.New("unable to parse fill time")
+)
+
+const (
+	defaultReconcileInterval = time.Minute
+	// maxFillsPages limits paging of fills back in history, e.g. on the first sync of user
+	maxFillsPages = 50
+
+	shortPositionSide = "short"
+	notFoundState     = "not found"
+)
+
+// userOrdersManagers returns orders manager of exchange of user
+type userOrdersManagers interface {
+	userOrdersManager(userID int) (web.KrakenOrdersManager, error)
+}
+
+// PortfolioService reads orders, positions and fills of user from exchange. Reconciler of service
+// periodically saves fills and positions of every user and compares exchange with state of bot:
+// open orders which are gone from exchange become filled or cancelled, orders unknown to bot and
+// positions which differ from positions of active trading sessions are saved as drifts
+type PortfolioService struct {
+	orders       userOrdersManagers
+	repo         repository.Portfolio
+	ordersRepo   repository.KrakenOrdersManager
+	sessionsRepo repository.TradingSessions
+	authRepo     repository.Authorization
+	now          func() time.Time
+
+	mu   sync.Mutex
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+func NewPortfolioService(orders userOrdersManagers, repo repository.Portfolio, ordersRepo repository.KrakenOrdersManager,
+	sessionsRepo repository.TradingSessions, authRepo repository.Authorization) *PortfolioService {
+	return &PortfolioService{
+		orders:       orders,
+		repo:         repo,
+		ordersRepo:   ordersRepo,
+		sessionsRepo: sessionsRepo,
+		authRepo:     authRepo,
+		now:          time.Now,
+	}
+}
+
+func (p *PortfolioService) GetOpenOrders(userID int) ([]krakenFuturesSDK.OpenOrder, error) {
+	sdk, err := p.orders.userOrdersManager(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetOpenOrders, err)
+	}
+
+	openOrders, err := sdk.OpenOrders()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetOpenOrders, err)
+	}
+	return openOrders, nil
+}
+
+func (p *PortfolioService) GetAccounts(userID int) (map[string]krakenFuturesSDK.Account, error) {
+	sdk, err := p.orders.userOrdersManager(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetAccounts, err)
+	}
+
+	accounts, err := sdk.Accounts()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetAccounts, err)
+	}
+	return accounts, nil
+}
+
+// GetHistoricalOrders returns order events of user since timestamp in milliseconds
+func (p *PortfolioService) GetHistoricalOrders(userID int, since int64) ([]krakenFuturesSDK.HistoricalOrderElement, error) {
+	sdk, err := p.orders.userOrdersManager(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetHistoricalOrders, err)
+	}
+
+	elements, err := sdk.HistoricalOrders(since)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetHistoricalOrders, err)
+	}
+	return elements, nil
+}
+
+// GetPositions returns positions of user saved on the last sync
+func (p *PortfolioService) GetPositions(userID int) ([]models.Position, error) {
+	positions, err := p.repo.GetPositions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetPositions, err)
+	}
+	return positions, nil
+}
+
+// GetFills returns fills of user saved by syncs
+func (p *PortfolioService) GetFills(userID int) ([]models.Fill, error) {
+	fills, err := p.repo.GetFills(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetFills, err)
+	}
+	return fills, nil
+}
+
+// GetDrifts returns drifts found on the last sync
+func (p *PortfolioService) GetDrifts(userID int) ([]models.PortfolioDrift, error) {
+	drifts, err := p.repo.GetDrifts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetDrifts, err)
+	}
+	return drifts, nil
+}
+
+// SyncPortfolio saves fills and positions of user from exchange, resolves open orders which are gone
+// from exchange and replaces drifts of user by the found ones
+func (p *PortfolioService) SyncPortfolio(userID int) (models.PortfolioSync, error) {
+	sdk, err := p.orders.userOrdersManager(userID)
+	if err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+
+	// orders sent after snapshot of open orders is taken are not in it, so they are not reconciled
+	snapshotTime := p.now().UTC()
+	exchangeOrders, err := sdk.OpenOrders()
+	if err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+	exchangePositions, err := sdk.OpenPositions()
+	if err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+	exchangeFills, err := p.exchangeFills(userID, sdk)
+	if err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+
+	now := p.now().UTC()
+	result := models.PortfolioSync{SyncedAt: now, Drifts: []models.PortfolioDrift{}}
+
+	result.NewFills, err = p.repo.SaveFills(userID, p.toFills(userID, exchangeFills))
+	if err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+
+	result.Positions = p.toPositions(userID, exchangePositions)
+	if err := p.repo.SavePositions(userID, result.Positions); err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+
+	orderDrifts, updated, err := p.reconcileOrders(userID, exchangeOrders, snapshotTime)
+	if err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+	result.UpdatedOrders = updated
+	result.Drifts = append(result.Drifts, orderDrifts...)
+
+	positionDrifts, err := p.reconcilePositions(userID, result.Positions)
+	if err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+	result.Drifts = append(result.Drifts, positionDrifts...)
+
+	for i := range result.Drifts {
+		result.Drifts[i].UserID = userID
+		result.Drifts[i].DetectedAt = now
+	}
+	if err := p.repo.SaveDrifts(userID, result.Drifts); err != nil {
+		return models.PortfolioSync{}, fmt.Errorf("%s: %w", ErrSyncPortfolio, err)
+	}
+
+	return result, nil
+}
+
+// exchangeFills returns fills of user made on exchange since the newest saved fill. Exchange returns the last
+// fills only, so older ones are read page by page, every page ends before fill time of the oldest fill of previous one
+func (p *PortfolioService) exchangeFills(userID int, sdk web.KrakenOrdersManager) ([]krakenFuturesSDK.Fill, error) {
+	savedFills, err := p.repo.GetFills(userID)
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	for _, fill := range savedFills {
+		if fill.FillTime.After(since) {
+			since = fill.FillTime
+		}
+	}
+
+	var fills []krakenFuturesSDK.Fill
+	var lastFillTime string
+	for page := 0; page < maxFillsPages; page++ {
+		pageFills, err := sdk.Fills(lastFillTime)
+		if err != nil {
+			return nil, err
+		}
+		if len(pageFills) == 0 {
+			break
+		}
+		fills = append(fills, pageFills...)
+
+		oldest := pageFills[len(pageFills)-1].FillTime
+		if oldest == lastFillTime || !p.parseTime(oldest).After(since) {
+			break
+		}
+		lastFillTime = oldest
+	}
+	return fills, nil
+}
+
+// reconcileOrders marks open orders of bot sent before snapshot time which are gone from exchange as filled
+// when they have fills or as cancelled otherwise. Returns drifts and count of updated orders
+func (p *PortfolioService) reconcileOrders(userID int, exchangeOrders []krakenFuturesSDK.OpenOrder,
+	snapshotTime time.Time) ([]models.PortfolioDrift, int, error) {
+	localOrders, err := p.ordersRepo.FindUserOrders(userID, models.OrdersFilter{Status: models.OrderOpen, To: snapshotTime})
+	if err != nil {
+		return nil, 0, err
+	}
+	fills, err := p.repo.GetFills(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filled := make(map[string]struct{}, len(fills))
+	for _, fill := range fills {
+		filled[fill.OrderID] = struct{}{}
+	}
+	resting := make(map[string]struct{}, len(exchangeOrders))
+	for _, order := range exchangeOrders {
+		resting[order.OrderID] = struct{}{}
+	}
+	local := make(map[string]models.Order, len(localOrders))
+	for _, order := range localOrders {
+		local[order.ID] = order
+	}
+
+	var drifts []models.PortfolioDrift
+	var updated int
+	for _, order := range localOrders {
+		if _, ok := resting[order.ID]; ok {
+			continue
+		}
+
+		status := models.OrderCancelled
+		if _, ok := filled[order.ID]; ok {
+			status = models.OrderFilled
+		} else {
+			drifts = append(drifts, models.PortfolioDrift{
+				Kind:          models.DriftMissingOrder,
+				Reference:     order.ID,
+				LocalState:    order.Status,
+				ExchangeState: notFoundState,
+			})
+		}
+
+		if err := p.ordersRepo.UpdateOrderStatus(userID, order.ID, status); err != nil {
+			return nil, 0, err
+		}
+		updated++
+	}
+
+	for _, order := range exchangeOrders {
+		if _, ok := local[order.OrderID]; ok {
+			continue
+		}
+
+		localState := notFoundState
+		localOrder, err := p.ordersRepo.GetOrder(order.OrderID)
+		switch {
+		case err == nil && localOrder.UserID == userID:
+			if localOrder.Status == models.OrderOpen {
+				// order has been saved after snapshot time
+				continue
+			}
+			localState = localOrder.Status
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return nil, 0, err
+		}
+		drifts = append(drifts, models.PortfolioDrift{
+			Kind:          models.DriftUnknownOrder,
+			Reference:     order.OrderID,
+			LocalState:    localState,
+			ExchangeState: fmt.Sprintf("%s %s %s %g", order.Status, order.Side, order.Symbol, order.UnfilledSize),
+		})
+	}
+
+	return drifts, updated, nil
+}
+
+// reconcilePositions compares positions on exchange with positions of active trading sessions of user.
+// Only symbols traded by sessions are compared, positions opened by hand are not drifts
+func (p *PortfolioService) reconcilePositions(userID int, positions []models.Position) ([]models.PortfolioDrift, error) {
+	sessions, err := p.sessionsRepo.GetUserSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make(map[string]float64)
+	var symbols []string
+	for _, session := range sessions {
+		if !session.IsActive() || session.EntryOrderID == "" {
+			continue
+		}
+
+		symbol := strings.ToLower(session.Details.Symbol)
+		if _, ok := expected[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+		size := float64(session.Details.Size)
+		if session.Details.Side == krakenFuturesSDK.SellSide {
+			size = -size
+		}
+		expected[symbol] += size
+	}
+
+	actual := make(map[string]float64, len(positions))
+	for _, position := range positions {
+		size := position.Size
+		if position.Side == shortPositionSide {
+			size = -size
+		}
+		actual[strings.ToLower(position.Symbol)] += size
+	}
+
+	var drifts []models.PortfolioDrift
+	for _, symbol := range symbols {
+		if expected[symbol] == actual[symbol] {
+			continue
+		}
+		drifts = append(drifts, models.PortfolioDrift{
+			Kind:          models.DriftPositionMismatch,
+			Reference:     symbol,
+			LocalState:    fmt.Sprintf("%g", expected[symbol]),
+			ExchangeState: fmt.Sprintf("%g", actual[symbol]),
+		})
+	}
+	return drifts, nil
+}
+
+func (p *PortfolioService) toFills(userID int, exchangeFills []krakenFuturesSDK.Fill) []models.Fill {
+	fills := make([]models.Fill, 0, len(exchangeFills))
+	for _, fill := range exchangeFills {
+		fills = append(fills, models.Fill{
+			ID:            fill.FillID,
+			UserID:        userID,
+			OrderID:       fill.OrderID,
+			ClientOrderID: fill.CliOrdID,
+			Symbol:        fill.Symbol,
+			Side:          fill.Side,
+			Size:          fill.Size,
+			Price:         fill.Price,
+			FillType:      fill.FillType,
+			FillTime:      p.parseTime(fill.FillTime),
+		})
+	}
+	return fills
+}
+
+func (p *PortfolioService) toPositions(userID int, exchangePositions []krakenFuturesSDK.OpenPosition) []models.Position {
+	positions := make([]models.Position, 0, len(exchangePositions))
+	for _, position := range exchangePositions {
+		positions = append(positions, models.Position{
+			UserID:   userID,
+			Symbol:   position.Symbol,
+			Side:     position.Side,
+			Size:     position.Size,
+			Price:    position.Price,
+			FillTime: p.parseTime(position.FillTime),
+		})
+	}
+	return positions
+}
+
+// parseTime parses time of exchange, current time is used when it is invalid
+func (p *PortfolioService) parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Warnf("%s: %s", ErrUnableToParseFillTime, err)
+		return p.now().UTC()
+	}
+	return t
+}
+
+// StartReconciler syncs portfolios of all users every interval until StopReconciler is called
+func (p *PortfolioService) StartReconciler(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		return
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	p.stop = stop
+	p.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.reconcile(ctx)
+			}
+		}
+	}(p.done)
+}
+
+// StopReconciler stops reconciler and waits for the running sync
+func (p *PortfolioService) StopReconciler() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	stop()
+	<-done
+}
+
+func (p *PortfolioService) reconcile(ctx context.Context) {
+	userIDs, err := p.authRepo.GetUserIDs()
+	if err != nil {
+		log.Errorf("%s: %s", ErrReconcilePortfolios, err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		result, err := p.SyncPortfolio(userID)
+		if err != nil {
+			log.Warnf("%s: user %d: %s", ErrReconcilePortfolios, userID, err)
+			continue
+		}
+		if len(result.Drifts) > 0 {
+			log.Warnf("portfolio of user %d drifts from exchange: %+v", userID, result.Drifts)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository/postgresRepo"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+// exchangePortfolio is an exchange of user with fixed open orders, positions and fills from the newest one.
+// Fills are returned by pages of pageSize when it is set, requested fill times are recorded
+type exchangePortfolio struct {
+	web.KrakenOrdersManager
+	openOrders    []krakenFuturesSDK.OpenOrder
+	positions     []krakenFuturesSDK.OpenPosition
+	fills         []krakenFuturesSDK.Fill
+	pageSize      int
+	lastFillTimes []string
+}
+
+func (e *exchangePortfolio) userOrdersManager(userID int) (web.KrakenOrdersManager, error) {
+	return e, nil
+}
+
+func (e *exchangePortfolio) OpenOrders() ([]krakenFuturesSDK.OpenOrder, error) {
+	return e.openOrders, nil
+}
+
+func (e *exchangePortfolio) OpenPositions() ([]krakenFuturesSDK.OpenPosition, error) {
+	return e.positions, nil
+}
+
+func (e *exchangePortfolio) Fills(lastFillTime string) ([]krakenFuturesSDK.Fill, error) {
+	e.lastFillTimes = append(e.lastFillTimes, lastFillTime)
+
+	var fills []krakenFuturesSDK.Fill
+	for _, fill := range e.fills {
+		if lastFillTime != "" && fill.FillTime >= lastFillTime {
+			continue
+		}
+		if e.pageSize > 0 && len(fills) == e.pageSize {
+			break
+		}
+		fills = append(fills, fill)
+	}
+	return fills, nil
+}
+
+// portfolioRepo keeps portfolio and orders of the only user in memory
+type portfolioRepo struct {
+	fills     map[string]models.Fill
+	positions []models.Position
+	drifts    []models.PortfolioDrift
+	orders    []models.Order
+	updated   []models.Order
+	statuses  map[string]string
+}
+
+func (r *portfolioRepo) SaveFills(userID int, fills []models.Fill) (int, error) {
+	var saved int
+	for _, fill := range fills {
+		if _, ok := r.fills[fill.ID]; !ok {
+			r.fills[fill.ID] = fill
+			saved++
+		}
+	}
+	return saved, nil
+}
+
+func (r *portfolioRepo) GetFills(userID int) ([]models.Fill, error) {
+	var fills []models.Fill
+	for _, fill := range r.fills {
+		fills = append(fills, fill)
+	}
+	return fills, nil
+}
+
+func (r *portfolioRepo) SavePositions(userID int, positions []models.Position) error {
+	r.positions = positions
+	return nil
+}
+
+func (r *portfolioRepo) GetPositions(userID int) ([]models.Position, error) {
+	return r.positions, nil
+}
+
+func (r *portfolioRepo) SaveDrifts(userID int, drifts []models.PortfolioDrift) error {
+	r.drifts = drifts
+	return nil
+}
+
+func (r *portfolioRepo) GetDrifts(userID int) ([]models.PortfolioDrift, error) {
+	return r.drifts, nil
+}
+
+func (r *portfolioRepo) CreateOrder(userID int, order models.Order) error {
+	return nil
+}
+
+// FindUserOrders selects orders like postgres repository, they are sorted only by timestamp and id
+func (r *portfolioRepo) FindUserOrders(userID int, filter models.OrdersFilter) ([]models.Order, error) {
+	less := func(a, b models.Order) bool {
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.ID < b.ID
+	}
+	before := func(a, b models.Order) bool {
+		if filter.Descending {
+			return less(b, a)
+		}
+		return less(a, b)
+	}
+
+	orders := append([]models.Order(nil), r.orders...)
+	sort.Slice(orders, func(i, j int) bool {
+		return before(orders[i], orders[j])
+	})
+
+	var found []models.Order
+	for _, order := range orders {
+		if order.UserID != userID ||
+			filter.Symbol != "" && !strings.EqualFold(order.Symbol, filter.Symbol) ||
+			filter.Side != "" && order.Side != filter.Side ||
+			filter.Status != "" && order.Status != filter.Status ||
+			!filter.From.IsZero() && order.Timestamp.Before(filter.From) ||
+			!filter.To.IsZero() && !order.Timestamp.Before(filter.To) ||
+			filter.After != nil && !before(*filter.After, order) {
+			continue
+		}
+		found = append(found, order)
+		if len(found) == filter.Limit {
+			break
+		}
+	}
+	return found, nil
+}
+
+func (r *portfolioRepo) GetOrder(orderID string) (models.Order, error) {
+	for _, order := range r.orders {
+		if order.ID == orderID {
+			return order, nil
+		}
+	}
+	return models.Order{}, sql.ErrNoRows
+}
+
+func (r *portfolioRepo) UpdateOrder(userID int, order models.Order) error {
+	r.updated = append(r.updated, order)
+	return nil
+}
+
+func (r *portfolioRepo) UpdateOrderStatus(userID int, orderID, status string) error {
+	for _, order := range r.orders {
+		if order.ID == orderID && order.UserID == userID {
+			r.statuses[orderID] = status
+			return nil
+		}
+	}
+	return postgresRepo.ErrOrderNotFound
+}
+
+func (r *portfolioRepo) GetUsersWithOpenOrders() ([]int, error) {
+	var userIDs []int
+	seen := make(map[int]bool)
+	for _, order := range r.orders {
+		if order.Status == models.OrderOpen && !seen[order.UserID] {
+			seen[order.UserID] = true
+			userIDs = append(userIDs, order.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+func TestPortfolioService_SyncPortfolio(t *testing.T) {
+	now := time.Unix(1640000000, 0).UTC()
+	exchange := &exchangePortfolio{
+		openOrders: []krakenFuturesSDK.OpenOrder{
+			{OrderID: "resting", Status: "untouched", Side: "sell", Symbol: "pi_xbtusd", UnfilledSize: 1},
+			{OrderID: "manual", Status: "untouched", Side: "buy", Symbol: "pi_ethusd", UnfilledSize: 2},
+			{OrderID: "cancelled", Status: "untouched", Side: "buy", Symbol: "pi_ethusd", UnfilledSize: 1},
+		},
+		positions: []krakenFuturesSDK.OpenPosition{
+			{Side: "long", Symbol: "pi_xbtusd", Price: 100, Size: 2, FillTime: "2021-12-20T11:33:20.123Z"},
+		},
+		fills: []krakenFuturesSDK.Fill{
+			{FillID: "fill-1", OrderID: "entry", Symbol: "pi_xbtusd", Side: "buy", Size: 1, Price: 100, FillTime: "2021-12-20T11:33:20Z"},
+			{FillID: "fill-2", OrderID: "take-profit", Symbol: "pi_xbtusd", Side: "sell", Size: 1, Price: 120, FillTime: "invalid"},
+		},
+	}
+	repo := &portfolioRepo{
+		fills: map[string]models.Fill{"fill-1": {ID: "fill-1", OrderID: "entry"}},
+		orders: []models.Order{
+			{ID: "entry", UserID: 1, Status: models.OrderFilled, Timestamp: now.Add(-time.Hour)},
+			{ID: "resting", UserID: 1, Status: models.OrderOpen, Timestamp: now.Add(-time.Hour)},
+			{ID: "take-profit", UserID: 1, Status: models.OrderOpen, Timestamp: now.Add(-time.Hour)},
+			{ID: "stop-loss", UserID: 1, Status: models.OrderOpen, Timestamp: now.Add(-time.Hour)},
+			{ID: "cancelled", UserID: 1, Status: models.OrderCancelled, Timestamp: now.Add(-time.Hour)},
+			// sent after snapshot of open orders
+			{ID: "just-sent", UserID: 1, Status: models.OrderOpen, Timestamp: now},
+		},
+		statuses: make(map[string]string),
+	}
+	sessions := newSessionsRepo(
+		models.TradingSession{ID: 1, UserID: 1, Status: models.SessionMonitoring, EntryOrderID: "entry",
+			Details: testTradingDetails},
+		models.TradingSession{ID: 2, UserID: 1, Status: models.SessionClosed, EntryOrderID: "entry",
+			Details: testTradingDetails},
+	)
+
+	service := NewPortfolioService(exchange, repo, repo, sessions, nil)
+	service.now = func() time.Time { return now }
+
+	got, err := service.SyncPortfolio(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, got.NewFills)
+	assert.Equal(t, 2, got.UpdatedOrders)
+	assert.Equal(t, now, got.SyncedAt)
+	assert.Equal(t, now, repo.fills["fill-2"].FillTime)
+	assert.Equal(t, map[string]string{"take-profit": models.OrderFilled, "stop-loss": models.OrderCancelled}, repo.statuses)
+
+	assert.Len(t, got.Positions, 1)
+	assert.Equal(t, time.Date(2021, 12, 20, 11, 33, 20, 123000000, time.UTC), got.Positions[0].FillTime)
+	assert.Equal(t, got.Positions, repo.positions)
+
+	assert.Equal(t, []models.PortfolioDrift{
+		{UserID: 1, Kind: models.DriftMissingOrder, Reference: "stop-loss", LocalState: "open", ExchangeState: "not found", DetectedAt: now},
+		{UserID: 1, Kind: models.DriftUnknownOrder, Reference: "manual", LocalState: "not found", ExchangeState: "untouched buy pi_ethusd 2", DetectedAt: now},
+		{UserID: 1, Kind: models.DriftUnknownOrder, Reference: "cancelled", LocalState: "cancelled", ExchangeState: "untouched buy pi_ethusd 1", DetectedAt: now},
+		{UserID: 1, Kind: models.DriftPositionMismatch, Reference: "pi_xbtusd", LocalState: "1", ExchangeState: "2", DetectedAt: now},
+	}, got.Drifts)
+	assert.Equal(t, got.Drifts, repo.drifts)
+}
+
+func TestPortfolioService_SyncPortfolio_FillsPages(t *testing.T) {
+	exchange := &exchangePortfolio{
+		fills: []krakenFuturesSDK.Fill{
+			{FillID: "fill-5", FillTime: "2021-12-20T11:05:00Z"},
+			{FillID: "fill-4", FillTime: "2021-12-20T11:04:00Z"},
+			{FillID: "fill-3", FillTime: "2021-12-20T11:03:00Z"},
+			{FillID: "fill-2", FillTime: "2021-12-20T11:02:00Z"},
+			{FillID: "fill-1", FillTime: "2021-12-20T11:01:00Z"},
+		},
+		pageSize: 2,
+	}
+	repo := &portfolioRepo{
+		fills: map[string]models.Fill{
+			"fill-1": {ID: "fill-1", FillTime: time.Date(2021, 12, 20, 11, 1, 0, 0, time.UTC)},
+			"fill-2": {ID: "fill-2", FillTime: time.Date(2021, 12, 20, 11, 2, 0, 0, time.UTC)},
+		},
+		statuses: make(map[string]string),
+	}
+
+	service := NewPortfolioService(exchange, repo, repo, newSessionsRepo(), nil)
+
+	got, err := service.SyncPortfolio(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, got.NewFills)
+	assert.Len(t, repo.fills, 5)
+	// paging stops at the newest saved fill
+	assert.Equal(t, []string{"", "2021-12-20T11:04:00Z"}, exchange.lastFillTimes)
+}
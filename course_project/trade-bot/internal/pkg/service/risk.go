@@ -0,0 +1,312 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrRiskRejected     = errors.New("rejected by risk check")
+	ErrCheckOrder       = errors.New("check order")
+	ErrCheckSession     = errors.New("check trading session")
+	ErrUpdateRiskLimits = errors.New("update risk limits")
+	ErrNoPrice          = errors.New("no price of symbol")
+)
+
+// Rules of risk check, rule of rejection names the broken one
+const (
+	RiskRuleUnknownSymbol   = "unknown_symbol"
+	RiskRuleNotTradeable    = "not_tradeable"
+	RiskRuleTickSize        = "tick_size"
+	RiskRuleMaxOrderSize    = "max_order_size"
+	RiskRuleMaxNotional     = "max_notional"
+	RiskRuleMaxOpenSessions = "max_open_sessions"
+	RiskRuleDailyLossLimit  = "daily_loss_limit"
+)
+
+// inverseInstrumentType is a type of contracts which are quoted in USD and settled in base currency,
+// their size is a value in USD
+const inverseInstrumentType = "futures_inverse"
+
+const longPositionSide = "long"
+
+// RiskRejection is an error of order which breaks one of rules of risk check, it wraps ErrRiskRejected
+type RiskRejection struct {
+	Rule   string
+	Reason string
+}
+
+func (r *RiskRejection) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrRiskRejected, r.Rule, r.Reason)
+}
+
+func (r *RiskRejection) Unwrap() error {
+	return ErrRiskRejected
+}
+
+func newRiskRejection(rule, format string, args ...interface{}) error {
+	return &RiskRejection{Rule: rule, Reason: fmt.Sprintf(format, args...)}
+}
+
+// RiskService checks orders of user before they are sent to exchange. Symbol of every order must be
+// tradeable instrument and its prices must be multiples of tick size, the other rules are limits of user.
+// Notional values and losses are in USD, they are estimated by prices of orders or mark prices
+type RiskService struct {
+	limitsRepo    repository.RiskLimits
+	ordersRepo    repository.KrakenOrdersManager
+	sessionsRepo  repository.TradingSessions
+	portfolioRepo repository.Portfolio
+	market        Market
+	now           func() time.Time
+}
+
+func NewRiskService(limitsRepo repository.RiskLimits, ordersRepo repository.KrakenOrdersManager,
+	sessionsRepo repository.TradingSessions, portfolioRepo repository.Portfolio, market Market) *RiskService {
+	return &RiskService{
+		limitsRepo:    limitsRepo,
+		ordersRepo:    ordersRepo,
+		sessionsRepo:  sessionsRepo,
+		portfolioRepo: portfolioRepo,
+		market:        market,
+		now:           time.Now,
+	}
+}
+
+func (r *RiskService) GetRiskLimits(userID int) (models.RiskLimits, error) {
+	return r.limitsRepo.GetRiskLimits(userID)
+}
+
+func (r *RiskService) UpdateRiskLimits(userID int, limits models.RiskLimits) error {
+	if limits.MaxNotional < 0 || limits.MaxOpenSessions < 0 || limits.DailyLossLimit < 0 {
+		return fmt.Errorf("%s: limits must not be negative", ErrUpdateRiskLimits)
+	}
+	return r.limitsRepo.UpdateRiskLimits(userID, limits)
+}
+
+// CheckOrder returns *RiskRejection when order breaks one of rules
+func (r *RiskService) CheckOrder(userID int, args krakenFuturesSDK.SendOrderArguments) error {
+	instruments, err := r.market.GetInstruments()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCheckOrder, err)
+	}
+	instrument, err := checkInstrument(instruments, args)
+	if err != nil {
+		return err
+	}
+
+	limits, err := r.limitsRepo.GetRiskLimits(userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCheckOrder, err)
+	}
+
+	if limits.MaxOrderSize != 0 && args.Size > limits.MaxOrderSize {
+		return newRiskRejection(RiskRuleMaxOrderSize, "size %d is larger than %d", args.Size, limits.MaxOrderSize)
+	}
+
+	if limits.MaxNotional != 0 {
+		if err := r.checkNotional(userID, args, instrument, limits.MaxNotional); err != nil {
+			return err
+		}
+	}
+
+	if limits.DailyLossLimit != 0 {
+		pnl, err := r.dailyRealizedPnL(userID, instruments)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ErrCheckOrder, err)
+		}
+		if -pnl >= limits.DailyLossLimit {
+			return newRiskRejection(RiskRuleDailyLossLimit, "loss of today is %.2f USD, limit is %.2f USD",
+				-pnl, limits.DailyLossLimit)
+		}
+	}
+	return nil
+}
+
+// CheckSession returns *RiskRejection when user can not start one more trading session
+func (r *RiskService) CheckSession(userID int) error {
+	limits, err := r.limitsRepo.GetRiskLimits(userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCheckSession, err)
+	}
+	if limits.MaxOpenSessions == 0 {
+		return nil
+	}
+
+	sessions, err := r.sessionsRepo.GetUserSessions(userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCheckSession, err)
+	}
+
+	var active int
+	for _, session := range sessions {
+		if session.IsActive() {
+			active++
+		}
+	}
+	if active >= limits.MaxOpenSessions {
+		return newRiskRejection(RiskRuleMaxOpenSessions, "%d trading sessions are open already", active)
+	}
+	return nil
+}
+
+func checkInstrument(instruments []krakenFuturesSDK.Instrument, args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.Instrument, error) {
+	instrument, ok := findInstrument(instruments, args.Symbol)
+	if !ok {
+		return krakenFuturesSDK.Instrument{}, newRiskRejection(RiskRuleUnknownSymbol, "%s", args.Symbol)
+	}
+	if !instrument.Tradeable {
+		return krakenFuturesSDK.Instrument{}, newRiskRejection(RiskRuleNotTradeable, "%s", instrument.Symbol)
+	}
+
+	for _, price := range []float64{args.LimitPrice, args.StopPrice} {
+		if price != 0 && !isMultiple(price, instrument.TickSize) {
+			return krakenFuturesSDK.Instrument{}, newRiskRejection(RiskRuleTickSize,
+				"price %v is not a multiple of tick size %v", price, instrument.TickSize)
+		}
+	}
+	return instrument, nil
+}
+
+// checkNotional checks notional of position of symbol which it will have after order is filled,
+// orders which only reduce position are not limited
+func (r *RiskService) checkNotional(userID int, args krakenFuturesSDK.SendOrderArguments,
+	instrument krakenFuturesSDK.Instrument, maxNotional float64) error {
+	positions, err := r.portfolioRepo.GetPositions(userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCheckOrder, err)
+	}
+
+	var current float64
+	for _, position := range positions {
+		if strings.EqualFold(position.Symbol, instrument.Symbol) {
+			current = position.Size
+			if position.Side != longPositionSide {
+				current = -current
+			}
+		}
+	}
+
+	next := current + float64(args.Size)
+	if args.Side == krakenFuturesSDK.SellSide {
+		next = current - float64(args.Size)
+	}
+	if math.Abs(next) <= math.Abs(current) {
+		return nil
+	}
+
+	price, err := r.orderPrice(args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrCheckOrder, err)
+	}
+
+	notional := notionalValue(instrument, math.Abs(next), price)
+	if notional > maxNotional {
+		return newRiskRejection(RiskRuleMaxNotional, "position of %s would be %.2f USD, limit is %.2f USD",
+			instrument.Symbol, notional, maxNotional)
+	}
+	return nil
+}
+
+// orderPrice returns limit or stop price of order, market orders are priced by mark price of symbol
+func (r *RiskService) orderPrice(args krakenFuturesSDK.SendOrderArguments) (float64, error) {
+	if args.LimitPrice != 0 {
+		return args.LimitPrice, nil
+	}
+	if args.StopPrice != 0 {
+		return args.StopPrice, nil
+	}
+
+	tickers, err := r.market.GetTickers()
+	if err != nil {
+		return 0, err
+	}
+	if price, ok := tickerPrice(tickers, args.Symbol); ok {
+		return price, nil
+	}
+	return 0, fmt.Errorf("%s: %s", ErrNoPrice, args.Symbol)
+}
+
+// dailyRealizedPnL estimates PnL realized by filled orders of the current UTC day. Bought and sold sizes
+// of every symbol are matched at their average prices, so position opened before the day is not taken into account
+func (r *RiskService) dailyRealizedPnL(userID int, instruments []krakenFuturesSDK.Instrument) (float64, error) {
+	orders, err := r.ordersRepo.FindUserOrders(userID, models.OrdersFilter{
+		Status: models.OrderFilled,
+		From:   r.now().UTC().Truncate(24 * time.Hour),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	type volumes struct {
+		bought, boughtValue, sold, soldValue float64
+	}
+	bySymbol := make(map[string]*volumes)
+	for _, order := range orders {
+		if order.Price == 0 {
+			continue
+		}
+
+		symbol := strings.ToUpper(order.Symbol)
+		v, ok := bySymbol[symbol]
+		if !ok {
+			v = &volumes{}
+			bySymbol[symbol] = v
+		}
+		if order.Side == krakenFuturesSDK.BuySide {
+			v.bought += order.Quantity
+			v.boughtValue += order.Quantity * order.Price
+		} else {
+			v.sold += order.Quantity
+			v.soldValue += order.Quantity * order.Price
+		}
+	}
+
+	var pnl float64
+	for symbol, v := range bySymbol {
+		matched := math.Min(v.bought, v.sold)
+		if matched == 0 {
+			continue
+		}
+		buyPrice, sellPrice := v.boughtValue/v.bought, v.soldValue/v.sold
+		pnl += positionPnL(instrumentOrDefault(instruments, symbol), true, matched, buyPrice, sellPrice)
+	}
+	return pnl, nil
+}
+
+func findInstrument(instruments []krakenFuturesSDK.Instrument, symbol string) (krakenFuturesSDK.Instrument, bool) {
+	for _, instrument := range instruments {
+		if strings.EqualFold(instrument.Symbol, symbol) {
+			return instrument, true
+		}
+	}
+	return krakenFuturesSDK.Instrument{}, false
+}
+
+// notionalValue returns value in USD of size contracts at price
+func notionalValue(instrument krakenFuturesSDK.Instrument, size, price float64) float64 {
+	contractSize := float64(instrument.ContractSize)
+	if contractSize == 0 {
+		contractSize = 1
+	}
+	if instrument.Type == inverseInstrumentType {
+		return size * contractSize
+	}
+	return size * contractSize * price
+}
+
+// isMultiple reports whether value is a multiple of step with tolerance of float rounding
+func isMultiple(value, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	ratio := value / step
+	return math.Abs(ratio-math.Round(ratio)) < 1e-6
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+// noRisk passes every order and trading session
+type noRisk struct{}
+
+func (noRisk) GetRiskLimits(userID int) (models.RiskLimits, error) {
+	return models.RiskLimits{}, nil
+}
+
+func (noRisk) UpdateRiskLimits(userID int, limits models.RiskLimits) error {
+	return nil
+}
+
+func (noRisk) CheckOrder(userID int, args krakenFuturesSDK.SendOrderArguments) error {
+	return nil
+}
+
+func (noRisk) CheckSession(userID int) error {
+	return nil
+}
+
+type riskLimitsRepo struct {
+	limits models.RiskLimits
+}
+
+func (r *riskLimitsRepo) GetRiskLimits(userID int) (models.RiskLimits, error) {
+	return r.limits, nil
+}
+
+func (r *riskLimitsRepo) UpdateRiskLimits(userID int, limits models.RiskLimits) error {
+	r.limits = limits
+	return nil
+}
+
+// riskMarket serves instruments and mark prices of risk checks
+type riskMarket struct {
+	marketDataCounter
+}
+
+func (m *riskMarket) Tickers() ([]krakenFuturesSDK.Ticker, error) {
+	return []krakenFuturesSDK.Ticker{
+		{Symbol: "PI_XBTUSD", MarkPrice: 20000},
+		{Symbol: "PF_ETHUSD", MarkPrice: 1000},
+	}, nil
+}
+
+func (m *riskMarket) Instruments() ([]krakenFuturesSDK.Instrument, error) {
+	return []krakenFuturesSDK.Instrument{
+		{Symbol: "PI_XBTUSD", Type: "futures_inverse", Tradeable: true, TickSize: 0.5, ContractSize: 1},
+		{Symbol: "PF_ETHUSD", Type: "flexible_futures", Tradeable: true, TickSize: 0.1, ContractSize: 1},
+		{Symbol: "FI_XBTUSD_211231", Type: "futures_inverse", Tradeable: false, TickSize: 0.5, ContractSize: 1},
+	}, nil
+}
+
+func TestRiskService_CheckOrder(t *testing.T) {
+	now := time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+	today, yesterday := now.Add(-time.Hour), now.Add(-24*time.Hour)
+
+	tests := []struct {
+		name      string
+		limits    models.RiskLimits
+		positions []models.Position
+		orders    []models.Order
+		args      krakenFuturesSDK.SendOrderArguments
+		wantRule  string
+	}{
+		{
+			name: "No limits",
+			args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: 100000},
+		},
+		{
+			name:     "Unknown symbol",
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PI_DOGEUSD", Side: "buy", Size: 1},
+			wantRule: RiskRuleUnknownSymbol,
+		},
+		{
+			name:     "Not tradeable",
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "FI_XBTUSD_211231", Side: "buy", Size: 1},
+			wantRule: RiskRuleNotTradeable,
+		},
+		{
+			name:     "Limit price off tick size",
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "lmt", Symbol: "PI_XBTUSD", Side: "buy", Size: 1, LimitPrice: 20000.3},
+			wantRule: RiskRuleTickSize,
+		},
+		{
+			name: "Limit price on tick size",
+			args: krakenFuturesSDK.SendOrderArguments{OrderType: "lmt", Symbol: "PF_ETHUSD", Side: "buy", Size: 1, LimitPrice: 1000.3},
+		},
+		{
+			name:     "Max order size",
+			limits:   models.RiskLimits{MaxOrderSize: 10},
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "buy", Size: 11},
+			wantRule: RiskRuleMaxOrderSize,
+		},
+		{
+			name:     "Max notional of linear contract at mark price",
+			limits:   models.RiskLimits{MaxNotional: 5000},
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PF_ETHUSD", Side: "buy", Size: 6},
+			wantRule: RiskRuleMaxNotional,
+		},
+		{
+			name:      "Max notional including position",
+			limits:    models.RiskLimits{MaxNotional: 5000},
+			positions: []models.Position{{Symbol: "PI_XBTUSD", Side: "short", Size: 4500}},
+			args:      krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "sell", Size: 1000},
+			wantRule:  RiskRuleMaxNotional,
+		},
+		{
+			name:      "Reducing order is not limited by notional",
+			limits:    models.RiskLimits{MaxNotional: 5000},
+			positions: []models.Position{{Symbol: "PI_XBTUSD", Side: "short", Size: 9000}},
+			args:      krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "buy", Size: 1000},
+		},
+		{
+			name:   "Daily loss limit",
+			limits: models.RiskLimits{DailyLossLimit: 50},
+			orders: []models.Order{
+				{UserID: 1, Symbol: "PF_ETHUSD", Side: "buy", Quantity: 2, Price: 1000, Status: models.OrderFilled, Timestamp: today},
+				{UserID: 1, Symbol: "PF_ETHUSD", Side: "sell", Quantity: 2, Price: 970, Status: models.OrderFilled, Timestamp: today},
+			},
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PF_ETHUSD", Side: "buy", Size: 1},
+			wantRule: RiskRuleDailyLossLimit,
+		},
+		{
+			name:   "Loss of previous day and open orders are not counted",
+			limits: models.RiskLimits{DailyLossLimit: 50},
+			orders: []models.Order{
+				{UserID: 1, Symbol: "PF_ETHUSD", Side: "buy", Quantity: 2, Price: 1000, Status: models.OrderFilled, Timestamp: yesterday},
+				{UserID: 1, Symbol: "PF_ETHUSD", Side: "sell", Quantity: 2, Price: 900, Status: models.OrderFilled, Timestamp: today},
+				{UserID: 1, Symbol: "PF_ETHUSD", Side: "buy", Quantity: 2, Price: 1000, Status: models.OrderOpen, Timestamp: today},
+			},
+			args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PF_ETHUSD", Side: "buy", Size: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			market := NewMarketService(&riskMarket{}, &memoryCache{values: make(map[string][]byte)})
+			repo := &portfolioRepo{positions: test.positions, orders: test.orders}
+			r := NewRiskService(&riskLimitsRepo{limits: test.limits}, repo, newSessionsRepo(), repo, market)
+			r.now = func() time.Time { return now }
+
+			err := r.CheckOrder(1, test.args)
+			if test.wantRule == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var rejection *RiskRejection
+			if assert.True(t, errors.As(err, &rejection)) {
+				assert.Equal(t, test.wantRule, rejection.Rule)
+			}
+			assert.ErrorIs(t, err, ErrRiskRejected)
+		})
+	}
+}
+
+func TestRiskService_CheckSession(t *testing.T) {
+	sessions := newSessionsRepo(
+		models.TradingSession{ID: 1, UserID: 1, Status: models.SessionMonitoring},
+		models.TradingSession{ID: 2, UserID: 1, Status: models.SessionClosed},
+	)
+
+	tests := []struct {
+		name        string
+		limits      models.RiskLimits
+		wantErrorIs error
+	}{
+		{name: "No limit"},
+		{name: "Under limit", limits: models.RiskLimits{MaxOpenSessions: 2}},
+		{name: "Limit reached", limits: models.RiskLimits{MaxOpenSessions: 1}, wantErrorIs: ErrRiskRejected},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewRiskService(&riskLimitsRepo{limits: test.limits}, nil, sessions, nil, nil)
+
+			err := r.CheckSession(1)
+			if test.wantErrorIs != nil {
+				assert.ErrorIs(t, err, test.wantErrorIs)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestKrakenOrdersManagerService_SendOrderRejected(t *testing.T) {
+	risk := NewRiskService(&riskLimitsRepo{limits: models.RiskLimits{MaxOrderSize: 1}}, nil, nil, nil,
+		NewMarketService(&riskMarket{}, &memoryCache{values: make(map[string][]byte)}))
+	notifier := &notifyRecorder{}
+	k := NewKrakenOrdersManagerService(nil, nil, nil, nil, nil, risk, notifier, nil)
+
+	_, err := k.SendOrder(1, krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "buy", Size: 2})
+	assert.ErrorIs(t, err, ErrRiskRejected)
+	if assert.Len(t, notifier.events, 1) {
+		assert.Equal(t, models.EventOrderRejected, notifier.events[0].Event)
+		assert.Equal(t, "Order rejected: buy PI_XBTUSD", notifier.events[0].Subject)
+	}
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"time"
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/backtest"
 	"trade-bot/internal/pkg/models"
 	"trade-bot/internal/pkg/repository"
 	"trade-bot/internal/pkg/tradeAlgorithm"
@@ -12,26 +15,142 @@ import (
 
 type Authorization interface {
 	CreateUser(user models.User) (int, error)
-	GenerateJWT(username string, password string) (string, error)
+	GenerateJWT(username string, password string, client models.SessionClient) (models.Tokens, error)
+	RefreshJWT(refreshToken string, client models.SessionClient) (models.Tokens, error)
 	GetUserIDByJWT(token string) (int, error)
 	LogoutUser(token string) error
+	GetUserSessions(userID int) ([]models.AuthSession, error)
+	RevokeSession(userID int, sessionID string) error
 	GetUserAPIKeys(userID int) (string, string, error)
 }
 
 type KrakenOrdersManager interface {
 	SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error)
-	GetUserOrders(userID int) ([]models.Order, error)
-	StartTrading(ctx context.Context, userID int, details types.TradingDetails) (models.Order, error)
+	SendExitOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error)
+	EditOrder(userID int, args krakenFuturesSDK.EditOrderArguments) (models.Order, error)
+	CancelOrder(userID int, args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error)
+	CancelAllOrders(userID int, symbol string) (krakenFuturesSDK.CancelAllStatus, error)
+	GetOrderFillPrice(userID int, orderID string) (float64, bool, error)
+	FindOrder(userID int, cliOrderID string) (models.Order, bool, error)
+	GetUserOrders(userID int, filter models.OrdersFilter, cursor string) (models.OrdersPage, error)
+	GetStrategies() []types.StrategySchema
+	WatchOpenOrders() error
+	StopWatchingOrders()
+}
+
+type Backtest interface {
+	RunBacktest(ctx context.Context, input models.BacktestInput) (backtest.Report, error)
+}
+
+type Settings interface {
+	GetSettings(userID int) (models.UserSettings, error)
+	UpdateSettings(userID int, settings models.UserSettings) error
+}
+
+type Risk interface {
+	GetRiskLimits(userID int) (models.RiskLimits, error)
+	UpdateRiskLimits(userID int, limits models.RiskLimits) error
+	CheckOrder(userID int, args krakenFuturesSDK.SendOrderArguments) error
+	CheckSession(userID int) error
+}
+
+type TradingSessions interface {
+	StartSession(userID int, details types.TradingDetails) (models.TradingSession, error)
+	WaitSession(ctx context.Context, userID, sessionID int) (models.TradingSession, error)
+	GetSession(userID, sessionID int) (models.TradingSession, error)
+	GetUserSessions(userID int) ([]models.TradingSession, error)
+	CancelSession(userID, sessionID int) (models.TradingSession, error)
+	ResumeSessions() error
+	StopSessions()
+}
+
+type Portfolio interface {
+	GetOpenOrders(userID int) ([]krakenFuturesSDK.OpenOrder, error)
+	GetPositions(userID int) ([]models.Position, error)
+	GetFills(userID int) ([]models.Fill, error)
+	GetAccounts(userID int) (map[string]krakenFuturesSDK.Account, error)
+	GetHistoricalOrders(userID int, since int64) ([]krakenFuturesSDK.HistoricalOrderElement, error)
+	GetDrifts(userID int) ([]models.PortfolioDrift, error)
+	SyncPortfolio(userID int) (models.PortfolioSync, error)
+	StartReconciler(interval time.Duration)
+	StopReconciler()
+}
+
+type Reports interface {
+	GetPnLReport(userID int, from, to time.Time) (models.PnLReport, error)
+}
+
+type Market interface {
+	GetTickers() ([]krakenFuturesSDK.Ticker, error)
+	GetOrderBook(symbol string) (krakenFuturesSDK.OrderBook, error)
+	GetInstruments() ([]krakenFuturesSDK.Instrument, error)
+	GetFeeSchedules() ([]krakenFuturesSDK.FeeSchedules, error)
+}
+
+type Candles interface {
+	GetCandles(symbol, interval string, from, to time.Time) ([]models.Candle, error)
+	StartRecorder() error
+	StopRecorder()
+}
+
+// Notifier enqueues notifications of trading events, it never fails caller
+type Notifier interface {
+	Notify(event models.TradingEvent)
+}
+
+type Notifications interface {
+	Notifier
+	GetNotificationPreferences(userID int) (models.NotificationPreferences, error)
+	UpdateNotificationPreferences(userID int, preferences models.NotificationPreferences) error
+	GetUserNotifications(userID int) ([]models.Notification, error)
+	StartDispatcher()
+	StopDispatcher()
+}
+
+type Signals interface {
+	CreateSignalWebhook(userID int) (models.SignalWebhook, error)
+	HandleSignal(token, signature string, body []byte) (models.Signal, error)
+	GetUserSignals(userID int) ([]models.Signal, error)
 }
 
 type Service struct {
 	Authorization
 	KrakenOrdersManager
+	Backtest
+	Settings
+	Risk
+	TradingSessions
+	Portfolio
+	Reports
+	Market
+	Candles
+	Signals
+	Notifications
 }
 
-func NewService(r *repository.Repository, w *web.Web, a *tradeAlgorithm.TradeAlgorithm) *Service {
+func NewService(r *repository.Repository, w *web.Web, a *tradeAlgorithm.TradeAlgorithm, auth configs.AuthConfiguration,
+	candlesRecorder configs.CandlesRecorderConfiguration, notificationsConfig configs.NotificationsConfiguration) *Service {
+	market := NewMarketService(w.KrakenMarketData, r.MarketCache)
+	reports := NewPnLService(r.Portfolio, r.TradingSessions, market)
+	notifications := NewNotificationsService(r.Notifications, w.Notifiers, reports, notificationsConfig)
+	risk := NewRiskService(r.RiskLimits, r.KrakenOrdersManager, r.TradingSessions, r.Portfolio, market)
+	ordersManager := NewKrakenOrdersManagerService(w.KrakenOrdersManagerFactory, w.KrakenPrivateFeeds, r.Authorization, r.Settings,
+		r.KrakenOrdersManager, risk, notifications, a.Strategies)
+	sessions := NewSessionSupervisor(r.TradingSessions, r.Settings, ordersManager, risk, notifications, a.Strategies)
+	candles := NewCandlesService(w.KrakenAnalyzer, r.Candles, candlesRecorder)
+
 	return &Service{
-		Authorization:       NewAuthService(r.Authorization, r.JWT),
-		KrakenOrdersManager: NewKrakenOrdersManagerService(w.KrakenOrdersManager, r.KrakenOrdersManager, a.Trader),
+		Authorization:       NewAuthService(r.Authorization, r.JWT, auth),
+		KrakenOrdersManager: ordersManager,
+		Backtest:            NewBacktestService(candles, w.KrakenMarketData, a.Strategies),
+		Settings:            NewSettingsService(r.Settings, w.KrakenOrdersManagerFactory),
+		Risk:                risk,
+		TradingSessions:     sessions,
+		Portfolio:           NewPortfolioService(ordersManager, r.Portfolio, r.KrakenOrdersManager, r.TradingSessions, r.Authorization),
+		Reports:             reports,
+		Market:              market,
+		Candles:             candles,
+		Signals:             NewSignalsService(r.Signals, ordersManager, sessions),
+		Notifications:       notifications,
 	}
 }
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/web"
+)
+
+type SettingsService struct {
+	repo   repository.Settings
+	orders web.KrakenOrdersManagerFactory
+}
+
+func NewSettingsService(repo repository.Settings, orders web.KrakenOrdersManagerFactory) *SettingsService {
+	return &SettingsService{repo: repo, orders: orders}
+}
+
+func (s *SettingsService) GetSettings(userID int) (models.UserSettings, error) {
+	return s.repo.GetUserSettings(userID)
+}
+
+// UpdateSettings saves settings of user, simulated exchange of user is dropped when paper trading is turned off
+func (s *SettingsService) UpdateSettings(userID int, settings models.UserSettings) error {
+	if err := s.repo.UpdateUserSettings(userID, settings); err != nil {
+		return err
+	}
+	if !settings.PaperTrading {
+		s.orders.ClosePaperOrdersManager(userID)
+	}
+	return nil
+}
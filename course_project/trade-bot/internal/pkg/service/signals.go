@@ -0,0 +1,255 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrHandleSignal          = errors.New("handle signal")
+	ErrCreateSignalWebhook   = errors.New("create signal webhook")
+	ErrSignalWebhookNotFound = errors.New("signal webhook not found")
+	ErrInvalidSignature      = errors.New("invalid signature of signal")
+	ErrInvalidSignal         = errors.New("invalid signal")
+	ErrDuplicateSignal       = errors.New("signal with the same id is accepted already")
+	ErrTooManySignatures     = errors.New("too many signals with invalid signature, try again later")
+)
+
+const (
+	// signalsLogLimit is the largest count of signals returned by GetUserSignals
+	signalsLogLimit = 100
+	// webhookRandomSize is a size in bytes of random token and secret of webhook
+	webhookRandomSize = 32
+	// signaturePrefix may precede hex encoded signature, like in signatures of github webhooks
+	signaturePrefix = "sha256="
+	// maxSignalIDLength is the largest length of client order id on kraken, id of signal becomes one
+	maxSignalIDLength = 100
+	// invalidSignaturesLimit is the largest count of signals with invalid signature logged for token
+	// during invalidSignaturesWindow, the following ones are rejected without logging
+	invalidSignaturesLimit  = 10
+	invalidSignaturesWindow = time.Minute
+
+	signalMarketOrder = "mkt"
+	signalLimitOrder  = "lmt"
+)
+
+// SignalsService turns signals of external tools into orders and trading sessions of users.
+// Signal is accepted when it is signed by secret of webhook and its id is not accepted yet,
+// every received signal is logged with the reason of rejection. Body of signal with invalid signature
+// is not logged and such signals are limited per token
+type SignalsService struct {
+	repo     repository.Signals
+	orders   KrakenOrdersManager
+	sessions TradingSessions
+	now      func() time.Time
+
+	mu                sync.Mutex
+	invalidSignatures map[string]signatureAttempts
+}
+
+// signatureAttempts counts signals with invalid signature received since start of window
+type signatureAttempts struct {
+	since time.Time
+	count int
+}
+
+func NewSignalsService(repo repository.Signals, orders KrakenOrdersManager, sessions TradingSessions) *SignalsService {
+	return &SignalsService{repo: repo, orders: orders, sessions: sessions, now: time.Now,
+		invalidSignatures: make(map[string]signatureAttempts)}
+}
+
+// CreateSignalWebhook generates new token and secret of webhook of user, the previous ones stop working
+func (s *SignalsService) CreateSignalWebhook(userID int) (models.SignalWebhook, error) {
+	token, err := randomHex(webhookRandomSize)
+	if err != nil {
+		return models.SignalWebhook{}, fmt.Errorf("%s: %w", ErrCreateSignalWebhook, err)
+	}
+	secret, err := randomHex(webhookRandomSize)
+	if err != nil {
+		return models.SignalWebhook{}, fmt.Errorf("%s: %w", ErrCreateSignalWebhook, err)
+	}
+
+	webhook := models.SignalWebhook{UserID: userID, Token: token, Secret: secret, CreatedAt: s.now().UTC()}
+	if err := s.repo.SaveSignalWebhook(webhook); err != nil {
+		return models.SignalWebhook{}, fmt.Errorf("%s: %w", ErrCreateSignalWebhook, err)
+	}
+	return webhook, nil
+}
+
+// HandleSignal verifies hex encoded HMAC-SHA256 signature of body by secret of webhook of token
+// and sends order or starts trading session of signal. Logged signal is returned along with error
+// when it is rejected
+func (s *SignalsService) HandleSignal(token, signature string, body []byte) (models.Signal, error) {
+	webhook, err := s.repo.GetSignalWebhook(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Signal{}, fmt.Errorf("%s: %w", ErrHandleSignal, ErrSignalWebhookNotFound)
+	}
+	if err != nil {
+		return models.Signal{}, fmt.Errorf("%s: %w", ErrHandleSignal, err)
+	}
+
+	signal := models.Signal{UserID: webhook.UserID, Payload: string(body), ReceivedAt: s.now().UTC()}
+	if !validSignature(webhook.Secret, signature, body) {
+		if !s.countInvalidSignature(token) {
+			return models.Signal{}, fmt.Errorf("%s: %w", ErrHandleSignal, ErrTooManySignatures)
+		}
+		// body is not signed by user, so it is not kept
+		signal.Payload = ""
+		return s.reject(signal, ErrInvalidSignature)
+	}
+
+	var payload models.SignalPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return s.reject(signal, fmt.Errorf("%w: %s", ErrInvalidSignal, err))
+	}
+	signal.SignalID = payload.ID
+	if err := validateSignal(&payload); err != nil {
+		return s.reject(signal, err)
+	}
+
+	signal.Status = models.SignalPending
+	id, created, err := s.repo.CreateSignal(signal)
+	if err != nil {
+		return models.Signal{}, fmt.Errorf("%s: %w", ErrHandleSignal, err)
+	}
+	if !created {
+		return s.reject(signal, ErrDuplicateSignal)
+	}
+	signal.ID = id
+
+	if payload.StopLoss > 0 || payload.TakeProfit > 0 {
+		var session models.TradingSession
+		session, err = s.sessions.StartSession(webhook.UserID, signalRules(payload).TradingDetails())
+		signal.SessionID = session.ID
+	} else {
+		var order models.Order
+		order, err = s.orders.SendOrder(webhook.UserID, krakenFuturesSDK.SendOrderArguments{
+			OrderType:  payload.OrderType,
+			Symbol:     payload.Symbol,
+			Side:       payload.Side,
+			Size:       payload.Size,
+			LimitPrice: payload.LimitPrice,
+			CliOrderID: payload.ID,
+		})
+		signal.OrderID = order.ID
+	}
+
+	signal.Status = models.SignalAccepted
+	if err != nil {
+		signal.Status, signal.Reason = models.SignalRejected, err.Error()
+	}
+	if updateErr := s.repo.UpdateSignal(signal); updateErr != nil && err == nil {
+		err = updateErr
+	}
+	if err != nil {
+		return signal, fmt.Errorf("%s: %w", ErrHandleSignal, err)
+	}
+	return signal, nil
+}
+
+func (s *SignalsService) GetUserSignals(userID int) ([]models.Signal, error) {
+	return s.repo.GetUserSignals(userID, signalsLogLimit)
+}
+
+// reject logs signal as rejected by reason
+func (s *SignalsService) reject(signal models.Signal, reason error) (models.Signal, error) {
+	signal.Status, signal.Reason = models.SignalRejected, reason.Error()
+
+	id, _, err := s.repo.CreateSignal(signal)
+	if err != nil {
+		return models.Signal{}, fmt.Errorf("%s: %s: %w", ErrHandleSignal, reason, err)
+	}
+	signal.ID = id
+	return signal, fmt.Errorf("%s: %w", ErrHandleSignal, reason)
+}
+
+// countInvalidSignature counts signal with invalid signature of token and returns whether it is within limit
+func (s *SignalsService) countInvalidSignature(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	attempts := s.invalidSignatures[token]
+	if now.Sub(attempts.since) >= invalidSignaturesWindow {
+		attempts = signatureAttempts{since: now}
+	}
+	attempts.count++
+	s.invalidSignatures[token] = attempts
+	return attempts.count <= invalidSignaturesLimit
+}
+
+func validSignature(secret, signature string, body []byte) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), signaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// validateSignal checks payload and sets market order type when it is not given
+func validateSignal(payload *models.SignalPayload) error {
+	payload.Side = strings.ToLower(payload.Side)
+	if payload.OrderType == "" {
+		payload.OrderType = signalMarketOrder
+	}
+
+	switch {
+	case payload.ID == "":
+		return fmt.Errorf("%w: id is required", ErrInvalidSignal)
+	case len(payload.ID) > maxSignalIDLength:
+		return fmt.Errorf("%w: id must be at most %d characters", ErrInvalidSignal, maxSignalIDLength)
+	case payload.Symbol == "":
+		return fmt.Errorf("%w: symbol is required", ErrInvalidSignal)
+	case payload.Side != krakenFuturesSDK.BuySide && payload.Side != krakenFuturesSDK.SellSide:
+		return fmt.Errorf("%w: side must be buy or sell, got %q", ErrInvalidSignal, payload.Side)
+	case payload.Size == 0:
+		return fmt.Errorf("%w: size must be greater than 0", ErrInvalidSignal)
+	case payload.OrderType != signalMarketOrder && payload.OrderType != signalLimitOrder:
+		return fmt.Errorf("%w: order_type must be mkt or lmt, got %q", ErrInvalidSignal, payload.OrderType)
+	case payload.OrderType == signalLimitOrder && payload.LimitPrice <= 0:
+		return fmt.Errorf("%w: limit_price is required by lmt order", ErrInvalidSignal)
+	case payload.StopLoss < 0 || payload.TakeProfit < 0:
+		return fmt.Errorf("%w: stop_loss and take_profit must not be negative", ErrInvalidSignal)
+	case (payload.StopLoss > 0 || payload.TakeProfit > 0) && payload.OrderType != signalMarketOrder:
+		return fmt.Errorf("%w: stop_loss and take_profit are supported by mkt orders only", ErrInvalidSignal)
+	}
+	return nil
+}
+
+// signalRules are rules of trading session which enters right away and exits by stop loss or take profit of signal
+func signalRules(payload models.SignalPayload) types.Rules {
+	return types.Rules{
+		Name:      "signal " + payload.ID,
+		Symbol:    payload.Symbol,
+		Side:      payload.Side,
+		OrderType: payload.OrderType,
+		Exit:      types.ExitRules{StopLoss: payload.StopLoss, TakeProfit: payload.TakeProfit},
+		Sizing:    types.SizingRules{Size: payload.Size},
+	}
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+// signalsRepo keeps signals in memory, pending and accepted signals are unique by id like in postgres
+type signalsRepo struct {
+	webhooks map[string]models.SignalWebhook
+	signals  []models.Signal
+}
+
+func (r *signalsRepo) SaveSignalWebhook(webhook models.SignalWebhook) error {
+	for token, saved := range r.webhooks {
+		if saved.UserID == webhook.UserID {
+			delete(r.webhooks, token)
+		}
+	}
+	r.webhooks[webhook.Token] = webhook
+	return nil
+}
+
+func (r *signalsRepo) GetSignalWebhook(token string) (models.SignalWebhook, error) {
+	webhook, ok := r.webhooks[token]
+	if !ok {
+		return models.SignalWebhook{}, sql.ErrNoRows
+	}
+	return webhook, nil
+}
+
+func (r *signalsRepo) CreateSignal(signal models.Signal) (int, bool, error) {
+	for _, saved := range r.signals {
+		if signal.Status != models.SignalRejected && saved.Status != models.SignalRejected &&
+			saved.UserID == signal.UserID && saved.SignalID == signal.SignalID {
+			return 0, false, nil
+		}
+	}
+	signal.ID = len(r.signals) + 1
+	r.signals = append(r.signals, signal)
+	return signal.ID, true, nil
+}
+
+func (r *signalsRepo) UpdateSignal(signal models.Signal) error {
+	r.signals[signal.ID-1] = signal
+	return nil
+}
+
+func (r *signalsRepo) GetUserSignals(userID, limit int) ([]models.Signal, error) {
+	return r.signals, nil
+}
+
+// signalOrders sends orders by ordersRecorder unless err is set
+type signalOrders struct {
+	ordersRecorder
+	err error
+}
+
+func (o *signalOrders) SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	if o.err != nil {
+		return models.Order{}, o.err
+	}
+	return o.ordersRecorder.SendOrder(userID, args)
+}
+
+// signalSessions records details of started trading sessions
+type signalSessions struct {
+	TradingSessions
+	details []types.TradingDetails
+}
+
+func (s *signalSessions) StartSession(userID int, details types.TradingDetails) (models.TradingSession, error) {
+	s.details = append(s.details, details)
+	return models.TradingSession{ID: len(s.details), UserID: userID, Details: details}, nil
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignalsService_HandleSignal(t *testing.T) {
+	const (
+		marketSignal   = `{"id": "s1", "symbol": "PI_XBTUSD", "side": "BUY", "size": 10}`
+		protectSignal  = `{"id": "s2", "symbol": "PI_XBTUSD", "side": "sell", "size": 5, "stop_loss": 100}`
+		limitSignal    = `{"id": "s3", "symbol": "PI_XBTUSD", "side": "buy", "size": 1, "order_type": "lmt", "limit_price": 50000}`
+		noSizeSignal   = `{"id": "s4", "symbol": "PI_XBTUSD", "side": "buy"}`
+		limitSLSignal  = `{"id": "s5", "symbol": "PI_XBTUSD", "side": "buy", "size": 1, "order_type": "lmt", "limit_price": 1, "take_profit": 5}`
+		brokenSignal   = `{"id": "s6", `
+		riskSignalBody = `{"id": "s7", "symbol": "PI_XBTUSD", "side": "buy", "size": 1000}`
+	)
+	receivedAt := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	riskErr := &RiskRejection{Rule: RiskRuleMaxOrderSize, Reason: "size 1000 is greater than 100"}
+
+	tests := []struct {
+		name        string
+		token       string
+		signature   string
+		body        string
+		ordersErr   error
+		wantErr     error
+		wantSignal  models.Signal
+		wantOrder   *krakenFuturesSDK.SendOrderArguments
+		wantSession *types.TradingDetails
+	}{
+		{
+			name:       "Market order",
+			body:       marketSignal,
+			wantSignal: models.Signal{ID: 1, SignalID: "s1", Status: models.SignalAccepted, OrderID: "order-1"},
+			wantOrder: &krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "buy", Size: 10,
+				CliOrderID: "s1"},
+		},
+		{
+			name:       "Limit order",
+			body:       limitSignal,
+			wantSignal: models.Signal{ID: 1, SignalID: "s3", Status: models.SignalAccepted, OrderID: "order-1"},
+			wantOrder: &krakenFuturesSDK.SendOrderArguments{OrderType: "lmt", Symbol: "PI_XBTUSD", Side: "buy", Size: 1,
+				LimitPrice: 50000, CliOrderID: "s3"},
+		},
+		{
+			name:       "Trading session with stop loss",
+			body:       protectSignal,
+			wantSignal: models.Signal{ID: 1, SignalID: "s2", Status: models.SignalAccepted, SessionID: 1},
+			wantSession: &types.TradingDetails{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "sell", Size: 5,
+				Strategy: types.RulesStrategy, Rules: &types.Rules{Name: "signal s2", Symbol: "PI_XBTUSD", Side: "sell",
+					OrderType: "mkt", Exit: types.ExitRules{StopLoss: 100}, Sizing: types.SizingRules{Size: 5}}},
+		},
+		{
+			name:    "Unknown token",
+			token:   "unknown",
+			body:    marketSignal,
+			wantErr: ErrSignalWebhookNotFound,
+		},
+		{
+			name:       "Invalid signature",
+			signature:  sign("other secret", marketSignal),
+			body:       marketSignal,
+			wantErr:    ErrInvalidSignature,
+			wantSignal: models.Signal{ID: 1, Status: models.SignalRejected, Reason: ErrInvalidSignature.Error()},
+		},
+		{
+			name:       "Signature is not hex",
+			signature:  "signature",
+			body:       marketSignal,
+			wantErr:    ErrInvalidSignature,
+			wantSignal: models.Signal{ID: 1, Status: models.SignalRejected, Reason: ErrInvalidSignature.Error()},
+		},
+		{
+			name:    "Broken JSON",
+			body:    brokenSignal,
+			wantErr: ErrInvalidSignal,
+			wantSignal: models.Signal{ID: 1, Status: models.SignalRejected,
+				Reason: "invalid signal: unexpected end of JSON input"},
+		},
+		{
+			name:    "No size",
+			body:    noSizeSignal,
+			wantErr: ErrInvalidSignal,
+			wantSignal: models.Signal{ID: 1, SignalID: "s4", Status: models.SignalRejected,
+				Reason: "invalid signal: size must be greater than 0"},
+		},
+		{
+			name:    "Stop loss of limit order",
+			body:    limitSLSignal,
+			wantErr: ErrInvalidSignal,
+			wantSignal: models.Signal{ID: 1, SignalID: "s5", Status: models.SignalRejected,
+				Reason: "invalid signal: stop_loss and take_profit are supported by mkt orders only"},
+		},
+		{
+			name:       "Rejected by risk check",
+			body:       riskSignalBody,
+			ordersErr:  riskErr,
+			wantErr:    ErrRiskRejected,
+			wantSignal: models.Signal{ID: 1, SignalID: "s7", Status: models.SignalRejected, Reason: riskErr.Error()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &signalsRepo{webhooks: map[string]models.SignalWebhook{
+				"token": {UserID: 1, Token: "token", Secret: "secret"},
+			}}
+			orders := &signalOrders{err: test.ordersErr}
+			sessions := &signalSessions{}
+			s := NewSignalsService(repo, orders, sessions)
+			s.now = func() time.Time { return receivedAt }
+
+			token, signature := test.token, test.signature
+			if token == "" {
+				token = "token"
+			}
+			if signature == "" {
+				signature = sign("secret", test.body)
+			}
+
+			signal, err := s.HandleSignal(token, signature, []byte(test.body))
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if test.wantSignal.ID != 0 {
+				test.wantSignal.UserID, test.wantSignal.ReceivedAt = 1, receivedAt
+				// body of signal with invalid signature is not kept
+				if !errors.Is(test.wantErr, ErrInvalidSignature) {
+					test.wantSignal.Payload = test.body
+				}
+				assert.Equal(t, test.wantSignal, signal)
+				assert.Equal(t, []models.Signal{test.wantSignal}, repo.signals)
+			} else {
+				assert.Empty(t, repo.signals)
+			}
+
+			if test.wantOrder != nil {
+				assert.Equal(t, []krakenFuturesSDK.SendOrderArguments{*test.wantOrder}, orders.args)
+			} else {
+				assert.Empty(t, orders.args)
+			}
+			if test.wantSession != nil {
+				assert.Equal(t, []types.TradingDetails{*test.wantSession}, sessions.details)
+			} else {
+				assert.Empty(t, sessions.details)
+			}
+		})
+	}
+}
+
+func TestSignalsService_HandleSignal_Duplicate(t *testing.T) {
+	repo := &signalsRepo{webhooks: map[string]models.SignalWebhook{
+		"token": {UserID: 1, Token: "token", Secret: "secret"},
+	}}
+	orders := &signalOrders{err: errors.New("exchange is down")}
+	s := NewSignalsService(repo, orders, &signalSessions{})
+
+	body := `{"id": "s1", "symbol": "PI_XBTUSD", "side": "buy", "size": 1}`
+	send := func() (models.Signal, error) {
+		return s.HandleSignal("token", sign("secret", body), []byte(body))
+	}
+
+	// rejected signal may be sent again
+	signal, err := send()
+	assert.Error(t, err)
+	assert.Equal(t, models.SignalRejected, signal.Status)
+
+	orders.err = nil
+	signal, err = send()
+	assert.NoError(t, err)
+	assert.Equal(t, models.SignalAccepted, signal.Status)
+
+	signal, err = send()
+	assert.ErrorIs(t, err, ErrDuplicateSignal)
+	assert.Equal(t, models.SignalRejected, signal.Status)
+
+	var statuses []string
+	for _, logged := range repo.signals {
+		statuses = append(statuses, fmt.Sprintf("%d %s", logged.ID, logged.Status))
+	}
+	assert.Equal(t, []string{"1 rejected", "2 accepted", "3 rejected"}, statuses)
+	assert.Len(t, orders.args, 1)
+}
+
+func TestSignalsService_HandleSignal_InvalidSignatureLimit(t *testing.T) {
+	repo := &signalsRepo{webhooks: map[string]models.SignalWebhook{
+		"token": {UserID: 1, Token: "token", Secret: "secret"},
+		"other": {UserID: 2, Token: "other", Secret: "other secret"},
+	}}
+	orders := &signalOrders{}
+	s := NewSignalsService(repo, orders, &signalSessions{})
+	now := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	body := `{"id": "s1", "symbol": "PI_XBTUSD", "side": "buy", "size": 1}`
+	for i := 0; i < invalidSignaturesLimit; i++ {
+		_, err := s.HandleSignal("token", "sha256=abc", []byte(body))
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	}
+
+	// signals over limit are not logged
+	_, err := s.HandleSignal("token", "sha256=abc", []byte(body))
+	assert.ErrorIs(t, err, ErrTooManySignatures)
+	assert.Len(t, repo.signals, invalidSignaturesLimit)
+
+	// other tokens and signed signals are not limited
+	_, err = s.HandleSignal("other", "sha256=abc", []byte(body))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	signal, err := s.HandleSignal("token", sign("secret", body), []byte(body))
+	assert.NoError(t, err)
+	assert.Equal(t, models.SignalAccepted, signal.Status)
+
+	now = now.Add(invalidSignaturesWindow)
+	_, err = s.HandleSignal("token", "sha256=abc", []byte(body))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	assert.Len(t, repo.signals, invalidSignaturesLimit+3)
+
+	for _, logged := range repo.signals {
+		if logged.Status == models.SignalRejected {
+			assert.Empty(t, logged.Payload)
+		}
+	}
+}
+
+func TestSignalsService_CreateSignalWebhook(t *testing.T) {
+	repo := &signalsRepo{webhooks: map[string]models.SignalWebhook{}}
+	s := NewSignalsService(repo, &signalOrders{}, &signalSessions{})
+
+	first, err := s.CreateSignalWebhook(1)
+	assert.NoError(t, err)
+	assert.Len(t, first.Token, 64)
+	assert.Len(t, first.Secret, 64)
+	assert.NotEqual(t, first.Token, first.Secret)
+
+	second, err := s.CreateSignalWebhook(1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Token, second.Token)
+
+	_, err = s.HandleSignal(first.Token, "", nil)
+	assert.ErrorIs(t, err, ErrSignalWebhookNotFound)
+	assert.Equal(t, map[string]models.SignalWebhook{second.Token: second}, repo.webhooks)
+}
@@ -0,0 +1,792 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/algorithms"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrStartSession           = errors.New("start trading session")
+	ErrInvalidTradingDetails  = errors.New("invalid trading details")
+	ErrCancelSession          = errors.New("cancel trading session")
+	ErrGetSession             = errors.New("get trading session")
+	ErrResumeSessions         = errors.New("resume trading sessions")
+	ErrSessionNotFound        = errors.New("trading session not found")
+	ErrSessionNotActive       = errors.New("trading session is not active")
+	ErrSessionNotRunning      = errors.New("trading session is not running")
+	ErrEntryOrderNotFound     = errors.New("entry order is not found on exchange")
+	ErrUnableToSendCloseOrder = errors.New("unable to send closing order")
+	ErrUnableToPlaceBracket   = errors.New("unable to place bracket orders, position is closed by strategy only")
+	ErrPaperSessionLost       = errors.New("simulated exchange of paper trading session is lost with restart of server")
+)
+
+const (
+	sessionRetryDelay           = 5 * time.Second
+	sessionCloseOrderRetries    = 5
+	sessionBracketCheckInterval = 10 * time.Second
+	sessionLease                = time.Minute
+	sessionLeaseRenewInterval   = 20 * time.Second
+
+	// client order ids of entry and closing orders of session, they let supervisor find the order
+	// which exchange has accepted while response to sending is lost
+	entryOrderIDFormat = "session-%d-entry"
+	exitOrderIDFormat  = "session-%d-exit"
+
+	stopLossOrderType   = "stp"
+	takeProfitOrderType = "take_profit"
+)
+
+// runningSession is a trading session watched by goroutine of supervisor
+type runningSession struct {
+	cancel    context.CancelFunc
+	done      chan struct{}
+	cancelled bool
+	// abandoned is set when lease of session is taken over by other replica, so session is left to it
+	abandoned bool
+}
+
+// SessionSupervisor runs trading sessions in background independently of clients which started them.
+// Every session is saved, so supervisor resumes watching of open positions after restart and always
+// sends closing order. Sessions are closed only by strategy or by cancel, stop of supervisor leaves
+// positions open until the next start.
+//
+// In bracket mode stop loss and take profit orders rest on exchange while session is watched, so they
+// protect position when server is down. Fills of both orders are checked while strategy runs and right
+// after resume, so the first filled order closes session at its fill price. When it happens, strategy
+// exits or session is cancelled both orders are cancelled, the one which is not found any more and has fills
+// closes session instead of market order (one cancels the other)
+//
+// Entry and closing orders are sent with client order ids of session, so the order which is accepted while
+// response to sending is lost is found on exchange instead of being sent again. Closing order is reduce only,
+// so it never reverses position. Every replica watches only sessions which it holds leases of, sessions of
+// stopped replica are taken over when their leases expire. Paper trading sessions are failed instead of
+// resume, since simulated exchange with their positions is kept in memory of replica only
+type SessionSupervisor struct {
+	repo                 repository.TradingSessions
+	settings             repository.Settings
+	orders               KrakenOrdersManager
+	risk                 Risk
+	notifier             Notifier
+	trader               tradeAlgorithm.Strategies
+	retryDelay           time.Duration
+	bracketCheckInterval time.Duration
+	owner                string
+	lease                time.Duration
+	leaseRenewInterval   time.Duration
+
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[int]*runningSession
+}
+
+func NewSessionSupervisor(repo repository.TradingSessions, settings repository.Settings, orders KrakenOrdersManager,
+	risk Risk, notifier Notifier, trader tradeAlgorithm.Strategies) *SessionSupervisor {
+	ctx, stop := context.WithCancel(context.Background())
+	return &SessionSupervisor{
+		repo:                 repo,
+		settings:             settings,
+		orders:               orders,
+		risk:                 risk,
+		notifier:             notifier,
+		trader:               trader,
+		retryDelay:           sessionRetryDelay,
+		bracketCheckInterval: sessionBracketCheckInterval,
+		owner:                leaseOwner(),
+		lease:                sessionLease,
+		leaseRenewInterval:   sessionLeaseRenewInterval,
+		ctx:                  ctx,
+		stop:                 stop,
+		running:              make(map[int]*runningSession),
+	}
+}
+
+// leaseOwner returns name of replica which holds leases of its sessions
+func leaseOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+}
+
+// StartSession sends entry order and starts watching of position in background. Entry order is checked
+// by risk limits of user, while orders which protect and close position are sent without risk check.
+// Session of rules with entry conditions is returned right away, entry order is sent in background
+// when the conditions are met. Session stays starting when it is unknown whether entry order has reached
+// exchange, its entry order is looked for in background then
+func (s *SessionSupervisor) StartSession(userID int, details types.TradingDetails) (models.TradingSession, error) {
+	details, err := s.trader.ValidateDetails(details)
+	if err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w: %s", ErrStartSession, ErrInvalidTradingDetails, err)
+	}
+	if err := s.risk.CheckSession(userID); err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStartSession, err)
+	}
+	settings, err := s.settings.GetUserSettings(userID)
+	if err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStartSession, err)
+	}
+
+	session := models.TradingSession{UserID: userID, Status: models.SessionStarting, Details: details,
+		PaperTrading: settings.PaperTrading}
+	if details.WaitsForEntry() {
+		session.Status = models.SessionWaitingEntry
+	}
+	session.ID, err = s.repo.CreateSession(session, s.owner, s.lease)
+	if err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStartSession, err)
+	}
+
+	if session.Status == models.SessionWaitingEntry {
+		s.launch(session, false)
+		return session, nil
+	}
+
+	if err := s.enter(&session); err != nil && session.Status == models.SessionFailed {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStartSession, err)
+	}
+	s.launch(session, false)
+	return session, nil
+}
+
+// enter sends entry order of session and saves opened position. When sending fails, entry order is looked for
+// on exchange: position is opened when it is found and session fails when it is not. Session stays starting
+// when exchange can not be asked
+func (s *SessionSupervisor) enter(session *models.TradingSession) error {
+	entryOrder, err := s.orders.SendOrder(session.UserID, krakenFuturesSDK.SendOrderArguments{
+		OrderType:  session.Details.OrderType,
+		Symbol:     session.Details.Symbol,
+		Side:       session.Details.Side,
+		Size:       session.Details.Size,
+		CliOrderID: fmt.Sprintf(entryOrderIDFormat, session.ID),
+	})
+	if err == nil {
+		s.open(session, entryOrder)
+		return nil
+	}
+
+	var rejection *RiskRejection
+	if errors.As(err, &rejection) {
+		// order is rejected before sending
+		s.failSession(session, err)
+		return err
+	}
+
+	log.Warnf("trading session %d: %s", session.ID, err)
+	if s.findEntry(session, err) && session.Status == models.SessionMonitoring {
+		return nil
+	}
+	return err
+}
+
+// findEntry looks for entry order of session on exchange. Position is opened when order is found and session
+// fails with notFoundErr when it is not. False is returned when exchange can not be asked
+func (s *SessionSupervisor) findEntry(session *models.TradingSession, notFoundErr error) bool {
+	entryOrder, ok, err := s.orders.FindOrder(session.UserID, fmt.Sprintf(entryOrderIDFormat, session.ID))
+	if err != nil {
+		log.Warnf("trading session %d: %s", session.ID, err)
+		return false
+	}
+
+	if ok {
+		s.open(session, entryOrder)
+	} else {
+		s.failSession(session, notFoundErr)
+	}
+	return true
+}
+
+// resolveEntry looks for entry order of session which is starting after restart or failed sending, until
+// exchange answers or ctx is done. It returns false when position is not opened
+func (s *SessionSupervisor) resolveEntry(ctx context.Context, session *models.TradingSession) bool {
+	for !s.findEntry(session, ErrEntryOrderNotFound) {
+		if !s.sleep(ctx) {
+			return false
+		}
+	}
+	return session.Status == models.SessionMonitoring
+}
+
+// open saves opened position of session and protects it by bracket orders in bracket mode
+func (s *SessionSupervisor) open(session *models.TradingSession, entryOrder models.Order) {
+	// position is opened, so from now on session must be watched and closed whatever happens
+	s.openSession(session, entryOrder)
+	if session.Details.Bracket {
+		s.placeBracket(session)
+	}
+}
+
+func (s *SessionSupervisor) failSession(session *models.TradingSession, err error) {
+	session.Status = models.SessionFailed
+	session.Error = err.Error()
+	s.saveSession(*session)
+	s.notifier.Notify(sessionEvent(*session, models.EventSessionError))
+}
+
+// waitForEntry waits until entry conditions of session are met and sends its entry order. It returns false
+// when position is not opened: session is cancelled, failed or it is not watched any more. True is returned
+// for session which stays starting, since its position may be opened
+func (s *SessionSupervisor) waitForEntry(ctx context.Context, session *models.TradingSession, running *runningSession) bool {
+	for !s.isCancelled(running) {
+		err := s.trader.WaitForEntry(ctx, session.Details)
+		if s.isStopped(running) {
+			return false
+		}
+		if s.isCancelled(running) {
+			break
+		}
+		if err != nil {
+			log.Warnf("trading session %d: %s", session.ID, err)
+			s.sleep(ctx)
+			continue
+		}
+
+		session.Status = models.SessionStarting
+		s.saveSession(*session)
+		if err := s.enter(session); err != nil {
+			log.Warnf("trading session %d: %s", session.ID, err)
+		}
+		return session.Status != models.SessionFailed
+	}
+
+	// position is not opened yet, so there is nothing to close
+	session.Status = models.SessionCancelled
+	s.saveSession(*session)
+	return false
+}
+
+func (s *SessionSupervisor) openSession(session *models.TradingSession, entryOrder models.Order) {
+	entryTime := entryOrder.Timestamp
+	if entryTime.IsZero() {
+		entryTime = time.Now().UTC()
+	}
+
+	session.Status = models.SessionMonitoring
+	session.EntryOrderID = entryOrder.ID
+	session.EntryPrice = entryOrder.Price
+	session.EntryTime = &entryTime
+	session.Details.BuyPrice = entryOrder.Price
+
+	s.saveSession(*session)
+}
+
+// placeBracket places reduce only stop loss and take profit orders opposite to entry order.
+// Session goes on when they can not be placed, its position is closed by strategy then
+func (s *SessionSupervisor) placeBracket(session *models.TradingSession) {
+	stopLossPrice, takeProfitPrice := bracketPrices(*session)
+
+	args := krakenFuturesSDK.SendOrderArguments{
+		OrderType:     stopLossOrderType,
+		Symbol:        session.Details.Symbol,
+		Side:          session.Details.Side,
+		Size:          session.Details.Size,
+		StopPrice:     stopLossPrice,
+		TriggerSignal: session.Details.TriggerSignal,
+		ReduceOnly:    true,
+	}
+	args.ChangeToOpositeOrderSide()
+
+	stopLoss, err := s.orders.SendExitOrder(session.UserID, args)
+	if err != nil {
+		session.Error = fmt.Sprintf("%s: %s", ErrUnableToPlaceBracket, err)
+		s.saveSession(*session)
+		s.notifier.Notify(sessionEvent(*session, models.EventSessionError))
+		return
+	}
+	session.StopLossOrderID = stopLoss.ID
+	s.saveSession(*session)
+
+	args.OrderType = takeProfitOrderType
+	args.StopPrice = takeProfitPrice
+	takeProfit, err := s.orders.SendExitOrder(session.UserID, args)
+	if err != nil {
+		session.Error = fmt.Sprintf("%s: %s", ErrUnableToPlaceBracket, err)
+		s.saveSession(*session)
+		s.notifier.Notify(sessionEvent(*session, models.EventSessionError))
+		return
+	}
+	session.TakeProfitOrderID = takeProfit.ID
+	s.saveSession(*session)
+}
+
+// bracketPrices returns stop prices of stop loss and take profit orders by borders of strategy
+func bracketPrices(session models.TradingSession) (float64, float64) {
+	stopLoss := session.Details.Parameters[algorithms.StopLossBorderParameter]
+	takeProfit := session.Details.Parameters[algorithms.TakeProfitBorderParameter]
+
+	if session.Details.Side == krakenFuturesSDK.BuySide {
+		return session.EntryPrice - stopLoss, session.EntryPrice + takeProfit
+	}
+	return session.EntryPrice + stopLoss, session.EntryPrice - takeProfit
+}
+
+// cancelBracket cancels orders of bracket and returns the filled one with its fill price.
+// Filled order is not found on exchange, so it is left when both orders are cancelled. Order which
+// is not found and has no fills has been cancelled by user, so position is closed by closing order
+func (s *SessionSupervisor) cancelBracket(session models.TradingSession) (models.Order, bool) {
+	var filled models.Order
+	for _, orderID := range []string{session.StopLossOrderID, session.TakeProfitOrderID} {
+		if orderID == "" {
+			continue
+		}
+
+		_, err := s.orders.CancelOrder(session.UserID, krakenFuturesSDK.CancelOrderArguments{OrderID: orderID})
+		if err == nil || filled.ID != "" {
+			continue
+		}
+		if !errors.Is(err, ErrOrderNotActive) {
+			log.Warnf("trading session %d: %s", session.ID, err)
+			continue
+		}
+
+		price, ok, err := s.orders.GetOrderFillPrice(session.UserID, orderID)
+		if err != nil {
+			log.Warnf("trading session %d: %s", session.ID, err)
+			continue
+		}
+		if ok {
+			filled = models.Order{ID: orderID, Price: price}
+		}
+	}
+	return filled, filled.ID != ""
+}
+
+// bracketFilled reports whether one of bracket orders of session has been filled
+func (s *SessionSupervisor) bracketFilled(session models.TradingSession) bool {
+	for _, orderID := range []string{session.StopLossOrderID, session.TakeProfitOrderID} {
+		if orderID == "" {
+			continue
+		}
+
+		_, ok, err := s.orders.GetOrderFillPrice(session.UserID, orderID)
+		if err != nil {
+			log.Warnf("trading session %d: %s", session.ID, err)
+			continue
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// analyze runs strategy of session until it exits. In bracket mode orders of bracket are checked right
+// away and then every bracket check interval, strategy is stopped and true is returned when one of them is filled
+func (s *SessionSupervisor) analyze(ctx context.Context, session models.TradingSession) (bool, error) {
+	if session.StopLossOrderID == "" && session.TakeProfitOrderID == "" {
+		return false, s.trader.StartAnalyzing(ctx, *session.EntryTime, session.Details)
+	}
+
+	analyzeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	filled := make(chan struct{})
+	checked := make(chan struct{})
+	go func() {
+		defer close(checked)
+		for {
+			if s.bracketFilled(session) {
+				close(filled)
+				cancel()
+				return
+			}
+			select {
+			case <-time.After(s.bracketCheckInterval):
+			case <-analyzeCtx.Done():
+				return
+			}
+		}
+	}()
+
+	err := s.trader.StartAnalyzing(analyzeCtx, *session.EntryTime, session.Details)
+	cancel()
+	<-checked
+
+	select {
+	case <-filled:
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// WaitSession waits until session is finished or ctx is done and returns its last state
+func (s *SessionSupervisor) WaitSession(ctx context.Context, userID, sessionID int) (models.TradingSession, error) {
+	s.mu.Lock()
+	running, ok := s.running[sessionID]
+	s.mu.Unlock()
+
+	if ok {
+		select {
+		case <-running.done:
+		case <-ctx.Done():
+		}
+	}
+
+	return s.GetSession(userID, sessionID)
+}
+
+func (s *SessionSupervisor) GetSession(userID, sessionID int) (models.TradingSession, error) {
+	session, err := s.repo.GetSession(sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrGetSession, ErrSessionNotFound)
+	}
+	if err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrGetSession, err)
+	}
+	if session.UserID != userID {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrGetSession, ErrSessionNotFound)
+	}
+	return session, nil
+}
+
+func (s *SessionSupervisor) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	return s.repo.GetUserSessions(userID)
+}
+
+// CancelSession stops watching of position by strategy and closes it
+func (s *SessionSupervisor) CancelSession(userID, sessionID int) (models.TradingSession, error) {
+	session, err := s.GetSession(userID, sessionID)
+	if err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrCancelSession, err)
+	}
+	if !session.IsActive() {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrCancelSession, ErrSessionNotActive)
+	}
+
+	s.mu.Lock()
+	running, ok := s.running[sessionID]
+	if ok {
+		running.cancelled = true
+		running.cancel()
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrCancelSession, ErrSessionNotRunning)
+	}
+
+	session.Status = models.SessionCancelling
+	return session, nil
+}
+
+// ResumeSessions continues watching of active sessions which are not watched by other replicas, e.g. sessions
+// which were active when server stopped. Then leases of watched sessions are renewed in background, while
+// sessions of replicas which have stopped renewing their leases are taken over
+func (s *SessionSupervisor) ResumeSessions() error {
+	if err := s.resumeSessions(); err != nil {
+		return err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.keepLeases()
+	}()
+	return nil
+}
+
+func (s *SessionSupervisor) resumeSessions() error {
+	sessions, err := s.repo.GetActiveSessions()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrResumeSessions, err)
+	}
+
+	for _, session := range sessions {
+		s.mu.Lock()
+		_, ok := s.running[session.ID]
+		s.mu.Unlock()
+		if ok {
+			continue
+		}
+
+		claimed, err := s.repo.ClaimSession(session.ID, s.owner, s.lease)
+		if err != nil {
+			log.Warnf("trading session %d: %s", session.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		// session may have been finished by its previous owner since it was read
+		current, err := s.repo.GetSession(session.ID)
+		if err != nil {
+			log.Warnf("trading session %d: %s", session.ID, err)
+		}
+		if err != nil || !current.IsActive() {
+			s.releaseSession(session.ID)
+			continue
+		}
+		session = current
+
+		// simulated exchange is kept in memory of replica which has started session, so its orders and position are lost
+		if session.PaperTrading {
+			s.failSession(&session, ErrPaperSessionLost)
+			s.releaseSession(session.ID)
+			continue
+		}
+
+		if session.EntryTime == nil && session.Status != models.SessionWaitingEntry && session.Status != models.SessionStarting {
+			entryTime := session.CreatedAt
+			session.EntryTime = &entryTime
+		}
+
+		log.Infof("resume trading session %d with status %s", session.ID, session.Status)
+		s.launch(session, session.Status == models.SessionCancelling)
+	}
+
+	return nil
+}
+
+// keepLeases renews leases of watched sessions and takes over sessions with expired leases every
+// lease renew interval until supervisor is stopped. Session is left when its lease is lost
+func (s *SessionSupervisor) keepLeases() {
+	for {
+		select {
+		case <-time.After(s.leaseRenewInterval):
+		case <-s.ctx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		running := make(map[int]*runningSession, len(s.running))
+		for sessionID, session := range s.running {
+			running[sessionID] = session
+		}
+		s.mu.Unlock()
+
+		for sessionID, session := range running {
+			renewed, err := s.repo.RenewSession(sessionID, s.owner, s.lease)
+			if err != nil {
+				log.Warnf("trading session %d: %s", sessionID, err)
+				continue
+			}
+			if !renewed {
+				log.Warnf("trading session %d is taken over by other replica", sessionID)
+				s.mu.Lock()
+				session.abandoned = true
+				session.cancel()
+				s.mu.Unlock()
+			}
+		}
+
+		if err := s.resumeSessions(); err != nil {
+			log.Warn(err)
+		}
+	}
+}
+
+// StopSessions stops watching of sessions without closing their positions
+func (s *SessionSupervisor) StopSessions() {
+	s.stop()
+	s.wg.Wait()
+}
+
+func (s *SessionSupervisor) launch(session models.TradingSession, cancelled bool) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	running := &runningSession{cancel: cancel, done: make(chan struct{}), cancelled: cancelled}
+
+	s.mu.Lock()
+	if _, ok := s.running[session.ID]; ok {
+		s.mu.Unlock()
+		cancel()
+		return
+	}
+	s.running[session.ID] = running
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer close(running.done)
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, session.ID)
+			abandoned := running.abandoned
+			s.mu.Unlock()
+			cancel()
+
+			if !abandoned {
+				s.releaseSession(session.ID)
+			}
+		}()
+
+		s.run(ctx, session, running)
+	}()
+}
+
+func (s *SessionSupervisor) run(ctx context.Context, session models.TradingSession, running *runningSession) {
+	if session.Status == models.SessionWaitingEntry && !s.waitForEntry(ctx, &session, running) {
+		return
+	}
+	if session.Status == models.SessionStarting && !s.resolveEntry(ctx, &session) {
+		return
+	}
+
+	for session.Status == models.SessionMonitoring && !s.isCancelled(running) {
+		filled, err := s.analyze(ctx, session)
+		if s.isStopped(running) {
+			return
+		}
+		if s.isCancelled(running) && !filled {
+			break
+		}
+		if err != nil {
+			log.Warnf("trading session %d: %s", session.ID, err)
+			s.sleep(ctx)
+			continue
+		}
+
+		session.Status = models.SessionClosing
+		s.saveSession(session)
+	}
+
+	if s.isCancelled(running) && session.Status != models.SessionCancelling {
+		session.Status = models.SessionCancelling
+		s.saveSession(session)
+	}
+
+	s.closeSession(session)
+}
+
+// closeSession sends order opposite to entry order unless one of bracket orders has closed position already.
+// Closing order is reduce only, since bracket order may have been filled when its cancel failed. Before every
+// attempt closing order is looked for on exchange, so the order sent by previous attempt or before restart
+// is not sent again
+func (s *SessionSupervisor) closeSession(session models.TradingSession) {
+	if filled, ok := s.cancelBracket(session); ok {
+		s.finishSession(session, filled)
+		return
+	}
+
+	args := krakenFuturesSDK.SendOrderArguments{
+		OrderType:  session.Details.OrderType,
+		Symbol:     session.Details.Symbol,
+		Side:       session.Details.Side,
+		Size:       session.Details.Size,
+		CliOrderID: fmt.Sprintf(exitOrderIDFormat, session.ID),
+		ReduceOnly: true,
+	}
+	args.ChangeToOpositeOrderSide()
+
+	var err error
+	for attempt := 0; attempt < sessionCloseOrderRetries; attempt++ {
+		if attempt > 0 && !s.sleep(s.ctx) {
+			return
+		}
+
+		exitOrder, ok, findErr := s.orders.FindOrder(session.UserID, args.CliOrderID)
+		if findErr != nil {
+			// reduce only order can't reverse position, so it is sent even when it is unknown whether it was sent
+			log.Warnf("trading session %d: %s", session.ID, findErr)
+		}
+		if ok {
+			s.finishSession(session, exitOrder)
+			return
+		}
+
+		exitOrder, err = s.orders.SendExitOrder(session.UserID, args)
+		if err != nil {
+			log.Warnf("trading session %d: %s: %s", session.ID, ErrUnableToSendCloseOrder, err)
+			continue
+		}
+
+		s.finishSession(session, exitOrder)
+		return
+	}
+
+	session.Status = models.SessionFailed
+	session.Error = fmt.Sprintf("%s: %s", ErrUnableToSendCloseOrder, err)
+	s.saveSession(session)
+	s.notifier.Notify(sessionEvent(session, models.EventSessionError))
+}
+
+func (s *SessionSupervisor) finishSession(session models.TradingSession, exitOrder models.Order) {
+	session.ExitOrderID = exitOrder.ID
+	session.ExitPrice = exitOrder.Price
+	if session.Status == models.SessionCancelling {
+		session.Status = models.SessionCancelled
+	} else {
+		session.Status = models.SessionClosed
+	}
+	s.saveSession(session)
+	s.notifier.Notify(sessionEvent(session, closedSessionEvent(session)))
+}
+
+// closedSessionEvent tells whether position of session is closed by stop loss or take profit. Filled bracket order
+// tells it in bracket mode, otherwise stop loss & take profit strategy exits with loss only by stop loss
+func closedSessionEvent(session models.TradingSession) string {
+	switch {
+	case session.Status != models.SessionClosed:
+		return models.EventSessionClosed
+	case session.StopLossOrderID != "" && session.ExitOrderID == session.StopLossOrderID:
+		return models.EventStopLossHit
+	case session.TakeProfitOrderID != "" && session.ExitOrderID == session.TakeProfitOrderID:
+		return models.EventTakeProfitHit
+	case session.Details.Strategy != algorithms.StopLossTakeProfitName:
+		return models.EventSessionClosed
+	}
+
+	profit := session.ExitPrice - session.EntryPrice
+	if session.Details.Side != krakenFuturesSDK.BuySide {
+		profit = -profit
+	}
+	if profit < 0 {
+		return models.EventStopLossHit
+	}
+	return models.EventTakeProfitHit
+}
+
+func (s *SessionSupervisor) isCancelled(running *runningSession) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return running.cancelled
+}
+
+// isStopped reports whether session is not watched any more: supervisor is stopped or session is abandoned
+func (s *SessionSupervisor) isStopped(running *runningSession) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx.Err() != nil || running.abandoned
+}
+
+// sleep waits for retry delay and returns false when ctx is done before
+func (s *SessionSupervisor) sleep(ctx context.Context) bool {
+	select {
+	case <-time.After(s.retryDelay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// releaseSession drops lease of session, so other replicas may take it over right away
+func (s *SessionSupervisor) releaseSession(sessionID int) {
+	if err := s.repo.ReleaseSession(sessionID, s.owner); err != nil {
+		log.Warnf("trading session %d: %s", sessionID, err)
+	}
+}
+
+func (s *SessionSupervisor) saveSession(session models.TradingSession) {
+	if err := s.repo.UpdateSession(session); err != nil {
+		log.Errorf("trading session %d: %s", session.ID, err)
+	}
+}
@@ -0,0 +1,783 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+// sessionsRepo keeps owners of leases, leases never expire
+type sessionsRepo struct {
+	mu       sync.Mutex
+	sessions map[int]models.TradingSession
+	leases   map[int]string
+}
+
+func newSessionsRepo(sessions ...models.TradingSession) *sessionsRepo {
+	r := &sessionsRepo{sessions: make(map[int]models.TradingSession), leases: make(map[int]string)}
+	for _, session := range sessions {
+		r.sessions[session.ID] = session
+	}
+	return r
+}
+
+func (r *sessionsRepo) CreateSession(session models.TradingSession, owner string, lease time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session.ID = len(r.sessions) + 1
+	r.sessions[session.ID] = session
+	r.leases[session.ID] = owner
+	return session.ID, nil
+}
+
+func (r *sessionsRepo) ClaimSession(sessionID int, owner string, lease time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.leases[sessionID]; ok {
+		return false, nil
+	}
+	r.leases[sessionID] = owner
+	return true, nil
+}
+
+func (r *sessionsRepo) RenewSession(sessionID int, owner string, lease time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leases[sessionID] == owner, nil
+}
+
+func (r *sessionsRepo) ReleaseSession(sessionID int, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.leases[sessionID] == owner {
+		delete(r.leases, sessionID)
+	}
+	return nil
+}
+
+func (r *sessionsRepo) lease(sessionID int) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	owner, ok := r.leases[sessionID]
+	return owner, ok
+}
+
+func (r *sessionsRepo) UpdateSession(session models.TradingSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *sessionsRepo) GetSession(sessionID int) (models.TradingSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return models.TradingSession{}, sql.ErrNoRows
+	}
+	return session, nil
+}
+
+func (r *sessionsRepo) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []models.TradingSession
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *sessionsRepo) GetActiveSessions() ([]models.TradingSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []models.TradingSession
+	for _, session := range r.sessions {
+		if session.IsActive() {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// ordersRecorder executes market orders at price growing by 100 with each order
+// and places the others at their stop price, orders from filled are filled at their price
+// and they are not found on cancel like cancelled orders from inactive. Orders with numbers from failSends fail, exchange accepts them
+// when lost is set. Orders accepted by exchange are found by client order id
+type ordersRecorder struct {
+	mu        sync.Mutex
+	sides     []string
+	args      []krakenFuturesSDK.SendOrderArguments
+	cancelled []string
+	filled    map[string]float64
+	inactive  map[string]bool
+	failSends map[int]bool
+	lost      bool
+	exchange  map[string]models.Order
+	findErr   error
+}
+
+func (o *ordersRecorder) SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sides = append(o.sides, args.Side)
+	o.args = append(o.args, args)
+
+	price := float64(100 * len(o.sides))
+	if args.StopPrice != 0 {
+		price = args.StopPrice
+	}
+	order := models.Order{
+		ID:            fmt.Sprintf("order-%d", len(o.sides)),
+		ClientOrderID: args.CliOrderID,
+		Side:          args.Side,
+		Price:         price,
+		Timestamp:     time.Unix(1640000000, 0).UTC(),
+	}
+
+	if o.failSends[len(o.sides)] {
+		if o.lost {
+			o.accept(order)
+		}
+		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, sql.ErrConnDone)
+	}
+	o.accept(order)
+	return order, nil
+}
+
+func (o *ordersRecorder) accept(order models.Order) {
+	if order.ClientOrderID == "" {
+		return
+	}
+	if o.exchange == nil {
+		o.exchange = make(map[string]models.Order)
+	}
+	o.exchange[order.ClientOrderID] = order
+}
+
+func (o *ordersRecorder) setFindErr(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.findErr = err
+}
+
+func (o *ordersRecorder) FindOrder(userID int, cliOrderID string) (models.Order, bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.findErr != nil {
+		return models.Order{}, false, o.findErr
+	}
+	order, ok := o.exchange[cliOrderID]
+	return order, ok, nil
+}
+
+func (o *ordersRecorder) SendExitOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	return o.SendOrder(userID, args)
+}
+
+func (o *ordersRecorder) CancelOrder(userID int, args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cancelled = append(o.cancelled, args.OrderID)
+	if _, ok := o.filled[args.OrderID]; ok || o.inactive[args.OrderID] {
+		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrderServiceMethod, ErrOrderNotActive)
+	}
+	return krakenFuturesSDK.CancelStatus{Status: "cancelled", OrderID: args.OrderID}, nil
+}
+
+func (o *ordersRecorder) EditOrder(userID int, args krakenFuturesSDK.EditOrderArguments) (models.Order, error) {
+	return models.Order{}, nil
+}
+
+func (o *ordersRecorder) CancelAllOrders(userID int, symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
+	return krakenFuturesSDK.CancelAllStatus{}, nil
+}
+
+func (o *ordersRecorder) GetOrderFillPrice(userID int, orderID string) (float64, bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	price, ok := o.filled[orderID]
+	return price, ok, nil
+}
+
+func (o *ordersRecorder) sent() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.sides...)
+}
+
+func (o *ordersRecorder) GetUserOrders(userID int, filter models.OrdersFilter, cursor string) (models.OrdersPage, error) {
+	return models.OrdersPage{}, nil
+}
+
+func (o *ordersRecorder) GetStrategies() []types.StrategySchema {
+	return nil
+}
+
+func (o *ordersRecorder) WatchOpenOrders() error {
+	return nil
+}
+
+func (o *ordersRecorder) StopWatchingOrders() {}
+
+// blockingTrader exits position right away or waits until context is done when block is set,
+// entry conditions are met by message of entries
+type blockingTrader struct {
+	block   bool
+	started chan struct{}
+	entries chan struct{}
+}
+
+func (b *blockingTrader) WaitForEntry(ctx context.Context, details types.TradingDetails) error {
+	if !details.WaitsForEntry() {
+		return nil
+	}
+	select {
+	case <-b.entries:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *blockingTrader) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	if !b.block {
+		return nil
+	}
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *blockingTrader) ValidateDetails(details types.TradingDetails) (types.TradingDetails, error) {
+	return details, nil
+}
+
+func (b *blockingTrader) Schemas() []types.StrategySchema {
+	return nil
+}
+
+var testTradingDetails = types.TradingDetails{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: 1}
+
+func TestSessionSupervisor_StartSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		cancel     bool
+		wantStatus string
+	}{
+		{name: "Closed by strategy", wantStatus: models.SessionClosed},
+		{name: "Cancelled", cancel: true, wantStatus: models.SessionCancelled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orders := &ordersRecorder{}
+			trader := &blockingTrader{block: test.cancel, started: make(chan struct{}, 1)}
+			supervisor := NewSessionSupervisor(newSessionsRepo(), usersKeys{}, orders, noRisk{}, &notifyRecorder{}, trader)
+			defer supervisor.StopSessions()
+
+			session, err := supervisor.StartSession(1, testTradingDetails)
+			assert.NoError(t, err)
+			assert.Equal(t, models.SessionMonitoring, session.Status)
+			assert.Equal(t, 100.0, session.EntryPrice)
+
+			if test.cancel {
+				<-trader.started
+				_, err := supervisor.CancelSession(1, session.ID)
+				assert.NoError(t, err)
+			}
+
+			got, err := supervisor.WaitSession(context.Background(), 1, session.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantStatus, got.Status)
+			assert.Equal(t, "order-2", got.ExitOrderID)
+			assert.Equal(t, 200.0, got.ExitPrice)
+			assert.Equal(t, []string{"buy", "sell"}, orders.sent())
+
+			_, err = supervisor.CancelSession(1, session.ID)
+			assert.ErrorIs(t, err, ErrSessionNotActive)
+			_, err = supervisor.GetSession(2, session.ID)
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+	}
+}
+
+func TestSessionSupervisor_WaitForEntry(t *testing.T) {
+	details := types.Rules{Symbol: "pi_xbtusd", Side: "buy", OrderType: "mkt", Sizing: types.SizingRules{Size: 1},
+		Entry: types.Conditions{All: []string{"price > 100"}}, Exit: types.ExitRules{MaxCandles: 1}}.TradingDetails()
+
+	tests := []struct {
+		name       string
+		cancel     bool
+		wantStatus string
+		wantSent   []string
+	}{
+		{name: "Entered and closed", wantStatus: models.SessionClosed, wantSent: []string{"buy", "sell"}},
+		{name: "Cancelled before entry", cancel: true, wantStatus: models.SessionCancelled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orders := &ordersRecorder{}
+			trader := &blockingTrader{entries: make(chan struct{})}
+			repo := newSessionsRepo()
+			supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, &notifyRecorder{}, trader)
+			defer supervisor.StopSessions()
+
+			session, err := supervisor.StartSession(1, details)
+			assert.NoError(t, err)
+			assert.Equal(t, models.SessionWaitingEntry, session.Status)
+			assert.Empty(t, orders.sent())
+
+			if test.cancel {
+				_, err := supervisor.CancelSession(1, session.ID)
+				assert.NoError(t, err)
+			} else {
+				trader.entries <- struct{}{}
+			}
+
+			got, err := supervisor.WaitSession(context.Background(), 1, session.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantStatus, got.Status)
+			assert.Equal(t, test.wantSent, orders.sent())
+		})
+	}
+
+	t.Run("Resumed waiting", func(t *testing.T) {
+		repo := newSessionsRepo(models.TradingSession{ID: 1, UserID: 1, Status: models.SessionWaitingEntry, Details: details})
+		orders := &ordersRecorder{}
+		trader := &blockingTrader{entries: make(chan struct{})}
+		supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, &notifyRecorder{}, trader)
+		defer supervisor.StopSessions()
+
+		assert.NoError(t, supervisor.ResumeSessions())
+		trader.entries <- struct{}{}
+
+		got, err := supervisor.WaitSession(context.Background(), 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.SessionClosed, got.Status)
+		assert.Equal(t, 100.0, got.EntryPrice)
+	})
+}
+
+func TestSessionSupervisor_Bracket(t *testing.T) {
+	details := testTradingDetails
+	details.Bracket = true
+	details.TriggerSignal = "mark"
+	details.Parameters = types.StrategyParameters{"stop_loss_border": 10, "take_profit_border": 20}
+
+	tests := []struct {
+		name          string
+		block         bool
+		cancel        bool
+		filled        map[string]float64
+		inactive      map[string]bool
+		wantStatus    string
+		wantSent      []string
+		wantExitOrder string
+		wantExitPrice float64
+		wantEvent     string
+	}{
+		{
+			name:          "Take profit filled",
+			filled:        map[string]float64{"order-3": 120.5},
+			wantStatus:    models.SessionClosed,
+			wantSent:      []string{"buy", "sell", "sell"},
+			wantExitOrder: "order-3",
+			wantExitPrice: 120.5,
+			wantEvent:     "take_profit_hit:1:closed",
+		},
+		{
+			name:          "Stop loss filled while strategy runs",
+			block:         true,
+			filled:        map[string]float64{"order-2": 89.5},
+			wantStatus:    models.SessionClosed,
+			wantSent:      []string{"buy", "sell", "sell"},
+			wantExitOrder: "order-2",
+			wantExitPrice: 89.5,
+			wantEvent:     "stop_loss_hit:1:closed",
+		},
+		{
+			name:          "Stop loss cancelled by user",
+			block:         true,
+			cancel:        true,
+			inactive:      map[string]bool{"order-2": true},
+			wantStatus:    models.SessionCancelled,
+			wantSent:      []string{"buy", "sell", "sell", "sell"},
+			wantExitOrder: "order-4",
+			wantExitPrice: 400,
+			wantEvent:     "session_closed:1:cancelled",
+		},
+		{
+			name:          "Cancelled before fill",
+			block:         true,
+			cancel:        true,
+			wantStatus:    models.SessionCancelled,
+			wantSent:      []string{"buy", "sell", "sell", "sell"},
+			wantExitOrder: "order-4",
+			wantExitPrice: 400,
+			wantEvent:     "session_closed:1:cancelled",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orders := &ordersRecorder{filled: test.filled, inactive: test.inactive}
+			trader := &blockingTrader{block: test.block, started: make(chan struct{}, 1)}
+			notifier := &notifyRecorder{}
+			supervisor := NewSessionSupervisor(newSessionsRepo(), usersKeys{}, orders, noRisk{}, notifier, trader)
+			supervisor.bracketCheckInterval = time.Millisecond
+			defer supervisor.StopSessions()
+
+			session, err := supervisor.StartSession(1, details)
+			assert.NoError(t, err)
+			assert.Equal(t, "order-2", session.StopLossOrderID)
+			assert.Equal(t, "order-3", session.TakeProfitOrderID)
+
+			if test.cancel {
+				<-trader.started
+				_, err := supervisor.CancelSession(1, session.ID)
+				assert.NoError(t, err)
+			}
+
+			got, err := supervisor.WaitSession(context.Background(), 1, session.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantStatus, got.Status)
+			assert.Equal(t, test.wantExitOrder, got.ExitOrderID)
+			assert.Equal(t, test.wantExitPrice, got.ExitPrice)
+			assert.Equal(t, test.wantSent, orders.sent())
+			assert.Equal(t, []string{"order-2", "order-3"}, orders.cancelled)
+			assert.Equal(t, []string{test.wantEvent}, notifier.keys())
+
+			stopLoss, takeProfit := orders.args[1], orders.args[2]
+			assert.Equal(t, krakenFuturesSDK.SendOrderArguments{OrderType: "stp", Symbol: "pi_xbtusd", Side: "sell", Size: 1,
+				StopPrice: 90, TriggerSignal: "mark", ReduceOnly: true}, stopLoss)
+			assert.Equal(t, "take_profit", takeProfit.OrderType)
+			assert.Equal(t, 120.0, takeProfit.StopPrice)
+			for _, args := range orders.args[3:] {
+				assert.True(t, args.ReduceOnly)
+			}
+		})
+	}
+}
+
+func TestSessionSupervisor_ResumeSessions(t *testing.T) {
+	entryTime := time.Unix(1640000000, 0)
+	repo := newSessionsRepo(
+		models.TradingSession{ID: 1, UserID: 1, Status: models.SessionMonitoring, Details: testTradingDetails, EntryTime: &entryTime},
+		models.TradingSession{ID: 2, UserID: 1, Status: models.SessionStarting, Details: testTradingDetails},
+		models.TradingSession{ID: 3, UserID: 1, Status: models.SessionStarting, Details: testTradingDetails},
+		models.TradingSession{ID: 4, UserID: 1, Status: models.SessionClosing, Details: testTradingDetails, EntryTime: &entryTime},
+		models.TradingSession{ID: 5, UserID: 1, Status: models.SessionMonitoring, Details: testTradingDetails, EntryTime: &entryTime},
+		models.TradingSession{ID: 6, UserID: 1, Status: models.SessionClosed, Details: testTradingDetails},
+		models.TradingSession{ID: 7, UserID: 1, Status: models.SessionMonitoring, Details: testTradingDetails, EntryTime: &entryTime,
+			PaperTrading: true},
+	)
+	// session 5 is watched by other replica
+	repo.leases[5] = "other"
+	// entry order of session 3 and closing order of session 4 were accepted before server stopped
+	orders := &ordersRecorder{exchange: map[string]models.Order{
+		"session-3-entry": {ID: "entry-3", Price: 150, Timestamp: entryTime},
+		"session-4-exit":  {ID: "exit-4", Price: 160},
+	}}
+	notifier := &notifyRecorder{}
+	supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, notifier, &blockingTrader{})
+	defer supervisor.StopSessions()
+
+	assert.NoError(t, supervisor.ResumeSessions())
+
+	for sessionID, wantStatus := range map[int]string{
+		1: models.SessionClosed,
+		2: models.SessionFailed,
+		3: models.SessionClosed,
+		4: models.SessionClosed,
+		5: models.SessionMonitoring,
+		7: models.SessionFailed,
+	} {
+		got, err := supervisor.WaitSession(context.Background(), 1, sessionID)
+		assert.NoError(t, err)
+		assert.Equal(t, wantStatus, got.Status, "session %d", sessionID)
+	}
+
+	interrupted, err := supervisor.GetSession(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrEntryOrderNotFound.Error(), interrupted.Error)
+
+	entered, err := supervisor.GetSession(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "entry-3", entered.EntryOrderID)
+	assert.Equal(t, 150.0, entered.EntryPrice)
+
+	closed, err := supervisor.GetSession(1, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "exit-4", closed.ExitOrderID)
+	assert.Equal(t, 160.0, closed.ExitPrice)
+
+	paper, err := supervisor.GetSession(1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, ErrPaperSessionLost.Error(), paper.Error)
+
+	var exitOrderIDs []string
+	for _, args := range orders.args {
+		assert.True(t, args.ReduceOnly)
+		exitOrderIDs = append(exitOrderIDs, args.CliOrderID)
+	}
+	assert.ElementsMatch(t, []string{"session-1-exit", "session-3-exit"}, exitOrderIDs)
+	assert.ElementsMatch(t, []string{"session_closed:1:closed", "session_error:2:failed", "session_closed:3:closed",
+		"session_closed:4:closed", "session_error:7:failed"}, notifier.keys())
+
+	for _, sessionID := range []int{1, 2, 3, 4, 7} {
+		_, ok := repo.lease(sessionID)
+		assert.False(t, ok, "session %d", sessionID)
+	}
+	owner, _ := repo.lease(5)
+	assert.Equal(t, "other", owner)
+}
+
+func TestSessionSupervisor_LostOrders(t *testing.T) {
+	tests := []struct {
+		name          string
+		failSends     map[int]bool
+		lost          bool
+		findErr       error
+		wantErr       bool
+		wantStarted   string
+		wantStatus    string
+		wantEntry     string
+		wantExitOrder string
+		wantSent      []string
+	}{
+		{
+			name:          "Entry accepted while response is lost",
+			failSends:     map[int]bool{1: true},
+			lost:          true,
+			wantStarted:   models.SessionMonitoring,
+			wantStatus:    models.SessionClosed,
+			wantEntry:     "order-1",
+			wantExitOrder: "order-2",
+			wantSent:      []string{"buy", "sell"},
+		},
+		{
+			name:       "Entry not sent",
+			failSends:  map[int]bool{1: true},
+			wantErr:    true,
+			wantStatus: models.SessionFailed,
+			wantSent:   []string{"buy"},
+		},
+		{
+			name:          "Entry is unknown until exchange answers",
+			failSends:     map[int]bool{1: true},
+			lost:          true,
+			findErr:       sql.ErrConnDone,
+			wantStarted:   models.SessionStarting,
+			wantStatus:    models.SessionClosed,
+			wantEntry:     "order-1",
+			wantExitOrder: "order-2",
+			wantSent:      []string{"buy", "sell"},
+		},
+		{
+			name:          "Closing order accepted while response is lost",
+			failSends:     map[int]bool{2: true},
+			lost:          true,
+			wantStarted:   models.SessionMonitoring,
+			wantStatus:    models.SessionClosed,
+			wantEntry:     "order-1",
+			wantExitOrder: "order-2",
+			wantSent:      []string{"buy", "sell"},
+		},
+		{
+			name:          "Closing order not sent",
+			failSends:     map[int]bool{2: true},
+			wantStarted:   models.SessionMonitoring,
+			wantStatus:    models.SessionClosed,
+			wantEntry:     "order-1",
+			wantExitOrder: "order-3",
+			wantSent:      []string{"buy", "sell", "sell"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orders := &ordersRecorder{failSends: test.failSends, lost: test.lost, findErr: test.findErr}
+			repo := newSessionsRepo()
+			supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, &notifyRecorder{}, &blockingTrader{})
+			supervisor.retryDelay = time.Millisecond
+			defer supervisor.StopSessions()
+
+			session, err := supervisor.StartSession(1, testTradingDetails)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantStarted, session.Status)
+			}
+			orders.setFindErr(nil)
+
+			got, err := supervisor.WaitSession(context.Background(), 1, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantStatus, got.Status)
+			assert.Equal(t, test.wantEntry, got.EntryOrderID)
+			assert.Equal(t, test.wantExitOrder, got.ExitOrderID)
+			assert.Equal(t, test.wantSent, orders.sent())
+
+			assert.Equal(t, "session-1-entry", orders.args[0].CliOrderID)
+			for _, args := range orders.args[1:] {
+				assert.Equal(t, "session-1-exit", args.CliOrderID)
+				assert.True(t, args.ReduceOnly)
+			}
+		})
+	}
+}
+
+func TestSessionSupervisor_Leases(t *testing.T) {
+	t.Run("Lease taken over", func(t *testing.T) {
+		repo := newSessionsRepo()
+		orders := &ordersRecorder{}
+		trader := &blockingTrader{block: true, started: make(chan struct{}, 1)}
+		supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, &notifyRecorder{}, trader)
+		supervisor.leaseRenewInterval = time.Millisecond
+		defer supervisor.StopSessions()
+
+		session, err := supervisor.StartSession(1, testTradingDetails)
+		assert.NoError(t, err)
+		owner, _ := repo.lease(session.ID)
+		assert.Equal(t, supervisor.owner, owner)
+		<-trader.started
+
+		// lease has expired and other replica has claimed it
+		repo.mu.Lock()
+		repo.leases[session.ID] = "other"
+		repo.mu.Unlock()
+		assert.NoError(t, supervisor.ResumeSessions())
+
+		got, err := supervisor.WaitSession(context.Background(), 1, session.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.SessionMonitoring, got.Status)
+		assert.Equal(t, []string{"buy"}, orders.sent())
+		owner, _ = repo.lease(session.ID)
+		assert.Equal(t, "other", owner)
+	})
+
+	t.Run("Session of stopped replica", func(t *testing.T) {
+		entryTime := time.Unix(1640000000, 0)
+		repo := newSessionsRepo(models.TradingSession{ID: 1, UserID: 1, Status: models.SessionMonitoring,
+			Details: testTradingDetails, EntryTime: &entryTime})
+		repo.leases[1] = "other"
+		orders := &ordersRecorder{}
+		supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, &notifyRecorder{}, &blockingTrader{})
+		supervisor.leaseRenewInterval = time.Millisecond
+		defer supervisor.StopSessions()
+
+		assert.NoError(t, supervisor.ResumeSessions())
+		assert.Empty(t, orders.sent())
+
+		// other replica has stopped, so its lease expires
+		repo.mu.Lock()
+		delete(repo.leases, 1)
+		repo.mu.Unlock()
+
+		assert.Eventually(t, func() bool {
+			got, err := repo.GetSession(1)
+			return err == nil && got.Status == models.SessionClosed
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"sell"}, orders.sent())
+	})
+}
+
+func TestSessionSupervisor_ResumeSessions_FilledBracket(t *testing.T) {
+	entryTime := time.Unix(1640000000, 0)
+	details := testTradingDetails
+	details.Bracket = true
+	details.Parameters = types.StrategyParameters{"stop_loss_border": 10, "take_profit_border": 20}
+	repo := newSessionsRepo(models.TradingSession{ID: 1, UserID: 1, Status: models.SessionMonitoring, Details: details,
+		EntryPrice: 100, EntryTime: &entryTime, StopLossOrderID: "sl", TakeProfitOrderID: "tp"})
+	// take profit has been filled while server was down
+	orders := &ordersRecorder{filled: map[string]float64{"tp": 121}}
+	trader := &blockingTrader{block: true, started: make(chan struct{}, 1)}
+	supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, &notifyRecorder{}, trader)
+	defer supervisor.StopSessions()
+
+	assert.NoError(t, supervisor.ResumeSessions())
+
+	resumed, err := supervisor.WaitSession(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionClosed, resumed.Status)
+	assert.Equal(t, "tp", resumed.ExitOrderID)
+	assert.Equal(t, 121.0, resumed.ExitPrice)
+	assert.Equal(t, []string{"sl", "tp"}, orders.cancelled)
+	assert.Empty(t, orders.sent())
+}
+
+func TestClosedSessionEvent(t *testing.T) {
+	sltp := types.TradingDetails{Strategy: "stop_loss_take_profit", Side: "sell"}
+
+	tests := []struct {
+		name      string
+		session   models.TradingSession
+		wantEvent string
+	}{
+		{
+			name: "Stop loss order filled",
+			session: models.TradingSession{Status: models.SessionClosed, Details: sltp, StopLossOrderID: "sl",
+				TakeProfitOrderID: "tp", ExitOrderID: "sl"},
+			wantEvent: models.EventStopLossHit,
+		},
+		{
+			name:      "Stop loss of short position",
+			session:   models.TradingSession{Status: models.SessionClosed, Details: sltp, EntryPrice: 100, ExitPrice: 110},
+			wantEvent: models.EventStopLossHit,
+		},
+		{
+			name:      "Take profit of short position",
+			session:   models.TradingSession{Status: models.SessionClosed, Details: sltp, EntryPrice: 100, ExitPrice: 90},
+			wantEvent: models.EventTakeProfitHit,
+		},
+		{
+			name:      "Cancelled",
+			session:   models.TradingSession{Status: models.SessionCancelled, Details: sltp, EntryPrice: 100, ExitPrice: 110},
+			wantEvent: models.EventSessionClosed,
+		},
+		{
+			name: "Other strategy",
+			session: models.TradingSession{Status: models.SessionClosed, Details: types.TradingDetails{Strategy: "rsi"},
+				EntryPrice: 100, ExitPrice: 90},
+			wantEvent: models.EventSessionClosed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantEvent, closedSessionEvent(test.session))
+		})
+	}
+}
+
+func TestSessionSupervisor_StopSessions(t *testing.T) {
+	orders := &ordersRecorder{}
+	trader := &blockingTrader{block: true, started: make(chan struct{}, 1)}
+	repo := newSessionsRepo()
+	supervisor := NewSessionSupervisor(repo, usersKeys{}, orders, noRisk{}, &notifyRecorder{}, trader)
+
+	session, err := supervisor.StartSession(1, testTradingDetails)
+	assert.NoError(t, err)
+	<-trader.started
+
+	supervisor.StopSessions()
+
+	got, err := repo.GetSession(session.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionMonitoring, got.Status)
+	assert.Equal(t, []string{"buy"}, orders.sent())
+}
@@ -0,0 +1,62 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+)
+
+const (
+	BollingerBreakoutName = "bollinger_breakout"
+
+	BollingerPeriodParameter     = "period"
+	BollingerDeviationsParameter = "deviations"
+)
+
+// BollingerBreakoutAlgo closes position when price breaks out of bollinger bands
+type BollingerBreakoutAlgo struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+}
+
+func NewBollingerBreakoutAlgo(krakenAnalyzer web.KrakenAnalyzer) *BollingerBreakoutAlgo {
+	return &BollingerBreakoutAlgo{krakenWebsocketSDK: krakenAnalyzer}
+}
+
+func (a *BollingerBreakoutAlgo) Schema() types.StrategySchema {
+	return types.StrategySchema{
+		Name:        BollingerBreakoutName,
+		Description: "closes position when price breaks out of bollinger bands",
+		Parameters: []types.ParameterSchema{
+			{Name: BollingerPeriodParameter, Description: "count of candles in moving average", Integer: true, Default: 20, Min: types.Bound(2)},
+			{Name: BollingerDeviationsParameter, Description: "width of bands in standard deviations", Default: 2, Min: types.Bound(0)},
+		},
+	}
+}
+
+func (a *BollingerBreakoutAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	bands, err := indicators.NewBollinger(int(details.Parameters[BollingerPeriodParameter]),
+		details.Parameters[BollingerDeviationsParameter])
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+
+	return waitForExit(ctx, a.krakenWebsocketSDK, buyTime, details, func(price float64, afterBuy bool) bool {
+		if !bands.Ready() {
+			bands.Add(price)
+			return false
+		}
+
+		// bands are built on previous prices, so breakout of current price is not smoothed by itself
+		previous := bands.Value()
+		bands.Add(price)
+
+		if !afterBuy {
+			return false
+		}
+		return price > previous.Upper || price < previous.Lower
+	})
+}
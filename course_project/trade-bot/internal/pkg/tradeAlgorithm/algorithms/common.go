@@ -0,0 +1,97 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrStartAnalyzing     = errors.New("start analyzing")
+	ErrUnableToGetCandles = errors.New("unable to get candles")
+	ErrParseCandleClose   = errors.New("parse candle close")
+	ErrCandlesInterval    = errors.New("unknown candles interval")
+)
+
+// historyCandles is number of closed candles which warm up algorithms before live candles
+const historyCandles = 500
+
+// waitForExit calls shouldExit for close price of every candle of interval of details until it returns true.
+// Prices of candles before buy time are passed with afterBuy set to false, so algorithm can warm up on them.
+// Candles are read right from analyzer without intermediate buffering, so the last candle read
+// is the one on which algorithm decided to exit
+func waitForExit(ctx context.Context, analyzer web.KrakenAnalyzer, buyTime time.Time, details types.TradingDetails,
+	shouldExit func(price float64, afterBuy bool) bool) error {
+	err := waitForCandle(ctx, analyzer, buyTime, details, func(candle krakenFuturesWSSDK.Candle, afterBuy bool) (bool, error) {
+		price, err := strconv.ParseFloat(candle.Close, 64)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", ErrParseCandleClose, err)
+		}
+		return shouldExit(price, afterBuy), nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+	return nil
+}
+
+// waitForCandle calls handle for every closed candle of interval of details until it returns true or error,
+// candles before since are passed with live set to false. The latest candles from history are passed first,
+// so indicators are warmed up when the first live candle comes
+func waitForCandle(ctx context.Context, analyzer web.KrakenAnalyzer, since time.Time, details types.TradingDetails,
+	handle func(candle krakenFuturesWSSDK.Candle, live bool) (bool, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	feed, ok := krakenFuturesWSSDK.CandlesFeed(details.CandlesInterval)
+	if !ok {
+		return fmt.Errorf("%s: %s", ErrCandlesInterval, details.CandlesInterval)
+	}
+
+	// algorithms still warm up on live candles without history
+	history, err := analyzer.CandlesHistory(feed, details.Symbol, historyCandles)
+	if err != nil {
+		log.Warn(err)
+	}
+
+	candles, err := analyzer.LookForCandles(ctx, feed, []string{details.Symbol})
+	if err != nil {
+		return err
+	}
+
+	lastTime := 0
+	deliver := func(candle krakenFuturesWSSDK.Candle) (bool, error) {
+		lastTime = candle.Time
+		return handle(candle, !time.Unix(int64(candle.Time), 0).Before(since))
+	}
+
+	for _, candle := range history {
+		if done, err := deliver(candle); done || err != nil {
+			return err
+		}
+	}
+	for candle := range candles {
+		// the first live candles may be already passed from history
+		if candle.Time <= lastTime {
+			continue
+		}
+		if done, err := deliver(candle); done || err != nil {
+			return err
+		}
+	}
+
+	return ErrUnableToGetCandles
+}
+
+func isLong(details types.TradingDetails) bool {
+	return details.Side == krakenFuturesSDK.BuySide
+}
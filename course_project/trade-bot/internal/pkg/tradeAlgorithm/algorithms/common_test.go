@@ -0,0 +1,189 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// candlesAnalyzer serves history and streams live candles, other market data is not used by algorithms.
+// It remembers index of the last live candle received by algorithm
+type candlesAnalyzer struct {
+	web.KrakenAnalyzer
+	history    []krakenFuturesWSSDK.Candle
+	historyErr error
+	live       []krakenFuturesWSSDK.Candle
+	delivered  int
+	done       chan struct{}
+}
+
+func (a *candlesAnalyzer) CandlesHistory(feed, productID string, count int) ([]krakenFuturesWSSDK.Candle, error) {
+	if a.historyErr != nil {
+		return nil, a.historyErr
+	}
+	if len(a.history) > count {
+		return a.history[len(a.history)-count:], nil
+	}
+	return a.history, nil
+}
+
+func (a *candlesAnalyzer) LookForCandles(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.Candle, error) {
+	candles := make(chan krakenFuturesWSSDK.Candle)
+	a.delivered, a.done = -1, make(chan struct{})
+	go func() {
+		defer close(a.done)
+		defer close(candles)
+		for i, candle := range a.live {
+			select {
+			case candles <- candle:
+				a.delivered = i
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return candles, nil
+}
+
+// lastDelivered waits for stream to stop and returns index of the last live candle received by algorithm
+func (a *candlesAnalyzer) lastDelivered() int {
+	<-a.done
+	return a.delivered
+}
+
+// testCandles returns one minute candles with close prices which start at start
+func testCandles(start time.Time, prices ...float64) []krakenFuturesWSSDK.Candle {
+	candles := make([]krakenFuturesWSSDK.Candle, 0, len(prices))
+	for i, price := range prices {
+		value := fmt.Sprintf("%f", price)
+		candles = append(candles, krakenFuturesWSSDK.Candle{
+			Time:   int(start.Add(time.Duration(i) * time.Minute).Unix()),
+			Open:   value,
+			High:   value,
+			Low:    value,
+			Close:  value,
+			Volume: 1,
+		})
+	}
+	return candles
+}
+
+func TestWaitForCandle(t *testing.T) {
+	start := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	since := start.Add(3 * time.Minute)
+	details := types.TradingDetails{Symbol: "PI_XBTUSD", CandlesInterval: "1m"}
+
+	tests := []struct {
+		name     string
+		analyzer *candlesAnalyzer
+		doneOn   float64
+		wantLive []bool
+		wantErr  bool
+	}{
+		{
+			name: "History before live candles",
+			analyzer: &candlesAnalyzer{
+				history: testCandles(start, 1, 2, 3),
+				// the first live candle is already in history
+				live: testCandles(start.Add(2*time.Minute), 3, 4, 5),
+			},
+			wantLive: []bool{false, false, false, true, true},
+			wantErr:  true,
+		},
+		{
+			name: "History error",
+			analyzer: &candlesAnalyzer{
+				historyErr: errors.New("charts"),
+				live:       testCandles(since, 4, 5),
+			},
+			wantLive: []bool{true, true},
+			wantErr:  true,
+		},
+		{
+			name: "Done on history candle",
+			analyzer: &candlesAnalyzer{
+				history: testCandles(start, 1, 2, 3, 4, 5),
+				live:    testCandles(start.Add(5*time.Minute), 6),
+			},
+			doneOn:   4,
+			wantLive: []bool{false, false, false, true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var times []int
+			var live []bool
+			err := waitForCandle(context.Background(), tc.analyzer, since, details,
+				func(candle krakenFuturesWSSDK.Candle, isLive bool) (bool, error) {
+					times = append(times, candle.Time)
+					live = append(live, isLive)
+					return candle.Close == fmt.Sprintf("%f", tc.doneOn), nil
+				})
+
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrUnableToGetCandles)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantLive, live)
+			for i := 1; i < len(times); i++ {
+				assert.Equal(t, 60, times[i]-times[i-1])
+			}
+		})
+	}
+}
+
+func TestRulesAlgo_WaitForEntry_WarmUp(t *testing.T) {
+	start := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	rules := types.Rules{
+		Symbol: "PI_XBTUSD",
+		Side:   "buy",
+		Indicators: map[string]types.IndicatorRule{
+			"fast": {Type: types.SMAIndicator, Period: 2},
+			"slow": {Type: types.SMAIndicator, Period: 4},
+		},
+		Entry:  types.Conditions{All: []string{"fast > slow"}},
+		Exit:   types.ExitRules{MaxCandles: 10},
+		Sizing: types.SizingRules{Size: 1},
+	}
+	details := types.TradingDetails{Symbol: "PI_XBTUSD", CandlesInterval: "1m", Rules: &rules}
+
+	tests := []struct {
+		name    string
+		history []krakenFuturesWSSDK.Candle
+		wantErr bool
+	}{
+		{
+			name:    "Indicators are ready on the first live candle",
+			history: testCandles(start, 1, 2, 3, 4),
+		},
+		{
+			name:    "Indicators are not ready without history",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			analyzer := &candlesAnalyzer{history: tc.history, live: testCandles(start.Add(4*time.Minute), 5)}
+			algo := NewRulesAlgo(analyzer)
+			algo.now = func() time.Time { return start.Add(4 * time.Minute) }
+
+			err := algo.WaitForEntry(context.Background(), details)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
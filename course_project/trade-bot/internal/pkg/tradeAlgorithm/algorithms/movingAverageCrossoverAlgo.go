@@ -0,0 +1,113 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+)
+
+var ErrFastPeriodNotLessThanSlow = errors.New("fast period must be less than slow period")
+
+const (
+	SMACrossoverName = "sma_crossover"
+	EMACrossoverName = "ema_crossover"
+
+	FastPeriodParameter = "fast_period"
+	SlowPeriodParameter = "slow_period"
+)
+
+type movingAverageKind int
+
+const (
+	simpleMovingAverage movingAverageKind = iota
+	exponentialMovingAverage
+)
+
+// MovingAverageCrossoverAlgo closes long position when fast moving average crosses slow one
+// from above and short position when it crosses from below
+type MovingAverageCrossoverAlgo struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+	kind               movingAverageKind
+}
+
+func NewSMACrossoverAlgo(krakenAnalyzer web.KrakenAnalyzer) *MovingAverageCrossoverAlgo {
+	return &MovingAverageCrossoverAlgo{krakenWebsocketSDK: krakenAnalyzer, kind: simpleMovingAverage}
+}
+
+func NewEMACrossoverAlgo(krakenAnalyzer web.KrakenAnalyzer) *MovingAverageCrossoverAlgo {
+	return &MovingAverageCrossoverAlgo{krakenWebsocketSDK: krakenAnalyzer, kind: exponentialMovingAverage}
+}
+
+func (a *MovingAverageCrossoverAlgo) Schema() types.StrategySchema {
+	name, average := SMACrossoverName, "simple"
+	if a.kind == exponentialMovingAverage {
+		name, average = EMACrossoverName, "exponential"
+	}
+
+	return types.StrategySchema{
+		Name:        name,
+		Description: fmt.Sprintf("closes position when fast %s moving average crosses slow one against position side", average),
+		Parameters: []types.ParameterSchema{
+			{Name: FastPeriodParameter, Description: "count of candles in fast moving average", Integer: true, Default: 9, Min: types.Bound(1)},
+			{Name: SlowPeriodParameter, Description: "count of candles in slow moving average", Integer: true, Default: 21, Min: types.Bound(2)},
+		},
+		Check: func(params types.StrategyParameters) error {
+			if params[FastPeriodParameter] >= params[SlowPeriodParameter] {
+				return ErrFastPeriodNotLessThanSlow
+			}
+			return nil
+		},
+	}
+}
+
+func (a *MovingAverageCrossoverAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	fast, err := a.newAverage(int(details.Parameters[FastPeriodParameter]))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+	slow, err := a.newAverage(int(details.Parameters[SlowPeriodParameter]))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+	long := isLong(details)
+
+	var prevDiff *float64
+
+	return waitForExit(ctx, a.krakenWebsocketSDK, buyTime, details, func(price float64, afterBuy bool) bool {
+		fast.Add(price)
+		slow.Add(price)
+		if !fast.Ready() || !slow.Ready() {
+			return false
+		}
+
+		diff := fast.Value() - slow.Value()
+		defer func() { prevDiff = &diff }()
+
+		if !afterBuy || prevDiff == nil {
+			return false
+		}
+		if long {
+			return *prevDiff > 0 && diff <= 0
+		}
+		return *prevDiff < 0 && diff >= 0
+	})
+}
+
+type movingAverage interface {
+	Add(price float64)
+	Ready() bool
+	Value() float64
+}
+
+func (a *MovingAverageCrossoverAlgo) newAverage(period int) (movingAverage, error) {
+	if a.kind == exponentialMovingAverage {
+		return indicators.NewEMA(period)
+	}
+	return indicators.NewSMA(period)
+}
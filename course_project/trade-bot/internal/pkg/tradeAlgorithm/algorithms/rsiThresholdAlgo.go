@@ -0,0 +1,72 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+)
+
+var ErrOversoldNotLessThanOverbought = errors.New("oversold level must be less than overbought level")
+
+const (
+	RSIThresholdName = "rsi_threshold"
+
+	RSIPeriodParameter  = "period"
+	OverboughtParameter = "overbought"
+	OversoldParameter   = "oversold"
+)
+
+// RSIThresholdAlgo closes long position when relative strength index reaches overbought level
+// and short position when it reaches oversold level
+type RSIThresholdAlgo struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+}
+
+func NewRSIThresholdAlgo(krakenAnalyzer web.KrakenAnalyzer) *RSIThresholdAlgo {
+	return &RSIThresholdAlgo{krakenWebsocketSDK: krakenAnalyzer}
+}
+
+func (a *RSIThresholdAlgo) Schema() types.StrategySchema {
+	return types.StrategySchema{
+		Name:        RSIThresholdName,
+		Description: "closes long position on overbought and short position on oversold relative strength index",
+		Parameters: []types.ParameterSchema{
+			{Name: RSIPeriodParameter, Description: "count of candles in relative strength index", Integer: true, Default: 14, Min: types.Bound(2)},
+			{Name: OverboughtParameter, Description: "overbought level", Default: 70, Min: types.Bound(0), Max: types.Bound(100)},
+			{Name: OversoldParameter, Description: "oversold level", Default: 30, Min: types.Bound(0), Max: types.Bound(100)},
+		},
+		Check: func(params types.StrategyParameters) error {
+			if params[OversoldParameter] >= params[OverboughtParameter] {
+				return ErrOversoldNotLessThanOverbought
+			}
+			return nil
+		},
+	}
+}
+
+func (a *RSIThresholdAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	index, err := indicators.NewRSI(int(details.Parameters[RSIPeriodParameter]))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+	overbought := details.Parameters[OverboughtParameter]
+	oversold := details.Parameters[OversoldParameter]
+	long := isLong(details)
+
+	return waitForExit(ctx, a.krakenWebsocketSDK, buyTime, details, func(price float64, afterBuy bool) bool {
+		index.Add(price)
+		if !index.Ready() || !afterBuy {
+			return false
+		}
+		if long {
+			return index.Value() >= overbought
+		}
+		return index.Value() <= oversold
+	})
+}
@@ -0,0 +1,378 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrWaitForEntry   = errors.New("wait for entry")
+	ErrCompileRules   = errors.New("compile rules")
+	ErrMissingRules   = errors.New("rules strategy requires rules")
+	ErrUnknownOperand = errors.New("unknown operand")
+)
+
+// RulesAlgo runs strategy described by rules of trading details, rules are compiled for every session
+type RulesAlgo struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+	now                func() time.Time
+}
+
+func NewRulesAlgo(krakenAnalyzer web.KrakenAnalyzer) *RulesAlgo {
+	return &RulesAlgo{krakenWebsocketSDK: krakenAnalyzer, now: time.Now}
+}
+
+func (a *RulesAlgo) Schema() types.StrategySchema {
+	return types.StrategySchema{
+		Name:        types.RulesStrategy,
+		Description: "opens and closes position by conditions over indicators and prices of declarative rules",
+		Parameters:  []types.ParameterSchema{},
+	}
+}
+
+// WaitForEntry waits until entry conditions of rules hold on a candle which starts after call,
+// previous candles warm up indicators. Rules without entry conditions enter right away
+func (a *RulesAlgo) WaitForEntry(ctx context.Context, details types.TradingDetails) error {
+	if details.Rules == nil {
+		return fmt.Errorf("%s: %w", ErrWaitForEntry, ErrMissingRules)
+	}
+	if details.Rules.Entry.Empty() {
+		return nil
+	}
+
+	program, err := compileRules(*details.Rules)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrWaitForEntry, err)
+	}
+
+	err = waitForCandle(ctx, a.krakenWebsocketSDK, a.now(), details, func(candle krakenFuturesWSSDK.Candle, live bool) (bool, error) {
+		if err := program.update(candle); err != nil {
+			return false, err
+		}
+		entry, _ := program.evaluate()
+		return live && entry, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrWaitForEntry, err)
+	}
+	return nil
+}
+
+func (a *RulesAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	if details.Rules == nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, ErrMissingRules)
+	}
+	program, err := compileRules(*details.Rules)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+
+	exit := details.Rules.Exit
+	long := isLong(details)
+	best := details.BuyPrice
+	candles := 0
+
+	err = waitForCandle(ctx, a.krakenWebsocketSDK, buyTime, details, func(candle krakenFuturesWSSDK.Candle, afterBuy bool) (bool, error) {
+		if err := program.update(candle); err != nil {
+			return false, err
+		}
+		_, exitHolds := program.evaluate()
+		if !afterBuy {
+			return false, nil
+		}
+		candles++
+
+		price := program.candle.Close
+		if long {
+			best = math.Max(best, price)
+		} else {
+			best = math.Min(best, price)
+		}
+		// price exits are counted in direction of position, so losses are positive
+		profit, retrace := price-details.BuyPrice, best-price
+		if !long {
+			profit, retrace = -profit, -retrace
+		}
+
+		return exitHolds ||
+			exit.StopLoss > 0 && -profit >= exit.StopLoss ||
+			exit.TakeProfit > 0 && profit >= exit.TakeProfit ||
+			exit.TrailingStop > 0 && retrace >= exit.TrailingStop ||
+			exit.MaxCandles > 0 && candles >= exit.MaxCandles, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+	return nil
+}
+
+// value returns operand value of the last candle and whether it is ready
+type value func() (float64, bool)
+
+type condition struct {
+	left, right value
+	operator    string
+	prevDiff    float64
+	hasPrev     bool
+}
+
+// holds checks condition by the last candle, crosses compare it with the previous ready one
+func (c *condition) holds() bool {
+	left, leftReady := c.left()
+	right, rightReady := c.right()
+	if !leftReady || !rightReady {
+		return false
+	}
+
+	diff := left - right
+	prevDiff, hasPrev := c.prevDiff, c.hasPrev
+	c.prevDiff, c.hasPrev = diff, true
+
+	switch c.operator {
+	case types.LessOperator:
+		return diff < 0
+	case types.LessOrEqualOperator:
+		return diff <= 0
+	case types.GreaterOperator:
+		return diff > 0
+	case types.GreaterOrEqualOperator:
+		return diff >= 0
+	case types.CrossesAboveOperator:
+		return hasPrev && prevDiff <= 0 && diff > 0
+	case types.CrossesBelowOperator:
+		return hasPrev && prevDiff >= 0 && diff < 0
+	}
+	return false
+}
+
+type conditions struct {
+	all []*condition
+	any []*condition
+}
+
+// holds checks every condition, so crosses of conditions which don't decide the result keep their state
+func (c conditions) holds() bool {
+	if len(c.all) == 0 && len(c.any) == 0 {
+		return false
+	}
+
+	all, any := true, len(c.any) == 0
+	for _, condition := range c.all {
+		if !condition.holds() {
+			all = false
+		}
+	}
+	for _, condition := range c.any {
+		if condition.holds() {
+			any = true
+		}
+	}
+	return all && any
+}
+
+// program is compiled rules, it keeps indicators and conditions updated by candles
+type program struct {
+	candle     models.Candle
+	indicators []indicators.Indicator
+	values     map[string]value
+	entry      conditions
+	exit       conditions
+}
+
+func compileRules(rules types.Rules) (*program, error) {
+	rules, err := rules.Normalize()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrCompileRules, err)
+	}
+
+	p := &program{values: make(map[string]value)}
+	p.addCandleValues()
+	for name, rule := range rules.Indicators {
+		if err := p.addIndicator(name, rule); err != nil {
+			return nil, fmt.Errorf("%s: indicators.%s: %w", ErrCompileRules, name, err)
+		}
+	}
+
+	if p.entry, err = p.compileConditions(rules.Entry); err != nil {
+		return nil, fmt.Errorf("%s: entry: %w", ErrCompileRules, err)
+	}
+	if p.exit, err = p.compileConditions(rules.Exit.Conditions); err != nil {
+		return nil, fmt.Errorf("%s: exit: %w", ErrCompileRules, err)
+	}
+	return p, nil
+}
+
+func (p *program) update(candle krakenFuturesWSSDK.Candle) error {
+	parsed, err := indicators.ParseCandle(candle)
+	if err != nil {
+		return err
+	}
+	p.candle = parsed
+
+	for _, indicator := range p.indicators {
+		if err := indicator.Update(candle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluate returns whether entry and exit conditions hold on the last candle
+func (p *program) evaluate() (bool, bool) {
+	return p.entry.holds(), p.exit.holds()
+}
+
+func (p *program) addCandleValues() {
+	candleValue := func(get func(candle models.Candle) float64) value {
+		return func() (float64, bool) {
+			return get(p.candle), true
+		}
+	}
+
+	closePrice := candleValue(func(candle models.Candle) float64 { return candle.Close })
+	p.values["price"] = closePrice
+	p.values["close"] = closePrice
+	p.values["open"] = candleValue(func(candle models.Candle) float64 { return candle.Open })
+	p.values["high"] = candleValue(func(candle models.Candle) float64 { return candle.High })
+	p.values["low"] = candleValue(func(candle models.Candle) float64 { return candle.Low })
+	p.values["volume"] = candleValue(func(candle models.Candle) float64 { return float64(candle.Volume) })
+}
+
+// addIndicator creates indicator of rule and values of its fields, name without field is the main value
+func (p *program) addIndicator(name string, rule types.IndicatorRule) error {
+	var (
+		indicator indicators.Indicator
+		fields    map[string]func() float64
+		err       error
+	)
+
+	switch rule.Type {
+	case types.SMAIndicator:
+		var sma *indicators.SMA
+		sma, err = indicators.NewSMA(rule.Period)
+		indicator, fields = sma, map[string]func() float64{"": func() float64 { return sma.Value() }}
+	case types.EMAIndicator:
+		var ema *indicators.EMA
+		ema, err = indicators.NewEMA(rule.Period)
+		indicator, fields = ema, map[string]func() float64{"": func() float64 { return ema.Value() }}
+	case types.WMAIndicator:
+		var wma *indicators.WMA
+		wma, err = indicators.NewWMA(rule.Period)
+		indicator, fields = wma, map[string]func() float64{"": func() float64 { return wma.Value() }}
+	case types.RSIIndicator:
+		var rsi *indicators.RSI
+		rsi, err = indicators.NewRSI(rule.Period)
+		indicator, fields = rsi, map[string]func() float64{"": func() float64 { return rsi.Value() }}
+	case types.ATRIndicator:
+		var atr *indicators.ATR
+		atr, err = indicators.NewATR(rule.Period)
+		indicator, fields = atr, map[string]func() float64{"": func() float64 { return atr.Value() }}
+	case types.MACDIndicator:
+		var macd *indicators.MACD
+		macd, err = indicators.NewMACD(rule.Fast, rule.Slow, rule.Signal)
+		indicator, fields = macd, map[string]func() float64{
+			"macd":      func() float64 { return macd.Value().MACD },
+			"signal":    func() float64 { return macd.Value().Signal },
+			"histogram": func() float64 { return macd.Value().Histogram },
+		}
+		fields[""] = fields["macd"]
+	case types.BollingerIndicator:
+		var bollinger *indicators.Bollinger
+		bollinger, err = indicators.NewBollinger(rule.Period, rule.Deviations)
+		indicator, fields = bollinger, map[string]func() float64{
+			"middle": func() float64 { return bollinger.Value().Middle },
+			"upper":  func() float64 { return bollinger.Value().Upper },
+			"lower":  func() float64 { return bollinger.Value().Lower },
+		}
+		fields[""] = fields["middle"]
+	case types.StochasticIndicator:
+		var stochastic *indicators.Stochastic
+		stochastic, err = indicators.NewStochastic(rule.Period, rule.Smoothing)
+		indicator, fields = stochastic, map[string]func() float64{
+			"k": func() float64 { return stochastic.Value().K },
+			"d": func() float64 { return stochastic.Value().D },
+		}
+		fields[""] = fields["k"]
+	case types.VWAPIndicator:
+		var session time.Duration
+		session, err = time.ParseDuration(rule.Session)
+		vwap := indicators.NewVWAP(session)
+		indicator, fields = vwap, map[string]func() float64{"": func() float64 { return vwap.Value() }}
+	case types.OBVIndicator:
+		obv := indicators.NewOBV()
+		indicator, fields = obv, map[string]func() float64{"": func() float64 { return obv.Value() }}
+	default:
+		err = fmt.Errorf("%s: %s", ErrUnknownOperand, rule.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.indicators = append(p.indicators, indicator)
+	for field, get := range fields {
+		key := name
+		if field != "" {
+			key = name + "." + field
+		}
+		get := get
+		p.values[key] = func() (float64, bool) {
+			return get(), indicator.Ready()
+		}
+	}
+	return nil
+}
+
+func (p *program) compileConditions(rules types.Conditions) (conditions, error) {
+	var compiled conditions
+	for _, list := range []struct {
+		expressions []string
+		conditions  *[]*condition
+	}{
+		{expressions: rules.All, conditions: &compiled.all},
+		{expressions: rules.Any, conditions: &compiled.any},
+	} {
+		for _, expression := range list.expressions {
+			parsed, err := types.ParseCondition(expression)
+			if err != nil {
+				return conditions{}, err
+			}
+			left, err := p.operand(parsed.Left)
+			if err != nil {
+				return conditions{}, err
+			}
+			right, err := p.operand(parsed.Right)
+			if err != nil {
+				return conditions{}, err
+			}
+			*list.conditions = append(*list.conditions, &condition{left: left, right: right, operator: parsed.Operator})
+		}
+	}
+	return compiled, nil
+}
+
+func (p *program) operand(operand types.Operand) (value, error) {
+	if operand.Name == "" {
+		constant := operand.Value
+		return func() (float64, bool) { return constant, true }, nil
+	}
+
+	key := operand.Name
+	if operand.Field != "" {
+		key += "." + operand.Field
+	}
+	v, ok := p.values[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", ErrUnknownOperand, key)
+	}
+	return v, nil
+}
@@ -0,0 +1,232 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+func TestProgram_evaluate(t *testing.T) {
+	start := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	// exit rules are required, max candles don't affect conditions
+	maxCandles := types.ExitRules{MaxCandles: 100}
+
+	tests := []struct {
+		name      string
+		rules     types.Rules
+		prices    []float64
+		wantEntry []bool
+		wantExit  []bool
+	}{
+		{
+			name: "Crosses above constant",
+			rules: types.Rules{
+				Entry: types.Conditions{All: []string{"close crosses_above 10"}},
+				Exit:  maxCandles,
+			},
+			prices:    []float64{11, 9, 11, 12, 10, 11},
+			wantEntry: []bool{false, false, true, false, false, true},
+			wantExit:  []bool{false, false, false, false, false, false},
+		},
+		{
+			name: "Crosses below constant",
+			rules: types.Rules{
+				Exit: types.ExitRules{Conditions: types.Conditions{Any: []string{"price crosses_below 10"}}},
+			},
+			prices:    []float64{9, 11, 9, 8, 10, 9},
+			wantEntry: []bool{false, false, false, false, false, false},
+			wantExit:  []bool{false, false, true, false, false, true},
+		},
+		{
+			name: "Unready indicator",
+			rules: types.Rules{
+				Indicators: map[string]types.IndicatorRule{"sma": {Type: types.SMAIndicator, Period: 3}},
+				Entry:      types.Conditions{All: []string{"sma > 0"}},
+				Exit:       types.ExitRules{Conditions: types.Conditions{Any: []string{"close < sma"}}},
+			},
+			prices:    []float64{1, 2, 3, 1},
+			wantEntry: []bool{false, false, true, true},
+			wantExit:  []bool{false, false, false, true},
+		},
+		{
+			name: "Crosses start when indicators are ready",
+			rules: types.Rules{
+				Indicators: map[string]types.IndicatorRule{
+					"fast": {Type: types.SMAIndicator, Period: 2},
+					"slow": {Type: types.SMAIndicator, Period: 3},
+				},
+				Entry: types.Conditions{All: []string{"fast crosses_above slow"}},
+				Exit:  types.ExitRules{Conditions: types.Conditions{Any: []string{"fast crosses_below slow"}}},
+			},
+			// fast is above slow when they are ready, which is not a cross
+			prices:    []float64{1, 2, 3, 4, 1, 1, 5},
+			wantEntry: []bool{false, false, false, false, false, false, true},
+			wantExit:  []bool{false, false, false, false, true, false, false},
+		},
+		{
+			name: "All and any",
+			rules: types.Rules{
+				Entry: types.Conditions{
+					All: []string{"close > 10", "volume >= 1"},
+					Any: []string{"close < 12", "high > 20"},
+				},
+				Exit: maxCandles,
+			},
+			prices:    []float64{9, 11, 13, 25},
+			wantEntry: []bool{false, true, false, true},
+			wantExit:  []bool{false, false, false, false},
+		},
+		{
+			name: "Any only",
+			rules: types.Rules{
+				Entry: types.Conditions{Any: []string{"close < 5", "close > 10"}},
+				Exit:  maxCandles,
+			},
+			prices:    []float64{4, 7, 11},
+			wantEntry: []bool{true, false, true},
+			wantExit:  []bool{false, false, false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := tc.rules
+			rules.Symbol, rules.Side, rules.Sizing = "PI_XBTUSD", "buy", types.SizingRules{Size: 1}
+
+			program, err := compileRules(rules)
+			if err != nil {
+				t.Fatalf("unexpected error while compiling rules: %s", err)
+			}
+
+			var entries, exits []bool
+			for _, candle := range testCandles(start, tc.prices...) {
+				assert.NoError(t, program.update(candle))
+				entry, exit := program.evaluate()
+				entries = append(entries, entry)
+				exits = append(exits, exit)
+			}
+			assert.Equal(t, tc.wantEntry, entries)
+			assert.Equal(t, tc.wantExit, exits)
+		})
+	}
+}
+
+func TestRulesAlgo_StartAnalyzing(t *testing.T) {
+	buyTime := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		side string
+		exit types.ExitRules
+		// prices of candles from buy time, candles before buy time are in warmUp
+		warmUp     []float64
+		prices     []float64
+		wantExitOn int
+		wantErr    bool
+	}{
+		{
+			name:       "Long stop loss",
+			side:       "buy",
+			exit:       types.ExitRules{StopLoss: 5},
+			prices:     []float64{99, 96, 95, 90},
+			wantExitOn: 2,
+		},
+		{
+			name:       "Short stop loss",
+			side:       "sell",
+			exit:       types.ExitRules{StopLoss: 5},
+			prices:     []float64{101, 104, 105, 110},
+			wantExitOn: 2,
+		},
+		{
+			name:       "Long take profit",
+			side:       "buy",
+			exit:       types.ExitRules{StopLoss: 5, TakeProfit: 10},
+			prices:     []float64{105, 110, 120},
+			wantExitOn: 1,
+		},
+		{
+			name:       "Short take profit",
+			side:       "sell",
+			exit:       types.ExitRules{StopLoss: 5, TakeProfit: 10},
+			prices:     []float64{95, 90, 80},
+			wantExitOn: 1,
+		},
+		{
+			name:       "Long trailing stop",
+			side:       "buy",
+			exit:       types.ExitRules{TrailingStop: 5},
+			prices:     []float64{105, 110, 106, 105, 100},
+			wantExitOn: 3,
+		},
+		{
+			name:       "Short trailing stop",
+			side:       "sell",
+			exit:       types.ExitRules{TrailingStop: 5},
+			prices:     []float64{95, 90, 94, 95, 100},
+			wantExitOn: 3,
+		},
+		{
+			name:       "Long max candles",
+			side:       "buy",
+			exit:       types.ExitRules{MaxCandles: 3},
+			warmUp:     []float64{100, 100},
+			prices:     []float64{100, 100, 100, 100},
+			wantExitOn: 2,
+		},
+		{
+			name:       "Short max candles",
+			side:       "sell",
+			exit:       types.ExitRules{MaxCandles: 3},
+			prices:     []float64{100, 100, 100, 100},
+			wantExitOn: 2,
+		},
+		{
+			name:       "Exit conditions",
+			side:       "buy",
+			exit:       types.ExitRules{Conditions: types.Conditions{Any: []string{"close crosses_below 100"}}},
+			warmUp:     []float64{101},
+			prices:     []float64{99, 98},
+			wantExitOn: 0,
+		},
+		{
+			name:    "Price exits are not checked before buy",
+			side:    "buy",
+			exit:    types.ExitRules{StopLoss: 5},
+			warmUp:  []float64{90},
+			prices:  []float64{100, 101},
+			wantErr: true,
+		},
+		{
+			name:    "No exit",
+			side:    "sell",
+			exit:    types.ExitRules{StopLoss: 5, TakeProfit: 10, TrailingStop: 3, MaxCandles: 5},
+			prices:  []float64{100, 99, 101, 98},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := types.Rules{Symbol: "PI_XBTUSD", Side: tc.side, Sizing: types.SizingRules{Size: 1}, Exit: tc.exit}
+			details := rules.TradingDetails()
+			details.BuyPrice = 100
+
+			warmUpStart := buyTime.Add(-time.Duration(len(tc.warmUp)) * time.Minute)
+			analyzer := &candlesAnalyzer{live: testCandles(warmUpStart, append(tc.warmUp, tc.prices...)...)}
+
+			err := NewRulesAlgo(analyzer).StartAnalyzing(context.Background(), buyTime, details)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, len(analyzer.live)-1, analyzer.lastDelivered())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, len(tc.warmUp)+tc.wantExitOn, analyzer.lastDelivered())
+		})
+	}
+}
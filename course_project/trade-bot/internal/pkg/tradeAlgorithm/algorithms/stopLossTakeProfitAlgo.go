@@ -2,20 +2,17 @@ package algorithms
 
 import (
 	"context"
-	"fmt"
-	"strconv"
 	"time"
 
-	"github.com/pkg/errors"
-
 	"trade-bot/internal/pkg/tradeAlgorithm/types"
 	"trade-bot/internal/pkg/web"
-	"trade-bot/pkg/krakenFuturesWSSDK"
 )
 
-var (
-	ErrStartAnalyzing     = errors.New("start analyzing")
-	ErrUnableToGetCandles = errors.New("unable to get candles")
+const (
+	StopLossTakeProfitName = "stop_loss_take_profit"
+
+	StopLossBorderParameter   = "stop_loss_border"
+	TakeProfitBorderParameter = "take_profit_border"
 )
 
 type StopLossTakeProfitAlgo struct {
@@ -28,30 +25,30 @@ func NewStopLossTakeProfitAlgo(krakenAnalyzer web.KrakenAnalyzer) *StopLossTakeP
 	}
 }
 
-func (a *StopLossTakeProfitAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
-	candles, err := a.krakenWebsocketSDK.LookForCandles(ctx, krakenFuturesWSSDK.OneMinuteCandlesFeed, []string{details.Symbol})
-	if err != nil {
-		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+func (a *StopLossTakeProfitAlgo) Schema() types.StrategySchema {
+	return types.StrategySchema{
+		Name:        StopLossTakeProfitName,
+		Description: "closes position when price moves away from buy price by one of borders",
+		Parameters: []types.ParameterSchema{
+			{Name: StopLossBorderParameter, Description: "price delta of loss to close position", Required: true, Positive: true},
+			{Name: TakeProfitBorderParameter, Description: "price delta of profit to close position", Required: true, Positive: true},
+		},
 	}
+}
 
-	for candle := range candles {
-		price, err := strconv.ParseFloat(candle.Close, 64)
-
-		if time.Unix(int64(candle.Time), 0).Before(buyTime) {
-			continue
-		}
+func (a *StopLossTakeProfitAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	stopLoss := details.Parameters[StopLossBorderParameter]
+	takeProfit := details.Parameters[TakeProfitBorderParameter]
+	long := isLong(details)
 
-		if err != nil {
-			return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	return waitForExit(ctx, a.krakenWebsocketSDK, buyTime, details, func(price float64, afterBuy bool) bool {
+		if !afterBuy {
+			return false
 		}
 
-		if price > details.BuyPrice+details.TakeProfitBorder {
-			return nil
-		}
-		if price < details.BuyPrice-details.StopLossBorder {
-			return nil
+		if long {
+			return price > details.BuyPrice+takeProfit || price < details.BuyPrice-stopLoss
 		}
-	}
-
-	return fmt.Errorf("%s: %s", ErrStartAnalyzing, ErrUnableToGetCandles)
+		return price < details.BuyPrice-takeProfit || price > details.BuyPrice+stopLoss
+	})
 }
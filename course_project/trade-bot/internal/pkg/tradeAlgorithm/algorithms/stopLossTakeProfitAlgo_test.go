@@ -0,0 +1,75 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+func TestStopLossTakeProfitAlgo_StartAnalyzing(t *testing.T) {
+	buyTime := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		side       string
+		prices     []float64
+		wantExitOn int
+		wantErr    bool
+	}{
+		{
+			name:       "Long stop loss",
+			side:       "buy",
+			prices:     []float64{99, 95, 94},
+			wantExitOn: 2,
+		},
+		{
+			name:       "Long take profit",
+			side:       "buy",
+			prices:     []float64{105, 110, 111},
+			wantExitOn: 2,
+		},
+		{
+			name:       "Short stop loss",
+			side:       "sell",
+			prices:     []float64{101, 105, 106},
+			wantExitOn: 2,
+		},
+		{
+			name:       "Short take profit",
+			side:       "sell",
+			prices:     []float64{95, 90, 89},
+			wantExitOn: 2,
+		},
+		{
+			name:    "Short keeps position within borders",
+			side:    "sell",
+			prices:  []float64{94, 96, 105, 90},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			details := types.TradingDetails{
+				Symbol:     "PI_XBTUSD",
+				Side:       tc.side,
+				BuyPrice:   100,
+				Parameters: types.StrategyParameters{StopLossBorderParameter: 5, TakeProfitBorderParameter: 10},
+			}
+			analyzer := &candlesAnalyzer{live: testCandles(buyTime, tc.prices...)}
+
+			err := NewStopLossTakeProfitAlgo(analyzer).StartAnalyzing(context.Background(), buyTime, details)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, len(analyzer.live)-1, analyzer.lastDelivered())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantExitOn, analyzer.lastDelivered())
+		})
+	}
+}
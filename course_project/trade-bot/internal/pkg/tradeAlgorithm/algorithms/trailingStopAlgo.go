@@ -0,0 +1,54 @@
+package algorithms
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+)
+
+const (
+	TrailingStopName = "trailing_stop"
+
+	TrailBorderParameter = "trail_border"
+)
+
+// TrailingStopAlgo closes position when price retraces from its best value since buy by trail border
+type TrailingStopAlgo struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+}
+
+func NewTrailingStopAlgo(krakenAnalyzer web.KrakenAnalyzer) *TrailingStopAlgo {
+	return &TrailingStopAlgo{krakenWebsocketSDK: krakenAnalyzer}
+}
+
+func (a *TrailingStopAlgo) Schema() types.StrategySchema {
+	return types.StrategySchema{
+		Name:        TrailingStopName,
+		Description: "closes position when price retraces from its best value since buy by trail border",
+		Parameters: []types.ParameterSchema{
+			{Name: TrailBorderParameter, Description: "price delta of retrace to close position", Required: true, Positive: true},
+		},
+	}
+}
+
+func (a *TrailingStopAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	trail := details.Parameters[TrailBorderParameter]
+	long := isLong(details)
+	best := details.BuyPrice
+
+	return waitForExit(ctx, a.krakenWebsocketSDK, buyTime, details, func(price float64, afterBuy bool) bool {
+		if !afterBuy {
+			return false
+		}
+
+		if long {
+			best = math.Max(best, price)
+			return price <= best-trail
+		}
+		best = math.Min(best, price)
+		return price >= best+trail
+	})
+}
@@ -0,0 +1,141 @@
+// Package indicators contains technical indicators which are updated by candles one by one in constant time,
+// so strategies keep them up to date on every candle of websocket feed
+package indicators
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrInvalidPeriod    = errors.New("invalid indicator period")
+	ErrInvalidParameter = errors.New("invalid indicator parameter")
+	ErrParseCandle      = errors.New("parse candle")
+	ErrWarmUp           = errors.New("warm up indicator")
+)
+
+// Indicator is updated by every closed candle, its value is meaningful only when it is ready
+type Indicator interface {
+	Update(candle krakenFuturesWSSDK.Candle) error
+	Ready() bool
+}
+
+// WarmUp updates indicator by history candles sorted by time, so it is ready by the first live candle
+func WarmUp(indicator Indicator, history []krakenFuturesWSSDK.Candle) error {
+	for _, candle := range history {
+		if err := indicator.Update(candle); err != nil {
+			return fmt.Errorf("%s: %w", ErrWarmUp, err)
+		}
+	}
+	return nil
+}
+
+// ParseCandle parses prices of candle of websocket feed
+func ParseCandle(candle krakenFuturesWSSDK.Candle) (models.Candle, error) {
+	prices := make([]float64, 4)
+	for i, value := range []string{candle.Open, candle.High, candle.Low, candle.Close} {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return models.Candle{}, fmt.Errorf("%s: %w", ErrParseCandle, err)
+		}
+		prices[i] = price
+	}
+
+	return models.Candle{
+		Time:   time.Unix(int64(candle.Time), 0).UTC(),
+		Open:   prices[0],
+		High:   prices[1],
+		Low:    prices[2],
+		Close:  prices[3],
+		Volume: candle.Volume,
+	}, nil
+}
+
+func parseClose(candle krakenFuturesWSSDK.Candle) (float64, error) {
+	price, err := strconv.ParseFloat(candle.Close, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", ErrParseCandle, err)
+	}
+	return price, nil
+}
+
+func checkPeriod(period int) error {
+	if period < 1 {
+		return fmt.Errorf("%s: %d", ErrInvalidPeriod, period)
+	}
+	return nil
+}
+
+// ring keeps the last size values
+type ring struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newRing(size int) *ring {
+	return &ring{values: make([]float64, size)}
+}
+
+// push adds value and returns the oldest value when it is evicted
+func (r *ring) push(value float64) (float64, bool) {
+	evicted, full := r.values[r.next], r.full
+	r.values[r.next] = value
+	r.next++
+	if r.next == len(r.values) {
+		r.next = 0
+		r.full = true
+	}
+	return evicted, full
+}
+
+// count returns count of values in ring
+func (r *ring) count() int {
+	if r.full {
+		return len(r.values)
+	}
+	return r.next
+}
+
+// extremum keeps max (or min) of the last size values with monotonic queue, every value is pushed and
+// popped once, so update takes amortized constant time
+type extremum struct {
+	size    int
+	max     bool
+	index   int
+	indexes []int
+	values  []float64
+}
+
+func newExtremum(size int, max bool) *extremum {
+	return &extremum{size: size, max: max}
+}
+
+func (e *extremum) push(value float64) float64 {
+	for len(e.values) > 0 && e.dominates(value, e.values[len(e.values)-1]) {
+		e.values = e.values[:len(e.values)-1]
+		e.indexes = e.indexes[:len(e.indexes)-1]
+	}
+	e.values = append(e.values, value)
+	e.indexes = append(e.indexes, e.index)
+
+	if e.indexes[0] <= e.index-e.size {
+		e.values = e.values[1:]
+		e.indexes = e.indexes[1:]
+	}
+	e.index++
+	return e.values[0]
+}
+
+func (e *extremum) dominates(value, other float64) bool {
+	if e.max {
+		return value >= other
+	}
+	return value <= other
+}
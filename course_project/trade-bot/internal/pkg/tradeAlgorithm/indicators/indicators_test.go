@@ -0,0 +1,273 @@
+package indicators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// candles are one minute candles with close prices of Wilder's RSI example published by StockCharts,
+// high, low and volume are added to them. Expected values are counted by textbook definitions over
+// whole windows and rounded to 4 decimals (RSI to 2 decimals)
+var candles = []krakenFuturesWSSDK.Candle{
+	{Time: 1641772800, Open: "44.34", High: "44.59", Low: "43.79", Close: "44.34", Volume: 1200},
+	{Time: 1641772860, Open: "44.09", High: "44.49", Low: "43.79", Close: "44.09", Volume: 1573},
+	{Time: 1641772920, Open: "44.15", High: "44.33", Low: "43.93", Close: "44.15", Volume: 1946},
+	{Time: 1641772980, Open: "43.61", High: "44.16", Low: "43.14", Close: "43.61", Volume: 1419},
+	{Time: 1641773040, Open: "44.33", High: "44.63", Low: "43.98", Close: "44.33", Volume: 1792},
+	{Time: 1641773100, Open: "44.83", High: "45.05", Low: "44.55", Close: "44.83", Volume: 1265},
+	{Time: 1641773160, Open: "45.1", High: "45.57", Low: "44.5", Close: "45.1", Volume: 1638},
+	{Time: 1641773220, Open: "45.42", High: "45.77", Low: "45.17", Close: "45.42", Volume: 2011},
+	{Time: 1641773280, Open: "45.84", High: "46.12", Low: "45.44", Close: "45.84", Volume: 1484},
+	{Time: 1641773340, Open: "46.08", High: "46.68", Low: "45.9", Close: "46.08", Volume: 1857},
+	{Time: 1641773400, Open: "45.89", High: "46.14", Low: "45.34", Close: "45.89", Volume: 1330},
+	{Time: 1641773460, Open: "46.03", High: "46.43", Low: "45.73", Close: "46.03", Volume: 1703},
+	{Time: 1641773520, Open: "45.61", High: "45.79", Low: "45.39", Close: "45.61", Volume: 2076},
+	{Time: 1641773580, Open: "46.28", High: "46.83", Low: "45.81", Close: "46.28", Volume: 1549},
+	{Time: 1641773640, Open: "46.28", High: "46.58", Low: "45.93", Close: "46.28", Volume: 1922},
+	{Time: 1641773700, Open: "46.0", High: "46.22", Low: "45.72", Close: "46.0", Volume: 1395},
+	{Time: 1641773760, Open: "46.03", High: "46.5", Low: "45.43", Close: "46.03", Volume: 1768},
+	{Time: 1641773820, Open: "46.41", High: "46.76", Low: "46.16", Close: "46.41", Volume: 1241},
+	{Time: 1641773880, Open: "46.22", High: "46.5", Low: "45.82", Close: "46.22", Volume: 1614},
+	{Time: 1641773940, Open: "45.64", High: "46.24", Low: "45.46", Close: "45.64", Volume: 1987},
+	{Time: 1641774000, Open: "46.21", High: "46.46", Low: "45.66", Close: "46.21", Volume: 1460},
+	{Time: 1641774060, Open: "46.25", High: "46.65", Low: "45.95", Close: "46.25", Volume: 1833},
+	{Time: 1641774120, Open: "45.71", High: "45.89", Low: "45.49", Close: "45.71", Volume: 1306},
+	{Time: 1641774180, Open: "46.45", High: "47.0", Low: "45.98", Close: "46.45", Volume: 1679},
+	{Time: 1641774240, Open: "45.78", High: "46.08", Low: "45.43", Close: "45.78", Volume: 2052},
+	{Time: 1641774300, Open: "45.35", High: "45.57", Low: "45.07", Close: "45.35", Volume: 1525},
+	{Time: 1641774360, Open: "44.03", High: "44.5", Low: "43.43", Close: "44.03", Volume: 1898},
+	{Time: 1641774420, Open: "44.18", High: "44.53", Low: "43.93", Close: "44.18", Volume: 1371},
+	{Time: 1641774480, Open: "44.22", High: "44.5", Low: "43.82", Close: "44.22", Volume: 1744},
+	{Time: 1641774540, Open: "44.57", High: "45.17", Low: "44.39", Close: "44.57", Volume: 1217},
+	{Time: 1641774600, Open: "43.42", High: "43.67", Low: "42.87", Close: "43.42", Volume: 1590},
+	{Time: 1641774660, Open: "42.66", High: "43.06", Low: "42.36", Close: "42.66", Volume: 1963},
+	{Time: 1641774720, Open: "43.13", High: "43.31", Low: "42.91", Close: "43.13", Volume: 1436},
+}
+
+func TestIndicators_GoldenValues(t *testing.T) {
+	sma, _ := NewSMA(5)
+	ema, _ := NewEMA(5)
+	wma, _ := NewWMA(5)
+	rsi, _ := NewRSI(14)
+	macd, _ := NewMACD(5, 10, 4)
+	atr, _ := NewATR(14)
+	bollinger, _ := NewBollinger(20, 2)
+	stochastic, _ := NewStochastic(14, 3)
+	vwap := NewVWAP(10 * time.Minute)
+	obv := NewOBV()
+
+	tests := []struct {
+		name      string
+		indicator Indicator
+		value     func() []float64
+		delta     float64
+		want      [][]float64
+	}{
+		{
+			name:      "SMA",
+			indicator: sma,
+			value:     func() []float64 { return []float64{sma.Value()} },
+			want:      column([]float64{44.104, 44.202, 44.404, 44.658, 45.104, 45.454, 45.666, 45.852, 45.89, 45.978, 46.018, 46.04, 46.04, 46.2, 46.188, 46.06, 46.102, 46.146, 46.006, 46.052, 46.08, 45.908, 45.464, 45.158, 44.712, 44.47, 44.084, 43.81, 43.6}),
+		},
+		{
+			name:      "EMA",
+			indicator: ema,
+			value:     func() []float64 { return []float64{ema.Value()} },
+			want:      column([]float64{44.104, 44.346, 44.5973, 44.8716, 45.1944, 45.4896, 45.6231, 45.7587, 45.7091, 45.8994, 46.0263, 46.0175, 46.0217, 46.1511, 46.1741, 45.9961, 46.0674, 46.1282, 45.9888, 46.1426, 46.0217, 45.7978, 45.2085, 44.8657, 44.6505, 44.6236, 44.2224, 43.7016, 43.5111}),
+		},
+		{
+			name:      "WMA",
+			indicator: wma,
+			value:     func() []float64 { return []float64{wma.Value()} },
+			want:      column([]float64{44.0707, 44.3127, 44.612, 44.9507, 45.3447, 45.67, 45.8153, 45.9367, 45.856, 45.986, 46.0867, 46.0807, 46.0773, 46.2007, 46.2073, 46.0247, 46.0747, 46.124, 45.9787, 46.1267, 46.036, 45.7927, 45.1667, 44.7387, 44.426, 44.3787, 44.0287, 43.554, 43.3273}),
+		},
+		{
+			name:      "RSI",
+			indicator: rsi,
+			value:     func() []float64 { return []float64{rsi.Value()} },
+			delta:     0.005,
+			want:      column([]float64{70.46, 66.25, 66.48, 69.35, 66.29, 57.92, 62.88, 63.21, 56.01, 62.34, 54.67, 50.39, 40.02, 41.49, 41.9, 45.5, 37.32, 33.09, 37.79}),
+		},
+		{
+			name:      "MACD",
+			indicator: macd,
+			value: func() []float64 {
+				v := macd.Value()
+				return []float64{v.MACD, v.Signal, v.Histogram}
+			},
+			want: [][]float64{
+				{0.4577, 0.5993, -0.1416},
+				{0.461, 0.544, -0.083},
+				{0.4348, 0.5003, -0.0655},
+				{0.3518, 0.4409, -0.0891},
+				{0.2897, 0.3804, -0.0907},
+				{0.2959, 0.3466, -0.0507},
+				{0.2525, 0.309, -0.0565},
+				{0.1257, 0.2357, -0.11},
+				{0.1353, 0.1955, -0.0602},
+				{0.1383, 0.1726, -0.0343},
+				{0.0498, 0.1235, -0.0737},
+				{0.1106, 0.1184, -0.0077},
+				{0.0356, 0.0852, -0.0497},
+				{-0.0727, 0.0221, -0.0947},
+				{-0.3273, -0.1177, -0.2096},
+				{-0.4236, -0.2401, -0.1836},
+				{-0.4444, -0.3218, -0.1226},
+				{-0.3758, -0.3434, -0.0324},
+				{-0.4899, -0.402, -0.0879},
+				{-0.6375, -0.4962, -0.1413},
+				{-0.6082, -0.541, -0.0672},
+			},
+		},
+		{
+			name:      "ATR",
+			indicator: atr,
+			value:     func() []float64 { return []float64{atr.Value()} },
+			want:      column([]float64{0.8071, 0.7959, 0.7791, 0.7998, 0.7949, 0.7867, 0.7862, 0.7886, 0.7823, 0.7807, 0.8171, 0.8316, 0.8229, 0.9012, 0.8797, 0.8655, 0.8715, 0.9307, 0.9399, 0.9192}),
+		},
+		{
+			name:      "Bollinger",
+			indicator: bollinger,
+			value: func() []float64 {
+				v := bollinger.Value()
+				return []float64{v.Upper, v.Middle, v.Lower}
+			},
+			want: [][]float64{
+				{47.1153, 45.409, 43.7027},
+				{47.1687, 45.5025, 43.8363},
+				{47.1733, 45.6105, 44.0477},
+				{47.1004, 45.6885, 44.2766},
+				{46.9097, 45.8305, 44.7513},
+				{46.736, 45.903, 45.07},
+				{46.6516, 45.929, 45.2064},
+				{46.9217, 45.8755, 44.8293},
+				{47.0834, 45.8135, 44.5436},
+				{47.1796, 45.7325, 44.2854},
+				{47.1793, 45.657, 44.1347},
+				{47.3352, 45.5335, 43.7318},
+				{47.541, 45.365, 43.189},
+				{47.6202, 45.241, 42.8618},
+			},
+		},
+		{
+			name:      "Stochastic",
+			indicator: stochastic,
+			value: func() []float64 {
+				v := stochastic.Value()
+				return []float64{v.K, v.D}
+			},
+			want: [][]float64{
+				{77.5068, 82.5655},
+				{78.3198, 80.3071},
+				{85.2632, 80.3632},
+				{73.8197, 79.1342},
+				{48.927, 69.3366},
+				{62.6506, 61.7991},
+				{61.0738, 57.5505},
+				{24.8322, 49.5189},
+				{66.8675, 50.9245},
+				{24.2236, 38.6411},
+				{14.5078, 35.1996},
+				{16.8067, 18.5127},
+				{21.0084, 17.441},
+				{22.1289, 19.9813},
+				{31.9328, 25.0233},
+				{13.3172, 22.4596},
+				{6.4655, 17.2385},
+				{16.5948, 12.1258},
+			},
+		},
+		{
+			name:      "VWAP",
+			indicator: vwap,
+			value:     func() []float64 { return []float64{vwap.Value()} },
+			want:      column([]float64{44.24, 44.1738, 44.1585, 44.0379, 44.1001, 44.1978, 44.3276, 44.5039, 44.6381, 44.8196, 45.79, 45.9435, 45.8026, 45.9198, 45.9968, 45.9944, 45.9933, 46.0363, 46.0522, 46.0196, 46.11, 46.2065, 46.0617, 46.1727, 46.0718, 45.9571, 45.6389, 45.4899, 45.3363, 45.2889, 43.32, 42.9738, 43.0149}),
+		},
+		{
+			name:      "OBV",
+			indicator: obv,
+			value:     func() []float64 { return []float64{obv.Value()} },
+			want:      column([]float64{0, -1573, 373, -1046, 746, 2011, 3649, 5660, 7144, 9001, 7671, 9374, 7298, 8847, 8847, 7452, 9220, 10461, 8847, 6860, 8320, 10153, 8847, 10526, 8474, 6949, 5051, 6422, 8166, 9383, 7793, 5830, 7266}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delta := test.delta
+			if delta == 0 {
+				delta = 0.00005
+			}
+
+			var got [][]float64
+			for _, candle := range candles {
+				if err := test.indicator.Update(candle); err != nil {
+					t.Fatalf("unexpected error of update: %s", err)
+				}
+				if test.indicator.Ready() {
+					got = append(got, test.value())
+				}
+			}
+
+			if !assert.Len(t, got, len(test.want)) {
+				return
+			}
+			for i := range test.want {
+				assert.InDeltaSlice(t, test.want[i], got[i], delta, "value %d", i)
+			}
+		})
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	live, _ := NewMACD(5, 10, 4)
+	for _, candle := range candles {
+		if err := live.Update(candle); err != nil {
+			t.Fatalf("unexpected error of update: %s", err)
+		}
+	}
+
+	warmed, _ := NewMACD(5, 10, 4)
+	assert.NoError(t, WarmUp(warmed, candles[:20]))
+	assert.True(t, warmed.Ready())
+	for _, candle := range candles[20:] {
+		assert.NoError(t, warmed.Update(candle))
+	}
+	assert.Equal(t, live.Value(), warmed.Value())
+
+	invalid := []krakenFuturesWSSDK.Candle{{Time: 1, Close: "price"}}
+	assert.Error(t, WarmUp(warmed, invalid))
+}
+
+func TestStochastic_FlatRange(t *testing.T) {
+	stochastic, _ := NewStochastic(2, 1)
+	stochastic.Add(10, 10, 10)
+	stochastic.Add(10, 10, 10)
+
+	assert.True(t, stochastic.Ready())
+	assert.Equal(t, StochasticValue{K: 50, D: 50}, stochastic.Value())
+}
+
+func TestNewIndicators_InvalidParameters(t *testing.T) {
+	_, err := NewSMA(0)
+	assert.Error(t, err)
+	_, err = NewEMA(-1)
+	assert.Error(t, err)
+	_, err = NewMACD(26, 12, 9)
+	assert.Error(t, err)
+	_, err = NewStochastic(14, 0)
+	assert.Error(t, err)
+	_, err = NewBollinger(20, -1)
+	assert.Error(t, err)
+}
+
+func column(values []float64) [][]float64 {
+	column := make([][]float64, len(values))
+	for i, value := range values {
+		column[i] = []float64{value}
+	}
+	return column
+}
@@ -0,0 +1,141 @@
+package indicators
+
+import (
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// SMA is a simple moving average of close prices
+type SMA struct {
+	values *ring
+	sum    float64
+}
+
+func NewSMA(period int) (*SMA, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &SMA{values: newRing(period)}, nil
+}
+
+func (s *SMA) Update(candle krakenFuturesWSSDK.Candle) error {
+	price, err := parseClose(candle)
+	if err != nil {
+		return err
+	}
+	s.Add(price)
+	return nil
+}
+
+// Add updates average by value, so average of other series is built too
+func (s *SMA) Add(value float64) {
+	evicted, ok := s.values.push(value)
+	if ok {
+		s.sum -= evicted
+	}
+	s.sum += value
+}
+
+func (s *SMA) Ready() bool {
+	return s.values.full
+}
+
+func (s *SMA) Value() float64 {
+	if s.values.count() == 0 {
+		return 0
+	}
+	return s.sum / float64(s.values.count())
+}
+
+// EMA is an exponential moving average of close prices seeded with simple moving average of first period prices
+type EMA struct {
+	alpha float64
+	seed  *SMA
+	value float64
+	ready bool
+}
+
+func NewEMA(period int) (*EMA, error) {
+	seed, err := NewSMA(period)
+	if err != nil {
+		return nil, err
+	}
+	return &EMA{alpha: 2 / float64(period+1), seed: seed}, nil
+}
+
+func (e *EMA) Update(candle krakenFuturesWSSDK.Candle) error {
+	price, err := parseClose(candle)
+	if err != nil {
+		return err
+	}
+	e.Add(price)
+	return nil
+}
+
+func (e *EMA) Add(value float64) {
+	if e.ready {
+		e.value = e.alpha*value + (1-e.alpha)*e.value
+		return
+	}
+
+	e.seed.Add(value)
+	if e.seed.Ready() {
+		e.value = e.seed.Value()
+		e.ready = true
+	}
+}
+
+func (e *EMA) Ready() bool {
+	return e.ready
+}
+
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+// WMA is a linearly weighted moving average of close prices, the latest price has weight of period.
+// Weighted sum is shifted by sum of window on every value instead of recounting it
+type WMA struct {
+	values   *ring
+	period   int
+	sum      float64
+	weighted float64
+}
+
+func NewWMA(period int) (*WMA, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &WMA{values: newRing(period), period: period}, nil
+}
+
+func (w *WMA) Update(candle krakenFuturesWSSDK.Candle) error {
+	price, err := parseClose(candle)
+	if err != nil {
+		return err
+	}
+	w.Add(price)
+	return nil
+}
+
+func (w *WMA) Add(value float64) {
+	evicted, ok := w.values.push(value)
+	if ok {
+		w.weighted += float64(w.period)*value - w.sum
+		w.sum += value - evicted
+		return
+	}
+	w.weighted += float64(w.values.count()) * value
+	w.sum += value
+}
+
+func (w *WMA) Ready() bool {
+	return w.values.full
+}
+
+func (w *WMA) Value() float64 {
+	count := w.values.count()
+	if count == 0 {
+		return 0
+	}
+	return w.weighted / float64(count*(count+1)/2)
+}
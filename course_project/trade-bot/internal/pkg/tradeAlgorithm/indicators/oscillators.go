@@ -0,0 +1,204 @@
+package indicators
+
+import (
+	"fmt"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// RSI is a relative strength index of close prices with Wilder's smoothing
+type RSI struct {
+	period  int
+	prev    float64
+	count   int
+	avgGain float64
+	avgLoss float64
+}
+
+func NewRSI(period int) (*RSI, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &RSI{period: period}, nil
+}
+
+func (r *RSI) Update(candle krakenFuturesWSSDK.Candle) error {
+	price, err := parseClose(candle)
+	if err != nil {
+		return err
+	}
+	r.Add(price)
+	return nil
+}
+
+func (r *RSI) Add(price float64) {
+	r.count++
+	if r.count == 1 {
+		r.prev = price
+		return
+	}
+
+	change := price - r.prev
+	r.prev = price
+
+	gain, loss := 0.0, 0.0
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	// the first averages are simple averages of period changes
+	period := float64(r.period)
+	if r.count <= r.period+1 {
+		r.avgGain += gain / period
+		r.avgLoss += loss / period
+		return
+	}
+	r.avgGain = (r.avgGain*(period-1) + gain) / period
+	r.avgLoss = (r.avgLoss*(period-1) + loss) / period
+}
+
+func (r *RSI) Ready() bool {
+	return r.count > r.period
+}
+
+func (r *RSI) Value() float64 {
+	if !r.Ready() {
+		return 0
+	}
+	if r.avgLoss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+r.avgGain/r.avgLoss)
+}
+
+// MACDValue is moving average convergence divergence line, its signal line and difference between them
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// MACD is a difference between fast and slow exponential moving averages of close prices
+// with exponential moving average of the difference as signal line
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	line   float64
+}
+
+func NewMACD(fastPeriod, slowPeriod, signalPeriod int) (*MACD, error) {
+	if fastPeriod >= slowPeriod {
+		return nil, fmt.Errorf("%s: fast period %d must be less than slow period %d", ErrInvalidPeriod, fastPeriod, slowPeriod)
+	}
+	fast, err := NewEMA(fastPeriod)
+	if err != nil {
+		return nil, err
+	}
+	slow, err := NewEMA(slowPeriod)
+	if err != nil {
+		return nil, err
+	}
+	signal, err := NewEMA(signalPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return &MACD{fast: fast, slow: slow, signal: signal}, nil
+}
+
+func (m *MACD) Update(candle krakenFuturesWSSDK.Candle) error {
+	price, err := parseClose(candle)
+	if err != nil {
+		return err
+	}
+	m.Add(price)
+	return nil
+}
+
+func (m *MACD) Add(price float64) {
+	m.fast.Add(price)
+	m.slow.Add(price)
+	if !m.slow.Ready() {
+		return
+	}
+	m.line = m.fast.Value() - m.slow.Value()
+	m.signal.Add(m.line)
+}
+
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
+
+func (m *MACD) Value() MACDValue {
+	return MACDValue{
+		MACD:      m.line,
+		Signal:    m.signal.Value(),
+		Histogram: m.line - m.signal.Value(),
+	}
+}
+
+// StochasticValue is %K of stochastic oscillator and its simple moving average %D
+type StochasticValue struct {
+	K float64
+	D float64
+}
+
+// Stochastic is a position of close price in range of the last period candles in percents.
+// Close of candles range with zero width is in the middle of it
+type Stochastic struct {
+	period  int
+	count   int
+	highest *extremum
+	lowest  *extremum
+	k       float64
+	d       *SMA
+}
+
+func NewStochastic(period, smoothingPeriod int) (*Stochastic, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	d, err := NewSMA(smoothingPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return &Stochastic{
+		period:  period,
+		highest: newExtremum(period, true),
+		lowest:  newExtremum(period, false),
+		d:       d,
+	}, nil
+}
+
+func (s *Stochastic) Update(candle krakenFuturesWSSDK.Candle) error {
+	parsed, err := ParseCandle(candle)
+	if err != nil {
+		return err
+	}
+	s.Add(parsed.High, parsed.Low, parsed.Close)
+	return nil
+}
+
+func (s *Stochastic) Add(high, low, price float64) {
+	highest, lowest := s.highest.push(high), s.lowest.push(low)
+	s.count++
+	if s.count < s.period {
+		return
+	}
+
+	s.k = 50
+	if highest > lowest {
+		s.k = 100 * (price - lowest) / (highest - lowest)
+	}
+	s.d.Add(s.k)
+}
+
+func (s *Stochastic) Ready() bool {
+	return s.d.Ready()
+}
+
+func (s *Stochastic) Value() StochasticValue {
+	return StochasticValue{K: s.k, D: s.d.Value()}
+}
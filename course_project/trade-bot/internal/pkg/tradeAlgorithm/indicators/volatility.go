@@ -0,0 +1,129 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// ATR is an average true range with Wilder's smoothing, true range of the first candle is its high low range
+type ATR struct {
+	period    int
+	count     int
+	prevClose float64
+	value     float64
+}
+
+func NewATR(period int) (*ATR, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &ATR{period: period}, nil
+}
+
+func (a *ATR) Update(candle krakenFuturesWSSDK.Candle) error {
+	parsed, err := ParseCandle(candle)
+	if err != nil {
+		return err
+	}
+	a.Add(parsed.High, parsed.Low, parsed.Close)
+	return nil
+}
+
+func (a *ATR) Add(high, low, price float64) {
+	trueRange := high - low
+	if a.count > 0 {
+		trueRange = math.Max(trueRange, math.Max(math.Abs(high-a.prevClose), math.Abs(low-a.prevClose)))
+	}
+	a.prevClose = price
+	a.count++
+
+	period := float64(a.period)
+	if a.count <= a.period {
+		a.value += trueRange / period
+		return
+	}
+	a.value = (a.value*(period-1) + trueRange) / period
+}
+
+func (a *ATR) Ready() bool {
+	return a.count >= a.period
+}
+
+func (a *ATR) Value() float64 {
+	if !a.Ready() {
+		return 0
+	}
+	return a.value
+}
+
+// Bands are bollinger bands around moving average
+type Bands struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+// Bollinger is simple moving average of close prices with bands at count of standard deviations from it.
+// Mean and sum of squared deviations of window are updated by replaced value, so they are not recounted
+// and don't lose precision like sum of squares
+type Bollinger struct {
+	values     *ring
+	deviations float64
+	mean       float64
+	squares    float64
+}
+
+func NewBollinger(period int, deviations float64) (*Bollinger, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	if deviations < 0 {
+		return nil, fmt.Errorf("%s: deviations %v", ErrInvalidParameter, deviations)
+	}
+	return &Bollinger{values: newRing(period), deviations: deviations}, nil
+}
+
+func (b *Bollinger) Update(candle krakenFuturesWSSDK.Candle) error {
+	price, err := parseClose(candle)
+	if err != nil {
+		return err
+	}
+	b.Add(price)
+	return nil
+}
+
+func (b *Bollinger) Add(price float64) {
+	evicted, ok := b.values.push(price)
+	if !ok {
+		delta := price - b.mean
+		b.mean += delta / float64(b.values.count())
+		b.squares += delta * (price - b.mean)
+		return
+	}
+
+	prevMean := b.mean
+	b.mean += (price - evicted) / float64(b.values.count())
+	b.squares += (price - evicted) * (price - b.mean + evicted - prevMean)
+	if b.squares < 0 {
+		b.squares = 0
+	}
+}
+
+func (b *Bollinger) Ready() bool {
+	return b.values.full
+}
+
+// StdDev returns population standard deviation of window
+func (b *Bollinger) StdDev() float64 {
+	if b.values.count() == 0 {
+		return 0
+	}
+	return math.Sqrt(b.squares / float64(b.values.count()))
+}
+
+func (b *Bollinger) Value() Bands {
+	width := b.deviations * b.StdDev()
+	return Bands{Upper: b.mean + width, Middle: b.mean, Lower: b.mean - width}
+}
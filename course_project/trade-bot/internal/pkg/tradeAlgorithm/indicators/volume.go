@@ -0,0 +1,98 @@
+package indicators
+
+import (
+	"time"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// VWAP is a volume weighted average of typical prices (high + low + close) / 3 of candles since start
+// of session. Sessions start at multiples of session length in UTC, zero length means one endless session
+type VWAP struct {
+	session      time.Duration
+	start        time.Time
+	volume       float64
+	weightedSum  float64
+	sessionKnown bool
+}
+
+func NewVWAP(session time.Duration) *VWAP {
+	return &VWAP{session: session}
+}
+
+func (v *VWAP) Update(candle krakenFuturesWSSDK.Candle) error {
+	parsed, err := ParseCandle(candle)
+	if err != nil {
+		return err
+	}
+	v.Add(parsed)
+	return nil
+}
+
+func (v *VWAP) Add(candle models.Candle) {
+	if v.session > 0 {
+		start := candle.Time.Truncate(v.session)
+		if !v.sessionKnown || !start.Equal(v.start) {
+			v.start, v.sessionKnown = start, true
+			v.volume, v.weightedSum = 0, 0
+		}
+	}
+
+	volume := float64(candle.Volume)
+	v.volume += volume
+	v.weightedSum += volume * (candle.High + candle.Low + candle.Close) / 3
+}
+
+// Ready reports whether some volume is traded in session
+func (v *VWAP) Ready() bool {
+	return v.volume > 0
+}
+
+func (v *VWAP) Value() float64 {
+	if !v.Ready() {
+		return 0
+	}
+	return v.weightedSum / v.volume
+}
+
+// OBV is an on-balance volume, volume of candle is added when close price rises and subtracted when it falls
+type OBV struct {
+	count     int
+	prevClose float64
+	value     float64
+}
+
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+func (o *OBV) Update(candle krakenFuturesWSSDK.Candle) error {
+	price, err := parseClose(candle)
+	if err != nil {
+		return err
+	}
+	o.Add(price, float64(candle.Volume))
+	return nil
+}
+
+func (o *OBV) Add(price, volume float64) {
+	if o.count > 0 {
+		switch {
+		case price > o.prevClose:
+			o.value += volume
+		case price < o.prevClose:
+			o.value -= volume
+		}
+	}
+	o.prevClose = price
+	o.count++
+}
+
+func (o *OBV) Ready() bool {
+	return o.count > 0
+}
+
+func (o *OBV) Value() float64 {
+	return o.value
+}
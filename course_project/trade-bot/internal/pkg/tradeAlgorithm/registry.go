@@ -0,0 +1,183 @@
+package tradeAlgorithm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/algorithms"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+var (
+	ErrUnknownStrategy = errors.New("unknown strategy")
+	ErrValidateDetails = errors.New("validate trading details")
+	ErrBracketStrategy = errors.New("bracket orders are supported by stop loss & take profit strategy only")
+	ErrRulesStrategy   = errors.New("rules are supported by rules strategy only")
+)
+
+const defaultTriggerSignal = "mark"
+
+// EntryAlgorithm is an Algorithm which opens position only when its entry conditions are met
+type EntryAlgorithm interface {
+	Algorithm
+	WaitForEntry(ctx context.Context, details types.TradingDetails) error
+}
+
+// Registry is a Trader which runs one of registered algorithms by strategy name of trading details
+type Registry struct {
+	mu              sync.RWMutex
+	algorithms      map[string]Algorithm
+	defaultStrategy string
+}
+
+func NewRegistry(defaultStrategy string) *Registry {
+	return &Registry{algorithms: make(map[string]Algorithm), defaultStrategy: defaultStrategy}
+}
+
+// Register adds algorithm under name from its schema, replacing already registered one
+func (r *Registry) Register(algorithm Algorithm) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.algorithms[algorithm.Schema().Name] = algorithm
+}
+
+// ValidateDetails resolves strategy of trading details and validates its parameters.
+// Returned details have defaults of missing optional parameters
+func (r *Registry) ValidateDetails(details types.TradingDetails) (types.TradingDetails, error) {
+	if details.Strategy == "" && details.Rules != nil {
+		details.Strategy = types.RulesStrategy
+	}
+	if details.Strategy == "" {
+		details.Strategy = r.defaultStrategy
+	}
+
+	algorithm, err := r.algorithm(details.Strategy)
+	if err != nil {
+		return types.TradingDetails{}, fmt.Errorf("%s: %w", ErrValidateDetails, err)
+	}
+
+	if details.Strategy == types.RulesStrategy {
+		if details, err = withRules(details); err != nil {
+			return types.TradingDetails{}, fmt.Errorf("%s: %w", ErrValidateDetails, err)
+		}
+	} else if details.Rules != nil {
+		return types.TradingDetails{}, fmt.Errorf("%s: %w", ErrValidateDetails, ErrRulesStrategy)
+	}
+
+	params := withLegacyBorders(details)
+	validated, err := algorithm.Schema().Validate(params)
+	if err != nil {
+		return types.TradingDetails{}, fmt.Errorf("%s: %w", ErrValidateDetails, err)
+	}
+
+	details.Parameters = validated
+
+	if details.Bracket {
+		if details.Strategy != algorithms.StopLossTakeProfitName {
+			return types.TradingDetails{}, fmt.Errorf("%s: %w", ErrValidateDetails, ErrBracketStrategy)
+		}
+		if details.TriggerSignal == "" {
+			details.TriggerSignal = defaultTriggerSignal
+		}
+	}
+
+	return details, nil
+}
+
+func (r *Registry) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
+	details, err := r.ValidateDetails(details)
+	if err != nil {
+		return err
+	}
+
+	algorithm, err := r.algorithm(details.Strategy)
+	if err != nil {
+		return err
+	}
+	return algorithm.StartAnalyzing(ctx, buyTime, details)
+}
+
+// WaitForEntry waits until entry conditions of algorithm of details are met,
+// position of algorithm without entry conditions is opened right away
+func (r *Registry) WaitForEntry(ctx context.Context, details types.TradingDetails) error {
+	details, err := r.ValidateDetails(details)
+	if err != nil {
+		return err
+	}
+
+	algorithm, err := r.algorithm(details.Strategy)
+	if err != nil {
+		return err
+	}
+	if entryAlgorithm, ok := algorithm.(EntryAlgorithm); ok {
+		return entryAlgorithm.WaitForEntry(ctx, details)
+	}
+	return nil
+}
+
+// Schemas returns schemas of registered algorithms sorted by name
+func (r *Registry) Schemas() []types.StrategySchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schemas := make([]types.StrategySchema, 0, len(r.algorithms))
+	for _, algorithm := range r.algorithms {
+		schemas = append(schemas, algorithm.Schema())
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+	return schemas
+}
+
+func (r *Registry) algorithm(strategy string) (Algorithm, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	algorithm, ok := r.algorithms[strategy]
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", ErrUnknownStrategy, strategy)
+	}
+	return algorithm, nil
+}
+
+// withLegacyBorders moves stop loss and take profit borders of trading details into parameters
+// of stop loss & take profit strategy, so clients which do not know about strategies keep working
+func withLegacyBorders(details types.TradingDetails) types.StrategyParameters {
+	params := make(types.StrategyParameters, len(details.Parameters))
+	for name, value := range details.Parameters {
+		params[name] = value
+	}
+
+	if details.Strategy != algorithms.StopLossTakeProfitName {
+		return params
+	}
+	if _, ok := params[algorithms.StopLossBorderParameter]; !ok && details.StopLossBorder != nil {
+		params[algorithms.StopLossBorderParameter] = *details.StopLossBorder
+	}
+	if _, ok := params[algorithms.TakeProfitBorderParameter]; !ok && details.TakeProfitBorder != nil {
+		params[algorithms.TakeProfitBorderParameter] = *details.TakeProfitBorder
+	}
+	return params
+}
+
+// withRules replaces position of details by position of normalized rules, so rules are the only source of it
+func withRules(details types.TradingDetails) (types.TradingDetails, error) {
+	if details.Rules == nil {
+		return types.TradingDetails{}, algorithms.ErrMissingRules
+	}
+	rules, err := details.Rules.Normalize()
+	if err != nil {
+		return types.TradingDetails{}, err
+	}
+
+	validated := rules.TradingDetails()
+	validated.BuyPrice = details.BuyPrice
+	return validated, nil
+}
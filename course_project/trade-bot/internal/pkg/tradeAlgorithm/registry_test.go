@@ -0,0 +1,306 @@
+package tradeAlgorithm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/algorithms"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// pricesAnalyzer streams candles with close prices, other market data is not used by strategies under test
+type pricesAnalyzer struct {
+	web.KrakenAnalyzer
+	start  time.Time
+	prices []float64
+}
+
+func (a pricesAnalyzer) LookForCandles(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.Candle, error) {
+	candles := make(chan krakenFuturesWSSDK.Candle)
+	go func() {
+		defer close(candles)
+		for i, price := range a.prices {
+			value := fmt.Sprintf("%f", price)
+			candle := krakenFuturesWSSDK.Candle{
+				Time:  int(a.start.Add(time.Duration(i) * time.Minute).Unix()),
+				Open:  value,
+				High:  value,
+				Low:   value,
+				Close: value,
+			}
+			select {
+			case candles <- candle:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return candles, nil
+}
+
+// CandlesHistory returns no candles, so strategies warm up on streamed ones
+func (a pricesAnalyzer) CandlesHistory(feed, productID string, count int) ([]krakenFuturesWSSDK.Candle, error) {
+	return nil, nil
+}
+
+func newTestTradeAlgorithm(analyzer web.KrakenAnalyzer) *TradeAlgorithm {
+	return NewTradeAlgorithm(&web.Web{KrakenAnalyzer: analyzer})
+}
+
+// crossoverRules close long position when fast moving average crosses slow one from above
+var crossoverRules = types.Rules{
+	Symbol: "PI_XBTUSD",
+	Side:   "buy",
+	Indicators: map[string]types.IndicatorRule{
+		"fast": {Type: types.SMAIndicator, Period: 2},
+		"slow": {Type: types.SMAIndicator, Period: 4},
+	},
+	Exit:   types.ExitRules{Conditions: types.Conditions{Any: []string{"fast crosses_below slow"}}},
+	Sizing: types.SizingRules{Size: 1},
+}
+
+func withExit(rules types.Rules, exit types.ExitRules) *types.Rules {
+	rules.Exit = exit
+	return &rules
+}
+
+func TestRegistry_ValidateDetails(t *testing.T) {
+	algorithm := newTestTradeAlgorithm(pricesAnalyzer{})
+
+	tests := []struct {
+		name        string
+		details     types.TradingDetails
+		want        types.StrategyParameters
+		wantErr     bool
+		wantErrText string
+	}{
+		{
+			name:    "Legacy stop loss take profit",
+			details: types.TradingDetails{StopLossBorder: types.Bound(10), TakeProfitBorder: types.Bound(20)},
+			want:    types.StrategyParameters{"stop_loss_border": 10, "take_profit_border": 20},
+		},
+		{
+			name:        "Legacy zero border",
+			details:     types.TradingDetails{StopLossBorder: types.Bound(0), TakeProfitBorder: types.Bound(20)},
+			wantErr:     true,
+			wantErrText: "stop_loss_border = 0, must be positive",
+		},
+		{
+			name:    "Zero trail border",
+			details: types.TradingDetails{Strategy: "trailing_stop", Parameters: types.StrategyParameters{"trail_border": 0}},
+			wantErr: true,
+		},
+		{
+			name:    "Stop loss take profit without borders",
+			details: types.TradingDetails{},
+			wantErr: true,
+		},
+		{
+			name:    "Defaults",
+			details: types.TradingDetails{Strategy: "ema_crossover"},
+			want:    types.StrategyParameters{"fast_period": 9, "slow_period": 21},
+		},
+		{
+			name:    "Unknown strategy",
+			details: types.TradingDetails{Strategy: "unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown parameter",
+			details: types.TradingDetails{Strategy: "trailing_stop", Parameters: types.StrategyParameters{"trail_border": 1, "other": 1}},
+			wantErr: true,
+		},
+		{
+			name:    "Not integer period",
+			details: types.TradingDetails{Strategy: "rsi_threshold", Parameters: types.StrategyParameters{"period": 1.5}},
+			wantErr: true,
+		},
+		{
+			name:    "Out of range",
+			details: types.TradingDetails{Strategy: "rsi_threshold", Parameters: types.StrategyParameters{"overbought": 120}},
+			wantErr: true,
+		},
+		{
+			name:    "Bracket",
+			details: types.TradingDetails{Bracket: true, StopLossBorder: types.Bound(10), TakeProfitBorder: types.Bound(20)},
+			want:    types.StrategyParameters{"stop_loss_border": 10, "take_profit_border": 20},
+		},
+		{
+			name:    "Bracket with other strategy",
+			details: types.TradingDetails{Bracket: true, Strategy: "ema_crossover"},
+			wantErr: true,
+		},
+		{
+			name:    "Fast period not less than slow",
+			details: types.TradingDetails{Strategy: "sma_crossover", Parameters: types.StrategyParameters{"fast_period": 30}},
+			wantErr: true,
+		},
+		{
+			name:    "Rules",
+			details: types.TradingDetails{Rules: &crossoverRules},
+			want:    types.StrategyParameters{},
+		},
+		{
+			name:    "Rules with other strategy",
+			details: types.TradingDetails{Strategy: "ema_crossover", Rules: &crossoverRules},
+			wantErr: true,
+		},
+		{
+			name:    "Rules strategy without rules",
+			details: types.TradingDetails{Strategy: "rules"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid rules",
+			details: types.TradingDetails{Rules: withExit(crossoverRules, types.ExitRules{})},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := algorithm.ValidateDetails(test.details)
+			if test.wantErr {
+				assert.Error(t, err)
+				if test.wantErrText != "" {
+					assert.Contains(t, err.Error(), test.wantErrText)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got.Parameters)
+			}
+		})
+	}
+}
+
+func TestRegistry_StartAnalyzing(t *testing.T) {
+	buyTime := time.Unix(1640000000, 0)
+
+	tests := []struct {
+		name    string
+		prices  []float64
+		details types.TradingDetails
+		wantErr bool
+	}{
+		{
+			name:   "Trailing stop hit",
+			prices: []float64{100, 105, 110, 104},
+			details: types.TradingDetails{Side: "buy", BuyPrice: 100, Strategy: algorithms.TrailingStopName,
+				Parameters: types.StrategyParameters{"trail_border": 5}},
+		},
+		{
+			name:   "Trailing stop not hit",
+			prices: []float64{100, 105, 110, 106},
+			details: types.TradingDetails{Side: "buy", BuyPrice: 100, Strategy: algorithms.TrailingStopName,
+				Parameters: types.StrategyParameters{"trail_border": 5}},
+			wantErr: true,
+		},
+		{
+			name:   "SMA crossover",
+			prices: []float64{1, 2, 3, 4, 5, 4, 3, 2},
+			details: types.TradingDetails{Side: "buy", Strategy: algorithms.SMACrossoverName,
+				Parameters: types.StrategyParameters{"fast_period": 2, "slow_period": 4}},
+		},
+		{
+			name:   "RSI overbought",
+			prices: []float64{1, 2, 3, 4},
+			details: types.TradingDetails{Side: "buy", Strategy: algorithms.RSIThresholdName,
+				Parameters: types.StrategyParameters{"period": 2}},
+		},
+		{
+			name:   "Bollinger breakout",
+			prices: []float64{10, 11, 10, 11, 20},
+			details: types.TradingDetails{Side: "sell", Strategy: algorithms.BollingerBreakoutName,
+				Parameters: types.StrategyParameters{"period": 4}},
+		},
+		{
+			name:    "Rules exit condition",
+			prices:  []float64{1, 2, 3, 4, 5, 4, 3, 2},
+			details: types.TradingDetails{Rules: &crossoverRules},
+		},
+		{
+			name:    "Rules exit condition not met",
+			prices:  []float64{1, 2, 3, 4, 5, 6, 7, 8},
+			details: types.TradingDetails{Rules: &crossoverRules},
+			wantErr: true,
+		},
+		{
+			name:    "Rules stop loss",
+			prices:  []float64{100, 98, 94},
+			details: types.TradingDetails{BuyPrice: 100, Rules: withExit(crossoverRules, types.ExitRules{StopLoss: 5})},
+		},
+		{
+			name:    "Rules max candles",
+			prices:  []float64{100, 100},
+			details: types.TradingDetails{Rules: withExit(crossoverRules, types.ExitRules{MaxCandles: 2})},
+		},
+		{
+			name:    "Invalid details",
+			prices:  []float64{1},
+			details: types.TradingDetails{Strategy: "unknown"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			algorithm := newTestTradeAlgorithm(pricesAnalyzer{start: buyTime, prices: test.prices})
+
+			err := algorithm.StartAnalyzing(context.Background(), buyTime, test.details)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRegistry_WaitForEntry(t *testing.T) {
+	rules := crossoverRules
+	rules.Entry = types.Conditions{All: []string{"price > 10"}}
+	// candles start after call of WaitForEntry, so they are not warm up candles
+	start := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		prices  []float64
+		details types.TradingDetails
+		wantErr bool
+	}{
+		{
+			name:    "Entry conditions met",
+			prices:  []float64{5, 8, 11},
+			details: types.TradingDetails{Rules: &rules},
+		},
+		{
+			name:    "Entry conditions not met",
+			prices:  []float64{5, 8, 10},
+			details: types.TradingDetails{Rules: &rules},
+			wantErr: true,
+		},
+		{
+			name:    "Strategy without entry conditions",
+			details: types.TradingDetails{Strategy: algorithms.EMACrossoverName},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			algorithm := newTestTradeAlgorithm(pricesAnalyzer{start: start, prices: test.prices})
+
+			err := algorithm.WaitForEntry(context.Background(), test.details)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
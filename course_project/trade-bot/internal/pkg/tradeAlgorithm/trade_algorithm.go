@@ -12,10 +12,32 @@ type Trader interface {
 	StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error
 }
 
-type TradeAlgorithm struct {
+// Algorithm is a Trader which describes its parameters
+type Algorithm interface {
+	Trader
+	Schema() types.StrategySchema
+}
+
+type Strategies interface {
 	Trader
+	WaitForEntry(ctx context.Context, details types.TradingDetails) error
+	ValidateDetails(details types.TradingDetails) (types.TradingDetails, error)
+	Schemas() []types.StrategySchema
+}
+
+type TradeAlgorithm struct {
+	Strategies
 }
 
 func NewTradeAlgorithm(w *web.Web) *TradeAlgorithm {
-	return &TradeAlgorithm{Trader: algorithms.NewStopLossTakeProfitAlgo(w.KrakenAnalyzer)}
+	registry := NewRegistry(algorithms.StopLossTakeProfitName)
+	registry.Register(algorithms.NewStopLossTakeProfitAlgo(w.KrakenAnalyzer))
+	registry.Register(algorithms.NewTrailingStopAlgo(w.KrakenAnalyzer))
+	registry.Register(algorithms.NewSMACrossoverAlgo(w.KrakenAnalyzer))
+	registry.Register(algorithms.NewEMACrossoverAlgo(w.KrakenAnalyzer))
+	registry.Register(algorithms.NewRSIThresholdAlgo(w.KrakenAnalyzer))
+	registry.Register(algorithms.NewBollingerBreakoutAlgo(w.KrakenAnalyzer))
+	registry.Register(algorithms.NewRulesAlgo(w.KrakenAnalyzer))
+
+	return &TradeAlgorithm{Strategies: registry}
 }
@@ -0,0 +1,433 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrParseRules       = errors.New("parse strategy rules")
+	ErrInvalidRules     = errors.New("invalid strategy rules")
+	ErrInvalidCondition = errors.New("invalid condition")
+)
+
+// RulesStrategy is a name of strategy which runs rules of trading details
+const RulesStrategy = "rules"
+
+// Operators of conditions
+const (
+	LessOperator           = "<"
+	LessOrEqualOperator    = "<="
+	GreaterOperator        = ">"
+	GreaterOrEqualOperator = ">="
+	CrossesAboveOperator   = "crosses_above"
+	CrossesBelowOperator   = "crosses_below"
+)
+
+// Types of indicators of rules
+const (
+	SMAIndicator        = "sma"
+	EMAIndicator        = "ema"
+	WMAIndicator        = "wma"
+	RSIIndicator        = "rsi"
+	MACDIndicator       = "macd"
+	ATRIndicator        = "atr"
+	BollingerIndicator  = "bollinger"
+	VWAPIndicator       = "vwap"
+	StochasticIndicator = "stochastic"
+	OBVIndicator        = "obv"
+)
+
+// candleOperands are values of the last candle which conditions compare
+var candleOperands = map[string]struct{}{"price": {}, "open": {}, "high": {}, "low": {}, "close": {}, "volume": {}}
+
+// indicatorFields are fields of indicators with several values, the first one is the value of indicator name
+var indicatorFields = map[string][]string{
+	MACDIndicator:       {"macd", "signal", "histogram"},
+	BollingerIndicator:  {"middle", "upper", "lower"},
+	StochasticIndicator: {"k", "d"},
+}
+
+var (
+	operators       = []string{LessOperator, LessOrEqualOperator, GreaterOperator, GreaterOrEqualOperator, CrossesAboveOperator, CrossesBelowOperator}
+	rulesIntervals  = []string{"1m", "5m", "15m", "1h", "4h", "1d"}
+	indicatorName   = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	defaultInterval = "1m"
+	defaultOrder    = "mkt"
+)
+
+// Rules describe strategy by conditions over indicators and prices of candles, so strategies are written
+// in YAML or JSON instead of Go. Position is opened when entry conditions hold and closed when exit
+// conditions hold or price reaches one of price exits. Conditions are checked on every candle of interval
+type Rules struct {
+	Name       string                   `json:"name,omitempty" yaml:"name"`
+	Symbol     string                   `json:"symbol" yaml:"symbol"`
+	Side       string                   `json:"side" yaml:"side"`
+	OrderType  string                   `json:"order_type" yaml:"order_type"`
+	Interval   string                   `json:"interval" yaml:"interval"`
+	Indicators map[string]IndicatorRule `json:"indicators,omitempty" yaml:"indicators"`
+	Entry      Conditions               `json:"entry" yaml:"entry"`
+	Exit       ExitRules                `json:"exit" yaml:"exit"`
+	Sizing     SizingRules              `json:"sizing" yaml:"sizing"`
+}
+
+// IndicatorRule is an indicator of rules, parameters which are not used by its type must be zero.
+// Zero parameters are replaced by defaults: period of rsi, atr and stochastic is 14, of bollinger is 20,
+// macd is 12, 26, 9, bollinger deviations are 2, stochastic smoothing is 3 and vwap session is 24h
+type IndicatorRule struct {
+	Type       string  `json:"type" yaml:"type"`
+	Period     int     `json:"period,omitempty" yaml:"period"`
+	Fast       int     `json:"fast,omitempty" yaml:"fast"`
+	Slow       int     `json:"slow,omitempty" yaml:"slow"`
+	Signal     int     `json:"signal,omitempty" yaml:"signal"`
+	Smoothing  int     `json:"smoothing,omitempty" yaml:"smoothing"`
+	Deviations float64 `json:"deviations,omitempty" yaml:"deviations"`
+	Session    string  `json:"session,omitempty" yaml:"session"`
+}
+
+// Conditions hold when all of All hold and one of Any holds, empty lists are not checked.
+// Condition is "<operand> <operator> <operand>", where operand is a number, price (close), open, high,
+// low, volume, name of indicator or name.field of indicator with several values, for example
+// "rsi < 30", "fast crosses_above slow" or "price > bands.upper"
+type Conditions struct {
+	All []string `json:"all,omitempty" yaml:"all"`
+	Any []string `json:"any,omitempty" yaml:"any"`
+}
+
+func (c Conditions) Empty() bool {
+	return len(c.All) == 0 && len(c.Any) == 0
+}
+
+// ExitRules close position by conditions or by price deltas from entry price:
+// stop loss, take profit and trailing stop from the best price since entry, or after max candles
+type ExitRules struct {
+	Conditions   `yaml:",inline"`
+	StopLoss     float64 `json:"stop_loss,omitempty" yaml:"stop_loss"`
+	TakeProfit   float64 `json:"take_profit,omitempty" yaml:"take_profit"`
+	TrailingStop float64 `json:"trailing_stop,omitempty" yaml:"trailing_stop"`
+	MaxCandles   int     `json:"max_candles,omitempty" yaml:"max_candles"`
+}
+
+type SizingRules struct {
+	Size uint `json:"size" yaml:"size"`
+}
+
+// Operand is a constant when Name is empty, otherwise it is a value of candle or indicator
+type Operand struct {
+	Name  string
+	Field string
+	Value float64
+}
+
+type Condition struct {
+	Left     Operand
+	Operator string
+	Right    Operand
+}
+
+// ParseRules parses rules from YAML or JSON document, unknown fields are errors,
+// returned rules have defaults and are validated
+func ParseRules(document []byte) (Rules, error) {
+	rules, err := decodeRules(document)
+	if err != nil {
+		return Rules{}, err
+	}
+	return rules.Normalize()
+}
+
+// UnmarshalJSON reads rules sent as YAML or JSON document in string or as JSON object, unknown fields
+// are errors. Rules are not validated, trading details with rules are validated by Normalize of rules
+func (r *Rules) UnmarshalJSON(data []byte) error {
+	document := data
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		document = []byte(text)
+	}
+
+	rules, err := decodeRules(document)
+	if err != nil {
+		return err
+	}
+	*r = rules
+	return nil
+}
+
+func decodeRules(document []byte) (Rules, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(document))
+	decoder.KnownFields(true)
+
+	var rules Rules
+	if err := decoder.Decode(&rules); err != nil {
+		return Rules{}, fmt.Errorf("%s: %w", ErrParseRules, err)
+	}
+	return rules, nil
+}
+
+// Normalize returns rules with defaults or error which lists every problem of rules
+func (r Rules) Normalize() (Rules, error) {
+	r.Side = strings.ToLower(r.Side)
+	if r.OrderType == "" {
+		r.OrderType = defaultOrder
+	}
+	if r.Interval == "" {
+		r.Interval = defaultInterval
+	}
+
+	indicators := make(map[string]IndicatorRule, len(r.Indicators))
+	for name, indicator := range r.Indicators {
+		indicators[name] = indicator.withDefaults()
+	}
+	r.Indicators = indicators
+
+	if problems := r.problems(); len(problems) != 0 {
+		return Rules{}, fmt.Errorf("%s: %s", ErrInvalidRules, strings.Join(problems, "; "))
+	}
+	return r, nil
+}
+
+// TradingDetails returns details of position opened by rules
+func (r Rules) TradingDetails() TradingDetails {
+	return TradingDetails{
+		OrderType:       r.OrderType,
+		Symbol:          r.Symbol,
+		Side:            r.Side,
+		Size:            r.Sizing.Size,
+		Strategy:        RulesStrategy,
+		CandlesInterval: r.Interval,
+		Rules:           &r,
+	}
+}
+
+func (r Rules) problems() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if r.Symbol == "" {
+		add("symbol is required")
+	}
+	if r.Side != "buy" && r.Side != "sell" {
+		add("side must be buy or sell, got %q", r.Side)
+	}
+	if !contains(rulesIntervals, r.Interval) {
+		add("interval must be one of %s, got %q", strings.Join(rulesIntervals, ", "), r.Interval)
+	}
+	if r.Sizing.Size == 0 {
+		add("sizing.size must be greater than 0")
+	}
+
+	names := make([]string, 0, len(r.Indicators))
+	for name := range r.Indicators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !indicatorName.MatchString(name) {
+			add("indicators.%s: name must consist of lower case letters, digits and _", name)
+		}
+		if _, ok := candleOperands[name]; ok {
+			add("indicators.%s: name is reserved for candle value", name)
+		}
+		for _, problem := range r.Indicators[name].problems() {
+			add("indicators.%s: %s", name, problem)
+		}
+	}
+
+	problems = append(problems, r.conditionsProblems("entry", r.Entry)...)
+	problems = append(problems, r.conditionsProblems("exit", r.Exit.Conditions)...)
+
+	exit := r.Exit
+	if exit.StopLoss < 0 || exit.TakeProfit < 0 || exit.TrailingStop < 0 || exit.MaxCandles < 0 {
+		add("exit: stop_loss, take_profit, trailing_stop and max_candles must not be negative")
+	}
+	if exit.Empty() && exit.StopLoss == 0 && exit.TakeProfit == 0 && exit.TrailingStop == 0 && exit.MaxCandles == 0 {
+		add("exit: conditions or one of stop_loss, take_profit, trailing_stop, max_candles are required")
+	}
+	return problems
+}
+
+func (r Rules) conditionsProblems(path string, conditions Conditions) []string {
+	var problems []string
+	for list, expressions := range map[string][]string{"all": conditions.All, "any": conditions.Any} {
+		for i, expression := range expressions {
+			condition, err := ParseCondition(expression)
+			if err == nil {
+				err = r.checkCondition(condition)
+			}
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s.%s[%d]: %s", path, list, i, err))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func (r Rules) checkCondition(condition Condition) error {
+	for _, operand := range []Operand{condition.Left, condition.Right} {
+		if err := r.checkOperand(operand); err != nil {
+			return err
+		}
+	}
+	if condition.Left.Name == "" && condition.Right.Name == "" {
+		return fmt.Errorf("%s: both operands are numbers", ErrInvalidCondition)
+	}
+	return nil
+}
+
+func (r Rules) checkOperand(operand Operand) error {
+	if operand.Name == "" {
+		return nil
+	}
+	if _, ok := candleOperands[operand.Name]; ok {
+		if operand.Field != "" {
+			return fmt.Errorf("%s: %s has no fields", ErrInvalidCondition, operand.Name)
+		}
+		return nil
+	}
+
+	indicator, ok := r.Indicators[operand.Name]
+	if !ok {
+		return fmt.Errorf("%s: unknown indicator %q", ErrInvalidCondition, operand.Name)
+	}
+	fields := indicatorFields[indicator.Type]
+	if operand.Field != "" && !contains(fields, operand.Field) {
+		if len(fields) == 0 {
+			return fmt.Errorf("%s: %s indicator %s has no fields", ErrInvalidCondition, indicator.Type, operand.Name)
+		}
+		return fmt.Errorf("%s: %s indicator %s has fields %s, got %q", ErrInvalidCondition, indicator.Type,
+			operand.Name, strings.Join(fields, ", "), operand.Field)
+	}
+	return nil
+}
+
+// ParseCondition parses condition "<operand> <operator> <operand>"
+func ParseCondition(expression string) (Condition, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != 3 {
+		return Condition{}, fmt.Errorf("%s: %q must be \"<operand> <operator> <operand>\"", ErrInvalidCondition, expression)
+	}
+	if !contains(operators, parts[1]) {
+		return Condition{}, fmt.Errorf("%s: %q: operator must be one of %s, got %q", ErrInvalidCondition, expression,
+			strings.Join(operators, ", "), parts[1])
+	}
+
+	return Condition{Left: parseOperand(parts[0]), Operator: parts[1], Right: parseOperand(parts[2])}, nil
+}
+
+func parseOperand(value string) Operand {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return Operand{Value: number}
+	}
+	name, field := value, ""
+	if i := strings.Index(value, "."); i >= 0 {
+		name, field = value[:i], value[i+1:]
+	}
+	return Operand{Name: name, Field: field}
+}
+
+func (i IndicatorRule) withDefaults() IndicatorRule {
+	setDefault := func(value *int, defaultValue int) {
+		if *value == 0 {
+			*value = defaultValue
+		}
+	}
+
+	switch i.Type {
+	case RSIIndicator, ATRIndicator:
+		setDefault(&i.Period, 14)
+	case MACDIndicator:
+		setDefault(&i.Fast, 12)
+		setDefault(&i.Slow, 26)
+		setDefault(&i.Signal, 9)
+	case BollingerIndicator:
+		setDefault(&i.Period, 20)
+		if i.Deviations == 0 {
+			i.Deviations = 2
+		}
+	case StochasticIndicator:
+		setDefault(&i.Period, 14)
+		setDefault(&i.Smoothing, 3)
+	case VWAPIndicator:
+		if i.Session == "" {
+			i.Session = "24h"
+		}
+	}
+	return i
+}
+
+func (i IndicatorRule) problems() []string {
+	var problems []string
+	positive := func(name string, value int) {
+		if value < 1 {
+			problems = append(problems, fmt.Sprintf("%s must be greater than 0", name))
+		}
+	}
+	unused := func(used ...string) {
+		for name, set := range map[string]bool{
+			"period": i.Period != 0, "fast": i.Fast != 0, "slow": i.Slow != 0, "signal": i.Signal != 0,
+			"smoothing": i.Smoothing != 0, "deviations": i.Deviations != 0, "session": i.Session != "",
+		} {
+			if set && !contains(used, name) {
+				problems = append(problems, fmt.Sprintf("%s is not a parameter of %s", name, i.Type))
+			}
+		}
+	}
+
+	switch i.Type {
+	case SMAIndicator, EMAIndicator, WMAIndicator, RSIIndicator, ATRIndicator:
+		positive("period", i.Period)
+		unused("period")
+	case MACDIndicator:
+		positive("fast", i.Fast)
+		positive("slow", i.Slow)
+		positive("signal", i.Signal)
+		if i.Fast >= i.Slow {
+			problems = append(problems, "fast must be less than slow")
+		}
+		unused("fast", "slow", "signal")
+	case BollingerIndicator:
+		positive("period", i.Period)
+		if i.Deviations < 0 {
+			problems = append(problems, "deviations must not be negative")
+		}
+		unused("period", "deviations")
+	case StochasticIndicator:
+		positive("period", i.Period)
+		positive("smoothing", i.Smoothing)
+		unused("period", "smoothing")
+	case VWAPIndicator:
+		if session, err := time.ParseDuration(i.Session); err != nil || session < 0 {
+			problems = append(problems, fmt.Sprintf("session must be duration like 24h, got %q", i.Session))
+		}
+		unused("session")
+	case OBVIndicator:
+		unused()
+	default:
+		problems = append(problems, fmt.Sprintf("unknown type %q, must be one of %s", i.Type, strings.Join([]string{
+			SMAIndicator, EMAIndicator, WMAIndicator, RSIIndicator, MACDIndicator, ATRIndicator, BollingerIndicator,
+			VWAPIndicator, StochasticIndicator, OBVIndicator}, ", ")))
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRules(t *testing.T) {
+	yamlRules := `
+name: macd trend
+symbol: PI_XBTUSD
+side: buy
+interval: 5m
+indicators:
+  trend: {type: macd}
+  rsi: {type: rsi}
+entry:
+  all:
+    - trend.macd crosses_above trend.signal
+    - rsi < 70
+exit:
+  any: [trend crosses_below trend.signal]
+  stop_loss: 150
+sizing:
+  size: 2
+`
+	want := Rules{
+		Name:      "macd trend",
+		Symbol:    "PI_XBTUSD",
+		Side:      "buy",
+		OrderType: "mkt",
+		Interval:  "5m",
+		Indicators: map[string]IndicatorRule{
+			"trend": {Type: MACDIndicator, Fast: 12, Slow: 26, Signal: 9},
+			"rsi":   {Type: RSIIndicator, Period: 14},
+		},
+		Entry: Conditions{All: []string{"trend.macd crosses_above trend.signal", "rsi < 70"}},
+		Exit: ExitRules{
+			Conditions: Conditions{Any: []string{"trend crosses_below trend.signal"}},
+			StopLoss:   150,
+		},
+		Sizing: SizingRules{Size: 2},
+	}
+
+	tests := []struct {
+		name      string
+		document  string
+		want      Rules
+		wantError string
+	}{
+		{
+			name:     "YAML",
+			document: yamlRules,
+			want:     want,
+		},
+		{
+			name: "JSON",
+			document: `{"name": "macd trend", "symbol": "PI_XBTUSD", "side": "BUY", "interval": "5m",
+				"indicators": {"trend": {"type": "macd"}, "rsi": {"type": "rsi"}},
+				"entry": {"all": ["trend.macd crosses_above trend.signal", "rsi < 70"]},
+				"exit": {"any": ["trend crosses_below trend.signal"], "stop_loss": 150}, "sizing": {"size": 2}}`,
+			want: want,
+		},
+		{
+			name:      "Unknown field",
+			document:  "symbol: PI_XBTUSD\nstop_los: 10\n",
+			wantError: "parse strategy rules: yaml: unmarshal errors:\n  line 2: field stop_los not found in type types.Rules",
+		},
+		{
+			name: "Every problem is listed",
+			document: `
+symbol: PI_XBTUSD
+side: long
+indicators:
+  fast: {type: ema}
+  price: {type: sma, period: 5, deviations: 2}
+entry:
+  all: [fast >> 10, slow < 10]
+exit:
+  any: [fast.upper < 10, 1 < 2]
+`,
+			wantError: "invalid strategy rules: side must be buy or sell, got \"long\"; sizing.size must be greater than 0; " +
+				"indicators.fast: period must be greater than 0; indicators.price: name is reserved for candle value; " +
+				"indicators.price: deviations is not a parameter of sma; " +
+				"entry.all[0]: invalid condition: \"fast >> 10\": operator must be one of <, <=, >, >=, crosses_above, crosses_below, got \">>\"; " +
+				"entry.all[1]: invalid condition: unknown indicator \"slow\"; " +
+				"exit.any[0]: invalid condition: ema indicator fast has no fields; " +
+				"exit.any[1]: invalid condition: both operands are numbers",
+		},
+		{
+			name:      "No exit",
+			document:  "symbol: PI_XBTUSD\nside: sell\nsizing: {size: 1}\n",
+			wantError: "invalid strategy rules: exit: conditions or one of stop_loss, take_profit, trailing_stop, max_candles are required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseRules([]byte(test.document))
+			if test.wantError != "" {
+				assert.EqualError(t, err, test.wantError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRules_TradingDetails(t *testing.T) {
+	rules := Rules{Symbol: "PI_XBTUSD", Side: "sell", OrderType: "mkt", Interval: "1h", Sizing: SizingRules{Size: 3},
+		Exit: ExitRules{MaxCandles: 10}}
+
+	details := rules.TradingDetails()
+	assert.Equal(t, TradingDetails{OrderType: "mkt", Symbol: "PI_XBTUSD", Side: "sell", Size: 3, Strategy: RulesStrategy,
+		CandlesInterval: "1h", Rules: &rules}, details)
+	assert.False(t, details.WaitsForEntry())
+
+	rules.Entry = Conditions{Any: []string{"price > 100"}}
+	assert.True(t, rules.TradingDetails().WaitsForEntry())
+}
+
+func TestRules_UnmarshalJSON(t *testing.T) {
+	want := Rules{
+		Symbol:     "PI_XBTUSD",
+		Side:       "buy",
+		Indicators: map[string]IndicatorRule{"rsi": {Type: RSIIndicator, Period: 7}},
+		Entry:      Conditions{All: []string{"rsi < 30"}},
+		Exit:       ExitRules{StopLoss: 100},
+		Sizing:     SizingRules{Size: 1},
+	}
+
+	tests := []struct {
+		name      string
+		message   string
+		want      *Rules
+		wantError bool
+	}{
+		{
+			name: "YAML document in string",
+			message: `{"symbol": "PI_XBTUSD", "rules": "symbol: PI_XBTUSD\nside: buy\nindicators: {rsi: {type: rsi, period: 7}}\n` +
+				`entry: {all: [rsi < 30]}\nexit: {stop_loss: 100}\nsizing: {size: 1}"}`,
+			want: &want,
+		},
+		{
+			name: "JSON object",
+			message: `{"rules": {"symbol": "PI_XBTUSD", "side": "buy", "indicators": {"rsi": {"type": "rsi", "period": 7}},
+				"entry": {"all": ["rsi < 30"]}, "exit": {"stop_loss": 100}, "sizing": {"size": 1}}}`,
+			want: &want,
+		},
+		{
+			name:    "Without rules",
+			message: `{"symbol": "PI_XBTUSD", "rules": null}`,
+		},
+		{
+			name:      "Unknown field",
+			message:   `{"rules": {"symbol": "PI_XBTUSD", "sizing": {"size": 1}, "leverage": 10}}`,
+			wantError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var details TradingDetails
+			err := json.Unmarshal([]byte(test.message), &details)
+			if test.wantError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), ErrParseRules.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, details.Rules)
+		})
+	}
+
+	// saved rules are read back as they are
+	saved, err := json.Marshal(want)
+	assert.NoError(t, err)
+	var got Rules
+	assert.NoError(t, json.Unmarshal(saved, &got))
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,103 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrValidateParameters     = errors.New("validate strategy parameters")
+	ErrUnknownParameter       = errors.New("unknown parameter")
+	ErrMissingParameter       = errors.New("missing required parameter")
+	ErrParameterOutOfRange    = errors.New("parameter out of range")
+	ErrParameterMustBeInteger = errors.New("parameter must be integer")
+)
+
+// StrategyParameters are numeric parameters of trading strategy by their names
+type StrategyParameters map[string]float64
+
+type ParameterSchema struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Integer     bool     `json:"integer"`
+	Positive    bool     `json:"positive"`
+	Default     float64  `json:"default,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+}
+
+type StrategySchema struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Parameters  []ParameterSchema `json:"parameters"`
+	// Check validates relations between parameters after each of them was validated
+	Check func(params StrategyParameters) error `json:"-"`
+}
+
+// Validate checks parameters against schema and returns them with defaults of missing optional parameters
+func (s StrategySchema) Validate(params StrategyParameters) (StrategyParameters, error) {
+	known := make(map[string]struct{}, len(s.Parameters))
+	for _, p := range s.Parameters {
+		known[p.Name] = struct{}{}
+	}
+
+	unknown := make([]string, 0)
+	for name := range params {
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s: %s: %s: %v", ErrValidateParameters, s.Name, ErrUnknownParameter, unknown)
+	}
+
+	validated := make(StrategyParameters, len(s.Parameters))
+	for _, p := range s.Parameters {
+		value, ok := params[p.Name]
+		if !ok {
+			if p.Required {
+				return nil, fmt.Errorf("%s: %s: %s: %s", ErrValidateParameters, s.Name, ErrMissingParameter, p.Name)
+			}
+			value = p.Default
+		}
+
+		if err := p.validate(value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", ErrValidateParameters, s.Name, err)
+		}
+		validated[p.Name] = value
+	}
+
+	if s.Check != nil {
+		if err := s.Check(validated); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", ErrValidateParameters, s.Name, err)
+		}
+	}
+
+	return validated, nil
+}
+
+func (p ParameterSchema) validate(value float64) error {
+	if p.Integer && value != math.Trunc(value) {
+		return fmt.Errorf("%s: %s = %v", ErrParameterMustBeInteger, p.Name, value)
+	}
+	if p.Positive && value <= 0 {
+		return fmt.Errorf("%s: %s = %v, must be positive", ErrParameterOutOfRange, p.Name, value)
+	}
+	if p.Min != nil && value < *p.Min {
+		return fmt.Errorf("%s: %s = %v, min %v", ErrParameterOutOfRange, p.Name, value, *p.Min)
+	}
+	if p.Max != nil && value > *p.Max {
+		return fmt.Errorf("%s: %s = %v, max %v", ErrParameterOutOfRange, p.Name, value, *p.Max)
+	}
+	return nil
+}
+
+// Bound is a helper for setting Min and Max of ParameterSchema
+func Bound(value float64) *float64 {
+	return &value
+}
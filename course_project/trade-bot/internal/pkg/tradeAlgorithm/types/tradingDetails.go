@@ -1,11 +1,28 @@
 package types
 
+// TradingDetails describe position opened by trading session and strategy which closes it.
+// With Bracket stop loss and take profit orders are placed on exchange after entry, so position
+// is protected even when bot is down, they are triggered by TriggerSignal price: mark, index or last.
+// Strategy analyzes closes of candles of CandlesInterval, one minute candles by default.
+// Rules strategy opens and closes position by Rules instead of Parameters.
+// StopLossBorder and TakeProfitBorder are legacy parameters of stop loss take profit strategy, nil when not sent
 type TradingDetails struct {
-	OrderType        string  `json:"order_type" validate:"required"`
-	Symbol           string  `json:"symbol" validate:"required"`
-	Side             string  `json:"side" validate:"required"`
-	Size             uint    `json:"size" validate:"required,gte=0"`
-	StopLossBorder   float64 `json:"stop_loss_border" validate:"required,gte=0"`
-	TakeProfitBorder float64 `json:"take_profit_border" validate:"required,gte=0"`
+	OrderType        string             `json:"order_type" validate:"required"`
+	Symbol           string             `json:"symbol" validate:"required"`
+	Side             string             `json:"side" validate:"required"`
+	Size             uint               `json:"size" validate:"required,gte=0"`
+	Strategy         string             `json:"strategy,omitempty"`
+	Parameters       StrategyParameters `json:"parameters,omitempty"`
+	StopLossBorder   *float64           `json:"stop_loss_border,omitempty" validate:"omitempty,gte=0"`
+	TakeProfitBorder *float64           `json:"take_profit_border,omitempty" validate:"omitempty,gte=0"`
+	Bracket          bool               `json:"bracket,omitempty"`
+	TriggerSignal    string             `json:"trigger_signal,omitempty" validate:"omitempty,oneof=mark index last"`
+	CandlesInterval  string             `json:"candles_interval,omitempty" validate:"omitempty,oneof=1m 5m 15m 1h 4h 1d"`
+	Rules            *Rules             `json:"rules,omitempty"`
 	BuyPrice         float64
 }
+
+// WaitsForEntry reports whether position is opened only when entry conditions of rules are met
+func (d TradingDetails) WaitsForEntry() bool {
+	return d.Rules != nil && !d.Rules.Entry.Empty()
+}
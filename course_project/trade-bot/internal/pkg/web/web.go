@@ -2,33 +2,131 @@ package web
 
 import (
 	"context"
+	"net/http"
+	"time"
 
+	"trade-bot/configs"
 	"trade-bot/internal/pkg/models"
 	"trade-bot/internal/pkg/web/webKraken"
+	"trade-bot/internal/pkg/web/webNotify"
 	"trade-bot/pkg/krakenFuturesSDK"
 	"trade-bot/pkg/krakenFuturesWSSDK"
 )
 
+// ErrOrderNotFound is returned by EditOrder and CancelOrder of orders manager when order is filled or cancelled already
+var ErrOrderNotFound = webKraken.ErrOrderNotFound
+
+// KrakenPortfolio reads state of account on exchange
+type KrakenPortfolio interface {
+	OpenOrders() ([]krakenFuturesSDK.OpenOrder, error)
+	OpenPositions() ([]krakenFuturesSDK.OpenPosition, error)
+	Fills(lastFillTime string) ([]krakenFuturesSDK.Fill, error)
+	Accounts() (map[string]krakenFuturesSDK.Account, error)
+	HistoricalOrders(since int64) ([]krakenFuturesSDK.HistoricalOrderElement, error)
+}
+
 type KrakenOrdersManager interface {
+	KrakenPortfolio
 	SendOrder(args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error)
 	EditOrder(args krakenFuturesSDK.EditOrderArguments) (krakenFuturesSDK.EditStatus, error)
 	CancelOrder(args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error)
 	CancelAllOrders(symbol string) (krakenFuturesSDK.CancelAllStatus, error)
-	ParseSendStatusToExecutedOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error)
+	ParseSendStatusToOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error)
+}
+
+type KrakenOrdersManagerFactory interface {
+	GetOrdersManager(userID int, publicAPIKey, privateAPIKey string) KrakenOrdersManager
+	GetPaperOrdersManager(userID int) KrakenOrdersManager
+	ClosePaperOrdersManager(userID int)
+	Close()
+}
+
+// KrakenMarketData reads public market data of kraken futures
+type KrakenMarketData interface {
+	FeeSchedules() ([]krakenFuturesSDK.FeeSchedules, error)
+	Tickers() ([]krakenFuturesSDK.Ticker, error)
+	OrderBook(symbol string) (krakenFuturesSDK.OrderBook, error)
+	Instruments() ([]krakenFuturesSDK.Instrument, error)
 }
 
+// KrakenAnalyzer streams public market data of products
 type KrakenAnalyzer interface {
 	LookForCandles(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.Candle, error)
+	LookForCandlesUpdates(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.CandlesTradeData, error)
+	CandlesHistory(feed, productID string, count int) ([]krakenFuturesWSSDK.Candle, error)
+	LookForTicker(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TickerData, error)
+	LookForTickerLite(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TickerLiteData, error)
+	LookForTrades(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TradeData, error)
+	LookForOrderBook(ctx context.Context, productsIDs []string, depth int) (<-chan krakenFuturesWSSDK.OrderBookDepth, error)
+}
+
+// KrakenPrivateFeeds streams private websocket feeds of user
+type KrakenPrivateFeeds interface {
+	LookForOrders(ctx context.Context, publicAPIKey, privateAPIKey string) (<-chan krakenFuturesWSSDK.OpenOrdersData, error)
+}
+
+// Notifier sends notification to its address by one channel
+type Notifier interface {
+	Send(ctx context.Context, notification models.Notification) error
 }
 
 type Web struct {
-	KrakenOrdersManager
+	KrakenOrdersManagerFactory
 	KrakenAnalyzer
+	KrakenMarketData
+	KrakenPrivateFeeds
+	// Notifiers send notifications by their channels
+	Notifiers map[string]Notifier
 }
 
-func NewWeb(krakenAPISDK *krakenFuturesSDK.API, krakenWebsocketSDK *krakenFuturesWSSDK.WSAPI) *Web {
+func NewWeb(krakenAPIURL string, krakenWebsocketSDK *krakenFuturesWSSDK.WSAPI, paperTrading configs.PaperTradingConfiguration,
+	telegramAPIToken string, notifications configs.NotificationsConfiguration) *Web {
+	publicAPI := krakenFuturesSDK.NewAPI("", "", krakenAPIURL)
+	analyzer := webKraken.NewKrakenAnalyzerWebSDK(krakenWebsocketSDK, publicAPI)
+	marketData := webKraken.NewKrakenMarketDataWebSDK(publicAPI)
+	factory := webKraken.NewKrakenOrdersManagerFactory(krakenAPIURL, analyzer, marketData, paperTrading)
+
 	return &Web{
-		KrakenOrdersManager: webKraken.NewKrakenOrdersManagerWebSDK(krakenAPISDK),
-		KrakenAnalyzer:      webKraken.NewKrakenAnalyzerWebSDK(krakenWebsocketSDK),
+		KrakenOrdersManagerFactory: &krakenOrdersManagerFactory{factory: factory},
+		KrakenAnalyzer:             analyzer,
+		KrakenMarketData:           marketData,
+		KrakenPrivateFeeds:         webKraken.NewKrakenPrivateFeedsWebSDK(krakenWebsocketSDK),
+		Notifiers:                  newNotifiers(telegramAPIToken, notifications),
 	}
 }
+
+// defaultNotifierTimeout limits requests of telegram and webhook notifiers when config does not set timeout
+const defaultNotifierTimeout = 10 * time.Second
+
+func newNotifiers(telegramAPIToken string, config configs.NotificationsConfiguration) map[string]Notifier {
+	timeout := time.Duration(config.WebhookTimeoutInSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultNotifierTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	return map[string]Notifier{
+		models.ChannelTelegram: webNotify.NewTelegramNotifier(client, config.TelegramAPIURL, telegramAPIToken),
+		models.ChannelEmail:    webNotify.NewEmailNotifier(config.SMTP),
+		models.ChannelWebhook:  webNotify.NewWebhookNotifier(timeout, config.WebhookSigningKey),
+	}
+}
+
+type krakenOrdersManagerFactory struct {
+	factory *webKraken.KrakenOrdersManagerFactory
+}
+
+func (f *krakenOrdersManagerFactory) GetOrdersManager(userID int, publicAPIKey, privateAPIKey string) KrakenOrdersManager {
+	return f.factory.GetOrdersManager(userID, publicAPIKey, privateAPIKey)
+}
+
+func (f *krakenOrdersManagerFactory) GetPaperOrdersManager(userID int) KrakenOrdersManager {
+	return f.factory.GetPaperOrdersManager(userID)
+}
+
+func (f *krakenOrdersManagerFactory) ClosePaperOrdersManager(userID int) {
+	f.factory.ClosePaperOrdersManager(userID)
+}
+
+func (f *krakenOrdersManagerFactory) Close() {
+	f.factory.Close()
+}
@@ -3,39 +3,63 @@ package webKraken
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"trade-bot/pkg/krakenFuturesSDK"
 	"trade-bot/pkg/krakenFuturesWSSDK"
 )
 
 var (
 	ErrConvertTradeDataToCandle = errors.New("convert trade data to candle")
 	ErrLookForCandles           = errors.New("look for candles")
+	ErrBackfillCandles          = errors.New("backfill candles")
+	ErrCandlesHistory           = errors.New("candles history")
+	ErrLookForTicker            = errors.New("look for ticker")
+	ErrLookForTrades            = errors.New("look for trades")
+	ErrLookForOrderBook         = errors.New("look for order book")
 )
 
-const unixTimeLen = 10
+const (
+	unixTimeLen     = 10
+	tradeChartsType = "trade"
+)
+
+// chartsAPI serves historical candles which are used to backfill candles missed by websocket
+type chartsAPI interface {
+	Charts(tickType, symbol, resolution string, from, to int64) (*krakenFuturesSDK.ChartsResponse, error)
+}
 
 type KrakenAnalyzerWebSDK struct {
 	krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI
+	charts             chartsAPI
+	now                func() time.Time
 }
 
-func NewKrakenAnalyzerWebSDK(krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI) *KrakenAnalyzerWebSDK {
-	return &KrakenAnalyzerWebSDK{krakenWebsocketAPI: krakenWebsocketAPI}
+func NewKrakenAnalyzerWebSDK(krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI, charts *krakenFuturesSDK.API) *KrakenAnalyzerWebSDK {
+	return &KrakenAnalyzerWebSDK{krakenWebsocketAPI: krakenWebsocketAPI, charts: charts, now: time.Now}
 }
 
+// LookForCandles streams closed candles of products, every candle is sent with its last update
+// once update of the next candle comes. Time of candles is in unix seconds
+
 func (k *KrakenAnalyzerWebSDK) LookForCandles(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.Candle, error) {
 	tradeDataCh, err := k.krakenWebsocketAPI.CandlesTrade(ctx, feed, productsIDs)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ErrLookForCandles, err)
 	}
 
-	candleCh, errCh := convertTradeDataToCandle(tradeDataCh)
+	filledTradeDataCh, errCh := k.fillCandlesGaps(feed, tradeDataCh)
 	go logErrors(errCh)
 
-	filteredCandles := filterCandles(candleCh)
+	candleCh, errCh := convertTradeDataToCandle(filledTradeDataCh)
+	go logErrors(errCh)
+
+	filteredCandles := closedCandles(candleCh)
 
 	filteredUnixTimeCandles, errCh := filterCandlesUnixTime(filteredCandles)
 	go logErrors(errCh)
@@ -43,12 +67,203 @@ func (k *KrakenAnalyzerWebSDK) LookForCandles(ctx context.Context, feed string,
 	return filteredUnixTimeCandles, nil
 }
 
+// LookForCandlesUpdates streams every update of candles of products, so the last update of candle is its final state.
+// Unlike LookForCandles, updates keep product id and time of candles is in unix seconds
+func (k *KrakenAnalyzerWebSDK) LookForCandlesUpdates(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.CandlesTradeData, error) {
+	tradeDataCh, err := k.krakenWebsocketAPI.CandlesTrade(ctx, feed, productsIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLookForCandles, err)
+	}
+
+	filledTradeDataCh, errCh := k.fillCandlesGaps(feed, tradeDataCh)
+	go logErrors(errCh)
+
+	return candlesUpdates(feed, filledTradeDataCh), nil
+}
+
+// CandlesHistory returns up to count the latest closed candles of product of feed from charts,
+// time of candles is in unix seconds
+func (k *KrakenAnalyzerWebSDK) CandlesHistory(feed, productID string, count int) ([]krakenFuturesWSSDK.Candle, error) {
+	resolution, interval, ok := krakenFuturesWSSDK.CandlesFeedInterval(feed)
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown candles feed %s", ErrCandlesHistory, feed)
+	}
+
+	now := k.now()
+	from := now.Add(-time.Duration(count+1) * interval)
+	candles, err := k.chartCandles(productID, resolution, int(from.UnixMilli()), int(now.UnixMilli()))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrCandlesHistory, err)
+	}
+
+	// the last candle of charts is the current one until its interval is over
+	intervalMillis := int(interval / time.Millisecond)
+	for len(candles) > 0 && candles[len(candles)-1].Time+intervalMillis > int(now.UnixMilli()) {
+		candles = candles[:len(candles)-1]
+	}
+	if len(candles) > count {
+		candles = candles[len(candles)-count:]
+	}
+
+	for i := range candles {
+		candles[i].Time = unixSeconds(candles[i].Time)
+	}
+	return candles, nil
+}
+
+func (k *KrakenAnalyzerWebSDK) LookForTicker(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TickerData, error) {
+	tickerDataCh, err := k.krakenWebsocketAPI.Ticker(ctx, productsIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLookForTicker, err)
+	}
+
+	tickerCh := make(chan krakenFuturesWSSDK.TickerData)
+	go func() {
+		defer close(tickerCh)
+		for ticker := range tickerDataCh {
+			tickerCh <- *ticker
+		}
+	}()
+
+	return tickerCh, nil
+}
+
+func (k *KrakenAnalyzerWebSDK) LookForTickerLite(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TickerLiteData, error) {
+	tickerDataCh, err := k.krakenWebsocketAPI.TickerLite(ctx, productsIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLookForTicker, err)
+	}
+
+	tickerCh := make(chan krakenFuturesWSSDK.TickerLiteData)
+	go func() {
+		defer close(tickerCh)
+		for ticker := range tickerDataCh {
+			tickerCh <- *ticker
+		}
+	}()
+
+	return tickerCh, nil
+}
+
+func (k *KrakenAnalyzerWebSDK) LookForTrades(ctx context.Context, productsIDs []string) (<-chan krakenFuturesWSSDK.TradeData, error) {
+	tradeDataCh, err := k.krakenWebsocketAPI.Trade(ctx, productsIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLookForTrades, err)
+	}
+
+	tradesCh := make(chan krakenFuturesWSSDK.TradeData)
+	go func() {
+		defer close(tradesCh)
+		for trade := range tradeDataCh {
+			tradesCh <- *trade
+		}
+	}()
+
+	return tradesCh, nil
+}
+
+// LookForOrderBook streams depth best levels of local order books of products on every change of them
+func (k *KrakenAnalyzerWebSDK) LookForOrderBook(ctx context.Context, productsIDs []string, depth int) (<-chan krakenFuturesWSSDK.OrderBookDepth, error) {
+	bookCh, err := k.krakenWebsocketAPI.Book(ctx, productsIDs, depth)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLookForOrderBook, err)
+	}
+	return bookCh, nil
+}
+
 func logErrors(errs <-chan error) {
 	for err := range errs {
 		log.Warn(err)
 	}
 }
 
+// fillCandlesGaps passes candles of feed and backfills candles of product missed between the last
+// and the new one from charts, e.g. when websocket connection was lost for a while
+func (k *KrakenAnalyzerWebSDK) fillCandlesGaps(feed string, tradeData <-chan *krakenFuturesWSSDK.CandlesTradeData) (<-chan *krakenFuturesWSSDK.CandlesTradeData, <-chan error) {
+	errCh := make(chan error, 1)
+	tradeDataCh := make(chan *krakenFuturesWSSDK.CandlesTradeData)
+
+	resolution, interval, ok := krakenFuturesWSSDK.CandlesFeedInterval(feed)
+	intervalMillis := int(interval / time.Millisecond)
+
+	go func() {
+		defer close(tradeDataCh)
+		defer close(errCh)
+
+		lastCandleTimes := make(map[string]int)
+		for data := range tradeData {
+			if !ok || data.Feed != feed {
+				tradeDataCh <- data
+				continue
+			}
+
+			lastTime, found := lastCandleTimes[data.ProductID]
+			if found && data.Candle.Time > lastTime+intervalMillis {
+				missed, err := k.missedCandles(data.ProductID, resolution, lastTime, data.Candle.Time)
+				if err != nil {
+					errCh <- err
+				}
+				for _, candle := range missed {
+					tradeDataCh <- &krakenFuturesWSSDK.CandlesTradeData{Feed: feed, Candle: candle, ProductID: data.ProductID}
+				}
+			}
+
+			if data.Candle.Time > lastTime {
+				lastCandleTimes[data.ProductID] = data.Candle.Time
+			}
+			tradeDataCh <- data
+		}
+	}()
+
+	return tradeDataCh, errCh
+}
+
+// missedCandles returns candles of product from charts which are between from and to unix milliseconds
+func (k *KrakenAnalyzerWebSDK) missedCandles(productID, resolution string, from, to int) ([]krakenFuturesWSSDK.Candle, error) {
+	candles, err := k.chartCandles(productID, resolution, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrBackfillCandles, err)
+	}
+	return candles, nil
+}
+
+// chartCandles returns candles of product from charts which are between from and to unix milliseconds
+// sorted by time, time of candles is in unix milliseconds
+func (k *KrakenAnalyzerWebSDK) chartCandles(productID, resolution string, from, to int) ([]krakenFuturesWSSDK.Candle, error) {
+	response, err := k.charts.Charts(tradeChartsType, productID, resolution, int64(from/1000), int64(to/1000))
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+
+	candles := make([]krakenFuturesWSSDK.Candle, 0, len(response.Candles))
+	for _, chartCandle := range response.Candles {
+		if int(chartCandle.Time) <= from || int(chartCandle.Time) >= to {
+			continue
+		}
+
+		volume, err := chartCandle.Volume.Float64()
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, krakenFuturesWSSDK.Candle{
+			Time:   int(chartCandle.Time),
+			Open:   chartCandle.Open,
+			High:   chartCandle.High,
+			Low:    chartCandle.Low,
+			Close:  chartCandle.Close,
+			Volume: int(volume),
+		})
+	}
+
+	sort.Slice(candles, func(i, j int) bool {
+		return candles[i].Time < candles[j].Time
+	})
+	return candles, nil
+}
+
 func convertTradeDataToCandle(tradeData <-chan *krakenFuturesWSSDK.CandlesTradeData) (<-chan krakenFuturesWSSDK.Candle, <-chan error) {
 	errCh := make(chan error, 1)
 	candlesChan := make(chan krakenFuturesWSSDK.Candle)
@@ -70,27 +285,54 @@ func convertTradeDataToCandle(tradeData <-chan *krakenFuturesWSSDK.CandlesTradeD
 	return candlesChan, errCh
 }
 
-func filterCandles(candles <-chan krakenFuturesWSSDK.Candle) <-chan krakenFuturesWSSDK.Candle {
+// candlesUpdates passes candles of feed with time converted from unix milliseconds to seconds
+func candlesUpdates(feed string, tradeData <-chan *krakenFuturesWSSDK.CandlesTradeData) <-chan krakenFuturesWSSDK.CandlesTradeData {
+	updates := make(chan krakenFuturesWSSDK.CandlesTradeData)
+
+	go func() {
+		defer close(updates)
+
+		for data := range tradeData {
+			if data.Feed != feed {
+				continue
+			}
+
+			update := *data
+			update.Candle.Time = unixSeconds(update.Candle.Time)
+			updates <- update
+		}
+	}()
+
+	return updates
+}
+
+// unixSeconds converts unix time in milliseconds to seconds, time in seconds is returned as is
+func unixSeconds(unixTime int) int {
+	for len(strconv.Itoa(unixTime)) > unixTimeLen {
+		unixTime /= 10
+	}
+	return unixTime
+}
+
+// closedCandles passes the last update of every candle when update of a later candle comes,
+// so the current candle is held back until it is closed and updates of earlier candles are skipped
+func closedCandles(candles <-chan krakenFuturesWSSDK.Candle) <-chan krakenFuturesWSSDK.Candle {
 	candlesChan := make(chan krakenFuturesWSSDK.Candle)
 
 	go func() {
 		defer close(candlesChan)
 
-		var lastUpdateTime *int
+		var current *krakenFuturesWSSDK.Candle
 
 		for candle := range candles {
-			if lastUpdateTime == nil {
-				t := candle.Time
-				lastUpdateTime = &t
-				candlesChan <- candle
-				continue
+			candle := candle
+			switch {
+			case current == nil || candle.Time == current.Time:
+				current = &candle
+			case candle.Time > current.Time:
+				candlesChan <- *current
+				current = &candle
 			}
-			if candle.Time <= *lastUpdateTime {
-				continue
-			}
-
-			*lastUpdateTime = candle.Time
-			candlesChan <- candle
 		}
 	}()
 
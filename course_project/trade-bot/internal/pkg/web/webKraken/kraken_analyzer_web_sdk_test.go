@@ -0,0 +1,196 @@
+package webKraken
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// chartsStub returns the same candles for every request and records requested symbols
+type chartsStub struct {
+	candles []krakenFuturesSDK.ChartCandle
+	err     error
+	symbols []string
+}
+
+func (c *chartsStub) Charts(tickType, symbol, resolution string, from, to int64) (*krakenFuturesSDK.ChartsResponse, error) {
+	c.symbols = append(c.symbols, symbol)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &krakenFuturesSDK.ChartsResponse{Candles: c.candles}, nil
+}
+
+func TestKrakenAnalyzerWebSDK_FillCandlesGaps(t *testing.T) {
+	const minute = 60000
+	start := 1650000000000
+
+	tests := []struct {
+		name        string
+		charts      *chartsStub
+		times       []int
+		wantTimes   []int
+		wantSymbols []string
+		wantErr     bool
+	}{
+		{
+			name:      "No gaps",
+			charts:    &chartsStub{},
+			times:     []int{start, start, start + minute, start + 2*minute},
+			wantTimes: []int{start, start, start + minute, start + 2*minute},
+		},
+		{
+			name: "Backfill missed candles",
+			charts: &chartsStub{candles: []krakenFuturesSDK.ChartCandle{
+				{Time: int64(start + 3*minute), Volume: "2"},
+				{Time: int64(start + minute), Volume: "1"},
+				{Time: int64(start + 2*minute), Volume: "1.5"},
+				{Time: int64(start + 4*minute), Volume: "3"},
+			}},
+			times:       []int{start, start + 4*minute},
+			wantTimes:   []int{start, start + minute, start + 2*minute, start + 3*minute, start + 4*minute},
+			wantSymbols: []string{"PI_XBTUSD"},
+		},
+		{
+			name:        "Charts error",
+			charts:      &chartsStub{err: errors.New("charts")},
+			times:       []int{start, start + 3*minute},
+			wantTimes:   []int{start, start + 3*minute},
+			wantSymbols: []string{"PI_XBTUSD"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			analyzer := &KrakenAnalyzerWebSDK{charts: tc.charts}
+
+			tradeData := make(chan *krakenFuturesWSSDK.CandlesTradeData, len(tc.times))
+			for _, candleTime := range tc.times {
+				tradeData <- &krakenFuturesWSSDK.CandlesTradeData{
+					Feed:      krakenFuturesWSSDK.OneMinuteCandlesFeed,
+					ProductID: "PI_XBTUSD",
+					Candle:    krakenFuturesWSSDK.Candle{Time: candleTime},
+				}
+			}
+			close(tradeData)
+
+			filled, errCh := analyzer.fillCandlesGaps(krakenFuturesWSSDK.OneMinuteCandlesFeed, tradeData)
+
+			var errs []error
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for err := range errCh {
+					errs = append(errs, err)
+				}
+			}()
+
+			var times []int
+			for data := range filled {
+				times = append(times, data.Candle.Time)
+			}
+			<-done
+
+			assert.Equal(t, tc.wantTimes, times)
+			assert.Equal(t, tc.wantSymbols, tc.charts.symbols)
+			assert.Equal(t, tc.wantErr, len(errs) > 0)
+		})
+	}
+}
+
+func TestClosedCandles(t *testing.T) {
+	const minute = 60000
+	start := 1650000000000
+
+	updates := []krakenFuturesWSSDK.Candle{
+		{Time: start, Open: "100", High: "100", Low: "100", Close: "100", Volume: 1},
+		{Time: start, Open: "100", High: "105", Low: "100", Close: "104", Volume: 3},
+		{Time: start, Open: "100", High: "105", Low: "98", Close: "101", Volume: 7},
+		{Time: start + minute, Open: "101", High: "101", Low: "101", Close: "101", Volume: 1},
+		// late update of closed candle is skipped
+		{Time: start, Open: "100", High: "110", Low: "90", Close: "95", Volume: 9},
+		{Time: start + minute, Open: "101", High: "102", Low: "99", Close: "99", Volume: 4},
+		{Time: start + 2*minute, Open: "99", High: "99", Low: "99", Close: "99", Volume: 1},
+	}
+
+	candles := make(chan krakenFuturesWSSDK.Candle, len(updates))
+	for _, update := range updates {
+		candles <- update
+	}
+	close(candles)
+
+	var got []krakenFuturesWSSDK.Candle
+	for candle := range closedCandles(candles) {
+		got = append(got, candle)
+	}
+
+	// the current candle is not sent until it is closed
+	assert.Equal(t, []krakenFuturesWSSDK.Candle{updates[2], updates[5]}, got)
+}
+
+func TestKrakenAnalyzerWebSDK_CandlesHistory(t *testing.T) {
+	const minute = 60000
+	now := time.Unix(1650000000, 0).Add(90 * time.Second)
+	start := 1650000000000 - 3*minute
+
+	tests := []struct {
+		name      string
+		feed      string
+		charts    *chartsStub
+		count     int
+		wantTimes []int
+		wantErr   bool
+	}{
+		{
+			name: "Closed candles",
+			feed: krakenFuturesWSSDK.OneMinuteCandlesFeed,
+			charts: &chartsStub{candles: []krakenFuturesSDK.ChartCandle{
+				{Time: int64(start + 4*minute), Volume: "1"},
+				{Time: int64(start + minute), Volume: "1"},
+				{Time: int64(start + 2*minute), Volume: "1"},
+				{Time: int64(start + 3*minute), Volume: "1"},
+			}},
+			count:     2,
+			wantTimes: []int{(start + 2*minute) / 1000, (start + 3*minute) / 1000},
+		},
+		{
+			name:    "Unknown feed",
+			feed:    krakenFuturesWSSDK.TradeFeed,
+			charts:  &chartsStub{},
+			count:   2,
+			wantErr: true,
+		},
+		{
+			name:    "Charts error",
+			feed:    krakenFuturesWSSDK.OneMinuteCandlesFeed,
+			charts:  &chartsStub{err: errors.New("charts")},
+			count:   2,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			analyzer := &KrakenAnalyzerWebSDK{charts: tc.charts, now: func() time.Time { return now }}
+
+			candles, err := analyzer.CandlesHistory(tc.feed, "PI_XBTUSD", tc.count)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var times []int
+			for _, candle := range candles {
+				times = append(times, candle.Time)
+			}
+			assert.Equal(t, tc.wantTimes, times)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package webKraken
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrFeeSchedules = errors.New("web sdk: fee schedules")
+	ErrTickers      = errors.New("web sdk: tickers")
+	ErrOrderBook    = errors.New("web sdk: order book")
+	ErrInstruments  = errors.New("web sdk: instruments")
+)
+
+// KrakenMarketDataWebSDK serves public market data of kraken futures, which does not need api keys
+type KrakenMarketDataWebSDK struct {
+	api *krakenFuturesSDK.API
+}
+
+func NewKrakenMarketDataWebSDK(api *krakenFuturesSDK.API) *KrakenMarketDataWebSDK {
+	return &KrakenMarketDataWebSDK{api: api}
+}
+
+func (k *KrakenMarketDataWebSDK) FeeSchedules() ([]krakenFuturesSDK.FeeSchedules, error) {
+	response, err := k.api.FeeSchedules()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrFeeSchedules, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrFeeSchedules, err)
+	}
+
+	return response.FeeSchedules, nil
+}
+
+func (k *KrakenMarketDataWebSDK) Tickers() ([]krakenFuturesSDK.Ticker, error) {
+	response, err := k.api.Tickers()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrTickers, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrTickers, err)
+	}
+
+	return response.Tickers, nil
+}
+
+func (k *KrakenMarketDataWebSDK) OrderBook(symbol string) (krakenFuturesSDK.OrderBook, error) {
+	response, err := k.api.OrderBook(symbol)
+	if err != nil {
+		return krakenFuturesSDK.OrderBook{}, fmt.Errorf("%s: %w", ErrOrderBook, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return krakenFuturesSDK.OrderBook{}, fmt.Errorf("%s: %w", ErrOrderBook, err)
+	}
+
+	return response.OrderBook, nil
+}
+
+func (k *KrakenMarketDataWebSDK) Instruments() ([]krakenFuturesSDK.Instrument, error) {
+	response, err := k.api.Instruments()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrInstruments, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrInstruments, err)
+	}
+
+	return response.Instruments, nil
+}
@@ -0,0 +1,97 @@
+package webKraken
+
+import (
+	"sync"
+
+	"trade-bot/configs"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+type userOrdersManager struct {
+	publicAPIKey  string
+	privateAPIKey string
+	manager       *KrakenOrdersManagerWebSDK
+}
+
+// KrakenOrdersManagerFactory creates orders managers signed with keys of concrete user
+// and caches them by user id, so every user trades on his own kraken account.
+// Users with enabled paper trading get their own simulated exchange instead
+type KrakenOrdersManagerFactory struct {
+	apiURL        string
+	paperCandles  CandlesSource
+	paperTickers  TickersSource
+	paperConfig   configs.PaperTradingConfiguration
+	mu            sync.Mutex
+	managers      map[int]userOrdersManager
+	paperManagers map[int]*KrakenPaperOrdersManager
+}
+
+func NewKrakenOrdersManagerFactory(apiURL string, paperCandles CandlesSource, paperTickers TickersSource,
+	paperConfig configs.PaperTradingConfiguration) *KrakenOrdersManagerFactory {
+	return &KrakenOrdersManagerFactory{
+		apiURL:        apiURL,
+		paperCandles:  paperCandles,
+		paperTickers:  paperTickers,
+		paperConfig:   paperConfig,
+		managers:      make(map[int]userOrdersManager),
+		paperManagers: make(map[int]*KrakenPaperOrdersManager),
+	}
+}
+
+// GetOrdersManager returns cached orders manager of user. New one is created
+// when user is not cached yet or his api keys have been changed
+func (f *KrakenOrdersManagerFactory) GetOrdersManager(userID int, publicAPIKey, privateAPIKey string) *KrakenOrdersManagerWebSDK {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cached, ok := f.managers[userID]
+	if ok && cached.publicAPIKey == publicAPIKey && cached.privateAPIKey == privateAPIKey {
+		return cached.manager
+	}
+
+	manager := NewKrakenOrdersManagerWebSDK(krakenFuturesSDK.NewAPI(publicAPIKey, privateAPIKey, f.apiURL))
+	f.managers[userID] = userOrdersManager{
+		publicAPIKey:  publicAPIKey,
+		privateAPIKey: privateAPIKey,
+		manager:       manager,
+	}
+
+	return manager
+}
+
+// GetPaperOrdersManager returns simulated exchange of user. Balance and positions of user
+// are kept while server is running until paper trading of user is turned off
+func (f *KrakenOrdersManagerFactory) GetPaperOrdersManager(userID int) *KrakenPaperOrdersManager {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	manager, ok := f.paperManagers[userID]
+	if !ok {
+		manager = NewKrakenPaperOrdersManager(f.paperCandles, f.paperTickers, f.paperConfig)
+		f.paperManagers[userID] = manager
+	}
+
+	return manager
+}
+
+// ClosePaperOrdersManager stops simulated exchange of user and drops its balance and positions
+func (f *KrakenOrdersManagerFactory) ClosePaperOrdersManager(userID int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if manager, ok := f.paperManagers[userID]; ok {
+		manager.Close()
+		delete(f.paperManagers, userID)
+	}
+}
+
+// Close stops simulated exchanges of all users
+func (f *KrakenOrdersManagerFactory) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for userID, manager := range f.paperManagers {
+		manager.Close()
+		delete(f.paperManagers, userID)
+	}
+}
@@ -0,0 +1,116 @@
+package webKraken
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+const sendOrderResponse = `{
+	"result": "success",
+	"sendStatus": {
+		"order_id": "1",
+		"status": "placed",
+		"orderEvents": [{
+			"type": "EXECUTION",
+			"price": 100,
+			"orderPriorExecution": {"orderId": "1", "symbol": "pi_xbtusd", "side": "buy", "quantity": 1}
+		}]
+	}
+}`
+
+type apiKeysRecorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (r *apiKeysRecorder) handler(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	r.keys = append(r.keys, req.Header.Get("APIKey"))
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(sendOrderResponse))
+}
+
+func TestKrakenOrdersManagerFactory_GetOrdersManager(t *testing.T) {
+	recorder := &apiKeysRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(recorder.handler))
+	defer server.Close()
+
+	factory := NewKrakenOrdersManagerFactory(server.URL, nil, nil, configs.PaperTradingConfiguration{})
+
+	type user struct {
+		id            int
+		publicAPIKey  string
+		privateAPIKey string
+	}
+
+	tests := []struct {
+		name       string
+		user       user
+		wantAPIKey string
+	}{
+		{
+			name:       "First user",
+			user:       user{id: 1, publicAPIKey: "public-1", privateAPIKey: "cHJpdmF0ZS0x"},
+			wantAPIKey: "public-1",
+		},
+		{
+			name:       "Second user",
+			user:       user{id: 2, publicAPIKey: "public-2", privateAPIKey: "cHJpdmF0ZS0y"},
+			wantAPIKey: "public-2",
+		},
+		{
+			name:       "First user with changed keys",
+			user:       user{id: 1, publicAPIKey: "public-3", privateAPIKey: "cHJpdmF0ZS0z"},
+			wantAPIKey: "public-3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := factory.GetOrdersManager(test.user.id, test.user.publicAPIKey, test.user.privateAPIKey)
+
+			_, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+				OrderType: "mkt",
+				Symbol:    "pi_xbtusd",
+				Side:      "buy",
+				Size:      1,
+			})
+			assert.NoError(t, err)
+
+			recorder.mu.Lock()
+			gotAPIKey := recorder.keys[len(recorder.keys)-1]
+			recorder.mu.Unlock()
+			assert.Equal(t, test.wantAPIKey, gotAPIKey)
+
+			cached := factory.GetOrdersManager(test.user.id, test.user.publicAPIKey, test.user.privateAPIKey)
+			assert.Same(t, manager, cached)
+		})
+	}
+}
+
+func TestKrakenOrdersManagerFactory_ClosePaperOrdersManager(t *testing.T) {
+	factory := NewKrakenOrdersManagerFactory("", nil, nil, configs.PaperTradingConfiguration{})
+
+	first := factory.GetPaperOrdersManager(1)
+	second := factory.GetPaperOrdersManager(2)
+	assert.Same(t, first, factory.GetPaperOrdersManager(1))
+
+	// simulated exchange of user is dropped when paper trading is turned off
+	factory.ClosePaperOrdersManager(1)
+	assert.Error(t, first.ctx.Err())
+	assert.NoError(t, second.ctx.Err())
+	assert.NotSame(t, first, factory.GetPaperOrdersManager(1))
+
+	factory.Close()
+	assert.Error(t, second.ctx.Err())
+	assert.Empty(t, factory.paperManagers)
+}
@@ -2,6 +2,7 @@ package webKraken
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -16,6 +17,12 @@ var (
 	ErrCancelAllOrders       = errors.New("web sdk: cancel all orders")
 	ErrInvalidStatus         = errors.New("invalid status")
 	ErrUnknownSendStatusType = errors.New("unknown send status type")
+	ErrOrderNotFound         = errors.New("order not found")
+	ErrOpenOrders            = errors.New("web sdk: open orders")
+	ErrOpenPositions         = errors.New("web sdk: open positions")
+	ErrFills                 = errors.New("web sdk: fills")
+	ErrAccounts              = errors.New("web sdk: accounts")
+	ErrHistoricalOrders      = errors.New("web sdk: historical orders")
 )
 
 type KrakenOrdersManagerWebSDK struct {
@@ -56,6 +63,10 @@ func (k *KrakenOrdersManagerWebSDK) EditOrder(args krakenFuturesSDK.EditOrderArg
 		return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrEditOrder, err)
 	}
 
+	if response.EditStatus.Status == orderForEditNotFoundStatus {
+		return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrEditOrder, ErrOrderNotFound)
+	}
+
 	if !response.EditStatus.Status.IsSuccessStatus() {
 		err := fmt.Errorf("%s: status: %s", ErrInvalidStatus, response.EditStatus.Status)
 		return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrEditOrder, err)
@@ -75,6 +86,11 @@ func (k *KrakenOrdersManagerWebSDK) CancelOrder(args krakenFuturesSDK.CancelOrde
 		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrder, err)
 	}
 
+	if response.CancelStatus.Status == notFoundStatus {
+		// order is filled or cancelled already
+		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrder, ErrOrderNotFound)
+	}
+
 	if !response.CancelStatus.Status.IsSuccessStatus() {
 		err := fmt.Errorf("%s: status: %s", ErrInvalidStatus, response.CancelStatus.Status)
 		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrder, err)
@@ -102,8 +118,90 @@ func (k *KrakenOrdersManagerWebSDK) CancelAllOrders(symbol string) (krakenFuture
 	return response.CancelStatus, nil
 }
 
-func (k *KrakenOrdersManagerWebSDK) ParseSendStatusToExecutedOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
+func (k *KrakenOrdersManagerWebSDK) OpenOrders() ([]krakenFuturesSDK.OpenOrder, error) {
+	response, err := k.api.OpenOrders()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrOpenOrders, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrOpenOrders, err)
+	}
+
+	return response.OpenOrders, nil
+}
+
+func (k *KrakenOrdersManagerWebSDK) OpenPositions() ([]krakenFuturesSDK.OpenPosition, error) {
+	response, err := k.api.OpenPositions()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrOpenPositions, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrOpenPositions, err)
+	}
+
+	return response.OpenPositions, nil
+}
+
+func (k *KrakenOrdersManagerWebSDK) Fills(lastFillTime string) ([]krakenFuturesSDK.Fill, error) {
+	response, err := k.api.Fills(lastFillTime)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrFills, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrFills, err)
+	}
+
+	return response.Fills, nil
+}
+
+func (k *KrakenOrdersManagerWebSDK) Accounts() (map[string]krakenFuturesSDK.Account, error) {
+	response, err := k.api.Accounts()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrAccounts, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrAccounts, err)
+	}
+
+	return response.Accounts, nil
+}
+
+// HistoricalOrders returns order events since timestamp in milliseconds, following continuation tokens is not supported
+func (k *KrakenOrdersManagerWebSDK) HistoricalOrders(since int64) ([]krakenFuturesSDK.HistoricalOrderElement, error) {
+	response, err := k.api.HistoricalOrders(since)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrHistoricalOrders, err)
+	}
+
+	if response.Error != "" {
+		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+		return nil, fmt.Errorf("%s: %w", ErrHistoricalOrders, err)
+	}
+
+	return response.Elements, nil
+}
+
+func (k *KrakenOrdersManagerWebSDK) ParseSendStatusToOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
+	return parseSendStatusToOrder(userID, sendStatus)
+}
+
+// parseSendStatusToOrder converts executed order or order placed in order book, price of placed order
+// is its stop price for trigger orders and limit price for the others. Invalid times of order are
+// replaced by time when exchange received it
+func parseSendStatusToOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
+	if len(sendStatus.OrderEvents) == 0 {
+		return models.Order{}, ErrUnknownSendStatusType
+	}
 	orderEvent := sendStatus.OrderEvents[0]
+	receivedTime := models.ParseOrderTime(sendStatus.ReceivedTime, time.Now().UTC())
 
 	if orderEvent.Type == "EXECUTION" {
 		return models.Order{
@@ -116,8 +214,31 @@ func (k *KrakenOrdersManagerWebSDK) ParseSendStatusToExecutedOrder(userID int, s
 			Side:                orderEvent.OrderPriorExecution.Side,
 			Price:               orderEvent.Price,
 			Filled:              orderEvent.OrderPriorExecution.Filled,
-			Timestamp:           orderEvent.OrderPriorExecution.Timestamp,
-			LastUpdateTimestamp: orderEvent.OrderPriorExecution.LastUpdateTimestamp,
+			Timestamp:           models.ParseOrderTime(orderEvent.OrderPriorExecution.Timestamp, receivedTime),
+			LastUpdateTimestamp: models.ParseOrderTime(orderEvent.OrderPriorExecution.LastUpdateTimestamp, receivedTime),
+			Status:              models.OrderFilled,
+		}, nil
+	}
+
+	if orderEvent.Type == "PLACE" {
+		price := orderEvent.Order.LimitPrice
+		if orderEvent.Order.StopPrice != 0 {
+			price = orderEvent.Order.StopPrice
+		}
+
+		return models.Order{
+			ID:                  orderEvent.Order.OrderID,
+			UserID:              userID,
+			ClientOrderID:       orderEvent.Order.CliOrderID,
+			Type:                orderEvent.Type,
+			Symbol:              orderEvent.Order.Symbol,
+			Quantity:            orderEvent.Order.Quantity,
+			Side:                orderEvent.Order.Side,
+			Price:               price,
+			Filled:              orderEvent.Order.Filled,
+			Timestamp:           models.ParseOrderTime(orderEvent.Order.Timestamp, receivedTime),
+			LastUpdateTimestamp: models.ParseOrderTime(orderEvent.Order.LastUpdateTimestamp, receivedTime),
+			Status:              models.OrderOpen,
 		}, nil
 	}
 
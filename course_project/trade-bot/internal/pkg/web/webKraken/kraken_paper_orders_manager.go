@@ -0,0 +1,892 @@
+package webKraken
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrNoMarketPrice  = errors.New("paper trading: no market price")
+	ErrSubscribePrice = errors.New("paper trading: subscribe to prices")
+)
+
+const (
+	defaultPaperInitialBalance = 10000
+	defaultPaperTakerFee       = 0.0005
+	defaultPaperMakerFee       = 0.0002
+	defaultPaperMarginRate     = 0.1
+	defaultPaperPriceTimeout   = 10 * time.Second
+)
+
+// statuses of kraken futures api returned by simulated exchange
+const (
+	placedStatus               = "placed"
+	invalidSizeStatus          = "invalidSize"
+	invalidPriceStatus         = "invalidPrice"
+	insufficientFundsStatus    = "insufficientAvailableFunds"
+	wouldNotReducePosStatus    = "wouldNotReducePosition"
+	postWouldExecuteStatus     = "postWouldExecute"
+	iocWouldNotExecuteStatus   = "iocWouldNotExecute"
+	unknownOrderTypeStatus     = "unknownOrderType"
+	editedStatus               = "edited"
+	orderForEditNotFoundStatus = "orderForEditNotFound"
+	cancelledStatus            = "cancelled"
+	notFoundStatus             = "notFound"
+)
+
+const (
+	executionEventType          = "EXECUTION"
+	placeEventType              = "PLACE"
+	editEventType               = "EDIT"
+	cancelEventType             = "CANCEL"
+	marketOrderType             = "mkt"
+	limitOrderType              = "lmt"
+	postOrderType               = "post"
+	immediateOrCancelOrderType  = "ioc"
+	stopOrderType               = "stp"
+	takeProfitOrderType         = "take_profit"
+	cancelAllOrdersOfAllSymbols = "all"
+	makerFillType               = "maker"
+	takerFillType               = "taker"
+	longPositionSide            = "long"
+	shortPositionSide           = "short"
+	paperAccountName            = "paper"
+)
+
+// events and reasons of kraken futures history api recorded by simulated exchange
+const (
+	orderPlacedHistoryEvent    = "OrderPlaced"
+	orderUpdatedHistoryEvent   = "OrderUpdated"
+	orderCancelledHistoryEvent = "OrderCancelled"
+	fullFillHistoryReason      = "full_fill"
+	cancelledByUserReason      = "cancelled_by_user"
+)
+
+// CandlesSource streams every update of candles on which paper orders are filled. It may be live kraken
+// feed or replay of historical candles
+type CandlesSource interface {
+	LookForCandlesUpdates(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.CandlesTradeData, error)
+}
+
+// TickersSource returns the last prices of products, they are used until the first candle update arrives
+type TickersSource interface {
+	Tickers() ([]krakenFuturesSDK.Ticker, error)
+}
+
+// PaperPosition is an open position of simulated account. Size is negative for short position
+type PaperPosition struct {
+	Symbol string  `json:"symbol"`
+	Size   float64 `json:"size"`
+	Price  float64 `json:"price"`
+}
+
+// PaperFill is an execution of order on simulated exchange
+type PaperFill struct {
+	ID       string  `json:"fill_id"`
+	OrderID  string  `json:"order_id"`
+	CliOrdID string  `json:"cli_ord_id,omitempty"`
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"`
+	Size     float64 `json:"size"`
+	Price    float64 `json:"price"`
+	Fee      float64 `json:"fee"`
+	Time     string  `json:"time"`
+	Type     string  `json:"type"`
+}
+
+// PaperAccount is a snapshot of simulated account
+type PaperAccount struct {
+	Balance     float64                  `json:"balance"`
+	RealizedPnL float64                  `json:"realized_pnl"`
+	Fees        float64                  `json:"fees"`
+	Positions   []PaperPosition          `json:"positions"`
+	OpenOrders  []krakenFuturesSDK.Order `json:"open_orders"`
+	Fills       []PaperFill              `json:"fills"`
+}
+
+// KrakenPaperOrdersManager simulates kraken futures exchange for one user. It keeps balance, positions
+// and resting orders in memory and fills orders against candles of CandlesSource, so strategies can be
+// tried without real money. Responses have the same shape as responses of kraken futures api
+type KrakenPaperOrdersManager struct {
+	candles      CandlesSource
+	tickers      TickersSource
+	takerFee     float64
+	makerFee     float64
+	marginRate   float64
+	priceTimeout time.Duration
+	now          func() time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	balance     float64
+	realizedPnL float64
+	fees        float64
+	positions   map[string]*PaperPosition
+	orders      []*krakenFuturesSDK.Order
+	fills       []PaperFill
+	history     []krakenFuturesSDK.HistoricalOrderElement
+	prices      map[string]float64
+	priceReady  map[string]chan struct{}
+}
+
+func NewKrakenPaperOrdersManager(candles CandlesSource, tickers TickersSource,
+	cfg configs.PaperTradingConfiguration) *KrakenPaperOrdersManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &KrakenPaperOrdersManager{
+		candles:      candles,
+		tickers:      tickers,
+		takerFee:     cfg.TakerFee,
+		makerFee:     cfg.MakerFee,
+		marginRate:   cfg.MarginRate,
+		priceTimeout: time.Duration(cfg.PriceTimeoutInSeconds) * time.Second,
+		now:          time.Now,
+		ctx:          ctx,
+		cancel:       cancel,
+		balance:      cfg.InitialBalance,
+		positions:    make(map[string]*PaperPosition),
+		prices:       make(map[string]float64),
+		priceReady:   make(map[string]chan struct{}),
+	}
+
+	if m.balance == 0 {
+		m.balance = defaultPaperInitialBalance
+	}
+	if m.takerFee == 0 {
+		m.takerFee = defaultPaperTakerFee
+	}
+	if m.makerFee == 0 {
+		m.makerFee = defaultPaperMakerFee
+	}
+	if m.marginRate == 0 {
+		m.marginRate = defaultPaperMarginRate
+	}
+	if m.priceTimeout == 0 {
+		m.priceTimeout = defaultPaperPriceTimeout
+	}
+
+	return m
+}
+
+// Close stops listening to prices
+func (m *KrakenPaperOrdersManager) Close() {
+	m.cancel()
+}
+
+// Account returns snapshot of simulated account
+func (m *KrakenPaperOrdersManager) Account() PaperAccount {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account := PaperAccount{
+		Balance:     m.balance,
+		RealizedPnL: m.realizedPnL,
+		Fees:        m.fees,
+		Fills:       append([]PaperFill(nil), m.fills...),
+	}
+	for _, position := range m.positions {
+		account.Positions = append(account.Positions, *position)
+	}
+	for _, order := range m.orders {
+		account.OpenOrders = append(account.OpenOrders, *order)
+	}
+
+	return account
+}
+
+func (m *KrakenPaperOrdersManager) SendOrder(args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error) {
+	price, err := m.marketPrice(args.Symbol)
+	if err != nil {
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrSendOrder, err)
+	}
+
+	m.mu.Lock()
+	status := m.placeOrder(args, price)
+	m.mu.Unlock()
+
+	if !status.Status.IsSuccessStatus() {
+		err := fmt.Errorf("%s: status: %s", ErrInvalidStatus, status.Status)
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrSendOrder, err)
+	}
+
+	return status, nil
+}
+
+func (m *KrakenPaperOrdersManager) EditOrder(args krakenFuturesSDK.EditOrderArguments) (krakenFuturesSDK.EditStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findOrder(args.OrderID, args.CliOrdID)
+	if i < 0 {
+		return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrEditOrder, ErrOrderNotFound)
+	}
+
+	order := m.orders[i]
+	old := *order
+	if args.Size > 0 {
+		order.Quantity = float64(args.Size)
+	}
+	if args.LimitPrice > 0 {
+		order.LimitPrice = args.LimitPrice
+	}
+	if args.StopPrice > 0 {
+		order.StopPrice = args.StopPrice
+	}
+	if args.CliOrdID != "" {
+		order.CliOrderID = args.CliOrdID
+	}
+	order.LastUpdateTimestamp = m.timestamp()
+
+	status := krakenFuturesSDK.EditStatus{
+		OrderID:      order.OrderID,
+		CliOrderID:   order.CliOrderID,
+		ReceivedTime: order.LastUpdateTimestamp,
+		Status:       editedStatus,
+		OrderEvents:  []krakenFuturesSDK.OrderEvent{{Type: editEventType, Old: old, New: *order}},
+	}
+
+	// edited order may become executable at current price
+	if price, ok := m.prices[paperProductID(order.Symbol)]; ok {
+		m.matchOrders(paperProductID(order.Symbol), price, price)
+	}
+
+	return status, nil
+}
+
+func (m *KrakenPaperOrdersManager) CancelOrder(args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findOrder(args.OrderID, args.CliOrdID)
+	if i < 0 {
+		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrder, ErrOrderNotFound)
+	}
+
+	order := m.removeOrder(i)
+	m.recordHistory(orderCancelledHistoryEvent, order, cancelledByUserReason)
+	return krakenFuturesSDK.CancelStatus{
+		Status:       cancelledStatus,
+		OrderID:      order.OrderID,
+		CliOrdID:     order.CliOrderID,
+		ReceivedTime: m.timestamp(),
+		OrderEvents:  []krakenFuturesSDK.OrderEvent{{Type: cancelEventType, UID: order.OrderID, Order: order}},
+	}, nil
+}
+
+func (m *KrakenPaperOrdersManager) CancelAllOrders(symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := krakenFuturesSDK.CancelAllStatus{
+		ReceivedTime: m.timestamp(),
+		CancelOnly:   cancelAllOrdersOfAllSymbols,
+		Status:       cancelledStatus,
+	}
+	if symbol != "" {
+		status.CancelOnly = symbol
+	}
+
+	for i := 0; i < len(m.orders); {
+		if symbol != "" && !strings.EqualFold(m.orders[i].Symbol, symbol) {
+			i++
+			continue
+		}
+
+		order := m.removeOrder(i)
+		m.recordHistory(orderCancelledHistoryEvent, order, cancelledByUserReason)
+		status.CancelledOrders = append(status.CancelledOrders,
+			krakenFuturesSDK.CanceledOrder{OrderID: order.OrderID, CliOrdID: order.CliOrderID})
+		status.OrderEvents = append(status.OrderEvents,
+			krakenFuturesSDK.OrderEvent{Type: cancelEventType, UID: order.OrderID, Order: order})
+	}
+
+	return status, nil
+}
+
+// OpenOrders returns resting orders of simulated account
+func (m *KrakenPaperOrdersManager) OpenOrders() ([]krakenFuturesSDK.OpenOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	openOrders := make([]krakenFuturesSDK.OpenOrder, 0, len(m.orders))
+	for _, order := range m.orders {
+		openOrders = append(openOrders, krakenFuturesSDK.OpenOrder{
+			OrderID:        order.OrderID,
+			CliOrdID:       order.CliOrderID,
+			Status:         "untouched",
+			Side:           order.Side,
+			OrderType:      order.Type,
+			Symbol:         order.Symbol,
+			LimitPrice:     order.LimitPrice,
+			StopPrice:      order.StopPrice,
+			FilledSize:     order.Filled,
+			UnfilledSize:   order.Quantity - order.Filled,
+			ReduceOnly:     order.ReduceOnly,
+			ReceivedTime:   order.Timestamp,
+			LastUpdateTime: order.LastUpdateTimestamp,
+		})
+	}
+	return openOrders, nil
+}
+
+// OpenPositions returns positions of simulated account, size of position is positive for both sides
+func (m *KrakenPaperOrdersManager) OpenPositions() ([]krakenFuturesSDK.OpenPosition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	positions := make([]krakenFuturesSDK.OpenPosition, 0, len(m.positions))
+	for _, position := range m.positions {
+		side := longPositionSide
+		if position.Size < 0 {
+			side = shortPositionSide
+		}
+		positions = append(positions, krakenFuturesSDK.OpenPosition{
+			Side:     side,
+			Symbol:   position.Symbol,
+			Price:    position.Price,
+			FillTime: m.lastFillTime(position.Symbol),
+			Size:     math.Abs(position.Size),
+		})
+	}
+	return positions, nil
+}
+
+// Fills returns fills of simulated account from the newest one, only fills before lastFillTime are returned when it is set
+func (m *KrakenPaperOrdersManager) Fills(lastFillTime string) ([]krakenFuturesSDK.Fill, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fills := make([]krakenFuturesSDK.Fill, 0, len(m.fills))
+	for i := len(m.fills) - 1; i >= 0; i-- {
+		fill := m.fills[i]
+		if lastFillTime != "" && fill.Time >= lastFillTime {
+			continue
+		}
+		fills = append(fills, krakenFuturesSDK.Fill{
+			FillID:   fill.ID,
+			Symbol:   fill.Symbol,
+			Side:     fill.Side,
+			OrderID:  fill.OrderID,
+			CliOrdID: fill.CliOrdID,
+			Size:     fill.Size,
+			Price:    fill.Price,
+			FillTime: fill.Time,
+			FillType: fill.Type,
+		})
+	}
+	return fills, nil
+}
+
+// Accounts returns the only margin account of simulated exchange
+func (m *KrakenPaperOrdersManager) Accounts() (map[string]krakenFuturesSDK.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var unrealizedPnL, margin float64
+	for id, position := range m.positions {
+		price := m.prices[id]
+		unrealizedPnL += (price - position.Price) * position.Size
+		margin += math.Abs(position.Size) * price * m.marginRate
+	}
+
+	portfolioValue := m.balance + unrealizedPnL
+	return map[string]krakenFuturesSDK.Account{
+		paperAccountName: {
+			Type:            "marginAccount",
+			Balances:        map[string]float64{"usd": m.balance},
+			Auxiliary:       krakenFuturesSDK.AccountAuxiliary{USD: m.balance, PV: portfolioValue, PnL: unrealizedPnL, AF: portfolioValue - margin},
+			BalanceValue:    m.balance,
+			PortfolioValue:  portfolioValue,
+			AvailableMargin: portfolioValue - margin,
+		},
+	}, nil
+}
+
+// HistoricalOrders returns order events of simulated account since timestamp in milliseconds
+func (m *KrakenPaperOrdersManager) HistoricalOrders(since int64) ([]krakenFuturesSDK.HistoricalOrderElement, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var elements []krakenFuturesSDK.HistoricalOrderElement
+	for _, element := range m.history {
+		if element.Timestamp >= since {
+			elements = append(elements, element)
+		}
+	}
+	return elements, nil
+}
+
+func (m *KrakenPaperOrdersManager) ParseSendStatusToOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
+	return parseSendStatusToOrder(userID, sendStatus)
+}
+
+// marketPrice subscribes to candles of symbol and waits for the first price. Price of ticker
+// is used until the first update of candle arrives
+func (m *KrakenPaperOrdersManager) marketPrice(symbol string) (float64, error) {
+	productID := paperProductID(symbol)
+
+	m.mu.Lock()
+	_, ok := m.priceReady[productID]
+	m.mu.Unlock()
+
+	var tickerPrice float64
+	if !ok {
+		tickerPrice = m.tickerPrice(productID)
+	}
+
+	m.mu.Lock()
+	ready, ok := m.priceReady[productID]
+	if !ok {
+		updates, err := m.candles.LookForCandlesUpdates(m.ctx, krakenFuturesWSSDK.OneMinuteCandlesFeed, []string{productID})
+		if err != nil {
+			m.mu.Unlock()
+			return 0, fmt.Errorf("%s: %w", ErrSubscribePrice, err)
+		}
+
+		ready = make(chan struct{})
+		m.priceReady[productID] = ready
+		isReady := tickerPrice > 0
+		if isReady {
+			m.prices[productID] = tickerPrice
+			close(ready)
+		}
+		go m.listenCandles(productID, updates, ready, isReady)
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-time.After(m.priceTimeout):
+		return 0, fmt.Errorf("%s: %s", ErrNoMarketPrice, symbol)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	price, ok := m.prices[productID]
+	if !ok {
+		return 0, fmt.Errorf("%s: %s", ErrNoMarketPrice, symbol)
+	}
+	return price, nil
+}
+
+// tickerPrice returns the last price of product by rest api, zero when it is unknown
+func (m *KrakenPaperOrdersManager) tickerPrice(productID string) float64 {
+	if m.tickers == nil {
+		return 0
+	}
+
+	tickers, err := m.tickers.Tickers()
+	if err != nil {
+		log.Warnf("paper trading: get price of %s from tickers: %s", productID, err)
+		return 0
+	}
+	for _, ticker := range tickers {
+		if strings.EqualFold(ticker.Symbol, productID) {
+			if ticker.Last > 0 {
+				return ticker.Last
+			}
+			return ticker.MarkPrice
+		}
+	}
+	return 0
+}
+
+// listenCandles matches orders against every update of candles. Update carries high and low of the whole
+// candle, so only extremes reached since the previous update of the same candle are matched, otherwise
+// orders placed in the middle of candle would be filled by prices which were before them
+func (m *KrakenPaperOrdersManager) listenCandles(productID string, updates <-chan krakenFuturesWSSDK.CandlesTradeData,
+	ready chan struct{}, isReady bool) {
+	defer func() {
+		m.mu.Lock()
+		delete(m.priceReady, productID)
+		m.mu.Unlock()
+
+		if !isReady {
+			close(ready)
+		}
+	}()
+
+	var previous *paperCandle
+	for update := range updates {
+		candle, err := parsePaperCandle(update.Candle)
+		if err != nil {
+			log.Warnf("paper trading: skip invalid candle of %s: %+v", productID, update.Candle)
+			continue
+		}
+
+		high, low := candle.reachedSince(previous)
+		previous = &candle
+
+		m.mu.Lock()
+		m.prices[productID] = candle.close
+		m.matchOrders(productID, high, low)
+		m.mu.Unlock()
+
+		if !isReady {
+			isReady = true
+			close(ready)
+		}
+	}
+}
+
+// paperCandle is parsed update of candle
+type paperCandle struct {
+	time             int
+	high, low, close float64
+}
+
+func parsePaperCandle(candle krakenFuturesWSSDK.Candle) (paperCandle, error) {
+	high, err := strconv.ParseFloat(candle.High, 64)
+	if err != nil {
+		return paperCandle{}, err
+	}
+	low, err := strconv.ParseFloat(candle.Low, 64)
+	if err != nil {
+		return paperCandle{}, err
+	}
+	price, err := strconv.ParseFloat(candle.Close, 64)
+	if err != nil {
+		return paperCandle{}, err
+	}
+	return paperCandle{time: candle.Time, high: high, low: low, close: price}, nil
+}
+
+// reachedSince returns range of prices reached after previous update. Whole range of candle is
+// returned for the first update of candle, for the next ones close is extended by new high or low
+func (c paperCandle) reachedSince(previous *paperCandle) (high, low float64) {
+	if previous == nil || previous.time != c.time {
+		return c.high, c.low
+	}
+
+	high, low = c.close, c.close
+	if c.high > previous.high {
+		high = c.high
+	}
+	if c.low < previous.low {
+		low = c.low
+	}
+	return high, low
+}
+
+// placeOrder executes marketable order or puts it to order book. Must be called with locked mutex
+func (m *KrakenPaperOrdersManager) placeOrder(args krakenFuturesSDK.SendOrderArguments, price float64) krakenFuturesSDK.SendStatus {
+	now := m.timestamp()
+	orderID := newPaperID()
+
+	status := krakenFuturesSDK.SendStatus{
+		OrderID:      orderID,
+		CliOrderID:   args.CliOrderID,
+		Status:       placedStatus,
+		ReceivedTime: now,
+	}
+
+	order := krakenFuturesSDK.Order{
+		OrderID:             orderID,
+		CliOrderID:          args.CliOrderID,
+		ReduceOnly:          args.ReduceOnly,
+		Symbol:              strings.ToLower(args.Symbol),
+		Quantity:            float64(args.Size),
+		Side:                args.Side,
+		LimitPrice:          args.LimitPrice,
+		StopPrice:           args.StopPrice,
+		Type:                args.OrderType,
+		Timestamp:           now,
+		LastUpdateTimestamp: now,
+	}
+
+	if args.Size == 0 || (args.Side != krakenFuturesSDK.BuySide && args.Side != krakenFuturesSDK.SellSide) {
+		status.Status = invalidSizeStatus
+		return status
+	}
+
+	marketable := args.Side == krakenFuturesSDK.BuySide && args.LimitPrice >= price ||
+		args.Side == krakenFuturesSDK.SellSide && args.LimitPrice <= price
+
+	switch args.OrderType {
+	case marketOrderType:
+	case limitOrderType, postOrderType, immediateOrCancelOrderType:
+		if args.LimitPrice <= 0 {
+			status.Status = invalidPriceStatus
+			return status
+		}
+		if args.OrderType == postOrderType && marketable {
+			status.Status = postWouldExecuteStatus
+			return status
+		}
+		if args.OrderType == immediateOrCancelOrderType && !marketable {
+			status.Status = iocWouldNotExecuteStatus
+			return status
+		}
+	case stopOrderType, takeProfitOrderType:
+		if args.StopPrice <= 0 {
+			status.Status = invalidPriceStatus
+			return status
+		}
+		marketable = isTriggered(order, price, price)
+	default:
+		status.Status = unknownOrderTypeStatus
+		return status
+	}
+
+	if args.OrderType != marketOrderType && !marketable {
+		m.orders = append(m.orders, &order)
+		m.recordHistory(orderPlacedHistoryEvent, order, "")
+		status.OrderEvents = []krakenFuturesSDK.OrderEvent{{Type: placeEventType, Order: order}}
+		return status
+	}
+
+	if reason := m.rejectReason(order, price, m.takerFee); reason != "" {
+		status.Status = krakenFuturesSDK.SendOrderStatus(reason)
+		return status
+	}
+
+	status.OrderEvents = []krakenFuturesSDK.OrderEvent{m.execute(order, price, m.takerFee)}
+	return status
+}
+
+// matchOrders fills resting orders of product which are reached by candle. Must be called with locked mutex
+func (m *KrakenPaperOrdersManager) matchOrders(productID string, high, low float64) {
+	for i := 0; i < len(m.orders); {
+		order := m.orders[i]
+		if paperProductID(order.Symbol) != productID || !isTriggered(*order, high, low) {
+			i++
+			continue
+		}
+
+		price, fee := order.LimitPrice, m.makerFee
+		if order.Type == stopOrderType || order.Type == takeProfitOrderType {
+			// triggered stop with limit price becomes limit order
+			if order.LimitPrice > 0 {
+				order.Type = limitOrderType
+				if !isTriggered(*order, high, low) {
+					i++
+					continue
+				}
+			} else {
+				price, fee = order.StopPrice, m.takerFee
+			}
+		}
+
+		m.removeOrder(i)
+		if reason := m.rejectReason(*order, price, fee); reason != "" {
+			log.Infof("paper trading: order %s is cancelled: %s", order.OrderID, reason)
+			m.recordHistory(orderCancelledHistoryEvent, *order, reason)
+			continue
+		}
+		m.execute(*order, price, fee)
+	}
+}
+
+func isTriggered(order krakenFuturesSDK.Order, high, low float64) bool {
+	isBuy := order.Side == krakenFuturesSDK.BuySide
+
+	switch order.Type {
+	case stopOrderType:
+		return isBuy && high >= order.StopPrice || !isBuy && low <= order.StopPrice
+	case takeProfitOrderType:
+		return isBuy && low <= order.StopPrice || !isBuy && high >= order.StopPrice
+	default:
+		return isBuy && low <= order.LimitPrice || !isBuy && high >= order.LimitPrice
+	}
+}
+
+// rejectReason checks that order may be executed with price. Must be called with locked mutex
+func (m *KrakenPaperOrdersManager) rejectReason(order krakenFuturesSDK.Order, price, feeRate float64) string {
+	productID := paperProductID(order.Symbol)
+
+	var size float64
+	if position, ok := m.positions[productID]; ok {
+		size = position.Size
+	}
+	newSize := size + signedSize(order)
+
+	if order.ReduceOnly && (size == 0 || math.Abs(newSize) > math.Abs(size) || newSize*size < 0) {
+		return wouldNotReducePosStatus
+	}
+
+	if math.Abs(newSize) <= math.Abs(size) {
+		return ""
+	}
+
+	var equity, margin float64
+	equity = m.balance - order.Quantity*price*feeRate
+	for id, position := range m.positions {
+		positionPrice := m.prices[id]
+		equity += (positionPrice - position.Price) * position.Size
+		if id != productID {
+			margin += math.Abs(position.Size) * positionPrice * m.marginRate
+		}
+	}
+	margin += math.Abs(newSize) * price * m.marginRate
+
+	if equity < margin {
+		return insufficientFundsStatus
+	}
+	return ""
+}
+
+// execute fills order with price and updates position and balance. Must be called with locked mutex
+func (m *KrakenPaperOrdersManager) execute(order krakenFuturesSDK.Order, price, feeRate float64) krakenFuturesSDK.OrderEvent {
+	productID := paperProductID(order.Symbol)
+	fee := order.Quantity * price * feeRate
+
+	position, ok := m.positions[productID]
+	if !ok {
+		position = &PaperPosition{Symbol: order.Symbol}
+		m.positions[productID] = position
+	}
+
+	size := signedSize(order)
+	switch {
+	case position.Size*size >= 0:
+		position.Price = (position.Price*math.Abs(position.Size) + price*math.Abs(size)) / math.Abs(position.Size+size)
+	case math.Abs(size) <= math.Abs(position.Size):
+		m.realize((price - position.Price) * -size)
+	default:
+		m.realize((price - position.Price) * position.Size)
+		position.Price = price
+	}
+	position.Size += size
+
+	if position.Size == 0 {
+		delete(m.positions, productID)
+	}
+
+	m.balance -= fee
+	m.fees += fee
+
+	// resting orders are filled with maker fee and the others take liquidity
+	fillType := takerFillType
+	if feeRate != m.takerFee {
+		fillType = makerFillType
+	}
+
+	executionID := newPaperID()
+	m.fills = append(m.fills, PaperFill{
+		ID:       executionID,
+		OrderID:  order.OrderID,
+		CliOrdID: order.CliOrderID,
+		Symbol:   order.Symbol,
+		Side:     order.Side,
+		Size:     order.Quantity,
+		Price:    price,
+		Fee:      fee,
+		Time:     m.timestamp(),
+		Type:     fillType,
+	})
+
+	filled := order
+	filled.Filled = order.Quantity
+	m.recordHistory(orderUpdatedHistoryEvent, filled, fullFillHistoryReason)
+
+	return krakenFuturesSDK.OrderEvent{
+		Type:                executionEventType,
+		Price:               price,
+		Amount:              int(order.Quantity),
+		ExecutionID:         executionID,
+		OrderPriorExecution: order,
+	}
+}
+
+func (m *KrakenPaperOrdersManager) realize(pnl float64) {
+	m.balance += pnl
+	m.realizedPnL += pnl
+}
+
+func (m *KrakenPaperOrdersManager) findOrder(orderID, cliOrdID string) int {
+	for i, order := range m.orders {
+		if orderID != "" && order.OrderID == orderID || orderID == "" && cliOrdID != "" && order.CliOrderID == cliOrdID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *KrakenPaperOrdersManager) removeOrder(i int) krakenFuturesSDK.Order {
+	order := *m.orders[i]
+	m.orders = append(m.orders[:i], m.orders[i+1:]...)
+	return order
+}
+
+// lastFillTime returns time of the last fill of symbol. Must be called with locked mutex
+func (m *KrakenPaperOrdersManager) lastFillTime(symbol string) string {
+	for i := len(m.fills) - 1; i >= 0; i-- {
+		if paperProductID(m.fills[i].Symbol) == paperProductID(symbol) {
+			return m.fills[i].Time
+		}
+	}
+	return ""
+}
+
+// recordHistory saves event of order in format of kraken futures history api. Must be called with locked mutex
+func (m *KrakenPaperOrdersManager) recordHistory(event string, order krakenFuturesSDK.Order, reason string) {
+	now := m.now().UnixNano() / int64(time.Millisecond)
+	m.history = append(m.history, krakenFuturesSDK.HistoricalOrderElement{
+		UID:       newPaperID(),
+		Timestamp: now,
+		Event: map[string]krakenFuturesSDK.HistoricalOrderEvent{event: {
+			Order: krakenFuturesSDK.HistoricalOrder{
+				UID:                 order.OrderID,
+				ClientID:            order.CliOrderID,
+				Tradeable:           paperProductID(order.Symbol),
+				Direction:           order.Side,
+				Quantity:            order.Quantity,
+				Filled:              order.Filled,
+				LimitPrice:          order.LimitPrice,
+				OrderType:           order.Type,
+				ReduceOnly:          order.ReduceOnly,
+				Timestamp:           paperMillis(order.Timestamp),
+				LastUpdateTimestamp: now,
+			},
+			Reason: reason,
+		}},
+	})
+}
+
+func (m *KrakenPaperOrdersManager) timestamp() string {
+	return m.now().UTC().Format(time.RFC3339)
+}
+
+func signedSize(order krakenFuturesSDK.Order) float64 {
+	if order.Side == krakenFuturesSDK.SellSide {
+		return -order.Quantity
+	}
+	return order.Quantity
+}
+
+// paperMillis converts timestamp of simulated exchange to milliseconds
+func paperMillis(timestamp string) int64 {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func paperProductID(symbol string) string {
+	return strings.ToUpper(symbol)
+}
+
+func newPaperID() string {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	return id.String()
+}
@@ -0,0 +1,337 @@
+package webKraken
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// candlesFeed is a CandlesSource which streams updates of candles pushed by test
+type candlesFeed struct {
+	updates chan krakenFuturesWSSDK.CandlesTradeData
+	time    *int
+}
+
+func (f candlesFeed) LookForCandlesUpdates(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.CandlesTradeData, error) {
+	return f.updates, nil
+}
+
+// push streams the first update of the next candle
+func (f candlesFeed) push(high, low, close float64) {
+	*f.time += 60
+	f.update(high, low, close)
+}
+
+// update streams the next update of the current candle
+func (f candlesFeed) update(high, low, close float64) {
+	f.updates <- krakenFuturesWSSDK.CandlesTradeData{
+		Feed:      krakenFuturesWSSDK.OneMinuteCandlesFeed,
+		ProductID: "PI_XBTUSD",
+		Candle: krakenFuturesWSSDK.Candle{
+			Time:  *f.time,
+			High:  fmt.Sprint(high),
+			Low:   fmt.Sprint(low),
+			Close: fmt.Sprint(close),
+		},
+	}
+}
+
+// tickersList is a TickersSource with fixed tickers
+type tickersList []krakenFuturesSDK.Ticker
+
+func (l tickersList) Tickers() ([]krakenFuturesSDK.Ticker, error) {
+	return l, nil
+}
+
+func newTestPaperOrdersManager() (*KrakenPaperOrdersManager, candlesFeed) {
+	return newTestPaperOrdersManagerWithTickers(nil)
+}
+
+func newTestPaperOrdersManagerWithTickers(tickers TickersSource) (*KrakenPaperOrdersManager, candlesFeed) {
+	feed := candlesFeed{updates: make(chan krakenFuturesWSSDK.CandlesTradeData), time: new(int)}
+	manager := NewKrakenPaperOrdersManager(feed, tickers, configs.PaperTradingConfiguration{
+		InitialBalance: 1000,
+		TakerFee:       0.01,
+		MakerFee:       0.001,
+		MarginRate:     0.5,
+	})
+	return manager, feed
+}
+
+func waitForPrice(t *testing.T, manager *KrakenPaperOrdersManager, feed candlesFeed, price float64) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := manager.marketPrice("pi_xbtusd")
+		assert.NoError(t, err)
+	}()
+	feed.push(price, price, price)
+	<-done
+}
+
+func TestKrakenPaperOrdersManager_MarketOrders(t *testing.T) {
+	manager, feed := newTestPaperOrdersManager()
+	defer manager.Close()
+	waitForPrice(t, manager, feed, 100)
+
+	tests := []struct {
+		name        string
+		args        krakenFuturesSDK.SendOrderArguments
+		wantErr     bool
+		wantBalance float64
+		wantSize    float64
+	}{
+		{
+			name:        "Open long",
+			args:        krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: 5},
+			wantBalance: 995,
+			wantSize:    5,
+		},
+		{
+			name:        "Insufficient funds",
+			args:        krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: 20},
+			wantErr:     true,
+			wantBalance: 995,
+			wantSize:    5,
+		},
+		{
+			name:        "Reduce only",
+			args:        krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: 1, ReduceOnly: true},
+			wantErr:     true,
+			wantBalance: 995,
+			wantSize:    5,
+		},
+		{
+			name:        "Invalid size",
+			args:        krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy"},
+			wantErr:     true,
+			wantBalance: 995,
+			wantSize:    5,
+		},
+		{
+			name:        "Flip to short",
+			args:        krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "sell", Size: 8},
+			wantBalance: 987,
+			wantSize:    -3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, err := manager.SendOrder(test.args)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, krakenFuturesSDK.SendOrderStatus("placed"), status.Status)
+
+				order, err := manager.ParseSendStatusToOrder(1, status)
+				assert.NoError(t, err)
+				assert.Equal(t, status.OrderID, order.ID)
+				assert.Equal(t, 100.0, order.Price)
+				assert.Equal(t, float64(test.args.Size), order.Quantity)
+				assert.False(t, order.Timestamp.IsZero())
+			}
+
+			account := manager.Account()
+			assert.InDelta(t, test.wantBalance, account.Balance, 1e-9)
+			assert.Len(t, account.Positions, 1)
+			assert.Equal(t, test.wantSize, account.Positions[0].Size)
+		})
+	}
+}
+
+func TestKrakenPaperOrdersManager_RestingOrders(t *testing.T) {
+	manager, feed := newTestPaperOrdersManager()
+	defer manager.Close()
+	waitForPrice(t, manager, feed, 100)
+
+	limit, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "lmt", Symbol: "pi_xbtusd", Side: "buy", Size: 2, LimitPrice: 90})
+	assert.NoError(t, err)
+	assert.Equal(t, "PLACE", limit.OrderEvents[0].Type)
+
+	_, err = manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "post", Symbol: "pi_xbtusd", Side: "buy", Size: 2, LimitPrice: 110})
+	assert.Error(t, err)
+
+	takeProfit, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "take_profit", Symbol: "pi_xbtusd", Side: "sell", Size: 2, StopPrice: 120, ReduceOnly: true})
+	assert.NoError(t, err)
+
+	placed, err := manager.ParseSendStatusToOrder(1, takeProfit)
+	assert.NoError(t, err)
+	assert.Equal(t, takeProfit.OrderID, placed.ID)
+	assert.Equal(t, "PLACE", placed.Type)
+	assert.Equal(t, 120.0, placed.Price)
+
+	edited, err := manager.EditOrder(krakenFuturesSDK.EditOrderArguments{OrderID: limit.OrderID, LimitPrice: 95})
+	assert.NoError(t, err)
+	assert.Equal(t, krakenFuturesSDK.EditOrderStatus("edited"), edited.Status)
+	assert.Equal(t, 95.0, edited.OrderEvents[0].New.LimitPrice)
+
+	_, err = manager.EditOrder(krakenFuturesSDK.EditOrderArguments{OrderID: "unknown", LimitPrice: 95})
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	// limit order is filled at its price with maker fee
+	feed.push(101, 94, 97)
+	// take profit has not been triggered yet
+	feed.push(119, 97, 118)
+
+	account := manager.Account()
+	assert.Len(t, account.OpenOrders, 1)
+	assert.Equal(t, takeProfit.OrderID, account.OpenOrders[0].OrderID)
+	assert.Len(t, account.Positions, 1)
+	assert.Equal(t, PaperPosition{Symbol: "pi_xbtusd", Size: 2, Price: 95}, account.Positions[0])
+
+	// take profit closes position at stop price with taker fee
+	feed.push(121, 117, 120)
+	waitForPrice(t, manager, feed, 120)
+
+	account = manager.Account()
+	assert.Empty(t, account.OpenOrders)
+	assert.Empty(t, account.Positions)
+	assert.Len(t, account.Fills, 2)
+	assert.InDelta(t, 50, account.RealizedPnL, 1e-9)
+	assert.InDelta(t, 0.19+2.4, account.Fees, 1e-9)
+	assert.InDelta(t, 1000+50-0.19-2.4, account.Balance, 1e-9)
+}
+
+func TestKrakenPaperOrdersManager_IntraCandleUpdates(t *testing.T) {
+	manager, feed := newTestPaperOrdersManager()
+	defer manager.Close()
+	waitForPrice(t, manager, feed, 100)
+
+	// low of candle was reached before stop is placed, repeated update is received after the first one is matched
+	feed.update(100, 90, 99)
+	feed.update(100, 90, 99)
+	stop, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "stp", Symbol: "pi_xbtusd", Side: "sell", Size: 1, StopPrice: 95})
+	assert.NoError(t, err)
+
+	feed.update(101, 90, 97)
+	feed.update(101, 90, 97)
+	assert.Len(t, manager.Account().OpenOrders, 1)
+
+	// new low of the same candle triggers stop
+	feed.update(101, 88, 92)
+	waitForPrice(t, manager, feed, 92)
+
+	account := manager.Account()
+	assert.Empty(t, account.OpenOrders)
+	if assert.Len(t, account.Fills, 1) {
+		assert.Equal(t, stop.OrderID, account.Fills[0].OrderID)
+		assert.Equal(t, 95.0, account.Fills[0].Price)
+	}
+}
+
+func TestKrakenPaperOrdersManager_TickerPrice(t *testing.T) {
+	manager, _ := newTestPaperOrdersManagerWithTickers(tickersList{
+		{Symbol: "PI_ETHUSD", Last: 40},
+		{Symbol: "PI_XBTUSD", Last: 500, MarkPrice: 501},
+	})
+	defer manager.Close()
+
+	// market order is filled by price of ticker before the first update of candle
+	status, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: 1})
+	assert.NoError(t, err)
+
+	order, err := manager.ParseSendStatusToOrder(1, status)
+	assert.NoError(t, err)
+	assert.Equal(t, 500.0, order.Price)
+}
+
+func TestKrakenPaperOrdersManager_CancelOrders(t *testing.T) {
+	manager, feed := newTestPaperOrdersManager()
+	defer manager.Close()
+	waitForPrice(t, manager, feed, 100)
+
+	var ids []string
+	for _, price := range []float64{80, 85, 90} {
+		status, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+			OrderType: "lmt", Symbol: "pi_xbtusd", Side: "buy", Size: 1, LimitPrice: price, CliOrderID: fmt.Sprint(price)})
+		assert.NoError(t, err)
+		ids = append(ids, status.OrderID)
+	}
+
+	cancelled, err := manager.CancelOrder(krakenFuturesSDK.CancelOrderArguments{CliOrdID: "85"})
+	assert.NoError(t, err)
+	assert.Equal(t, ids[1], cancelled.OrderID)
+
+	_, err = manager.CancelOrder(krakenFuturesSDK.CancelOrderArguments{OrderID: ids[1]})
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	cancelledAll, err := manager.CancelAllOrders("PI_XBTUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, []krakenFuturesSDK.CanceledOrder{{OrderID: ids[0], CliOrdID: "80"}, {OrderID: ids[2], CliOrdID: "90"}},
+		cancelledAll.CancelledOrders)
+	assert.Empty(t, manager.Account().OpenOrders)
+}
+
+func TestKrakenPaperOrdersManager_Portfolio(t *testing.T) {
+	manager, feed := newTestPaperOrdersManager()
+	defer manager.Close()
+	waitForPrice(t, manager, feed, 100)
+
+	market, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "mkt", Symbol: "pi_xbtusd", Side: "sell", Size: 2, CliOrderID: "entry"})
+	assert.NoError(t, err)
+	limit, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "lmt", Symbol: "pi_xbtusd", Side: "buy", Size: 1, LimitPrice: 90})
+	assert.NoError(t, err)
+	cancelled, err := manager.SendOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: "lmt", Symbol: "pi_xbtusd", Side: "buy", Size: 1, LimitPrice: 50})
+	assert.NoError(t, err)
+	_, err = manager.CancelOrder(krakenFuturesSDK.CancelOrderArguments{OrderID: cancelled.OrderID})
+	assert.NoError(t, err)
+
+	openOrders, err := manager.OpenOrders()
+	assert.NoError(t, err)
+	assert.Len(t, openOrders, 1)
+	assert.Equal(t, limit.OrderID, openOrders[0].OrderID)
+	assert.Equal(t, 1.0, openOrders[0].UnfilledSize)
+
+	// limit order is filled and reduces short position
+	feed.push(100, 89, 95)
+	waitForPrice(t, manager, feed, 95)
+
+	positions, err := manager.OpenPositions()
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+	assert.Equal(t, "short", positions[0].Side)
+	assert.Equal(t, 1.0, positions[0].Size)
+	assert.Equal(t, 100.0, positions[0].Price)
+	assert.NotEmpty(t, positions[0].FillTime)
+
+	fills, err := manager.Fills("")
+	assert.NoError(t, err)
+	assert.Len(t, fills, 2)
+	assert.Equal(t, limit.OrderID, fills[0].OrderID)
+	assert.Equal(t, "maker", fills[0].FillType)
+	assert.Equal(t, market.OrderID, fills[1].OrderID)
+	assert.Equal(t, "entry", fills[1].CliOrdID)
+	assert.Equal(t, "taker", fills[1].FillType)
+	assert.NotEmpty(t, fills[1].FillID)
+
+	accounts, err := manager.Accounts()
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000+10-2-0.09, accounts["paper"].BalanceValue, 1e-9)
+	assert.InDelta(t, 1000+10-2-0.09+5, accounts["paper"].PortfolioValue, 1e-9)
+
+	history, err := manager.HistoricalOrders(0)
+	assert.NoError(t, err)
+	var events []string
+	for _, element := range history {
+		for event := range element.Event {
+			events = append(events, event)
+		}
+	}
+	assert.Equal(t, []string{"OrderUpdated", "OrderPlaced", "OrderPlaced", "OrderCancelled", "OrderUpdated"}, events)
+}
@@ -0,0 +1,42 @@
+package webKraken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var ErrLookForOrders = errors.New("look for orders")
+
+// KrakenPrivateFeedsWebSDK streams private feeds of user signed with his api keys
+type KrakenPrivateFeedsWebSDK struct {
+	krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI
+}
+
+func NewKrakenPrivateFeedsWebSDK(krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI) *KrakenPrivateFeedsWebSDK {
+	return &KrakenPrivateFeedsWebSDK{krakenWebsocketAPI: krakenWebsocketAPI}
+}
+
+// LookForOrders streams snapshot of open orders of user and then updates of them until ctx is done
+func (k *KrakenPrivateFeedsWebSDK) LookForOrders(ctx context.Context, publicAPIKey, privateAPIKey string) (<-chan krakenFuturesWSSDK.OpenOrdersData, error) {
+	openOrdersCh, err := k.krakenWebsocketAPI.OpenOrders(ctx, krakenFuturesWSSDK.APIKeys{
+		PublicAPIKey:  publicAPIKey,
+		PrivateAPIKey: privateAPIKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLookForOrders, err)
+	}
+
+	ordersCh := make(chan krakenFuturesWSSDK.OpenOrdersData)
+	go func() {
+		defer close(ordersCh)
+		for data := range openOrdersCh {
+			ordersCh <- *data
+		}
+	}()
+
+	return ordersCh, nil
+}
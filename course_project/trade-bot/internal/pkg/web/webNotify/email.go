@@ -0,0 +1,84 @@
+package webNotify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrEmail             = errors.New("email notifier")
+	ErrEmailNotAvailable = errors.New("smtp host is not set")
+	ErrInvalidEmail      = errors.New("invalid email address")
+)
+
+// EmailNotifier sends notifications as plain text emails by smtp server
+type EmailNotifier struct {
+	config configs.SMTPConfiguration
+	now    func() time.Time
+}
+
+func NewEmailNotifier(config configs.SMTPConfiguration) *EmailNotifier {
+	return &EmailNotifier{config: config, now: time.Now}
+}
+
+// Send sends email with subject and body of notification to its address. Server is authenticated by plain auth
+// when username is set, net/smtp allows it only over TLS or to localhost
+func (n *EmailNotifier) Send(ctx context.Context, notification models.Notification) error {
+	if n.config.Host == "" {
+		return fmt.Errorf("%s: %w", ErrEmail, ErrEmailNotAvailable)
+	}
+	if strings.ContainsAny(notification.Address, "\r\n") {
+		return fmt.Errorf("%s: %w", ErrEmail, ErrInvalidEmail)
+	}
+
+	var auth smtp.Auth
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	message := n.message(notification)
+
+	// smtp.SendMail does not take context, so the caller is not blocked by hung server after context is done
+	sent := make(chan error, 1)
+	go func() {
+		sent <- smtp.SendMail(addr, auth, n.config.From, []string{notification.Address}, message)
+	}()
+
+	select {
+	case err := <-sent:
+		if err != nil {
+			return fmt.Errorf("%s: %w", ErrEmail, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", ErrEmail, ctx.Err())
+	}
+}
+
+func (n *EmailNotifier) message(notification models.Notification) []byte {
+	subject := strings.NewReplacer("\r", " ", "\n", " ").Replace(notification.Subject)
+	body := strings.ReplaceAll(strings.ReplaceAll(notification.Body, "\r\n", "\n"), "\n", "\r\n")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&b, "To: %s\r\n", notification.Address)
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", n.now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,231 @@
+package webNotify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+)
+
+// smtpMail is a mail received by smtpServer
+type smtpMail struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a local stand-in of smtp server which accepts one mail per connection,
+// it rejects recipients from rejected domain
+type smtpServer struct {
+	listener net.Listener
+	mails    chan smtpMail
+	rejected string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to start smtp server: %s", err)
+	}
+	s := &smtpServer{listener: listener, mails: make(chan smtpMail, 1), rejected: "rejected.com"}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) config(username string) configs.SMTPConfiguration {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return configs.SMTPConfiguration{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		Username: username,
+		Password: "password",
+		From:     "bot@trade-bot.com",
+	}
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	var mail smtpMail
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			mail.auth = strings.TrimPrefix(line, "AUTH PLAIN ")
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			mail.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")
+			if strings.HasSuffix(to, "@"+s.rejected) {
+				reply("550 5.1.1 mailbox unavailable")
+				continue
+			}
+			mail.to = append(mail.to, to)
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			mail.data = data.String()
+			s.mails <- mail
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestEmailNotifier_Send(t *testing.T) {
+	server := newSMTPServer(t)
+	notification := models.Notification{
+		Event:   models.EventOrderFilled,
+		Address: "user@mail.com",
+		Subject: "Order filled",
+		Body:    "buy 1 PI_XBTUSD\nprice 50000",
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		wantAuth string
+	}{
+		{
+			name: "Without auth",
+		},
+		{
+			name:     "Plain auth",
+			username: "bot",
+			wantAuth: base64.StdEncoding.EncodeToString([]byte("\x00bot\x00password")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n := NewEmailNotifier(server.config(test.username))
+			n.now = func() time.Time { return time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC) }
+
+			err := n.Send(context.Background(), notification)
+			assert.NoError(t, err)
+
+			mail := <-server.mails
+			assert.Equal(t, test.wantAuth, mail.auth)
+			assert.Equal(t, "bot@trade-bot.com", mail.from)
+			assert.Equal(t, []string{"user@mail.com"}, mail.to)
+			assert.Equal(t, "From: bot@trade-bot.com\r\n"+
+				"To: user@mail.com\r\n"+
+				"Subject: Order filled\r\n"+
+				"Date: Wed, 01 Dec 2021 00:00:00 +0000\r\n"+
+				"MIME-Version: 1.0\r\n"+
+				"Content-Type: text/plain; charset=utf-8\r\n"+
+				"\r\n"+
+				"buy 1 PI_XBTUSD\r\n"+
+				"price 50000\r\n", mail.data)
+		})
+	}
+}
+
+func TestEmailNotifier_Send_Errors(t *testing.T) {
+	server := newSMTPServer(t)
+
+	tests := []struct {
+		name    string
+		config  configs.SMTPConfiguration
+		address string
+		wantErr error
+	}{
+		{
+			name:    "Rejected recipient",
+			config:  server.config(""),
+			address: "user@rejected.com",
+		},
+		{
+			name:    "Header injection",
+			config:  server.config(""),
+			address: "user@mail.com\r\nBcc: other@mail.com",
+			wantErr: ErrInvalidEmail,
+		},
+		{
+			name:    "No host",
+			address: "user@mail.com",
+			wantErr: ErrEmailNotAvailable,
+		},
+		{
+			name:    "Server is down",
+			config:  configs.SMTPConfiguration{Host: "127.0.0.1", Port: closedPort(t)},
+			address: "user@mail.com",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewEmailNotifier(test.config).Send(context.Background(),
+				models.Notification{Address: test.address, Subject: "subject"})
+			assert.Error(t, err)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+// closedPort returns port of local address which nobody listens to
+func closedPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %s", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	_ = listener.Close()
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("unable to parse port: %s", err)
+	}
+	return p
+}
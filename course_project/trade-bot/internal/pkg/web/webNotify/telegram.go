@@ -0,0 +1,86 @@
+package webNotify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrTelegram             = errors.New("telegram notifier")
+	ErrInvalidTelegramChat  = errors.New("invalid telegram chat id")
+	ErrTelegramNotAvailable = errors.New("telegram bot api token is not set")
+)
+
+// DefaultTelegramAPIURL is url of telegram bot api used when config does not set another one
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramNotifier sends notifications to chats of users with telegram bot by sendMessage method of bot api
+type TelegramNotifier struct {
+	client   *http.Client
+	apiURL   string
+	apiToken string
+}
+
+func NewTelegramNotifier(client *http.Client, apiURL, apiToken string) *TelegramNotifier {
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPIURL
+	}
+	return &TelegramNotifier{client: client, apiURL: apiURL, apiToken: apiToken}
+}
+
+type telegramMessage struct {
+	ChatID int64  `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Send sends subject and body of notification to chat of its address
+func (n *TelegramNotifier) Send(ctx context.Context, notification models.Notification) error {
+	if n.apiToken == "" {
+		return fmt.Errorf("%s: %w", ErrTelegram, ErrTelegramNotAvailable)
+	}
+
+	chatID, err := strconv.ParseInt(notification.Address, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrTelegram, ErrInvalidTelegramChat)
+	}
+
+	message, err := json.Marshal(telegramMessage{ChatID: chatID, Text: notification.Subject + "\n\n" + notification.Body})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrTelegram, err)
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", n.apiURL, n.apiToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrTelegram, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrTelegram, err)
+	}
+	defer resp.Body.Close()
+
+	var response telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("%s: status %d: %w", ErrTelegram, resp.StatusCode, err)
+	}
+	if !response.OK {
+		return fmt.Errorf("%s: status %d: %s", ErrTelegram, resp.StatusCode, response.Description)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package webNotify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+)
+
+func TestTelegramNotifier_Send(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiToken    string
+		address     string
+		status      int
+		response    string
+		wantRequest string
+		wantErr     error
+		wantErrText string
+	}{
+		{
+			name:        "OK",
+			apiToken:    "token",
+			address:     "42",
+			status:      http.StatusOK,
+			response:    `{"ok":true,"result":{}}`,
+			wantRequest: `{"chat_id":42,"text":"Order filled\n\nbuy 1 PI_XBTUSD"}`,
+		},
+		{
+			name:        "Chat not found",
+			apiToken:    "token",
+			address:     "42",
+			status:      http.StatusBadRequest,
+			response:    `{"ok":false,"description":"Bad Request: chat not found"}`,
+			wantRequest: `{"chat_id":42,"text":"Order filled\n\nbuy 1 PI_XBTUSD"}`,
+			wantErrText: "telegram notifier: status 400: Bad Request: chat not found",
+		},
+		{
+			name:     "Invalid chat id",
+			apiToken: "token",
+			address:  "chat",
+			wantErr:  ErrInvalidTelegramChat,
+		},
+		{
+			name:    "No token",
+			address: "42",
+			wantErr: ErrTelegramNotAvailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var path, request string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				path, request = r.URL.Path, string(body)
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			n := NewTelegramNotifier(server.Client(), server.URL, test.apiToken)
+			err := n.Send(context.Background(), models.Notification{Address: test.address, Subject: "Order filled",
+				Body: "buy 1 PI_XBTUSD"})
+
+			switch {
+			case test.wantErr != nil:
+				assert.ErrorIs(t, err, test.wantErr)
+			case test.wantErrText != "":
+				assert.EqualError(t, err, test.wantErrText)
+			default:
+				assert.NoError(t, err)
+			}
+
+			if test.wantRequest != "" {
+				assert.Equal(t, "/bottoken/sendMessage", path)
+				assert.Equal(t, test.wantRequest, request)
+			} else {
+				assert.Empty(t, request)
+			}
+		})
+	}
+}
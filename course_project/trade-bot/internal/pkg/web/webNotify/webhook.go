@@ -0,0 +1,136 @@
+package webNotify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrWebhook        = errors.New("webhook notifier")
+	ErrWebhookAddress = errors.New("webhook url must be https url of public host")
+)
+
+const (
+	// WebhookSignatureHeader keeps hex encoded HMAC-SHA256 of body by webhook secret of user prefixed by sha256=,
+	// like signature of signals received by trade bot
+	WebhookSignatureHeader = "X-Signature"
+	webhookSignaturePrefix = "sha256="
+	webhookScheme          = "https"
+)
+
+// WebhookNotifier posts notifications as JSON to urls of users, any 2xx response means notification is delivered.
+// Only https urls of public hosts are requested and redirects are not followed, so users can't reach internal network.
+// Body is signed by webhook secret of user when signing key is set
+type WebhookNotifier struct {
+	client     *http.Client
+	signingKey string
+}
+
+func NewWebhookNotifier(timeout time.Duration, signingKey string) *WebhookNotifier {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublic}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			ForceAttemptHTTP2:   true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &WebhookNotifier{client: client, signingKey: signingKey}
+}
+
+// WebhookSecret returns secret which signs notifications sent to webhook of user, it is derived from signing key
+// so it is not stored. Secret is empty without signing key
+func WebhookSecret(signingKey string, userID int) string {
+	if signingKey == "" {
+		return ""
+	}
+	return hex.EncodeToString(hmacSHA256(signingKey, []byte("webhook:"+strconv.Itoa(userID))))
+}
+
+// webhookPayload is a body of request sent to webhook of user
+type webhookPayload struct {
+	ID        int       `json:"id"`
+	Event     string    `json:"event"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, notification models.Notification) error {
+	address, err := url.Parse(notification.Address)
+	if err != nil || address.Scheme != webhookScheme {
+		return fmt.Errorf("%s: %w", ErrWebhook, ErrWebhookAddress)
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        notification.ID,
+		Event:     notification.Event,
+		Subject:   notification.Subject,
+		Body:      notification.Body,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrWebhook, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notification.Address, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrWebhook, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret := WebhookSecret(n.signingKey, notification.UserID); secret != "" {
+		req.Header.Set(WebhookSignatureHeader, webhookSignaturePrefix+hex.EncodeToString(hmacSHA256(secret, payload)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrWebhook, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status %d", ErrWebhook, resp.StatusCode)
+	}
+	return nil
+}
+
+// dialPublic refuses connections to loopback, private, link-local and other non public addresses,
+// it checks resolved address right before connecting, so host can't be rebound to internal one
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return fmt.Errorf("%w: %s", ErrWebhookAddress, host)
+	}
+	return nil
+}
+
+func hmacSHA256(key string, message []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(message)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,162 @@
+package webNotify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+)
+
+// newTestWebhookNotifier returns notifier with transport of test server, which trusts it and doesn't check its address
+func newTestWebhookNotifier(server *httptest.Server, signingKey string) *WebhookNotifier {
+	notifier := NewWebhookNotifier(time.Second, signingKey)
+	notifier.client.Transport = server.Client().Transport
+	return notifier
+}
+
+func TestWebhookNotifier_Send(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		signingKey    string
+		wantSignature bool
+		wantErr       bool
+	}{
+		{
+			name:          "OK",
+			status:        http.StatusOK,
+			signingKey:    "key",
+			wantSignature: true,
+		},
+		{
+			name:   "No signing key",
+			status: http.StatusNoContent,
+		},
+		{
+			name:          "Server error",
+			status:        http.StatusInternalServerError,
+			signingKey:    "key",
+			wantSignature: true,
+			wantErr:       true,
+		},
+		{
+			name:          "Redirect is not followed",
+			status:        http.StatusFound,
+			signingKey:    "key",
+			wantSignature: true,
+			wantErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var contentType, signature, request string
+			requests := 0
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				contentType, signature, request = r.Header.Get("Content-Type"), r.Header.Get(WebhookSignatureHeader), string(body)
+				requests++
+				if test.status == http.StatusFound {
+					w.Header().Set("Location", "/other")
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			err := newTestWebhookNotifier(server, test.signingKey).Send(context.Background(), models.Notification{
+				ID:        1,
+				UserID:    7,
+				Event:     models.EventSessionClosed,
+				Address:   server.URL + "/hook",
+				Subject:   "Session closed",
+				Body:      "session 1 closed",
+				CreatedAt: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC),
+			})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			wantRequest := `{"id":1,"event":"session_closed","subject":"Session closed",` +
+				`"body":"session 1 closed","created_at":"2021-12-01T00:00:00Z"}`
+			assert.Equal(t, 1, requests)
+			assert.Equal(t, "application/json", contentType)
+			assert.Equal(t, wantRequest, request)
+
+			if test.wantSignature {
+				// receiver verifies body by secret of user
+				mac := hmac.New(sha256.New, []byte(WebhookSecret(test.signingKey, 7)))
+				mac.Write([]byte(wantRequest))
+				assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+			} else {
+				assert.Empty(t, signature)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_Send_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	notifier := newTestWebhookNotifier(server, "")
+	notifier.client.Timeout = 50 * time.Millisecond
+
+	err := notifier.Send(context.Background(), models.Notification{Address: server.URL})
+	assert.Error(t, err)
+}
+
+func TestWebhookNotifier_Send_Address(t *testing.T) {
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		address string
+	}{
+		{name: "Plain http", address: "http://hooks.example.com/trade-bot"},
+		{name: "Not url", address: "://hook"},
+		{name: "Loopback", address: server.URL + "/hook"},
+		{name: "Localhost", address: "https://localhost:1/hook"},
+		{name: "IPv6 loopback", address: "https://[::1]:1/hook"},
+		{name: "Cloud metadata", address: "https://169.254.169.254/latest/meta-data"},
+		{name: "Private network", address: "https://10.0.0.1/hook"},
+		{name: "Private network by IPv4-mapped IPv6", address: "https://[::ffff:192.168.1.1]/hook"},
+		{name: "Unspecified", address: "https://0.0.0.0:1/hook"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewWebhookNotifier(time.Second, "key").Send(context.Background(), models.Notification{
+				Address: test.address,
+			})
+			assert.ErrorIs(t, err, ErrWebhookAddress)
+		})
+	}
+	assert.Equal(t, 0, requests)
+}
+
+func TestWebhookSecret(t *testing.T) {
+	assert.Empty(t, WebhookSecret("", 1))
+	assert.Len(t, WebhookSecret("key", 1), 64)
+	assert.Equal(t, WebhookSecret("key", 1), WebhookSecret("key", 1))
+	assert.NotEqual(t, WebhookSecret("key", 1), WebhookSecret("key", 2))
+	assert.NotEqual(t, WebhookSecret("key", 1), WebhookSecret("other key", 1))
+}
@@ -19,8 +19,21 @@ type SignInInput struct {
 }
 
 type SignInResponse struct {
-	AccessToken string `json:"access_token"`
-	Message     string `json:"message"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Message      string `json:"message"`
+}
+
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Message      string `json:"message"`
 }
 
 type LogoutInput struct {
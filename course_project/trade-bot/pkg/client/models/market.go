@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+type GetPriceInput struct {
+	Symbol string
+}
+
+type Ticker struct {
+	Symbol    string  `json:"symbol"`
+	Pair      string  `json:"pair,omitempty"`
+	MarkPrice float64 `json:"markPrice,omitempty"`
+	Bid       float64 `json:"bid,omitempty"`
+	Ask       float64 `json:"ask,omitempty"`
+	Last      float64 `json:"last,omitempty"`
+	Open24H   float64 `json:"open24h,omitempty"`
+	Vol24h    int     `json:"vol24h,omitempty"`
+	Suspended bool    `json:"suspended,omitempty"`
+}
+
+func (t *Ticker) String() string {
+	str := fmt.Sprintf(`
+		symbol:     %s,
+		last:       %f,
+		bid:        %f,
+		ask:        %f,
+		mark_price: %f,
+		open_24h:   %f,
+		volume_24h: %d,
+	`, t.Symbol, t.Last, t.Bid, t.Ask, t.MarkPrice, t.Open24H, t.Vol24h)
+	if t.Suspended {
+		str += "suspended: true\n"
+	}
+	return str
+}
+
+type GetTickersResponse struct {
+	Tickers []Ticker `json:"tickers,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+// Ticker finds ticker of symbol in any letter case
+func (r *GetTickersResponse) Ticker(symbol string) (Ticker, bool) {
+	for _, ticker := range r.Tickers {
+		if strings.EqualFold(ticker.Symbol, symbol) {
+			return ticker, true
+		}
+	}
+	return Ticker{}, false
+}
+
+type GetPriceResponse struct {
+	Ticker
+	Message string `json:"message,omitempty"`
+}
+
+func (r *GetPriceResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+	return r.Ticker.String()
+}
+
+type GetInstrumentsInput struct{}
+
+type Instrument struct {
+	Symbol       string  `json:"symbol"`
+	Type         string  `json:"type"`
+	Tradeable    bool    `json:"tradeable"`
+	Underlying   string  `json:"underlying,omitempty"`
+	TickSize     float64 `json:"tickSize,omitempty"`
+	ContractSize int     `json:"contractSize,omitempty"`
+}
+
+func (i *Instrument) String() string {
+	return fmt.Sprintf("%s - %s, tick size: %v, contract size: %d", i.Symbol, i.Type, i.TickSize, i.ContractSize)
+}
+
+type GetInstrumentsResponse struct {
+	Instruments []Instrument `json:"instruments,omitempty"`
+	Message     string       `json:"message,omitempty"`
+}
+
+// String lists only tradeable instruments
+func (r *GetInstrumentsResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+
+	instruments := ""
+	for _, instrument := range r.Instruments {
+		if instrument.Tradeable {
+			instruments += fmt.Sprintf("%s\n", instrument.String())
+		}
+	}
+	return instruments
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -53,21 +55,133 @@ type StartTradingInput struct {
 
 type StartTradingDetails struct {
 	SendOrderInput
-	StopLossBorder   uint `json:"stop_loss_border"`
-	TakeProfitBorder uint `json:"take_profit_border"`
+	Strategy         string             `json:"strategy,omitempty"`
+	Parameters       map[string]float64 `json:"parameters,omitempty"`
+	StopLossBorder   uint               `json:"stop_loss_border,omitempty"`
+	TakeProfitBorder uint               `json:"take_profit_border,omitempty"`
+	Bracket          bool               `json:"bracket,omitempty"`
 }
 
+// StartTradingResponse is sent when trading session is started and when it is finished
 type StartTradingResponse struct {
-	SendOrderResponse
+	TradingSession
+	Message string `json:"message,omitempty"`
 }
 
-type GetUserOrdersInput struct {
+func (r *StartTradingResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+	return r.TradingSession.String()
+}
+
+type TradingSession struct {
+	ID           int                 `json:"id"`
+	Status       string              `json:"status"`
+	Details      StartTradingDetails `json:"details"`
+	EntryOrderID string              `json:"entry_order_id,omitempty"`
+	EntryPrice   float64             `json:"entry_price,omitempty"`
+	ExitOrderID  string              `json:"exit_order_id,omitempty"`
+	ExitPrice    float64             `json:"exit_price,omitempty"`
+	Error        string              `json:"error,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+
+	StopLossOrderID   string `json:"stop_loss_order_id,omitempty"`
+	TakeProfitOrderID string `json:"take_profit_order_id,omitempty"`
+}
+
+func (s *TradingSession) String() string {
+	str := fmt.Sprintf(`
+		session_id:  %d,
+		status:      %s,
+		symbol:      %s,
+		side:        %s,
+		size:        %d,
+		strategy:    %s,
+		entry_price: %f,
+		exit_price:  %f,
+		created_at:  %s,
+	`, s.ID, s.Status, s.Details.Symbol, s.Details.Side, s.Details.Size, s.Details.Strategy,
+		s.EntryPrice, s.ExitPrice, s.CreatedAt)
+	if s.Details.Bracket {
+		str += fmt.Sprintf("stop_loss_order_id: %s,\ntake_profit_order_id: %s,\n", s.StopLossOrderID, s.TakeProfitOrderID)
+	}
+	if s.Error != "" {
+		str += fmt.Sprintf("error: %s\n", s.Error)
+	}
+	return str
+}
+
+type GetSessionsInput struct {
 	JWTToken string
 }
 
+type GetSessionsResponse struct {
+	Sessions []TradingSession `json:"sessions,omitempty"`
+	Message  string           `json:"message,omitempty"`
+}
+
+func (r *GetSessionsResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+
+	sessions := ""
+	for _, session := range r.Sessions {
+		sessions += fmt.Sprintf("%s\n\n", session.String())
+	}
+	return sessions
+}
+
+type CancelSessionInput struct {
+	SessionID int
+	JWTToken  string
+}
+
+type CancelSessionResponse struct {
+	TradingSession
+	Message string `json:"message,omitempty"`
+}
+
+func (r *CancelSessionResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+	return r.TradingSession.String()
+}
+
+type GetUserOrdersInput struct {
+	Symbol string
+	Side   string
+	Status string
+	// Ascending sorts orders from the earliest ones, the latest orders go first by default
+	Ascending bool
+	Limit     int
+	Cursor    string
+	JWTToken  string
+}
+
+// Query returns query parameters of orders filter, empty fields are omitted
+func (i *GetUserOrdersInput) Query() url.Values {
+	query := url.Values{}
+	for name, value := range map[string]string{"symbol": i.Symbol, "side": i.Side, "status": i.Status, "cursor": i.Cursor} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if i.Ascending {
+		query.Set("order", "asc")
+	}
+	if i.Limit != 0 {
+		query.Set("limit", strconv.Itoa(i.Limit))
+	}
+	return query
+}
+
 type GetUserOrdersResponse struct {
-	Orders  []Order `json:"orders,omitempty"`
-	Message string  `json:"message,omitempty"`
+	Orders     []Order `json:"orders,omitempty"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	Message    string  `json:"message,omitempty"`
 }
 
 func (r *GetUserOrdersResponse) String() string {
@@ -94,6 +208,7 @@ type Order struct {
 	Timestamp           time.Time `json:"timestamp"`
 	LastUpdateTimestamp time.Time `json:"last_update_timestamp"`
 	Price               float64   `json:"price"`
+	Status              string    `json:"status"`
 }
 
 func (o *Order) String() string {
@@ -106,5 +221,120 @@ func (o *Order) String() string {
 		filled:     %d,
 		timestamp:  %s,
 		price:      %f,
-	`, o.ID, o.Type, o.Symbol, o.Quantity, o.Side, o.Filled, o.Timestamp, o.Price)
+		status:     %s,
+	`, o.ID, o.Type, o.Symbol, o.Quantity, o.Side, o.Filled, o.Timestamp, o.Price, o.Status)
+}
+
+type EditOrderInput struct {
+	OrderID    string  `json:"-"`
+	Size       uint    `json:"size,omitempty"`
+	LimitPrice float64 `json:"limit_price,omitempty"`
+	StopPrice  float64 `json:"stop_price,omitempty"`
+	JWTToken   string  `json:"-"`
+}
+
+type EditOrderResponse struct {
+	Order
+	Message string `json:"message,omitempty"`
+}
+
+func (r *EditOrderResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+	return r.Order.String()
+}
+
+type CancelOrderInput struct {
+	OrderID  string
+	JWTToken string
+}
+
+type CancelOrderResponse struct {
+	Status  string `json:"status"`
+	OrderID string `json:"order_id"`
+	Message string `json:"message,omitempty"`
+}
+
+func (r *CancelOrderResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+	return fmt.Sprintf("order_id: %s, status: %s", r.OrderID, r.Status)
+}
+
+// CancelAllOrdersInput cancels orders by symbol or of all symbols when it is empty
+type CancelAllOrdersInput struct {
+	Symbol   string
+	JWTToken string
+}
+
+type CancelAllOrdersResponse struct {
+	Status          string `json:"status"`
+	CancelOnly      string `json:"cancelOnly"`
+	CancelledOrders []struct {
+		OrderID string `json:"order_id"`
+	} `json:"cancelledOrders,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (r *CancelAllOrdersResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+
+	orders := fmt.Sprintf("cancelled orders of %s: %d\n", r.CancelOnly, len(r.CancelledOrders))
+	for _, order := range r.CancelledOrders {
+		orders += fmt.Sprintf("order_id: %s\n", order.OrderID)
+	}
+	return orders
+}
+
+type GetStrategiesInput struct {
+	JWTToken string
+}
+
+type GetStrategiesResponse struct {
+	Strategies []Strategy `json:"strategies,omitempty"`
+	Message    string     `json:"message,omitempty"`
+}
+
+func (r *GetStrategiesResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+
+	strategies := ""
+	for _, strategy := range r.Strategies {
+		strategies += fmt.Sprintf("%s\n\n", strategy.String())
+	}
+	return strategies
+}
+
+type Strategy struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Parameters  []StrategyParameter `json:"parameters"`
+}
+
+func (s *Strategy) String() string {
+	str := fmt.Sprintf("%s - %s", s.Name, s.Description)
+	for _, p := range s.Parameters {
+		str += fmt.Sprintf("\n\t%s", p.String())
+	}
+	return str
+}
+
+type StrategyParameter struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Default     float64 `json:"default,omitempty"`
+}
+
+func (p *StrategyParameter) String() string {
+	if p.Required {
+		return fmt.Sprintf("%s (required) - %s", p.Name, p.Description)
+	}
+	return fmt.Sprintf("%s (default %v) - %s", p.Name, p.Default, p.Description)
 }
@@ -0,0 +1,46 @@
+package models
+
+import "fmt"
+
+type GetPnLInput struct {
+	From     string
+	To       string
+	JWTToken string
+}
+
+type PnLRow struct {
+	Day           string  `json:"day"`
+	Symbol        string  `json:"symbol"`
+	Strategy      string  `json:"strategy"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Fees          float64 `json:"fees"`
+	ClosedSize    float64 `json:"closed_size"`
+	OpenSize      float64 `json:"open_size"`
+}
+
+func (r *PnLRow) String() string {
+	return fmt.Sprintf("%s %s %s: realized %.2f, unrealized %.2f, fees %.2f",
+		r.Day, r.Symbol, r.Strategy, r.RealizedPnL, r.UnrealizedPnL, r.Fees)
+}
+
+type GetPnLResponse struct {
+	Rows          []PnLRow `json:"rows,omitempty"`
+	RealizedPnL   float64  `json:"realized_pnl"`
+	UnrealizedPnL float64  `json:"unrealized_pnl"`
+	Fees          float64  `json:"fees"`
+	Message       string   `json:"message,omitempty"`
+}
+
+func (r *GetPnLResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+
+	rows := ""
+	for _, row := range r.Rows {
+		rows += fmt.Sprintf("%s\n", row.String())
+	}
+	return rows + fmt.Sprintf("\nTotal (USD): realized %.2f, unrealized %.2f, fees %.2f",
+		r.RealizedPnL, r.UnrealizedPnL, r.Fees)
+}
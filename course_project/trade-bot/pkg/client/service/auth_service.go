@@ -11,9 +11,10 @@ import (
 )
 
 var (
-	ErrSignIn = errors.New("sign in")
-	ErrSignUp = errors.New("sign up")
-	ErrLogout = errors.New("logout")
+	ErrSignIn  = errors.New("sign in")
+	ErrSignUp  = errors.New("sign up")
+	ErrRefresh = errors.New("refresh")
+	ErrLogout  = errors.New("logout")
 )
 
 type AuthService struct {
@@ -64,6 +65,27 @@ func (s *AuthService) SignIn(input models.SignInInput) (models.SignInResponse, e
 	return output, err
 }
 
+// Refresh exchanges refresh token for new pair of tokens, the old refresh token can't be used again
+func (s *AuthService) Refresh(input models.RefreshInput) (models.RefreshResponse, error) {
+	req, err := s.client.NewRequest(http.MethodPost, "/auth/refresh", "", input)
+	if err != nil {
+		return models.RefreshResponse{}, fmt.Errorf("%s: %w", ErrRefresh, err)
+	}
+
+	var output models.RefreshResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.RefreshResponse{}, fmt.Errorf("%s: %w", ErrRefresh, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.RefreshResponse{}, fmt.Errorf("%s: %s: %s", ErrRefresh, resp.Status, output.Message)
+	}
+
+	return output, err
+}
+
 func (s *AuthService) Logout(input models.LogoutInput) (models.LogoutResponse, error) {
 	req, err := s.client.NewRequest(http.MethodDelete, "/auth/logout", input.JWTToken, nil)
 	if err != nil {
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/client/app"
+	"trade-bot/pkg/client/models"
+)
+
+var (
+	ErrGetPrice       = errors.New("get price")
+	ErrGetInstruments = errors.New("get instruments")
+	ErrSymbolNotFound = errors.New("symbol not found")
+)
+
+type MarketService struct {
+	client app.ClientActions
+}
+
+func NewMarketService(client app.ClientActions) *MarketService {
+	return &MarketService{client: client}
+}
+
+func (s *MarketService) GetPrice(input models.GetPriceInput) (models.GetPriceResponse, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "/market/tickers", "", nil)
+	if err != nil {
+		return models.GetPriceResponse{}, fmt.Errorf("%s: %w", ErrGetPrice, err)
+	}
+
+	var output models.GetTickersResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.GetPriceResponse{}, fmt.Errorf("%s: %w", ErrGetPrice, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.GetPriceResponse{}, fmt.Errorf("%s: %s: %s", ErrGetPrice, resp.Status, output.Message)
+	}
+
+	ticker, ok := output.Ticker(input.Symbol)
+	if !ok {
+		return models.GetPriceResponse{}, fmt.Errorf("%s: %s: %s", ErrGetPrice, ErrSymbolNotFound, input.Symbol)
+	}
+
+	return models.GetPriceResponse{Ticker: ticker}, nil
+}
+
+func (s *MarketService) GetInstruments(input models.GetInstrumentsInput) (models.GetInstrumentsResponse, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "/market/instruments", "", nil)
+	if err != nil {
+		return models.GetInstrumentsResponse{}, fmt.Errorf("%s: %w", ErrGetInstruments, err)
+	}
+
+	var output models.GetInstrumentsResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.GetInstrumentsResponse{}, fmt.Errorf("%s: %w", ErrGetInstruments, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.GetInstrumentsResponse{}, fmt.Errorf("%s: %s: %s", ErrGetInstruments, resp.Status, output.Message)
+	}
+
+	return output, err
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/pkg/errors"
 
@@ -11,9 +12,15 @@ import (
 )
 
 var (
-	ErrSendOrder     = errors.New("send order")
-	ErrStartTrading  = errors.New("start trading")
-	ErrGetUserOrders = errors.New("get user orders")
+	ErrSendOrder       = errors.New("send order")
+	ErrStartTrading    = errors.New("start trading")
+	ErrGetUserOrders   = errors.New("get user orders")
+	ErrEditOrder       = errors.New("edit order")
+	ErrCancelOrder     = errors.New("cancel order")
+	ErrCancelAllOrders = errors.New("cancel all orders")
+	ErrGetStrategies   = errors.New("get strategies")
+	ErrGetSessions     = errors.New("get trading sessions")
+	ErrCancelSession   = errors.New("cancel trading session")
 )
 
 type OrdersManagerService struct {
@@ -71,11 +78,13 @@ func (s *OrdersManagerService) StartTrading(input models.StartTradingInput) (<-c
 	return tradingRespCh, errCh, nil
 }
 
+// GetUserOrders returns page of orders of user, NextCursor of response is Cursor of the next page
 func (s *OrdersManagerService) GetUserOrders(input models.GetUserOrdersInput) (models.GetUserOrdersResponse, error) {
 	req, err := s.client.NewRequest(http.MethodGet, "/orderManager/my-orders", input.JWTToken, nil)
 	if err != nil {
 		return models.GetUserOrdersResponse{}, fmt.Errorf("%s: %w", ErrGetUserOrders, err)
 	}
+	req.URL.RawQuery = input.Query().Encode()
 
 	var output models.GetUserOrdersResponse
 
@@ -90,3 +99,129 @@ func (s *OrdersManagerService) GetUserOrders(input models.GetUserOrdersInput) (m
 
 	return output, err
 }
+
+func (s *OrdersManagerService) EditOrder(input models.EditOrderInput) (models.EditOrderResponse, error) {
+	path := fmt.Sprintf("/orderManager/orders/%s", url.PathEscape(input.OrderID))
+	req, err := s.client.NewRequest(http.MethodPut, path, input.JWTToken, input)
+	if err != nil {
+		return models.EditOrderResponse{}, fmt.Errorf("%s: %w", ErrEditOrder, err)
+	}
+
+	var output models.EditOrderResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.EditOrderResponse{}, fmt.Errorf("%s: %w", ErrEditOrder, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.EditOrderResponse{}, fmt.Errorf("%s: %s: %s", ErrEditOrder, resp.Status, output.Message)
+	}
+
+	return output, err
+}
+
+func (s *OrdersManagerService) CancelOrder(input models.CancelOrderInput) (models.CancelOrderResponse, error) {
+	path := fmt.Sprintf("/orderManager/orders/%s", url.PathEscape(input.OrderID))
+	req, err := s.client.NewRequest(http.MethodDelete, path, input.JWTToken, nil)
+	if err != nil {
+		return models.CancelOrderResponse{}, fmt.Errorf("%s: %w", ErrCancelOrder, err)
+	}
+
+	var output models.CancelOrderResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.CancelOrderResponse{}, fmt.Errorf("%s: %w", ErrCancelOrder, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.CancelOrderResponse{}, fmt.Errorf("%s: %s: %s", ErrCancelOrder, resp.Status, output.Message)
+	}
+
+	return output, err
+}
+
+func (s *OrdersManagerService) CancelAllOrders(input models.CancelAllOrdersInput) (models.CancelAllOrdersResponse, error) {
+	req, err := s.client.NewRequest(http.MethodDelete, "/orderManager/orders", input.JWTToken, nil)
+	if err != nil {
+		return models.CancelAllOrdersResponse{}, fmt.Errorf("%s: %w", ErrCancelAllOrders, err)
+	}
+	if input.Symbol != "" {
+		req.URL.RawQuery = url.Values{"symbol": {input.Symbol}}.Encode()
+	}
+
+	var output models.CancelAllOrdersResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.CancelAllOrdersResponse{}, fmt.Errorf("%s: %w", ErrCancelAllOrders, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.CancelAllOrdersResponse{}, fmt.Errorf("%s: %s: %s", ErrCancelAllOrders, resp.Status, output.Message)
+	}
+
+	return output, err
+}
+
+func (s *OrdersManagerService) GetStrategies(input models.GetStrategiesInput) (models.GetStrategiesResponse, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "/orderManager/strategies", input.JWTToken, nil)
+	if err != nil {
+		return models.GetStrategiesResponse{}, fmt.Errorf("%s: %w", ErrGetStrategies, err)
+	}
+
+	var output models.GetStrategiesResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.GetStrategiesResponse{}, fmt.Errorf("%s: %w", ErrGetStrategies, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.GetStrategiesResponse{}, fmt.Errorf("%s: %s: %s", ErrGetStrategies, resp.Status, output.Message)
+	}
+
+	return output, err
+}
+
+func (s *OrdersManagerService) GetSessions(input models.GetSessionsInput) (models.GetSessionsResponse, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "/orderManager/sessions", input.JWTToken, nil)
+	if err != nil {
+		return models.GetSessionsResponse{}, fmt.Errorf("%s: %w", ErrGetSessions, err)
+	}
+
+	var output models.GetSessionsResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.GetSessionsResponse{}, fmt.Errorf("%s: %w", ErrGetSessions, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.GetSessionsResponse{}, fmt.Errorf("%s: %s: %s", ErrGetSessions, resp.Status, output.Message)
+	}
+
+	return output, err
+}
+
+func (s *OrdersManagerService) CancelSession(input models.CancelSessionInput) (models.CancelSessionResponse, error) {
+	path := fmt.Sprintf("/orderManager/sessions/%d", input.SessionID)
+	req, err := s.client.NewRequest(http.MethodDelete, path, input.JWTToken, nil)
+	if err != nil {
+		return models.CancelSessionResponse{}, fmt.Errorf("%s: %w", ErrCancelSession, err)
+	}
+
+	var output models.CancelSessionResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.CancelSessionResponse{}, fmt.Errorf("%s: %w", ErrCancelSession, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.CancelSessionResponse{}, fmt.Errorf("%s: %s: %s", ErrCancelSession, resp.Status, output.Message)
+	}
+
+	return output, err
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/client/app"
+	"trade-bot/pkg/client/models"
+)
+
+var ErrGetPnL = errors.New("get pnl")
+
+type ReportsService struct {
+	client app.ClientActions
+}
+
+func NewReportsService(client app.ClientActions) *ReportsService {
+	return &ReportsService{client: client}
+}
+
+// GetPnL returns PnL report of user in JSON, empty From or To does not limit days
+func (s *ReportsService) GetPnL(input models.GetPnLInput) (models.GetPnLResponse, error) {
+	query := url.Values{}
+	if input.From != "" {
+		query.Set("from", input.From)
+	}
+	if input.To != "" {
+		query.Set("to", input.To)
+	}
+
+	req, err := s.client.NewRequest(http.MethodGet, "/reports/pnl", input.JWTToken, nil)
+	if err != nil {
+		return models.GetPnLResponse{}, fmt.Errorf("%s: %w", ErrGetPnL, err)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	var output models.GetPnLResponse
+
+	resp, err := s.client.Do(req, &output)
+	if err != nil {
+		return models.GetPnLResponse{}, fmt.Errorf("%s: %w", ErrGetPnL, err)
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 400) {
+		return models.GetPnLResponse{}, fmt.Errorf("%s: %s: %s", ErrGetPnL, resp.Status, output.Message)
+	}
+
+	return output, nil
+}
@@ -8,6 +8,7 @@ import (
 type Authorization interface {
 	SignUp(input models.SignUpInput) (models.SignUpResponse, error)
 	SignIn(input models.SignInInput) (models.SignInResponse, error)
+	Refresh(input models.RefreshInput) (models.RefreshResponse, error)
 	Logout(input models.LogoutInput) (models.LogoutResponse, error)
 }
 
@@ -15,16 +16,35 @@ type OrdersManager interface {
 	SendOrder(input models.SendOrderInput) (models.SendOrderResponse, error)
 	StartTrading(input models.StartTradingInput) (<-chan *models.StartTradingResponse, <-chan error, error)
 	GetUserOrders(input models.GetUserOrdersInput) (models.GetUserOrdersResponse, error)
+	EditOrder(input models.EditOrderInput) (models.EditOrderResponse, error)
+	CancelOrder(input models.CancelOrderInput) (models.CancelOrderResponse, error)
+	CancelAllOrders(input models.CancelAllOrdersInput) (models.CancelAllOrdersResponse, error)
+	GetStrategies(input models.GetStrategiesInput) (models.GetStrategiesResponse, error)
+	GetSessions(input models.GetSessionsInput) (models.GetSessionsResponse, error)
+	CancelSession(input models.CancelSessionInput) (models.CancelSessionResponse, error)
+}
+
+type Market interface {
+	GetPrice(input models.GetPriceInput) (models.GetPriceResponse, error)
+	GetInstruments(input models.GetInstrumentsInput) (models.GetInstrumentsResponse, error)
+}
+
+type Reports interface {
+	GetPnL(input models.GetPnLInput) (models.GetPnLResponse, error)
 }
 
 type Service struct {
 	Authorization
 	OrdersManager
+	Market
+	Reports
 }
 
 func NewService(client app.ClientActions) *Service {
 	return &Service{
 		Authorization: NewAuthService(client),
 		OrdersManager: NewOrdersManagerService(client),
+		Market:        NewMarketService(client),
+		Reports:       NewReportsService(client),
 	}
 }
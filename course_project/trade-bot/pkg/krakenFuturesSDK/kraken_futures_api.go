@@ -86,6 +86,20 @@ func (a *API) Instruments() (*InstrumentsResponse, error) {
 	return resp.(*InstrumentsResponse), nil
 }
 
+// Charts returns ohlc candles of symbol with resolution like 1m or 1h between from and to unix seconds,
+// tickType is one of trade, mark or spot
+func (a *API) Charts(tickType, symbol, resolution string, from, to int64) (*ChartsResponse, error) {
+	values := url.Values{}
+	values.Add("from", strconv.FormatInt(from, 10))
+	values.Add("to", strconv.FormatInt(to, 10))
+	endpoint := fmt.Sprintf("/api/charts/v1/%s/%s/%s", tickType, symbol, resolution)
+	resp, err := a.queryPublic(http.MethodGet, endpoint, values, &ChartsResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*ChartsResponse), nil
+}
+
 // --------------------------------------------------------------------------------- //
 
 // -------------------------- PRIVATE KRAKEN API ENDPOINTS -------------------------- //
@@ -180,6 +194,56 @@ func (a *API) CancelAllOrders(symbol string) (*CancelAllOrdersResponse, error) {
 	return resp.(*CancelAllOrdersResponse), nil
 }
 
+func (a *API) OpenOrders() (*OpenOrdersResponse, error) {
+	resp, err := a.queryPrivate(http.MethodGet, "/derivatives/api/v3/openorders", nil, &OpenOrdersResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*OpenOrdersResponse), nil
+}
+
+func (a *API) OpenPositions() (*OpenPositionsResponse, error) {
+	resp, err := a.queryPrivate(http.MethodGet, "/derivatives/api/v3/openpositions", nil, &OpenPositionsResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*OpenPositionsResponse), nil
+}
+
+// Fills returns last 100 fills of account, older fills are returned when lastFillTime is set
+func (a *API) Fills(lastFillTime string) (*FillsResponse, error) {
+	values := url.Values{}
+	if lastFillTime != "" {
+		values.Add("lastFillTime", lastFillTime)
+	}
+	resp, err := a.queryPrivate(http.MethodGet, "/derivatives/api/v3/fills", values, &FillsResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*FillsResponse), nil
+}
+
+func (a *API) Accounts() (*AccountsResponse, error) {
+	resp, err := a.queryPrivate(http.MethodGet, "/derivatives/api/v3/accounts", nil, &AccountsResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*AccountsResponse), nil
+}
+
+// HistoricalOrders returns order events of account since timestamp in milliseconds
+func (a *API) HistoricalOrders(since int64) (*HistoricalOrdersResponse, error) {
+	values := url.Values{}
+	if since != 0 {
+		values.Add("since", strconv.FormatInt(since, 10))
+	}
+	resp, err := a.queryPrivate(http.MethodGet, "/api/history/v2/orders", values, &HistoricalOrdersResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*HistoricalOrdersResponse), nil
+}
+
 // ---------------------------------------------------------------------------------- //
 
 func (s SendStatus) ValidateSendStatus() error {
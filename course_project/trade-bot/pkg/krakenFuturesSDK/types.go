@@ -1,5 +1,7 @@
 package krakenFuturesSDK
 
+import "encoding/json"
+
 const SellSide = "sell"
 const BuySide = "buy"
 
@@ -64,6 +66,13 @@ type OrderBookResponse struct {
 	OrderBook OrderBook `json:"orderBook,omitempty"`
 }
 
+// ChartsResponse wraps the Kraken API JSON Charts method
+type ChartsResponse struct {
+	KrakenErrorResponse
+	Candles     []ChartCandle `json:"candles,omitempty"`
+	MoreCandles bool          `json:"more_candles"`
+}
+
 // TickersResponse wraps the Kraken API JSON Tickers method
 type TickersResponse struct {
 	KrakenErrorResponse
@@ -133,6 +142,34 @@ type CancelAllOrdersResponse struct {
 	CancelStatus CancelAllStatus `json:"cancelStatus,omitempty"`
 }
 
+type OpenOrdersResponse struct {
+	KrakenErrorResponse
+	OpenOrders []OpenOrder `json:"openOrders,omitempty"`
+}
+
+type OpenPositionsResponse struct {
+	KrakenErrorResponse
+	OpenPositions []OpenPosition `json:"openPositions,omitempty"`
+}
+
+type FillsResponse struct {
+	KrakenErrorResponse
+	Fills []Fill `json:"fills,omitempty"`
+}
+
+type AccountsResponse struct {
+	KrakenErrorResponse
+	Accounts map[string]Account `json:"accounts,omitempty"`
+}
+
+// HistoricalOrdersResponse wraps the Kraken history API JSON orders method,
+// ContinuationToken is set when there are more events to request
+type HistoricalOrdersResponse struct {
+	KrakenErrorResponse
+	Elements          []HistoricalOrderElement `json:"elements,omitempty"`
+	ContinuationToken string                   `json:"continuationToken,omitempty"`
+}
+
 // --------------------------------------------------------------------------------------- //
 
 type CancelStatus struct {
@@ -237,6 +274,16 @@ type Tier struct {
 	UsdVolume float64 `json:"usdVolume"`
 }
 
+// ChartCandle is ohlc candle of charts, time is in unix milliseconds
+type ChartCandle struct {
+	Time   int64       `json:"time"`
+	Open   string      `json:"open"`
+	High   string      `json:"high"`
+	Low    string      `json:"low"`
+	Close  string      `json:"close"`
+	Volume json.Number `json:"volume"`
+}
+
 type Ticker struct {
 	Tag                   string  `json:"tag,omitempty"`
 	Pair                  string  `json:"pair,omitempty"`
@@ -256,3 +303,85 @@ type Ticker struct {
 	FundingRate           float64 `json:"funding_rate,omitempty"`
 	FundingRatePrediction float64 `json:"funding_rate_prediction,omitempty"`
 }
+
+type OpenOrder struct {
+	OrderID        string  `json:"order_id"`
+	CliOrdID       string  `json:"cliOrdId,omitempty"`
+	Status         string  `json:"status"`
+	Side           string  `json:"side"`
+	OrderType      string  `json:"orderType"`
+	Symbol         string  `json:"symbol"`
+	LimitPrice     float64 `json:"limitPrice,omitempty"`
+	StopPrice      float64 `json:"stopPrice,omitempty"`
+	FilledSize     float64 `json:"filledSize"`
+	UnfilledSize   float64 `json:"unfilledSize"`
+	ReduceOnly     bool    `json:"reduceOnly"`
+	TriggerSignal  string  `json:"triggerSignal,omitempty"`
+	ReceivedTime   string  `json:"receivedTime"`
+	LastUpdateTime string  `json:"lastUpdateTime"`
+}
+
+type OpenPosition struct {
+	Side              string  `json:"side"`
+	Symbol            string  `json:"symbol"`
+	Price             float64 `json:"price"`
+	FillTime          string  `json:"fillTime"`
+	Size              float64 `json:"size"`
+	UnrealizedFunding float64 `json:"unrealizedFunding,omitempty"`
+}
+
+type Fill struct {
+	FillID   string  `json:"fill_id"`
+	Symbol   string  `json:"symbol"`
+	Side     string  `json:"side"`
+	OrderID  string  `json:"order_id"`
+	CliOrdID string  `json:"cliOrdId,omitempty"`
+	Size     float64 `json:"size"`
+	Price    float64 `json:"price"`
+	FillTime string  `json:"fillTime"`
+	FillType string  `json:"fillType"`
+}
+
+type Account struct {
+	Type            string             `json:"type"`
+	Currency        string             `json:"currency,omitempty"`
+	Balances        map[string]float64 `json:"balances,omitempty"`
+	Auxiliary       AccountAuxiliary   `json:"auxiliary,omitempty"`
+	BalanceValue    float64            `json:"balanceValue,omitempty"`
+	PortfolioValue  float64            `json:"portfolioValue,omitempty"`
+	AvailableMargin float64            `json:"availableMargin,omitempty"`
+}
+
+type AccountAuxiliary struct {
+	USD     float64 `json:"usd"`
+	PV      float64 `json:"pv"`
+	PnL     float64 `json:"pnl"`
+	AF      float64 `json:"af"`
+	Funding float64 `json:"funding"`
+}
+
+type HistoricalOrderElement struct {
+	UID       string                          `json:"uid"`
+	Timestamp int64                           `json:"timestamp"`
+	Event     map[string]HistoricalOrderEvent `json:"event"`
+}
+
+// HistoricalOrderEvent is one of OrderPlaced, OrderUpdated, OrderCancelled, OrderRejected or OrderEditRejected events
+type HistoricalOrderEvent struct {
+	Order  HistoricalOrder `json:"order"`
+	Reason string          `json:"reason,omitempty"`
+}
+
+type HistoricalOrder struct {
+	UID                 string  `json:"uid"`
+	ClientID            string  `json:"clientId,omitempty"`
+	Tradeable           string  `json:"tradeable"`
+	Direction           string  `json:"direction"`
+	Quantity            float64 `json:"quantity,string"`
+	Filled              float64 `json:"filled,string"`
+	LimitPrice          float64 `json:"limitPrice,string"`
+	OrderType           string  `json:"orderType"`
+	ReduceOnly          bool    `json:"reduceOnly"`
+	Timestamp           int64   `json:"timestamp"`
+	LastUpdateTimestamp int64   `json:"lastUpdateTimestamp"`
+}
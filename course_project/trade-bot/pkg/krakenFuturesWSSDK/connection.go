@@ -0,0 +1,161 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrDial              = errors.New("dial kraken websocket")
+	ErrUnexpectedStatus  = errors.New("unexpected status of websocket handshake")
+	ErrInfoNotReceived   = errors.New("info event is not received")
+	ErrReconnectCanceled = errors.New("reconnect is canceled")
+)
+
+const (
+	minReconnectDelay    = 500 * time.Millisecond
+	maxReconnectDelay    = 30 * time.Second
+	maxReconnectAttempts = 10
+
+	defaultWriteWait  = 10 * time.Second
+	defaultPongWait   = 60 * time.Second
+	defaultPingPeriod = 10 * time.Second
+
+	connectionEventsBufferSize = 16
+)
+
+// ConnectionState is a state of websocket connection shared by public feeds
+type ConnectionState string
+
+const (
+	StateConnected    ConnectionState = "connected"
+	StateDisconnected ConnectionState = "disconnected"
+	StateReconnecting ConnectionState = "reconnecting"
+	StateReconnected  ConnectionState = "reconnected"
+	StateFailed       ConnectionState = "failed"
+)
+
+// ConnectionEvent is a change of connection state, attempt is a number of reconnect attempt
+type ConnectionEvent struct {
+	State   ConnectionState
+	Attempt int
+	Err     error
+	Time    time.Time
+}
+
+var jitter = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// backoffDelay returns exponential delay before reconnect attempt which starts from zero.
+// Delay is random between half and full of exponential delay, so clients do not reconnect at once
+func backoffDelay(attempt int) time.Duration {
+	delay := maxReconnectDelay
+	if attempt < 16 && minReconnectDelay<<attempt < maxReconnectDelay {
+		delay = minReconnectDelay << attempt
+	}
+
+	jitter.Lock()
+	defer jitter.Unlock()
+	half := delay / 2
+	return half + time.Duration(jitter.Int63n(int64(half)+1))
+}
+
+// sleep waits for delay and returns false when ctx is done earlier
+func sleep(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// dial opens connection and waits for info event which kraken sends to every new connection
+func (a *WSAPI) dial() (*websocket.Conn, error) {
+	conn, resp, err := a.ws.Dial(a.wsAPIURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrDial, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %s", ErrDial, ErrUnexpectedStatus)
+	}
+
+	var initResp map[string]interface{}
+	if err := conn.ReadJSON(&initResp); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %s: %w", ErrDial, ErrUnableToReadMessage, err)
+	}
+	if val, ok := initResp["event"]; !ok || val != "info" {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %s", ErrDial, ErrInfoNotReceived)
+	}
+
+	return conn, nil
+}
+
+// keepAlive pings connection every ping period until done is closed. Connection is lost
+// when neither message nor pong is read during pong wait
+func (a *WSAPI) keepAlive(conn *websocket.Conn, done <-chan struct{}) {
+	conn.SetReadLimit(int64(a.requestsConfig.MaxMessageSize))
+	_ = conn.SetReadDeadline(time.Now().Add(a.pongWait()))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(a.pongWait()))
+	})
+
+	go func() {
+		ticker := time.NewTicker(a.pingPeriod())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(a.writeWait())); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// readMessage reads the next message and prolongs read deadline of alive connection
+func (a *WSAPI) readMessage(conn *websocket.Conn) ([]byte, error) {
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return message, conn.SetReadDeadline(time.Now().Add(a.pongWait()))
+}
+
+func (a *WSAPI) writeWait() time.Duration {
+	return secondsOrDefault(a.requestsConfig.WriteWaitInSeconds, defaultWriteWait)
+}
+
+func (a *WSAPI) pongWait() time.Duration {
+	return secondsOrDefault(a.requestsConfig.PongWaitInSeconds, defaultPongWait)
+}
+
+func (a *WSAPI) pingPeriod() time.Duration {
+	return secondsOrDefault(a.requestsConfig.PingPeriodInSeconds, defaultPingPeriod)
+}
+
+func secondsOrDefault(seconds int, defaultDuration time.Duration) time.Duration {
+	if seconds <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(seconds) * time.Second
+}
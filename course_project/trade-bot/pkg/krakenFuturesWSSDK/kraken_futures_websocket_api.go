@@ -2,9 +2,11 @@ package krakenFuturesWSSDK
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"fmt"
-	"net/http"
-	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
@@ -22,45 +24,63 @@ var (
 	ErrCouldNotSubscribeToFeed  = errors.New("could not subscribe to feed")
 	ErrConnect                  = errors.New("connect to ws")
 	ErrLoopOverWS               = errors.New("loop over ws")
+	ErrAuthenticate             = errors.New("authenticate websocket")
+	ErrChallengeNotReceived     = errors.New("challenge is not received")
+	ErrInvalidPrivateAPIKey     = errors.New("invalid private api key")
 )
 
 const (
 	maxEstablishConnectCounter = 10
 )
 
+// APIKeys sign subscriptions to private feeds of user
+type APIKeys struct {
+	PublicAPIKey  string
+	PrivateAPIKey string
+}
+
+// subscription is a feed which is subscribed again after reconnect, subscription to private
+// feed is signed with new challenge every time
+type subscription struct {
+	args KrakenSendMessageArguments
+	keys *APIKeys
+}
+
 type WSAPI struct {
 	ws             *websocket.Dialer
 	wsAPIURL       string
 	requestsConfig configs.KrakenWSAPIRequestsConfiguration
+	router         *Router
+	subscriptions  *SubscriptionManager
 }
 
 func NewWSAPI(config configs.KrakenWSConfiguration) *WSAPI {
-	return &WSAPI{
+	api := &WSAPI{
 		ws:             websocket.DefaultDialer,
 		wsAPIURL:       config.Kraken.WSAPIURL,
 		requestsConfig: config.Requests,
+		router:         NewRouter(),
 	}
+	api.subscriptions = NewSubscriptionManager(api)
+	return api
 }
 
 // -------------------------- PUBLIC KRAKEN WEBSOCKET API ENDPOINTS -------------------------- //
 
 func (a *WSAPI) Heartbeat(ctx context.Context) (<-chan *HeartbeatSubscriptionData, error) {
 	heartbeatCh := make(chan *HeartbeatSubscriptionData)
-	hearbeatArgs := KrakenSendMessageArguments{
-		Event: "subscribe",
-		Feed:  "heartbeat",
-	}
 
-	dataCh, errCh, err := a.serveWS(ctx, hearbeatArgs, &HeartbeatSubscriptionData{})
+	dataCh, err := a.subscribePublic(ctx, HeartbeatFeed, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	go logErrors(errCh)
 	go func() {
 		defer close(heartbeatCh)
 		for val := range dataCh {
-			heartbeatCh <- val.(*HeartbeatSubscriptionData)
+			if data, ok := val.(*HeartbeatSubscriptionData); ok {
+				heartbeatCh <- data
+			}
 		}
 	}()
 
@@ -69,68 +89,325 @@ func (a *WSAPI) Heartbeat(ctx context.Context) (<-chan *HeartbeatSubscriptionDat
 
 func (a *WSAPI) CandlesTrade(ctx context.Context, feed string, productIDs []string) (<-chan *CandlesTradeData, error) {
 	candlesTradeCh := make(chan *CandlesTradeData)
-	candlesArgs := KrakenSendMessageArguments{
-		Event:      "subscribe",
-		Feed:       feed,
-		ProductIDs: productIDs,
-	}
 
-	dataCh, errCh, err := a.serveWS(ctx, candlesArgs, &CandlesTradeData{})
+	dataCh, err := a.subscribePublic(ctx, feed, productIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	go logErrors(errCh)
 	go func() {
 		defer close(candlesTradeCh)
 		for val := range dataCh {
-			candlesTradeCh <- val.(*CandlesTradeData)
+			if data, ok := val.(*CandlesTradeData); ok {
+				candlesTradeCh <- data
+			}
 		}
 	}()
 
 	return candlesTradeCh, nil
 }
 
+// Ticker streams tickers of products
+func (a *WSAPI) Ticker(ctx context.Context, productIDs []string) (<-chan *TickerData, error) {
+	tickerCh := make(chan *TickerData)
+
+	dataCh, err := a.subscribePublic(ctx, TickerFeed, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(tickerCh)
+		for val := range dataCh {
+			if data, ok := val.(*TickerData); ok {
+				tickerCh <- data
+			}
+		}
+	}()
+
+	return tickerCh, nil
+}
+
+// TickerLite streams tickers of products without funding and open interest, which are sent less often
+func (a *WSAPI) TickerLite(ctx context.Context, productIDs []string) (<-chan *TickerLiteData, error) {
+	tickerLiteCh := make(chan *TickerLiteData)
+
+	dataCh, err := a.subscribePublic(ctx, TickerLiteFeed, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(tickerLiteCh)
+		for val := range dataCh {
+			if data, ok := val.(*TickerLiteData); ok {
+				tickerLiteCh <- data
+			}
+		}
+	}()
+
+	return tickerLiteCh, nil
+}
+
+// Trade streams trades of products made after subscription, snapshot of the last trades is skipped
+func (a *WSAPI) Trade(ctx context.Context, productIDs []string) (<-chan *TradeData, error) {
+	tradeCh := make(chan *TradeData)
+
+	dataCh, err := a.subscribePublic(ctx, TradeFeed, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(tradeCh)
+		for val := range dataCh {
+			if data, ok := val.(*TradeData); ok {
+				tradeCh <- data
+			}
+		}
+	}()
+
+	return tradeCh, nil
+}
+
+// Book streams depth best levels of local order book of product on every change of it. Book is built
+// from snapshot and deltas, the new snapshot is requested when sequence gap of deltas is detected
+func (a *WSAPI) Book(ctx context.Context, productIDs []string, depth int) (<-chan OrderBookDepth, error) {
+	bookCh := make(chan OrderBookDepth)
+
+	dataCh, err := a.subscribePublic(ctx, BookFeed, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(bookCh)
+
+		books := make(map[string]*OrderBook)
+		for val := range dataCh {
+			var book *OrderBook
+			switch data := val.(type) {
+			case *BookSnapshotData:
+				book = NewOrderBook(data)
+				books[data.ProductID] = book
+			case *BookData:
+				// deltas are skipped until snapshot of product is received
+				if book = books[data.ProductID]; book == nil {
+					continue
+				}
+				if err := book.Apply(data); err != nil {
+					log.Warn(err)
+					delete(books, data.ProductID)
+					if err := a.subscriptions.resubscribe(BookFeed, data.ProductID); err != nil {
+						log.Warn(err)
+					}
+					continue
+				}
+			default:
+				continue
+			}
+
+			select {
+			case bookCh <- book.Depth(depth):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return bookCh, nil
+}
+
+// ConnectionEvents streams state changes of connection shared by public feeds until ctx is done
+func (a *WSAPI) ConnectionEvents(ctx context.Context) <-chan ConnectionEvent {
+	return a.subscriptions.ConnectionEvents(ctx)
+}
+
+// ------------------------------------------------------------------------------------------- //
+
+// -------------------------- PRIVATE KRAKEN WEBSOCKET API ENDPOINTS ------------------------- //
+
+// OpenOrders streams snapshot of open orders of user and then updates of every order
+func (a *WSAPI) OpenOrders(ctx context.Context, keys APIKeys) (<-chan *OpenOrdersData, error) {
+	openOrdersCh := make(chan *OpenOrdersData)
+
+	dataCh, err := a.subscribePrivate(ctx, OpenOrdersFeed, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(openOrdersCh)
+		for val := range dataCh {
+			if data, ok := val.(*OpenOrdersData); ok {
+				openOrdersCh <- data
+			}
+		}
+	}()
+
+	return openOrdersCh, nil
+}
+
+// Fills streams snapshot of the last fills of user and then new fills
+func (a *WSAPI) Fills(ctx context.Context, keys APIKeys) (<-chan *FillsData, error) {
+	fillsCh := make(chan *FillsData)
+
+	dataCh, err := a.subscribePrivate(ctx, FillsFeed, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(fillsCh)
+		for val := range dataCh {
+			if data, ok := val.(*FillsData); ok {
+				fillsCh <- data
+			}
+		}
+	}()
+
+	return fillsCh, nil
+}
+
+// OpenPositions streams open positions of user on every change of them
+func (a *WSAPI) OpenPositions(ctx context.Context, keys APIKeys) (<-chan *OpenPositionsData, error) {
+	openPositionsCh := make(chan *OpenPositionsData)
+
+	dataCh, err := a.subscribePrivate(ctx, OpenPositionsFeed, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(openPositionsCh)
+		for val := range dataCh {
+			if data, ok := val.(*OpenPositionsData); ok {
+				openPositionsCh <- data
+			}
+		}
+	}()
+
+	return openPositionsCh, nil
+}
+
+// Balances streams snapshot of balances and margin of user accounts and then their updates
+func (a *WSAPI) Balances(ctx context.Context, keys APIKeys) (<-chan *BalancesData, error) {
+	balancesCh := make(chan *BalancesData)
+
+	dataCh, err := a.subscribePrivate(ctx, BalancesFeed, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(balancesCh)
+		for val := range dataCh {
+			if data, ok := val.(*BalancesData); ok {
+				balancesCh <- data
+			}
+		}
+	}()
+
+	return balancesCh, nil
+}
+
+// NotificationsAuth streams notifications of exchange for user like maintenance or settlement
+func (a *WSAPI) NotificationsAuth(ctx context.Context, keys APIKeys) (<-chan *NotificationsData, error) {
+	notificationsCh := make(chan *NotificationsData)
+
+	dataCh, err := a.subscribePrivate(ctx, NotificationsAuthFeed, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(notificationsCh)
+		for val := range dataCh {
+			if data, ok := val.(*NotificationsData); ok {
+				notificationsCh <- data
+			}
+		}
+	}()
+
+	return notificationsCh, nil
+}
+
 // ------------------------------------------------------------------------------------------- //
 
+// subscribePublic subscribes to public feed through connection shared by all subscribers
+// and decodes every message of feed into its own value
+func (a *WSAPI) subscribePublic(ctx context.Context, feed string, productIDs []string) (<-chan interface{}, error) {
+	messages, err := a.subscriptions.Subscribe(ctx, feed, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrServeWS, err)
+	}
+
+	dataCh := make(chan interface{})
+	go func() {
+		defer close(dataCh)
+		for message := range messages {
+			data, err := a.router.Decode(message)
+			if err != nil {
+				log.Warn(err)
+				continue
+			}
+			select {
+			case dataCh <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return dataCh, nil
+}
+
+// subscribePrivate subscribes to private feed through own connection authenticated with keys of user
+func (a *WSAPI) subscribePrivate(ctx context.Context, feed string, keys APIKeys) (<-chan interface{}, error) {
+	dataCh, errCh, err := a.serveWS(ctx, subscription{
+		args: KrakenSendMessageArguments{Event: "subscribe", Feed: feed},
+		keys: &keys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go logErrors(errCh)
+	return dataCh, nil
+}
+
 func logErrors(errCh <-chan error) {
 	for val := range errCh {
 		log.Warn(val)
 	}
 }
 
-func (a *WSAPI) serveWS(ctx context.Context, args KrakenSendMessageArguments, typ interface{}) (<-chan interface{}, <-chan error, error) {
-	conn, err := a.connect(args)
+func (a *WSAPI) serveWS(ctx context.Context, sub subscription) (<-chan interface{}, <-chan error, error) {
+	conn, err := a.connect(ctx, sub)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%s: %w", ErrServeWS, err)
 	}
 
-	dataCh, errCh := a.loopOverWS(ctx, conn, args, typ)
+	dataCh, errCh := a.loopOverWS(ctx, conn, sub)
 	return dataCh, errCh, nil
 }
 
-func (a *WSAPI) establishConnect() (*websocket.Conn, error) {
-	var initResp map[string]interface{}
-
-	for i := 0; i < maxEstablishConnectCounter; i++ {
-		conn, resp, err := a.ws.Dial(a.wsAPIURL, nil)
-		if err != nil {
-			continue
-		}
-		if resp.StatusCode != http.StatusSwitchingProtocols {
-			continue
+// establishConnect dials kraken websocket until connection is established, attempts are delayed by exponential backoff
+func (a *WSAPI) establishConnect(ctx context.Context) (*websocket.Conn, error) {
+	var err error
+	for attempt := 0; attempt < maxEstablishConnectCounter; attempt++ {
+		if attempt > 0 && !sleep(ctx, backoffDelay(attempt-1)) {
+			return nil, fmt.Errorf("%s: %s", ErrUnableToEstablishConnect, ErrReconnectCanceled)
 		}
 
-		err = conn.ReadJSON(&initResp)
-		if err != nil {
-			continue
-		}
-		if val, ok := initResp["event"]; ok && val == "info" {
+		var conn *websocket.Conn
+		conn, err = a.dial()
+		if err == nil {
 			return conn, nil
 		}
 	}
 
-	return nil, ErrUnableToEstablishConnect
+	return nil, fmt.Errorf("%s: %w", ErrUnableToEstablishConnect, err)
 }
 
 func (a *WSAPI) sendEvent(conn *websocket.Conn, args KrakenSendMessageArguments) (KrakenSendMessageResponse, error) {
@@ -149,53 +426,127 @@ func (a *WSAPI) sendEvent(conn *websocket.Conn, args KrakenSendMessageArguments)
 	return response, nil
 }
 
-func (a *WSAPI) loopOverWS(ctx context.Context, conn *websocket.Conn, args KrakenSendMessageArguments, typ interface{}) (<-chan interface{}, <-chan error) {
+// loopOverWS reads messages of subscription until ctx is done, lost connection is restored
+// and subscribed again
+func (a *WSAPI) loopOverWS(ctx context.Context, conn *websocket.Conn, sub subscription) (<-chan interface{}, <-chan error) {
 	loopChan := make(chan interface{})
 	errChan := make(chan error, 1)
 
-	conn.SetReadLimit(int64(a.requestsConfig.MaxMessageSize))
-	conn.SetPongHandler(func(string) error {
-		return conn.SetReadDeadline(time.Now().Add(time.Second * time.Duration(a.requestsConfig.PongWaitInSeconds)))
-	})
-
-	go func() {
-		<-ctx.Done()
-		conn.Close()
-	}()
-
 	go func() {
 		defer close(loopChan)
 		defer close(errChan)
 
 		for {
-			err := conn.ReadJSON(&typ)
-			if err != nil {
-				if websocket.IsCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					conn, err = a.connect(args)
-					if err != nil {
-						errChan <- fmt.Errorf("%s: %w", ErrLoopOverWS, err)
-						break
-					}
-					continue
+			done := make(chan struct{})
+			a.keepAlive(conn, done)
+			go func(conn *websocket.Conn) {
+				select {
+				case <-ctx.Done():
+					conn.Close()
+				case <-done:
 				}
-				break
+			}(conn)
+
+			a.readSubscription(ctx, conn, sub, loopChan)
+			close(done)
+			conn.Close()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			var err error
+			conn, err = a.connect(ctx, sub)
+			if err != nil {
+				errChan <- fmt.Errorf("%s: %w", ErrLoopOverWS, err)
+				return
 			}
-			loopChan <- typ
 		}
 	}()
 
 	return loopChan, errChan
 }
 
-func (a *WSAPI) connect(args KrakenSendMessageArguments) (*websocket.Conn, error) {
-	conn, err := a.establishConnect()
+// readSubscription sends every decoded message of connection to dataCh until connection is lost or ctx is done
+func (a *WSAPI) readSubscription(ctx context.Context, conn *websocket.Conn, sub subscription, dataCh chan<- interface{}) {
+	for {
+		message, err := a.readMessage(conn)
+		if err != nil {
+			return
+		}
+
+		data, err := a.router.Decode(message)
+		if err != nil {
+			log.Warn(err)
+			continue
+		}
+		if errMessage, ok := data.(*ErrorMessage); ok {
+			log.Warnf("kraken websocket %s: %s", errMessage.Event, errMessage.Message)
+			continue
+		}
+
+		select {
+		case dataCh <- data:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *WSAPI) connect(ctx context.Context, sub subscription) (*websocket.Conn, error) {
+	conn, err := a.establishConnect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ErrConnect, err)
 	}
 
+	args := sub.args
+	if sub.keys != nil {
+		args.APIKey = sub.keys.PublicAPIKey
+		args.OriginalChallenge, args.SignedChallenge, err = a.authenticate(conn, *sub.keys)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", ErrConnect, err)
+		}
+	}
+
 	if _, err := a.sendEvent(conn, args); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("%s: %w", ErrConnect, err)
 	}
 
 	return conn, nil
 }
+
+// authenticate requests challenge for public api key and signs it with private api key
+func (a *WSAPI) authenticate(conn *websocket.Conn, keys APIKeys) (string, string, error) {
+	if err := conn.WriteJSON(KrakenChallengeArguments{Event: "challenge", APIKey: keys.PublicAPIKey}); err != nil {
+		return "", "", fmt.Errorf("%s: %s: %w", ErrAuthenticate, ErrUnableToWriteMessage, err)
+	}
+
+	var response KrakenChallengeResponse
+	if err := conn.ReadJSON(&response); err != nil {
+		return "", "", fmt.Errorf("%s: %s: %w", ErrAuthenticate, ErrUnableToReadMessage, err)
+	}
+	if response.Event != "challenge" || response.Message == "" {
+		return "", "", fmt.Errorf("%s: %s: %s", ErrAuthenticate, ErrChallengeNotReceived, response.Message)
+	}
+
+	signed, err := signChallenge(response.Message, keys.PrivateAPIKey)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", ErrAuthenticate, err)
+	}
+	return response.Message, signed, nil
+}
+
+// signChallenge hashes challenge with sha256 and signs hash by hmac-sha512 with base64 decoded private api key
+func signChallenge(challenge, privateAPIKey string) (string, error) {
+	secret, err := base64.StdEncoding.DecodeString(privateAPIKey)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ErrInvalidPrivateAPIKey, err)
+	}
+
+	sha := sha256.Sum256([]byte(challenge))
+	mac := hmac.New(sha512.New, secret)
+	mac.Write(sha[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
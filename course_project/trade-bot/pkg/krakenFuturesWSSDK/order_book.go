@@ -0,0 +1,113 @@
+package krakenFuturesWSSDK
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrBookSequenceGap = errors.New("book sequence gap")
+	ErrBookProduct     = errors.New("delta of another product")
+)
+
+const (
+	bookBuySide  = "buy"
+	bookSellSide = "sell"
+)
+
+// OrderBook is a local order book of product built from snapshot and deltas of book feed.
+// Levels are quantities by prices
+type OrderBook struct {
+	productID string
+	seq       int64
+	timestamp int64
+	bids      map[float64]float64
+	asks      map[float64]float64
+}
+
+// OrderBookDepth is a copy of the best levels of order book, bids are sorted from the highest price
+// and asks from the lowest one
+type OrderBookDepth struct {
+	ProductID string      `json:"product_id"`
+	Seq       int64       `json:"seq"`
+	Timestamp int64       `json:"timestamp"`
+	Bids      []BookLevel `json:"bids"`
+	Asks      []BookLevel `json:"asks"`
+}
+
+func NewOrderBook(snapshot *BookSnapshotData) *OrderBook {
+	book := &OrderBook{
+		productID: snapshot.ProductID,
+		seq:       snapshot.Seq,
+		timestamp: snapshot.Timestamp,
+		bids:      make(map[float64]float64, len(snapshot.Bids)),
+		asks:      make(map[float64]float64, len(snapshot.Asks)),
+	}
+	for _, level := range snapshot.Bids {
+		setLevel(book.bids, level.Price, level.Qty)
+	}
+	for _, level := range snapshot.Asks {
+		setLevel(book.asks, level.Price, level.Qty)
+	}
+	return book
+}
+
+// Apply applies delta which must follow the last applied sequence number, older deltas are skipped.
+// Book must be built from new snapshot after ErrBookSequenceGap
+func (b *OrderBook) Apply(delta *BookData) error {
+	if delta.ProductID != b.productID {
+		return fmt.Errorf("%s: %s", ErrBookProduct, delta.ProductID)
+	}
+	if delta.Seq <= b.seq {
+		return nil
+	}
+	if delta.Seq != b.seq+1 {
+		return fmt.Errorf("%s: %s: expected %d, got %d", ErrBookSequenceGap, b.productID, b.seq+1, delta.Seq)
+	}
+
+	switch delta.Side {
+	case bookBuySide:
+		setLevel(b.bids, delta.Price, delta.Qty)
+	case bookSellSide:
+		setLevel(b.asks, delta.Price, delta.Qty)
+	}
+	b.seq = delta.Seq
+	b.timestamp = delta.Timestamp
+	return nil
+}
+
+// Depth returns copy of depth best levels of both sides, all levels are returned for not positive depth
+func (b *OrderBook) Depth(depth int) OrderBookDepth {
+	return OrderBookDepth{
+		ProductID: b.productID,
+		Seq:       b.seq,
+		Timestamp: b.timestamp,
+		Bids:      sortedLevels(b.bids, depth, func(a, b float64) bool { return a > b }),
+		Asks:      sortedLevels(b.asks, depth, func(a, b float64) bool { return a < b }),
+	}
+}
+
+func setLevel(levels map[float64]float64, price, qty float64) {
+	if qty == 0 {
+		delete(levels, price)
+		return
+	}
+	levels[price] = qty
+}
+
+func sortedLevels(levels map[float64]float64, depth int, better func(a, b float64) bool) []BookLevel {
+	sorted := make([]BookLevel, 0, len(levels))
+	for price, qty := range levels {
+		sorted = append(sorted, BookLevel{Price: price, Qty: qty})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return better(sorted[i].Price, sorted[j].Price)
+	})
+
+	if depth > 0 && len(sorted) > depth {
+		sorted = sorted[:depth]
+	}
+	return sorted
+}
@@ -0,0 +1,131 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+)
+
+func TestOrderBook_Apply(t *testing.T) {
+	snapshot := &BookSnapshotData{
+		ProductID: "PI_XBTUSD",
+		Seq:       10,
+		Timestamp: 1000,
+		Bids:      []BookLevel{{Price: 99, Qty: 5}, {Price: 100, Qty: 1}, {Price: 98, Qty: 2}},
+		Asks:      []BookLevel{{Price: 102, Qty: 3}, {Price: 101, Qty: 4}},
+	}
+
+	tests := []struct {
+		name    string
+		deltas  []BookData
+		depth   int
+		want    OrderBookDepth
+		wantErr error
+	}{
+		{
+			name:  "Snapshot",
+			depth: 2,
+			want: OrderBookDepth{
+				ProductID: "PI_XBTUSD", Seq: 10, Timestamp: 1000,
+				Bids: []BookLevel{{Price: 100, Qty: 1}, {Price: 99, Qty: 5}},
+				Asks: []BookLevel{{Price: 101, Qty: 4}, {Price: 102, Qty: 3}},
+			},
+		},
+		{
+			name: "Deltas change, add and remove levels",
+			deltas: []BookData{
+				{ProductID: "PI_XBTUSD", Side: "buy", Seq: 11, Price: 100, Qty: 0, Timestamp: 1001},
+				{ProductID: "PI_XBTUSD", Side: "sell", Seq: 12, Price: 100.5, Qty: 7, Timestamp: 1002},
+				{ProductID: "PI_XBTUSD", Side: "buy", Seq: 13, Price: 98, Qty: 6, Timestamp: 1003},
+			},
+			want: OrderBookDepth{
+				ProductID: "PI_XBTUSD", Seq: 13, Timestamp: 1003,
+				Bids: []BookLevel{{Price: 99, Qty: 5}, {Price: 98, Qty: 6}},
+				Asks: []BookLevel{{Price: 100.5, Qty: 7}, {Price: 101, Qty: 4}, {Price: 102, Qty: 3}},
+			},
+		},
+		{
+			name: "Stale delta is skipped",
+			deltas: []BookData{
+				{ProductID: "PI_XBTUSD", Side: "buy", Seq: 9, Price: 100, Qty: 0, Timestamp: 999},
+			},
+			depth: 1,
+			want: OrderBookDepth{
+				ProductID: "PI_XBTUSD", Seq: 10, Timestamp: 1000,
+				Bids: []BookLevel{{Price: 100, Qty: 1}},
+				Asks: []BookLevel{{Price: 101, Qty: 4}},
+			},
+		},
+		{
+			name: "Sequence gap",
+			deltas: []BookData{
+				{ProductID: "PI_XBTUSD", Side: "buy", Seq: 12, Price: 100, Qty: 0, Timestamp: 1002},
+			},
+			wantErr: ErrBookSequenceGap,
+		},
+		{
+			name: "Another product",
+			deltas: []BookData{
+				{ProductID: "PI_ETHUSD", Side: "buy", Seq: 11, Price: 100, Qty: 0, Timestamp: 1001},
+			},
+			wantErr: ErrBookProduct,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			book := NewOrderBook(snapshot)
+
+			var err error
+			for i := range tc.deltas {
+				if err = book.Apply(&tc.deltas[i]); err != nil {
+					break
+				}
+			}
+
+			if tc.wantErr != nil {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.wantErr.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, book.Depth(tc.depth))
+		})
+	}
+}
+
+func TestWSAPI_BookResubscribesOnSequenceGap(t *testing.T) {
+	server := newReplayServer(1, []string{
+		`{"feed":"book_snapshot","product_id":"PI_XBTUSD","timestamp":1000,"seq":10,"bids":[{"price":100,"qty":1}],"asks":[{"price":101,"qty":2}]}`,
+		`{"feed":"book","product_id":"PI_XBTUSD","side":"buy","seq":11,"price":100,"qty":3,"timestamp":1001}`,
+		`{"feed":"book","product_id":"PI_XBTUSD","side":"buy","seq":13,"price":100,"qty":4,"timestamp":1003}`,
+	})
+	defer server.Close()
+
+	api := NewWSAPI(configs.KrakenWSConfiguration{Kraken: configs.KrakenWSAPIConfiguration{WSAPIURL: server.url()}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	books, err := api.Book(ctx, []string{"PI_XBTUSD"}, 10)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when subscribing", err)
+	}
+
+	assert.Equal(t, []BookLevel{{Price: 100, Qty: 1}}, (<-books).Bids)
+	assert.Equal(t, []BookLevel{{Price: 100, Qty: 3}}, (<-books).Bids)
+
+	productIDs := []string{"PI_XBTUSD"}
+	assert.Eventually(t, func() bool {
+		return len(server.subscribeRequests()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []KrakenSendMessageArguments{
+		{Event: "subscribe", Feed: BookFeed, ProductIDs: productIDs},
+		{Event: "unsubscribe", Feed: BookFeed, ProductIDs: productIDs},
+		{Event: "subscribe", Feed: BookFeed, ProductIDs: productIDs},
+	}, server.allRequests())
+}
@@ -0,0 +1,107 @@
+package krakenFuturesWSSDK
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrDecodeFrame  = errors.New("decode frame")
+	ErrUnknownFeed  = errors.New("unknown feed")
+	ErrUnknownEvent = errors.New("unknown event")
+)
+
+// frameHeader is a part of every frame which defines type of frame and its subscribers
+type frameHeader struct {
+	Event     string `json:"event"`
+	Feed      string `json:"feed"`
+	ProductID string `json:"product_id"`
+	Message   string `json:"message"`
+}
+
+// subscriptionFeed returns feed which frame belongs to, snapshot frames belong to their feeds
+func (h frameHeader) subscriptionFeed() string {
+	return strings.TrimSuffix(h.Feed, snapshotFeedSuffix)
+}
+
+// Router decodes every frame into its own value of type defined by event or feed of frame,
+// so values sent to consumers are never shared or overwritten by the next frame
+type Router struct {
+	events map[string]func() interface{}
+	feeds  map[string]func() interface{}
+}
+
+func NewRouter() *Router {
+	return &Router{
+		events: map[string]func() interface{}{
+			"info":         func() interface{} { return &InfoMessage{} },
+			"error":        func() interface{} { return &ErrorMessage{} },
+			"alert":        func() interface{} { return &ErrorMessage{} },
+			"subscribed":   func() interface{} { return &KrakenSendMessageResponse{} },
+			"unsubscribed": func() interface{} { return &KrakenSendMessageResponse{} },
+			"challenge":    func() interface{} { return &KrakenChallengeResponse{} },
+		},
+		feeds: map[string]func() interface{}{
+			HeartbeatFeed:          func() interface{} { return &HeartbeatSubscriptionData{} },
+			TickerFeed:             func() interface{} { return &TickerData{} },
+			TickerLiteFeed:         func() interface{} { return &TickerLiteData{} },
+			TradeFeed:              func() interface{} { return &TradeData{} },
+			TradeSnapshotFeed:      func() interface{} { return &TradeSnapshotData{} },
+			BookFeed:               func() interface{} { return &BookData{} },
+			BookSnapshotFeed:       func() interface{} { return &BookSnapshotData{} },
+			OpenOrdersFeed:         func() interface{} { return &OpenOrdersData{} },
+			OpenOrdersSnapshotFeed: func() interface{} { return &OpenOrdersData{} },
+			FillsFeed:              func() interface{} { return &FillsData{} },
+			FillsSnapshotFeed:      func() interface{} { return &FillsData{} },
+			OpenPositionsFeed:      func() interface{} { return &OpenPositionsData{} },
+			BalancesFeed:           func() interface{} { return &BalancesData{} },
+			BalancesSnapshotFeed:   func() interface{} { return &BalancesData{} },
+			NotificationsAuthFeed:  func() interface{} { return &NotificationsData{} },
+		},
+	}
+}
+
+// Decode decodes frame into new value, e.g. *CandlesTradeData for candles feed or *ErrorMessage for error event
+func (r *Router) Decode(frame []byte) (interface{}, error) {
+	var header frameHeader
+	if err := json.Unmarshal(frame, &header); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrDecodeFrame, err)
+	}
+
+	newValue, err := r.lookup(header)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrDecodeFrame, err)
+	}
+
+	value := newValue()
+	if err := json.Unmarshal(frame, value); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrDecodeFrame, err)
+	}
+	return value, nil
+}
+
+func (r *Router) lookup(header frameHeader) (func() interface{}, error) {
+	if header.Event != "" {
+		newValue, ok := r.events[header.Event]
+		if !ok {
+			return nil, fmt.Errorf("%s: %s", ErrUnknownEvent, header.Event)
+		}
+		return newValue, nil
+	}
+
+	if strings.HasPrefix(header.Feed, candlesTradeFeedPrefix) {
+		if strings.HasSuffix(header.Feed, snapshotFeedSuffix) {
+			return func() interface{} { return &CandlesTradeSnapshotData{} }, nil
+		}
+		return func() interface{} { return &CandlesTradeData{} }, nil
+	}
+
+	newValue, ok := r.feeds[header.Feed]
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", ErrUnknownFeed, header.Feed)
+	}
+	return newValue, nil
+}
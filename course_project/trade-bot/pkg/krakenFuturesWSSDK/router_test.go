@@ -0,0 +1,244 @@
+package krakenFuturesWSSDK
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// replayServer is a local kraken websocket which sends info event to every connection
+// and replays captured frames after replayAfter subscribe requests of connection
+type replayServer struct {
+	*httptest.Server
+	frames      []string
+	replayAfter int
+
+	mu       sync.Mutex
+	requests []KrakenSendMessageArguments
+}
+
+func newReplayServer(replayAfter int, frames []string) *replayServer {
+	s := &replayServer{frames: frames, replayAfter: replayAfter}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *replayServer) serve(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"info","version":1}`)); err != nil {
+		return
+	}
+
+	var subscribes int
+	for {
+		var args KrakenSendMessageArguments
+		if err := conn.ReadJSON(&args); err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, args)
+		s.mu.Unlock()
+
+		if args.Event != "subscribe" {
+			continue
+		}
+		if subscribes++; subscribes != s.replayAfter {
+			continue
+		}
+		for _, frame := range s.frames {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *replayServer) url() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+func (s *replayServer) allRequests() []KrakenSendMessageArguments {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]KrakenSendMessageArguments(nil), s.requests...)
+}
+
+func (s *replayServer) subscribeRequests() []KrakenSendMessageArguments {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []KrakenSendMessageArguments
+	for _, args := range s.requests {
+		if args.Event == "subscribe" {
+			requests = append(requests, args)
+		}
+	}
+	return requests
+}
+
+func TestRouter_Decode(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame string
+		want  interface{}
+	}{
+		{
+			name:  "Subscribed",
+			frame: `{"event":"subscribed","feed":"ticker","product_ids":["PI_XBTUSD"]}`,
+			want:  &KrakenSendMessageResponse{Event: "subscribed", Feed: "ticker", ProductIDs: []string{"PI_XBTUSD"}},
+		},
+		{
+			name:  "Error",
+			frame: `{"event":"error","message":"Invalid product id"}`,
+			want:  &ErrorMessage{Event: "error", Message: "Invalid product id"},
+		},
+		{
+			name:  "Heartbeat",
+			frame: `{"feed":"heartbeat","time":1534262350627}`,
+			want:  &HeartbeatSubscriptionData{Feed: "heartbeat", Time: 1534262350627},
+		},
+		{
+			name:  "Candles snapshot",
+			frame: `{"feed":"candles_trade_1m_snapshot","product_id":"PI_XBTUSD","candles":[{"time":1680623940000,"open":"28050.0","high":"28100.5","low":"28040.0","close":"28090.0","volume":1200}]}`,
+			want: &CandlesTradeSnapshotData{
+				Feed:      "candles_trade_1m_snapshot",
+				ProductID: "PI_XBTUSD",
+				Candles:   []Candle{{Time: 1680623940000, Open: "28050.0", High: "28100.5", Low: "28040.0", Close: "28090.0", Volume: 1200}},
+			},
+		},
+		{
+			name:  "Candles",
+			frame: `{"feed":"candles_trade_1m","candle":{"time":1680624000000,"open":"28050.0","high":"28150.0","low":"27983.0","close":"28126.0","volume":1089},"product_id":"PI_XBTUSD"}`,
+			want: &CandlesTradeData{
+				Feed:      "candles_trade_1m",
+				ProductID: "PI_XBTUSD",
+				Candle:    Candle{Time: 1680624000000, Open: "28050.0", High: "28150.0", Low: "27983.0", Close: "28126.0", Volume: 1089},
+			},
+		},
+		{
+			name:  "Ticker",
+			frame: `{"time":1612270825253,"feed":"ticker","product_id":"PI_XBTUSD","bid":34832.5,"ask":34847.5,"bid_size":42864,"ask_size":2300,"volume":262306237,"dtm":0,"leverage":"50x","index":34803.45,"premium":0.1,"last":34852,"change":2.99,"funding_rate":3.891007752e-9,"suspended":false,"tag":"perpetual","pair":"XBT:USD","openInterest":107706940,"markPrice":34844.25,"maturityTime":0,"next_funding_rate_time":1612281600000}`,
+			want: &TickerData{
+				Time: 1612270825253, Feed: "ticker", ProductID: "PI_XBTUSD", Bid: 34832.5, Ask: 34847.5,
+				BidSize: 42864, AskSize: 2300, Volume: 262306237, Leverage: "50x", Index: 34803.45, Premium: 0.1,
+				Last: 34852, Change: 2.99, FundingRate: 3.891007752e-9, Tag: "perpetual", Pair: "XBT:USD",
+				OpenInterest: 107706940, MarkPrice: 34844.25, NextFundingRateTime: 1612281600000,
+			},
+		},
+		{
+			name:  "Ticker lite",
+			frame: `{"feed":"ticker_lite","product_id":"PI_XBTUSD","bid":34932,"ask":34949.5,"change":3.37,"premium":0.1,"volume":264126741,"tag":"perpetual","pair":"XBT:USD","dtm":0,"maturityTime":0,"volumeQuote":264126741}`,
+			want: &TickerLiteData{
+				Feed: "ticker_lite", ProductID: "PI_XBTUSD", Bid: 34932, Ask: 34949.5, Change: 3.37, Premium: 0.1,
+				Volume: 264126741, Tag: "perpetual", Pair: "XBT:USD", VolumeQuote: 264126741,
+			},
+		},
+		{
+			name:  "Trade snapshot",
+			frame: `{"feed":"trade_snapshot","product_id":"PI_XBTUSD","trades":[{"feed":"trade","product_id":"PI_XBTUSD","uid":"caa9c653-420b-4c24-a9f1-462a054d86f1","side":"sell","type":"fill","seq":655508,"time":1612269657781,"qty":440,"price":34893}]}`,
+			want: &TradeSnapshotData{
+				Feed:      "trade_snapshot",
+				ProductID: "PI_XBTUSD",
+				Trades: []TradeData{{
+					Feed: "trade", ProductID: "PI_XBTUSD", UID: "caa9c653-420b-4c24-a9f1-462a054d86f1",
+					Side: "sell", Type: "fill", Seq: 655508, Time: 1612269657781, Qty: 440, Price: 34893,
+				}},
+			},
+		},
+		{
+			name:  "Trade",
+			frame: `{"feed":"trade","product_id":"PI_XBTUSD","uid":"05af78ac-a774-478c-a50c-8b9c234e071e","side":"sell","type":"fill","seq":653355,"time":1612266317519,"qty":15000,"price":34969.5}`,
+			want: &TradeData{
+				Feed: "trade", ProductID: "PI_XBTUSD", UID: "05af78ac-a774-478c-a50c-8b9c234e071e",
+				Side: "sell", Type: "fill", Seq: 653355, Time: 1612266317519, Qty: 15000, Price: 34969.5,
+			},
+		},
+		{
+			name:  "Book snapshot",
+			frame: `{"feed":"book_snapshot","product_id":"PI_XBTUSD","timestamp":1612269825817,"seq":326072249,"tickSize":null,"bids":[{"price":34892.5,"qty":6385},{"price":34892,"qty":10924}],"asks":[{"price":34911.5,"qty":20598}]}`,
+			want: &BookSnapshotData{
+				Feed: "book_snapshot", ProductID: "PI_XBTUSD", Timestamp: 1612269825817, Seq: 326072249,
+				Bids: []BookLevel{{Price: 34892.5, Qty: 6385}, {Price: 34892, Qty: 10924}},
+				Asks: []BookLevel{{Price: 34911.5, Qty: 20598}},
+			},
+		},
+		{
+			name:  "Book",
+			frame: `{"feed":"book","product_id":"PI_XBTUSD","side":"sell","seq":326094134,"price":34981,"qty":0,"timestamp":1612269953629}`,
+			want: &BookData{
+				Feed: "book", ProductID: "PI_XBTUSD", Side: "sell", Seq: 326094134, Price: 34981, Timestamp: 1612269953629,
+			},
+		},
+	}
+
+	frames := make([]string, 0, len(tests))
+	for _, tc := range tests {
+		frames = append(frames, tc.frame)
+	}
+	server := newReplayServer(1, frames)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(server.url(), nil)
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when dialing replay server", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	router := NewRouter()
+
+	_, frame, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when reading info event", err)
+	}
+	info, err := router.Decode(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, &InfoMessage{Event: "info", Version: 1}, info)
+
+	if err := conn.WriteJSON(KrakenSendMessageArguments{Event: "subscribe", Feed: "ticker"}); err != nil {
+		t.Fatalf("an error '%s' was not expected when subscribing", err)
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, frame, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when reading frame", err)
+			}
+
+			got, err := router.Decode(frame)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRouter_DecodeUnknown(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame string
+	}{
+		{name: "Unknown feed", frame: `{"feed":"unknown","product_id":"PI_XBTUSD"}`},
+		{name: "Unknown event", frame: `{"event":"unknown"}`},
+		{name: "Invalid json", frame: `{"feed":`},
+	}
+
+	router := NewRouter()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := router.Decode([]byte(tc.frame))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,443 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrSubscribe   = errors.New("subscribe")
+	ErrUnsubscribe = errors.New("unsubscribe")
+	ErrReconnect   = errors.New("reconnect")
+
+	ErrCouldNotUnmarshalMessage = errors.New("could not unmarshal message")
+)
+
+const (
+	subscriberBufferSize  = 64
+	subscriberSendTimeout = time.Second
+)
+
+// subscriptionKey is a feed of product, product is empty for feeds without products like heartbeat
+type subscriptionKey struct {
+	feed      string
+	productID string
+}
+
+type subscriber struct {
+	messages chan []byte
+	quit     chan struct{}
+	failed   <-chan struct{}
+	// dropped is closed by read loop when subscriber does not read messages during send timeout
+	dropped  chan struct{}
+	dropOnce sync.Once
+}
+
+// SubscriptionManager shares one websocket connection between all subscribers of public feeds.
+// Subscriptions are counted by feed and product: the first subscriber subscribes connection to
+// feed of product, every message is fanned out to all of its subscribers and the last one which
+// leaves unsubscribes. Subscriber which does not read messages during send timeout is dropped and its
+// stream is closed, so it does not stall the others. Connection is closed when nobody is subscribed. Lost connection is restored
+// with exponential backoff and every feed is subscribed again, subscribers are closed when
+// connection can not be restored
+type SubscriptionManager struct {
+	api *WSAPI
+
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	conn        *websocket.Conn
+	connected   bool
+	subscribers map[subscriptionKey]map[*subscriber]struct{}
+	failed      chan struct{}
+
+	eventsMu  sync.Mutex
+	listeners map[chan ConnectionEvent]struct{}
+}
+
+func NewSubscriptionManager(api *WSAPI) *SubscriptionManager {
+	return &SubscriptionManager{
+		api:         api,
+		subscribers: make(map[subscriptionKey]map[*subscriber]struct{}),
+		failed:      make(chan struct{}),
+		listeners:   make(map[chan ConnectionEvent]struct{}),
+	}
+}
+
+// Subscribe subscribes to feed of products and streams raw messages of feed until ctx is done
+func (m *SubscriptionManager) Subscribe(ctx context.Context, feed string, productIDs []string) (<-chan []byte, error) {
+	keys := subscriptionKeys(feed, productIDs)
+	sub := &subscriber{
+		messages: make(chan []byte, subscriberBufferSize),
+		quit:     make(chan struct{}),
+		dropped:  make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	var isConnected bool
+	for m.conn == nil {
+		// other subscribers are not blocked while connection is established
+		m.mu.Unlock()
+		conn, err := m.api.establishConnect(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrSubscribe, err)
+		}
+
+		m.mu.Lock()
+		if m.conn != nil {
+			// other subscriber has connected meanwhile
+			conn.Close()
+			break
+		}
+		m.conn = conn
+		m.connected = true
+		isConnected = true
+		go m.readLoop(conn)
+	}
+	sub.failed = m.failed
+
+	var newProductIDs []string
+	for _, key := range keys {
+		subscribers, ok := m.subscribers[key]
+		if !ok {
+			subscribers = make(map[*subscriber]struct{})
+			m.subscribers[key] = subscribers
+			if key.productID != "" {
+				newProductIDs = append(newProductIDs, key.productID)
+			}
+		}
+		subscribers[sub] = struct{}{}
+	}
+	isNewFeed := len(newProductIDs) > 0 || len(productIDs) == 0 && len(m.subscribers[keys[0]]) == 1
+	// feeds are subscribed by reconnect while connection is restored
+	shouldSubscribe := isNewFeed && m.connected
+	conn := m.conn
+	m.mu.Unlock()
+
+	if isConnected {
+		m.publish(ConnectionEvent{State: StateConnected})
+	}
+	if shouldSubscribe {
+		err := m.write(conn, KrakenSendMessageArguments{Event: "subscribe", Feed: feed, ProductIDs: newProductIDs})
+		if err != nil {
+			m.unsubscribe(sub, feed, keys)
+			return nil, fmt.Errorf("%s: %w", ErrSubscribe, err)
+		}
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		defer m.unsubscribe(sub, feed, keys)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.failed:
+				return
+			case <-sub.dropped:
+				return
+			case message := <-sub.messages:
+				select {
+				case out <- message:
+				case <-ctx.Done():
+					return
+				case <-sub.dropped:
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// unsubscribe removes subscriber and unsubscribes connection from products which have no subscribers
+func (m *SubscriptionManager) unsubscribe(sub *subscriber, feed string, keys []subscriptionKey) {
+	m.mu.Lock()
+	close(sub.quit)
+
+	var leftProductIDs []string
+	var isFeedLeft bool
+	for _, key := range keys {
+		subscribers, ok := m.subscribers[key]
+		if !ok {
+			continue
+		}
+		delete(subscribers, sub)
+		if len(subscribers) == 0 {
+			delete(m.subscribers, key)
+			isFeedLeft = true
+			if key.productID != "" {
+				leftProductIDs = append(leftProductIDs, key.productID)
+			}
+		}
+	}
+
+	conn, connected := m.conn, m.connected
+	if conn != nil && len(m.subscribers) == 0 {
+		// nobody is subscribed, read loop stops when connection is closed
+		m.conn = nil
+		m.mu.Unlock()
+		conn.Close()
+		return
+	}
+	m.mu.Unlock()
+
+	if !connected || !isFeedLeft {
+		return
+	}
+	err := m.write(conn, KrakenSendMessageArguments{Event: "unsubscribe", Feed: feed, ProductIDs: leftProductIDs})
+	if err != nil {
+		log.Warnf("%s: %s", ErrUnsubscribe, err)
+	}
+}
+
+// resubscribe subscribes connection to feed of product again, so kraken sends the new snapshot
+// to every subscriber of feed of product. Lost connection subscribes feeds again by itself
+func (m *SubscriptionManager) resubscribe(feed, productID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := subscriptionKey{feed: feed, productID: strings.ToUpper(productID)}
+	if _, ok := m.subscribers[key]; !ok || !m.connected {
+		return nil
+	}
+
+	productIDs := []string{key.productID}
+	if err := m.write(m.conn, KrakenSendMessageArguments{Event: "unsubscribe", Feed: feed, ProductIDs: productIDs}); err != nil {
+		return fmt.Errorf("%s: %w", ErrSubscribe, err)
+	}
+	if err := m.write(m.conn, KrakenSendMessageArguments{Event: "subscribe", Feed: feed, ProductIDs: productIDs}); err != nil {
+		return fmt.Errorf("%s: %w", ErrSubscribe, err)
+	}
+	return nil
+}
+
+func (m *SubscriptionManager) write(conn *websocket.Conn, args KrakenSendMessageArguments) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	if err := conn.WriteJSON(args); err != nil {
+		return fmt.Errorf("%s: %w", ErrUnableToWriteMessage, err)
+	}
+	return nil
+}
+
+func (m *SubscriptionManager) readLoop(conn *websocket.Conn) {
+	for {
+		done := make(chan struct{})
+		m.api.keepAlive(conn, done)
+
+		var err error
+		for {
+			var message []byte
+			message, err = m.api.readMessage(conn)
+			if err != nil {
+				break
+			}
+			m.route(message)
+		}
+		close(done)
+
+		conn = m.reconnect(conn, err)
+		if conn == nil {
+			return
+		}
+	}
+}
+
+// reconnect restores lost connection with exponential backoff and subscribes it to every feed again.
+// Returns nil when connection is closed by manager or can not be restored
+func (m *SubscriptionManager) reconnect(lost *websocket.Conn, cause error) *websocket.Conn {
+	m.mu.Lock()
+	if m.conn != lost {
+		m.mu.Unlock()
+		return nil
+	}
+	m.connected = false
+	m.mu.Unlock()
+
+	lost.Close()
+	m.publish(ConnectionEvent{State: StateDisconnected, Err: cause})
+
+	var err error
+	for attempt := 1; attempt <= maxReconnectAttempts; attempt++ {
+		time.Sleep(backoffDelay(attempt - 1))
+		if !m.isCurrent(lost) {
+			return nil
+		}
+		m.publish(ConnectionEvent{State: StateReconnecting, Attempt: attempt})
+
+		var conn *websocket.Conn
+		conn, err = m.api.dial()
+		if err != nil {
+			continue
+		}
+
+		m.mu.Lock()
+		if m.conn != lost {
+			m.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		m.conn = conn
+		m.connected = true
+		args := m.subscriptionsArgs()
+		m.mu.Unlock()
+
+		for _, arg := range args {
+			// read loop of new connection reconnects again when write fails
+			if err := m.write(conn, arg); err != nil {
+				log.Warnf("%s: %s", ErrReconnect, err)
+				break
+			}
+		}
+		m.publish(ConnectionEvent{State: StateReconnected, Attempt: attempt})
+		return conn
+	}
+
+	m.mu.Lock()
+	if m.conn == lost {
+		close(m.failed)
+		m.failed = make(chan struct{})
+		m.subscribers = make(map[subscriptionKey]map[*subscriber]struct{})
+		m.conn = nil
+	}
+	m.mu.Unlock()
+
+	log.Errorf("%s: %s", ErrReconnect, err)
+	m.publish(ConnectionEvent{State: StateFailed, Attempt: maxReconnectAttempts, Err: err})
+	return nil
+}
+
+func (m *SubscriptionManager) isCurrent(conn *websocket.Conn) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conn == conn
+}
+
+// ConnectionEvents streams changes of connection state until ctx is done. Events are dropped
+// when listener does not read them
+func (m *SubscriptionManager) ConnectionEvents(ctx context.Context) <-chan ConnectionEvent {
+	events := make(chan ConnectionEvent, connectionEventsBufferSize)
+
+	m.eventsMu.Lock()
+	m.listeners[events] = struct{}{}
+	m.eventsMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.eventsMu.Lock()
+		delete(m.listeners, events)
+		close(events)
+		m.eventsMu.Unlock()
+	}()
+
+	return events
+}
+
+func (m *SubscriptionManager) publish(event ConnectionEvent) {
+	event.Time = time.Now()
+
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	for listener := range m.listeners {
+		select {
+		case listener <- event:
+		default:
+		}
+	}
+}
+
+// subscriptionsArgs returns subscribe messages for every subscribed feed. Must be called with locked mutex
+func (m *SubscriptionManager) subscriptionsArgs() []KrakenSendMessageArguments {
+	productsByFeed := make(map[string][]string)
+	for key := range m.subscribers {
+		products := productsByFeed[key.feed]
+		if key.productID != "" {
+			products = append(products, key.productID)
+		}
+		productsByFeed[key.feed] = products
+	}
+
+	args := make([]KrakenSendMessageArguments, 0, len(productsByFeed))
+	for feed, products := range productsByFeed {
+		args = append(args, KrakenSendMessageArguments{Event: "subscribe", Feed: feed, ProductIDs: products})
+	}
+	return args
+}
+
+// route sends message to subscribers of its feed and product, snapshot is sent to subscribers of its feed
+func (m *SubscriptionManager) route(message []byte) {
+	var header frameHeader
+	if err := json.Unmarshal(message, &header); err != nil {
+		log.Warnf("%s: %s", ErrCouldNotUnmarshalMessage, err)
+		return
+	}
+
+	if header.Event != "" {
+		if header.Event == "error" || header.Event == "alert" {
+			log.Warnf("kraken websocket %s: %s", header.Event, header.Message)
+		}
+		return
+	}
+
+	key := subscriptionKey{feed: header.subscriptionFeed(), productID: strings.ToUpper(header.ProductID)}
+	m.mu.Lock()
+	subscribers := make([]*subscriber, 0, len(m.subscribers[key]))
+	for sub := range m.subscribers[key] {
+		subscribers = append(subscribers, sub)
+	}
+	m.mu.Unlock()
+
+	for _, sub := range subscribers {
+		m.send(sub, message, key)
+	}
+}
+
+// send passes message to subscriber. Read loop is shared by all feeds, so subscriber whose buffer
+// stays full during send timeout is dropped instead of stalling the others
+func (m *SubscriptionManager) send(sub *subscriber, message []byte, key subscriptionKey) {
+	select {
+	case sub.messages <- message:
+		return
+	case <-sub.quit:
+		return
+	case <-sub.dropped:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(subscriberSendTimeout)
+	defer timer.Stop()
+
+	select {
+	case sub.messages <- message:
+	case <-sub.quit:
+	case <-timer.C:
+		log.Warnf("kraken websocket: slow subscriber of %s %s is dropped", key.feed, key.productID)
+		sub.dropOnce.Do(func() { close(sub.dropped) })
+	}
+}
+
+func subscriptionKeys(feed string, productIDs []string) []subscriptionKey {
+	if len(productIDs) == 0 {
+		return []subscriptionKey{{feed: feed}}
+	}
+
+	keys := make([]subscriptionKey, 0, len(productIDs))
+	for _, productID := range productIDs {
+		keys = append(keys, subscriptionKey{feed: feed, productID: strings.ToUpper(productID)})
+	}
+	return keys
+}
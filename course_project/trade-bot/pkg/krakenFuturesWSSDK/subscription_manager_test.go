@@ -0,0 +1,136 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+)
+
+func TestWSAPI_CandlesTradeSharedSubscription(t *testing.T) {
+	server := newReplayServer(2, []string{
+		`{"event":"subscribed","feed":"candles_trade_1m","product_ids":["PI_XBTUSD"]}`,
+		`{"feed":"candles_trade_1m_snapshot","product_id":"PI_XBTUSD","candles":[{"time":1680623940000,"open":"1","high":"1","low":"1","close":"1","volume":1}]}`,
+		`{"feed":"candles_trade_1m","candle":{"time":1680624000000,"open":"1","high":"2","low":"1","close":"2","volume":3},"product_id":"PI_XBTUSD"}`,
+		`{"feed":"candles_trade_1m","candle":{"time":1680624060000,"open":"2","high":"3","low":"2","close":"3","volume":4},"product_id":"PI_XBTUSD"}`,
+	})
+	defer server.Close()
+
+	api := NewWSAPI(configs.KrakenWSConfiguration{Kraken: configs.KrakenWSAPIConfiguration{WSAPIURL: server.url()}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, err := api.CandlesTrade(ctx, OneMinuteCandlesFeed, []string{"PI_XBTUSD"})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when subscribing", err)
+	}
+	second, err := api.CandlesTrade(ctx, OneMinuteCandlesFeed, []string{"pi_xbtusd", "PI_ETHUSD"})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when subscribing", err)
+	}
+
+	var firstCandles, secondCandles []*CandlesTradeData
+	for i := 0; i < 2; i++ {
+		firstCandles = append(firstCandles, <-first)
+		secondCandles = append(secondCandles, <-second)
+	}
+
+	for i, wantTime := range []int{1680624000000, 1680624060000} {
+		assert.Equal(t, wantTime, firstCandles[i].Candle.Time)
+		assert.Equal(t, wantTime, secondCandles[i].Candle.Time)
+		assert.NotSame(t, firstCandles[i], secondCandles[i])
+	}
+	assert.Equal(t, []KrakenSendMessageArguments{
+		{Event: "subscribe", Feed: OneMinuteCandlesFeed, ProductIDs: []string{"PI_XBTUSD"}},
+		{Event: "subscribe", Feed: OneMinuteCandlesFeed, ProductIDs: []string{"PI_ETHUSD"}},
+	}, server.subscribeRequests())
+}
+
+// drain reads messages until channel is closed
+func drain(messages <-chan []byte) {
+	for range messages {
+	}
+}
+
+func TestSubscriptionManager_Unsubscribe(t *testing.T) {
+	server := newReplayServer(100, nil)
+	defer server.Close()
+
+	api := NewWSAPI(configs.KrakenWSConfiguration{Kraken: configs.KrakenWSAPIConfiguration{WSAPIURL: server.url()}})
+	m := api.subscriptions
+
+	var cancels []context.CancelFunc
+	var feeds []<-chan []byte
+	for _, productIDs := range [][]string{{"PI_XBTUSD"}, {"pi_xbtusd", "PI_ETHUSD"}, {"PI_ETHUSD"}} {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		messages, err := m.Subscribe(ctx, OneMinuteCandlesFeed, productIDs)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when subscribing", err)
+		}
+		cancels = append(cancels, cancel)
+		feeds = append(feeds, messages)
+	}
+
+	// product is still subscribed by the second subscriber
+	cancels[0]()
+	drain(feeds[0])
+	// the last subscriber of product unsubscribes it
+	cancels[1]()
+	drain(feeds[1])
+
+	assert.Eventually(t, func() bool { return len(server.allRequests()) == 3 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []KrakenSendMessageArguments{
+		{Event: "subscribe", Feed: OneMinuteCandlesFeed, ProductIDs: []string{"PI_XBTUSD"}},
+		{Event: "subscribe", Feed: OneMinuteCandlesFeed, ProductIDs: []string{"PI_ETHUSD"}},
+		{Event: "unsubscribe", Feed: OneMinuteCandlesFeed, ProductIDs: []string{"PI_XBTUSD"}},
+	}, server.allRequests())
+
+	// connection is closed when nobody is subscribed
+	cancels[2]()
+	drain(feeds[2])
+	m.mu.Lock()
+	assert.Nil(t, m.conn)
+	assert.Empty(t, m.subscribers)
+	m.mu.Unlock()
+}
+
+func TestSubscriptionManager_SlowSubscriber(t *testing.T) {
+	frames := make([]string, 2*subscriberBufferSize)
+	for i := range frames {
+		frames[i] = fmt.Sprintf(`{"feed":"candles_trade_1m","candle":{"time":%d,"open":"1","high":"1","low":"1","close":"1","volume":1},"product_id":"PI_XBTUSD"}`, i)
+	}
+	server := newReplayServer(2, frames)
+	defer server.Close()
+
+	api := NewWSAPI(configs.KrakenWSConfiguration{Kraken: configs.KrakenWSAPIConfiguration{WSAPIURL: server.url()}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the first subscriber never reads
+	slow, err := api.subscriptions.Subscribe(ctx, OneMinuteCandlesFeed, []string{"PI_XBTUSD"})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when subscribing", err)
+	}
+	messages, err := api.subscriptions.Subscribe(ctx, OneMinuteCandlesFeed, []string{"PI_XBTUSD", "PI_ETHUSD"})
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when subscribing", err)
+	}
+
+	for i := range frames {
+		select {
+		case message := <-messages:
+			assert.Equal(t, frames[i], string(message))
+		case <-ctx.Done():
+			t.Fatalf("message %d is not received", i)
+		}
+	}
+
+	// stream of dropped subscriber is closed
+	drain(slow)
+	assert.NoError(t, ctx.Err())
+}
@@ -1,13 +1,96 @@
 package krakenFuturesWSSDK
 
-const OneMinuteCandlesFeed = "candles_trade_1m"
+import (
+	"strings"
+	"time"
+)
+
+// candles feeds of trades with different intervals
+const (
+	OneMinuteCandlesFeed      = "candles_trade_1m"
+	FiveMinutesCandlesFeed    = "candles_trade_5m"
+	FifteenMinutesCandlesFeed = "candles_trade_15m"
+	OneHourCandlesFeed        = "candles_trade_1h"
+	FourHoursCandlesFeed      = "candles_trade_4h"
+	OneDayCandlesFeed         = "candles_trade_1d"
+)
+
+const candlesTradeFeedPrefix = "candles_trade_"
+
+// candlesIntervals are intervals of candles feeds by their resolution
+var candlesIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// CandlesFeed returns candles feed of resolution like 5m, feed of one minute candles is returned for empty resolution
+func CandlesFeed(resolution string) (string, bool) {
+	if resolution == "" {
+		return OneMinuteCandlesFeed, true
+	}
+	if _, ok := candlesIntervals[resolution]; !ok {
+		return "", false
+	}
+	return candlesTradeFeedPrefix + resolution, true
+}
+
+// CandlesFeedInterval returns resolution like 1m and interval of candles feed
+func CandlesFeedInterval(feed string) (string, time.Duration, bool) {
+	if !strings.HasPrefix(feed, candlesTradeFeedPrefix) {
+		return "", 0, false
+	}
+
+	resolution := strings.TrimPrefix(feed, candlesTradeFeedPrefix)
+	interval, ok := candlesIntervals[resolution]
+	return resolution, interval, ok
+}
+
+// public feeds, the first message of trade and book feeds is a snapshot which has feed name
+// with _snapshot suffix
+const (
+	HeartbeatFeed     = "heartbeat"
+	TickerFeed        = "ticker"
+	TickerLiteFeed    = "ticker_lite"
+	TradeFeed         = "trade"
+	TradeSnapshotFeed = "trade_snapshot"
+	BookFeed          = "book"
+	BookSnapshotFeed  = "book_snapshot"
+)
+
+const snapshotFeedSuffix = "_snapshot"
+
+// private feeds, the first message of open_orders, fills and balances feeds is a snapshot
+// which has feed name with _snapshot suffix
+const (
+	OpenOrdersFeed         = "open_orders"
+	OpenOrdersSnapshotFeed = "open_orders_snapshot"
+	FillsFeed              = "fills"
+	FillsSnapshotFeed      = "fills_snapshot"
+	OpenPositionsFeed      = "open_positions"
+	BalancesFeed           = "balances"
+	BalancesSnapshotFeed   = "balances_snapshot"
+	NotificationsAuthFeed  = "notifications_auth"
+)
+
+// directions of open order
+const (
+	BuyDirection  = 0
+	SellDirection = 1
+)
 
 // -------------------------- PUBLIC KRAKEN WEBSOCKET API DATA -------------------------- //
 
 type KrakenSendMessageArguments struct {
-	Event      string   `json:"event"`
-	Feed       string   `json:"feed"`
-	ProductIDs []string `json:"product_ids"`
+	Event             string   `json:"event"`
+	Feed              string   `json:"feed"`
+	ProductIDs        []string `json:"product_ids,omitempty"`
+	APIKey            string   `json:"api_key,omitempty"`
+	OriginalChallenge string   `json:"original_challenge,omitempty"`
+	SignedChallenge   string   `json:"signed_challenge,omitempty"`
 }
 
 type KrakenSendMessageResponse struct {
@@ -28,8 +111,247 @@ type CandlesTradeData struct {
 	ProductID string `json:"product_id"`
 }
 
+// CandlesTradeSnapshotData is the first message of candles feed with the last candles of product
+type CandlesTradeSnapshotData struct {
+	Feed      string   `json:"feed"`
+	Candles   []Candle `json:"candles"`
+	ProductID string   `json:"product_id"`
+}
+
+// InfoMessage is sent by kraken to every new connection
+type InfoMessage struct {
+	Event   string `json:"event"`
+	Version int    `json:"version"`
+}
+
+// ErrorMessage is sent by kraken on invalid request, alert events are decoded to it as well
+type ErrorMessage struct {
+	Event   string `json:"event"`
+	Message string `json:"message"`
+}
+
+type TickerData struct {
+	Time                          int64   `json:"time"`
+	Feed                          string  `json:"feed"`
+	ProductID                     string  `json:"product_id"`
+	Bid                           float64 `json:"bid"`
+	Ask                           float64 `json:"ask"`
+	BidSize                       float64 `json:"bid_size"`
+	AskSize                       float64 `json:"ask_size"`
+	Volume                        float64 `json:"volume"`
+	Dtm                           int     `json:"dtm"`
+	Leverage                      string  `json:"leverage"`
+	Index                         float64 `json:"index"`
+	Premium                       float64 `json:"premium"`
+	Last                          float64 `json:"last"`
+	Change                        float64 `json:"change"`
+	FundingRate                   float64 `json:"funding_rate"`
+	FundingRatePrediction         float64 `json:"funding_rate_prediction"`
+	Suspended                     bool    `json:"suspended"`
+	Tag                           string  `json:"tag"`
+	Pair                          string  `json:"pair"`
+	OpenInterest                  float64 `json:"openInterest"`
+	MarkPrice                     float64 `json:"markPrice"`
+	MaturityTime                  int64   `json:"maturityTime"`
+	RelativeFundingRate           float64 `json:"relative_funding_rate"`
+	RelativeFundingRatePrediction float64 `json:"relative_funding_rate_prediction"`
+	NextFundingRateTime           int64   `json:"next_funding_rate_time"`
+}
+
+type TickerLiteData struct {
+	Feed         string  `json:"feed"`
+	ProductID    string  `json:"product_id"`
+	Bid          float64 `json:"bid"`
+	Ask          float64 `json:"ask"`
+	Change       float64 `json:"change"`
+	Premium      float64 `json:"premium"`
+	Volume       float64 `json:"volume"`
+	Tag          string  `json:"tag"`
+	Pair         string  `json:"pair"`
+	Dtm          int     `json:"dtm"`
+	MaturityTime int64   `json:"maturityTime"`
+	VolumeQuote  float64 `json:"volumeQuote"`
+}
+
+// TradeData is a trade of product, type is fill, liquidation, termination or block
+type TradeData struct {
+	Feed      string  `json:"feed"`
+	ProductID string  `json:"product_id"`
+	UID       string  `json:"uid"`
+	Side      string  `json:"side"`
+	Type      string  `json:"type"`
+	Seq       int64   `json:"seq"`
+	Time      int64   `json:"time"`
+	Qty       float64 `json:"qty"`
+	Price     float64 `json:"price"`
+}
+
+// TradeSnapshotData is the first message of trade feed with the last trades of product
+type TradeSnapshotData struct {
+	Feed      string      `json:"feed"`
+	ProductID string      `json:"product_id"`
+	Trades    []TradeData `json:"trades"`
+}
+
+type BookLevel struct {
+	Price float64 `json:"price"`
+	Qty   float64 `json:"qty"`
+}
+
+// BookSnapshotData is the first message of book feed with all levels of order book of product
+type BookSnapshotData struct {
+	Feed      string      `json:"feed"`
+	ProductID string      `json:"product_id"`
+	Timestamp int64       `json:"timestamp"`
+	Seq       int64       `json:"seq"`
+	TickSize  *float64    `json:"tickSize"`
+	Bids      []BookLevel `json:"bids"`
+	Asks      []BookLevel `json:"asks"`
+}
+
+// BookData is a change of one level of order book, level with zero qty is removed
+type BookData struct {
+	Feed      string  `json:"feed"`
+	ProductID string  `json:"product_id"`
+	Side      string  `json:"side"`
+	Seq       int64   `json:"seq"`
+	Price     float64 `json:"price"`
+	Qty       float64 `json:"qty"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+// -------------------------------------------------------------------------------------- //
+
+// -------------------------- PRIVATE KRAKEN WEBSOCKET API DATA -------------------------- //
+
+type KrakenChallengeArguments struct {
+	Event  string `json:"event"`
+	APIKey string `json:"api_key"`
+}
+
+type KrakenChallengeResponse struct {
+	Event   string `json:"event"`
+	Message string `json:"message"`
+}
+
+// OpenOrdersData is a snapshot of open orders or update of one order. Update with IsCancel
+// removes order OrderID from open orders, Reason tells why (full_fill, cancelled_by_user, etc)
+type OpenOrdersData struct {
+	Feed     string      `json:"feed"`
+	Account  string      `json:"account,omitempty"`
+	Orders   []OpenOrder `json:"orders,omitempty"`
+	Order    *OpenOrder  `json:"order,omitempty"`
+	OrderID  string      `json:"order_id,omitempty"`
+	IsCancel bool        `json:"is_cancel"`
+	Reason   string      `json:"reason,omitempty"`
+}
+
+type FillsData struct {
+	Feed    string `json:"feed"`
+	Account string `json:"account,omitempty"`
+	Fills   []Fill `json:"fills,omitempty"`
+}
+
+type OpenPositionsData struct {
+	Feed      string     `json:"feed"`
+	Account   string     `json:"account,omitempty"`
+	Positions []Position `json:"positions,omitempty"`
+}
+
+type BalancesData struct {
+	Feed        string                    `json:"feed"`
+	Account     string                    `json:"account,omitempty"`
+	Seq         int64                     `json:"seq,omitempty"`
+	Timestamp   int64                     `json:"timestamp,omitempty"`
+	Holding     map[string]float64        `json:"holding,omitempty"`
+	Futures     map[string]FuturesBalance `json:"futures,omitempty"`
+	FlexFutures *FlexFuturesBalance       `json:"flex_futures,omitempty"`
+}
+
+type NotificationsData struct {
+	Feed          string         `json:"feed"`
+	Notifications []Notification `json:"notifications,omitempty"`
+}
+
 // -------------------------------------------------------------------------------------- //
 
+// OpenOrder is an order resting in order book, direction is 0 for buy and 1 for sell
+type OpenOrder struct {
+	Instrument     string  `json:"instrument"`
+	Time           int64   `json:"time"`
+	LastUpdateTime int64   `json:"last_update_time"`
+	Qty            float64 `json:"qty"`
+	Filled         float64 `json:"filled"`
+	LimitPrice     float64 `json:"limit_price"`
+	StopPrice      float64 `json:"stop_price,omitempty"`
+	Type           string  `json:"type"`
+	OrderID        string  `json:"order_id"`
+	CliOrdID       string  `json:"cli_ord_id,omitempty"`
+	Direction      int     `json:"direction"`
+	ReduceOnly     bool    `json:"reduce_only"`
+	TriggerSignal  string  `json:"triggerSignal,omitempty"`
+}
+
+type Fill struct {
+	Instrument  string  `json:"instrument"`
+	Time        int64   `json:"time"`
+	Price       float64 `json:"price"`
+	Seq         int64   `json:"seq"`
+	Buy         bool    `json:"buy"`
+	Qty         float64 `json:"qty"`
+	OrderID     string  `json:"order_id"`
+	CliOrdID    string  `json:"cli_ord_id,omitempty"`
+	FillID      string  `json:"fill_id"`
+	FillType    string  `json:"fill_type"`
+	FeePaid     float64 `json:"fee_paid,omitempty"`
+	FeeCurrency string  `json:"fee_currency,omitempty"`
+}
+
+// Position is an open position, balance is negative for short position
+type Position struct {
+	Instrument        string  `json:"instrument"`
+	Balance           float64 `json:"balance"`
+	EntryPrice        float64 `json:"entry_price"`
+	MarkPrice         float64 `json:"mark_price"`
+	IndexPrice        float64 `json:"index_price"`
+	PnL               float64 `json:"pnl"`
+	EffectiveLeverage float64 `json:"effective_leverage,omitempty"`
+	InitialMargin     float64 `json:"initial_margin,omitempty"`
+	MaintenanceMargin float64 `json:"maintenance_margin,omitempty"`
+}
+
+type FuturesBalance struct {
+	Name              string  `json:"name"`
+	Pair              string  `json:"pair"`
+	Unit              string  `json:"unit"`
+	PortfolioValue    float64 `json:"portfolio_value"`
+	Balance           float64 `json:"balance"`
+	MaintenanceMargin float64 `json:"maintenance_margin"`
+	InitialMargin     float64 `json:"initial_margin"`
+	Available         float64 `json:"available"`
+	UnrealizedFunding float64 `json:"unrealized_funding"`
+	PnL               float64 `json:"pnl"`
+}
+
+type FlexFuturesBalance struct {
+	BalanceValue      float64 `json:"balance_value"`
+	PortfolioValue    float64 `json:"portfolio_value"`
+	CollateralValue   float64 `json:"collateral_value"`
+	InitialMargin     float64 `json:"initial_margin"`
+	MaintenanceMargin float64 `json:"maintenance_margin"`
+	PnL               float64 `json:"pnl"`
+	UnrealizedFunding float64 `json:"unrealized_funding"`
+	AvailableMargin   float64 `json:"available_margin"`
+}
+
+type Notification struct {
+	ID            int    `json:"id"`
+	Type          string `json:"type"`
+	Priority      string `json:"priority"`
+	Note          string `json:"note"`
+	EffectiveTime int64  `json:"effective_time"`
+}
+
 type Candle struct {
 	Time   int    `json:"time"`
 	Open   string `json:"open"`
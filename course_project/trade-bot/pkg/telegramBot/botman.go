@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	log "github.com/sirupsen/logrus"
@@ -20,8 +21,17 @@ var (
 	ErrExitFromSignInInput            = errors.New("exited from sign in input")
 	ErrExitFromSendOrderInput         = errors.New("exited from send order input")
 	ErrExitFromStartTradingCommand    = errors.New("exited from start trading input")
+	ErrExitFromCancelSessionInput     = errors.New("exited from cancel session input")
+	ErrExitFromEditOrderInput         = errors.New("exited from edit order input")
+	ErrExitFromCancelOrderInput       = errors.New("exited from cancel order input")
+	ErrExitFromCancelAllOrdersInput   = errors.New("exited from cancel all orders input")
+	ErrExitFromPriceInput             = errors.New("exited from price input")
+	ErrInvalidEditOrderArgument       = errors.New("invalid edit order argument")
 	ErrUnableToReadFromUpdatesChannel = errors.New("unable to read from updates channel")
 	ErrUserAlreadyLoggedIn            = errors.New("user already logged in")
+	ErrInvalidStrategyParameter       = errors.New("invalid strategy parameter")
+	ErrUserNotLoggedIn                = errors.New("user not logged in")
+	ErrSessionExpired                 = errors.New("session expired, sign in again")
 )
 
 const (
@@ -36,17 +46,61 @@ const (
 	startTradingCommand         = "/start_trading"
 	exitFromStartTradingCommand = "/exit_from_start_trading"
 	getUserOrdersCommand        = "/get_user_orders"
+	nextOrdersCommand           = "/next_orders"
+	getStrategiesCommand        = "/strategies"
+	getSessionsCommand          = "/sessions"
+	cancelSessionCommand        = "/cancel_session"
+	exitFromCancelSession       = "/exit_from_cancel_session"
+	editOrderCommand            = "/edit_order"
+	exitFromEditOrderCommand    = "/exit_from_edit_order"
+	cancelOrderCommand          = "/cancel_order"
+	exitFromCancelOrderCommand  = "/exit_from_cancel_order"
+	cancelAllOrdersCommand      = "/cancel_all_orders"
+	exitFromCancelAllOrders     = "/exit_from_cancel_all_orders"
+	priceCommand                = "/price"
+	exitFromPriceCommand        = "/exit_from_price"
+	instrumentsCommand          = "/instruments"
+	pnlCommand                  = "/pnl"
 	logoutCommand               = "/logout"
 )
 
+// ordersPageSize is a count of orders in one message of /get_user_orders
+const ordersPageSize = 10
+
+// accessTokenRefreshMargin is a time before expiration of access token when it is refreshed
+const accessTokenRefreshMargin = 30 * time.Second
+
+const (
+	bracketArgument    = "bracket"
+	allSymbolsArgument = "all"
+	sizeArgument       = "size"
+	limitPriceArgument = "limit_price"
+	stopPriceArgument  = "stop_price"
+)
+
 type BotMan struct {
 	bot              *tgbotapi.BotAPI
 	tradeBotServices *service.Service
-	usersJWT         map[string]string
+	usersJWT         map[string]userTokens
+}
+
+// userTokens are tokens of signed in user, access token expires at expiresAt and is refreshed by refresh token
+type userTokens struct {
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+func newUserTokens(accessToken, refreshToken string, expiresIn int64) userTokens {
+	return userTokens{
+		accessToken:  accessToken,
+		refreshToken: refreshToken,
+		expiresAt:    time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}
 }
 
 func NewBotMan(bot *tgbotapi.BotAPI, tradeBotServices *service.Service) *BotMan {
-	return &BotMan{bot: bot, tradeBotServices: tradeBotServices, usersJWT: map[string]string{}}
+	return &BotMan{bot: bot, tradeBotServices: tradeBotServices, usersJWT: map[string]userTokens{}}
 }
 
 func (b *BotMan) ServeTelegram() {
@@ -106,7 +160,7 @@ func (b *BotMan) ServeTelegram() {
 				message.ReplyToMessageID = update.Message.MessageID
 				b.sendMessage(chatID, message)
 
-				token, err := b.executeSignIn(updates)
+				tokens, err := b.executeSignIn(updates)
 				if err != nil {
 					log.Warn(err)
 					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.SignInErrMessage, err.Error()))
@@ -114,7 +168,7 @@ func (b *BotMan) ServeTelegram() {
 					continue
 				}
 
-				b.usersJWT[update.Message.From.UserName] = token
+				b.usersJWT[update.Message.From.UserName] = tokens
 				successMessage := tgbotapi.NewMessage(chatID, utils.SignInSuccessMessage)
 				b.sendMessage(chatID, successMessage)
 
@@ -171,15 +225,190 @@ func (b *BotMan) ServeTelegram() {
 					continue
 				}
 
-				resp, err := b.executeGetUserOrders(token)
-				if err != nil {
+				if err := b.executeGetUserOrders(chatID, updates, token); err != nil {
 					log.Warn(err)
 					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.GetUserOrdersErrMessage, err.Error()))
 					b.sendMessage(chatID, errMessage)
 					continue
 				}
 
-				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.SendOrderSuccessMessage, resp.String()))
+			case editOrderCommand:
+				token, err := b.userIdentity(update.Message.From.UserName)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.EditOrderErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				message := tgbotapi.NewMessage(chatID, utils.EditOrderMessage)
+				b.sendMessage(chatID, message)
+
+				resp, err := b.executeEditOrder(updates, token)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.EditOrderErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.EditOrderSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case cancelOrderCommand:
+				token, err := b.userIdentity(update.Message.From.UserName)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.CancelOrderErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				message := tgbotapi.NewMessage(chatID, utils.CancelOrderMessage)
+				b.sendMessage(chatID, message)
+
+				resp, err := b.executeCancelOrder(updates, token)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.CancelOrderErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.CancelOrderSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case cancelAllOrdersCommand:
+				token, err := b.userIdentity(update.Message.From.UserName)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.CancelAllOrdersErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				message := tgbotapi.NewMessage(chatID, utils.CancelAllOrdersMessage)
+				b.sendMessage(chatID, message)
+
+				resp, err := b.executeCancelAllOrders(updates, token)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.CancelAllOrdersErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.CancelAllOrdersSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case getStrategiesCommand:
+				token, err := b.userIdentity(update.Message.From.UserName)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.GetStrategiesErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				resp, err := b.tradeBotServices.OrdersManager.GetStrategies(models.GetStrategiesInput{JWTToken: token})
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.GetStrategiesErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.GetStrategiesSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case getSessionsCommand:
+				token, err := b.userIdentity(update.Message.From.UserName)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.GetSessionsErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				resp, err := b.tradeBotServices.OrdersManager.GetSessions(models.GetSessionsInput{JWTToken: token})
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.GetSessionsErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.GetSessionsSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case cancelSessionCommand:
+				token, err := b.userIdentity(update.Message.From.UserName)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.CancelSessionErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				message := tgbotapi.NewMessage(chatID, utils.CancelSessionMessage)
+				b.sendMessage(chatID, message)
+
+				resp, err := b.executeCancelSession(updates, token)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.CancelSessionErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.CancelSessionSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case priceCommand:
+				message := tgbotapi.NewMessage(chatID, utils.PriceMessage)
+				b.sendMessage(chatID, message)
+
+				resp, err := b.executeGetPrice(updates)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.PriceErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.PriceSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case instrumentsCommand:
+				resp, err := b.tradeBotServices.Market.GetInstruments(models.GetInstrumentsInput{})
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.InstrumentsErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.InstrumentsSuccessMessage, resp.String()))
+				b.sendMessage(chatID, successMessage)
+
+			case pnlCommand:
+				token, err := b.userIdentity(update.Message.From.UserName)
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.PnLErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				resp, err := b.tradeBotServices.Reports.GetPnL(models.GetPnLInput{JWTToken: token})
+				if err != nil {
+					log.Warn(err)
+					errMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.PnLErrMessage, err.Error()))
+					b.sendMessage(chatID, errMessage)
+					continue
+				}
+
+				successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.PnLSuccessMessage, resp.String()))
 				b.sendMessage(chatID, successMessage)
 
 			case startTradingCommand:
@@ -212,12 +441,26 @@ func (b *BotMan) ServeTelegram() {
 	}
 }
 
+// userIdentity returns access token of user, it is refreshed when it is about to expire.
+// User is signed out when refresh fails, because refresh token is expired or its session is revoked
 func (b *BotMan) userIdentity(username string) (string, error) {
-	val, ok := b.usersJWT[username]
+	tokens, ok := b.usersJWT[username]
 	if !ok {
-		return "", fmt.Errorf("user not logged in")
+		return "", ErrUserNotLoggedIn
+	}
+	if time.Now().Before(tokens.expiresAt.Add(-accessTokenRefreshMargin)) {
+		return tokens.accessToken, nil
+	}
+
+	resp, err := b.tradeBotServices.Authorization.Refresh(models.RefreshInput{RefreshToken: tokens.refreshToken})
+	if err != nil {
+		delete(b.usersJWT, username)
+		return "", fmt.Errorf("%s: %w", ErrSessionExpired, err)
 	}
-	return val, nil
+
+	tokens = newUserTokens(resp.AccessToken, resp.RefreshToken, resp.ExpiresIn)
+	b.usersJWT[username] = tokens
+	return tokens.accessToken, nil
 }
 
 func (b *BotMan) sendMessage(chatID int64, message tgbotapi.MessageConfig) {
@@ -226,15 +469,39 @@ func (b *BotMan) sendMessage(chatID int64, message tgbotapi.MessageConfig) {
 	}
 }
 
-func (b *BotMan) executeGetUserOrders(token string) (models.GetUserOrdersResponse, error) {
-	input := models.GetUserOrdersInput{JWTToken: token}
+// executeGetUserOrders sends pages of orders of user from the latest ones, the next page is sent
+// on /next_orders command and any other message stops paging
+func (b *BotMan) executeGetUserOrders(chatID int64, updates tgbotapi.UpdatesChannel, token string) error {
+	input := models.GetUserOrdersInput{Limit: ordersPageSize, JWTToken: token}
 
-	resp, err := b.tradeBotServices.GetUserOrders(input)
-	if err != nil {
-		return models.GetUserOrdersResponse{}, err
-	}
+	for {
+		resp, err := b.tradeBotServices.GetUserOrders(input)
+		if err != nil {
+			return err
+		}
 
-	return resp, nil
+		if len(resp.Orders) == 0 {
+			b.sendMessage(chatID, tgbotapi.NewMessage(chatID, utils.GetUserOrdersEmptyMessage))
+			return nil
+		}
+
+		successMessage := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.GetUserOrdersSuccessMessage, resp.String()))
+		b.sendMessage(chatID, successMessage)
+
+		if resp.NextCursor == "" {
+			return nil
+		}
+		b.sendMessage(chatID, tgbotapi.NewMessage(chatID, utils.NextOrdersMessage))
+
+		update, ok := <-updates
+		if !ok {
+			return ErrUnableToReadFromUpdatesChannel
+		}
+		if update.Message == nil || update.Message.Text != nextOrdersCommand {
+			return nil
+		}
+		input.Cursor = resp.NextCursor
+	}
 }
 
 func (b *BotMan) executeStartTrading(chatID int64, updates tgbotapi.UpdatesChannel, token string) error {
@@ -258,7 +525,7 @@ func (b *BotMan) executeStartTrading(chatID int64, updates tgbotapi.UpdatesChann
 
 	go func(chatID int64) {
 		for val := range startTradingResp {
-			message := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\nOrder: %s", utils.StartTradingSuccessMessage, val.String()))
+			message := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\n%s", utils.StartTradingSuccessMessage, val.String()))
 			b.sendMessage(chatID, message)
 		}
 	}(chatID)
@@ -277,7 +544,7 @@ func (b *BotMan) getStartTradingInput(updates tgbotapi.UpdatesChannel) (models.S
 			return models.StartTradingInput{}, ErrExitFromStartTradingCommand
 		default:
 			inputValues := strings.FieldsFunc(update.Message.Text, split)
-			if len(inputValues) != 5 {
+			if len(inputValues) < 4 {
 				return models.StartTradingInput{}, fmt.Errorf("invalid count of arguments")
 			}
 			if inputValues[1] != "buy" && inputValues[1] != "sell" {
@@ -287,27 +554,31 @@ func (b *BotMan) getStartTradingInput(updates tgbotapi.UpdatesChannel) (models.S
 			if err != nil {
 				return models.StartTradingInput{}, fmt.Errorf("invalid start trading Size argument")
 			}
-			stopLoss, err := strconv.ParseFloat(inputValues[3], 64)
-			if err != nil {
-				return models.StartTradingInput{}, fmt.Errorf("invalid start trading Stop loss argument")
+
+			details := models.StartTradingDetails{
+				SendOrderInput: models.SendOrderInput{
+					OrderType: "mkt",
+					Symbol:    inputValues[0],
+					Side:      inputValues[1],
+					Size:      uint(amount),
+				},
 			}
-			takeProfit, err := strconv.ParseFloat(inputValues[4], 64)
-			if err != nil {
-				return models.StartTradingInput{}, fmt.Errorf("invalid start trading Take profit argument")
+
+			if _, err := strconv.ParseFloat(inputValues[3], 64); err == nil {
+				if err := parseStopLossTakeProfitInput(inputValues[3:], &details); err != nil {
+					return models.StartTradingInput{}, err
+				}
+			} else {
+				details.Strategy = inputValues[3]
+				details.Parameters, err = parseStrategyParameters(inputValues[4:])
+				if err != nil {
+					return models.StartTradingInput{}, err
+				}
 			}
 
 			return models.StartTradingInput{
-				Event: "start_trading",
-				TradingDetails: models.StartTradingDetails{
-					SendOrderInput: models.SendOrderInput{
-						OrderType: "mkt",
-						Symbol:    inputValues[0],
-						Side:      inputValues[1],
-						Size:      uint(amount),
-					},
-					StopLossBorder:   uint(stopLoss),
-					TakeProfitBorder: uint(takeProfit),
-				},
+				Event:          "start_trading",
+				TradingDetails: details,
 			}, nil
 		}
 	}
@@ -315,6 +586,185 @@ func (b *BotMan) getStartTradingInput(updates tgbotapi.UpdatesChannel) (models.S
 	return models.StartTradingInput{}, ErrUnableToReadFromUpdatesChannel
 }
 
+// parseStopLossTakeProfitInput parses borders of stop loss & take profit strategy
+// and optional "bracket" flag which places them on exchange
+func parseStopLossTakeProfitInput(inputValues []string, details *models.StartTradingDetails) error {
+	if len(inputValues) != 2 && len(inputValues) != 3 {
+		return fmt.Errorf("invalid count of arguments")
+	}
+	if len(inputValues) == 3 {
+		if inputValues[2] != bracketArgument {
+			return fmt.Errorf("invalid start trading Bracket argument")
+		}
+		details.Bracket = true
+	}
+	stopLoss, err := strconv.ParseFloat(inputValues[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid start trading Stop loss argument")
+	}
+	takeProfit, err := strconv.ParseFloat(inputValues[1], 64)
+	if err != nil {
+		return fmt.Errorf("invalid start trading Take profit argument")
+	}
+
+	details.StopLossBorder = uint(stopLoss)
+	details.TakeProfitBorder = uint(takeProfit)
+	return nil
+}
+
+// parseStrategyParameters parses strategy parameters of format name=value
+func parseStrategyParameters(inputValues []string) (map[string]float64, error) {
+	params := make(map[string]float64, len(inputValues))
+	for _, input := range inputValues {
+		parts := strings.SplitN(input, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s: %s", ErrInvalidStrategyParameter, input)
+		}
+		value, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", ErrInvalidStrategyParameter, input)
+		}
+		params[parts[0]] = value
+	}
+	return params, nil
+}
+
+func (b *BotMan) executeCancelSession(updates tgbotapi.UpdatesChannel, token string) (models.CancelSessionResponse, error) {
+	for update := range updates {
+		if update.Message == nil {
+			return models.CancelSessionResponse{}, nil
+		}
+
+		if update.Message.Text == exitFromCancelSession {
+			return models.CancelSessionResponse{}, ErrExitFromCancelSessionInput
+		}
+
+		sessionID, err := strconv.Atoi(strings.TrimSpace(update.Message.Text))
+		if err != nil {
+			return models.CancelSessionResponse{}, fmt.Errorf("invalid cancel session Session id argument")
+		}
+
+		return b.tradeBotServices.OrdersManager.CancelSession(models.CancelSessionInput{SessionID: sessionID, JWTToken: token})
+	}
+
+	return models.CancelSessionResponse{}, ErrUnableToReadFromUpdatesChannel
+}
+
+func (b *BotMan) executeGetPrice(updates tgbotapi.UpdatesChannel) (models.GetPriceResponse, error) {
+	for update := range updates {
+		if update.Message == nil {
+			return models.GetPriceResponse{}, nil
+		}
+
+		if update.Message.Text == exitFromPriceCommand {
+			return models.GetPriceResponse{}, ErrExitFromPriceInput
+		}
+
+		symbol := strings.TrimSpace(update.Message.Text)
+		return b.tradeBotServices.Market.GetPrice(models.GetPriceInput{Symbol: symbol})
+	}
+
+	return models.GetPriceResponse{}, ErrUnableToReadFromUpdatesChannel
+}
+
+func (b *BotMan) executeEditOrder(updates tgbotapi.UpdatesChannel, token string) (models.EditOrderResponse, error) {
+	for update := range updates {
+		if update.Message == nil {
+			return models.EditOrderResponse{}, nil
+		}
+
+		if update.Message.Text == exitFromEditOrderCommand {
+			return models.EditOrderResponse{}, ErrExitFromEditOrderInput
+		}
+
+		input, err := parseEditOrderInput(strings.FieldsFunc(update.Message.Text, split))
+		if err != nil {
+			return models.EditOrderResponse{}, err
+		}
+		input.JWTToken = token
+
+		return b.tradeBotServices.OrdersManager.EditOrder(input)
+	}
+
+	return models.EditOrderResponse{}, ErrUnableToReadFromUpdatesChannel
+}
+
+// parseEditOrderInput parses order id and its new size or prices of format name=value
+func parseEditOrderInput(inputValues []string) (models.EditOrderInput, error) {
+	if len(inputValues) < 2 {
+		return models.EditOrderInput{}, fmt.Errorf("invalid count of arguments")
+	}
+
+	input := models.EditOrderInput{OrderID: inputValues[0]}
+	for _, value := range inputValues[1:] {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return models.EditOrderInput{}, fmt.Errorf("%s: %s", ErrInvalidEditOrderArgument, value)
+		}
+
+		var err error
+		switch parts[0] {
+		case sizeArgument:
+			var size uint64
+			size, err = strconv.ParseUint(parts[1], 10, 64)
+			input.Size = uint(size)
+		case limitPriceArgument:
+			input.LimitPrice, err = strconv.ParseFloat(parts[1], 64)
+		case stopPriceArgument:
+			input.StopPrice, err = strconv.ParseFloat(parts[1], 64)
+		default:
+			err = fmt.Errorf("unknown argument")
+		}
+		if err != nil {
+			return models.EditOrderInput{}, fmt.Errorf("%s: %s", ErrInvalidEditOrderArgument, value)
+		}
+	}
+
+	return input, nil
+}
+
+func (b *BotMan) executeCancelOrder(updates tgbotapi.UpdatesChannel, token string) (models.CancelOrderResponse, error) {
+	for update := range updates {
+		if update.Message == nil {
+			return models.CancelOrderResponse{}, nil
+		}
+
+		if update.Message.Text == exitFromCancelOrderCommand {
+			return models.CancelOrderResponse{}, ErrExitFromCancelOrderInput
+		}
+
+		orderID := strings.TrimSpace(update.Message.Text)
+		if orderID == "" {
+			return models.CancelOrderResponse{}, fmt.Errorf("invalid cancel order Order id argument")
+		}
+
+		return b.tradeBotServices.OrdersManager.CancelOrder(models.CancelOrderInput{OrderID: orderID, JWTToken: token})
+	}
+
+	return models.CancelOrderResponse{}, ErrUnableToReadFromUpdatesChannel
+}
+
+func (b *BotMan) executeCancelAllOrders(updates tgbotapi.UpdatesChannel, token string) (models.CancelAllOrdersResponse, error) {
+	for update := range updates {
+		if update.Message == nil {
+			return models.CancelAllOrdersResponse{}, nil
+		}
+
+		if update.Message.Text == exitFromCancelAllOrders {
+			return models.CancelAllOrdersResponse{}, ErrExitFromCancelAllOrdersInput
+		}
+
+		symbol := strings.TrimSpace(update.Message.Text)
+		if symbol == allSymbolsArgument {
+			symbol = ""
+		}
+
+		return b.tradeBotServices.OrdersManager.CancelAllOrders(models.CancelAllOrdersInput{Symbol: symbol, JWTToken: token})
+	}
+
+	return models.CancelAllOrdersResponse{}, ErrUnableToReadFromUpdatesChannel
+}
+
 func (b *BotMan) executeSendOrder(updates tgbotapi.UpdatesChannel, token string) (models.SendOrderResponse, error) {
 	input, err := b.getSendOrderInput(updates)
 	if err != nil {
@@ -363,17 +813,17 @@ func (b *BotMan) getSendOrderInput(updates tgbotapi.UpdatesChannel) (models.Send
 	return models.SendOrderInput{}, ErrUnableToReadFromUpdatesChannel
 }
 
-func (b *BotMan) executeSignIn(updates tgbotapi.UpdatesChannel) (string, error) {
+func (b *BotMan) executeSignIn(updates tgbotapi.UpdatesChannel) (userTokens, error) {
 	input, err := b.getSignInInput(updates)
 	if err != nil {
-		return "", err
+		return userTokens{}, err
 	}
 
 	resp, err := b.tradeBotServices.Authorization.SignIn(input)
 	if err != nil {
-		return "", err
+		return userTokens{}, err
 	}
-	return resp.AccessToken, nil
+	return newUserTokens(resp.AccessToken, resp.RefreshToken, resp.ExpiresIn), nil
 }
 
 func (b *BotMan) getSignInInput(updates tgbotapi.UpdatesChannel) (models.SignInInput, error) {
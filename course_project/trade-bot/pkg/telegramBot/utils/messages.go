@@ -18,6 +18,24 @@ Commands 📟:
 	🔵 /exit_from_sign_in - stop getting input data to login you in the bot
 	🔵 /send_order - allow to send market order with symbol, side and amount arguments to kraken futures
 	🔵 /exit_from_send_order - stop getting input data to send order to kraken futures
+	🔵 /get_user_orders - list your orders from the latest ones, 10 orders per message
+	🔵 /next_orders - show the next orders of /get_user_orders
+	🔵 /edit_order - change size or prices of your resting order
+	🔵 /exit_from_edit_order - stop getting input data to edit order
+	🔵 /cancel_order - cancel your resting order
+	🔵 /exit_from_cancel_order - stop getting input data to cancel order
+	🔵 /cancel_all_orders - cancel your resting orders by symbol or of all symbols
+	🔵 /exit_from_cancel_all_orders - stop getting input data to cancel all orders
+	🔵 /strategies - list trading strategies with their parameters
+	🔵 /start_trading - open position and close it by one of trading strategies
+	🔵 /exit_from_start_trading - stop getting input data to start trading
+	🔵 /sessions - list your trading sessions
+	🔵 /cancel_session - stop trading session and close its position
+	🔵 /exit_from_cancel_session - stop getting input data to cancel trading session
+	🔵 /price - show last, bid, ask and mark prices of contract
+	🔵 /exit_from_price - stop getting input data to show price
+	🔵 /instruments - list tradeable contracts of kraken futures
+	🔵 /pnl - show your realized and unrealized PnL by day, symbol and strategy
 	🔵 /logout - logout you from trading bot system on every telegram device associated with your username
 `
 
@@ -94,18 +112,83 @@ const SendOrderSuccessMessage = `
 ✅ Successfully send order!
 `
 
+const EditOrderMessage = `
+🔳 Enter message in format:
+
+Order id (see /get_user_orders)
+New values in format name=value (size, limit_price or stop_price)
+
+🔳 Example:
+
+c18f0c17-9971-40e6-8e5b-10df05d422f0 size=5 limit_price=40000
+`
+
+const EditOrderErrMessage = `
+⛔ Unable to continue further execution of edit order due to
+`
+
+const EditOrderSuccessMessage = `
+✅ Successfully edited order!
+`
+
+const CancelOrderMessage = `
+🔳 Enter id of order from /get_user_orders
+
+🔳 Example:
+
+c18f0c17-9971-40e6-8e5b-10df05d422f0
+`
+
+const CancelOrderErrMessage = `
+⛔ Unable to continue further execution of cancel order due to
+`
+
+const CancelOrderSuccessMessage = `
+✅ Successfully cancelled order!
+`
+
+const CancelAllOrdersMessage = `
+🔳 Enter symbol of orders or all to cancel orders of every symbol
+
+🔳 Example:
+
+PI_XBTUSD
+`
+
+const CancelAllOrdersErrMessage = `
+⛔ Unable to continue further execution of cancel all orders due to
+`
+
+const CancelAllOrdersSuccessMessage = `
+✅ Successfully cancelled orders!
+`
+
 const StartTradingMessage = `
 🔳 Enter message in format:
 
 Symbol (one of symbols on kraken futures)
 Side   (buy or sell)      
 Size   (integer up to 25000)
-Take profit border (the value of the delta above which the order will be closed 📈)
 Stop loss border (the value of the delta below which the order will be closed 📉)
+Take profit border (the value of the delta above which the order will be closed 📈)
+bracket (optional, place stop loss and take profit orders on kraken, they protect position even if bot is down 🛡)
 
 🔳 Example:
 
 PI_XBTUSD buy 10000 1000 1000
+PI_XBTUSD buy 10000 1000 1000 bracket
+
+🔳 Or choose one of /strategies with its parameters in format name=value:
+
+Symbol
+Side
+Size
+Strategy
+Parameters
+
+🔳 Example:
+
+PI_XBTUSD buy 10000 ema_crossover fast_period=9 slow_period=21
 `
 
 const StartTradingErrMessage = `
@@ -113,13 +196,89 @@ const StartTradingErrMessage = `
 `
 
 const StartTradingWillNotifyMessage = `
-⌛ Bot will notify you when trading will stop
+⌛ Bot will notify you when trading will stop, trading goes on even if connection is lost, see /sessions
 `
 
 const StartTradingSuccessMessage = `
-✅ Order have been successfully traded!
+📊 Trading session:
 `
 
 const GetUserOrdersErrMessage = `
 ⛔ Unable to continue further execution of get user orders due to
 `
+
+const GetUserOrdersSuccessMessage = `
+📋 Your orders:
+`
+
+const GetUserOrdersEmptyMessage = `
+📋 You have no orders yet
+`
+
+const NextOrdersMessage = `
+🔳 Send /next_orders to show the next orders or any other message to stop
+`
+
+const GetStrategiesErrMessage = `
+⛔ Unable to continue further execution of get strategies due to
+`
+
+const GetStrategiesSuccessMessage = `
+📊 Trading strategies:
+`
+
+const GetSessionsErrMessage = `
+⛔ Unable to continue further execution of get trading sessions due to
+`
+
+const GetSessionsSuccessMessage = `
+📊 Trading sessions:
+`
+
+const CancelSessionMessage = `
+🔳 Enter id of trading session from /sessions
+
+🔳 Example:
+
+12
+`
+
+const CancelSessionErrMessage = `
+⛔ Unable to continue further execution of cancel trading session due to
+`
+
+const CancelSessionSuccessMessage = `
+✅ Trading session is cancelled, its position will be closed!
+`
+
+const PriceMessage = `
+🔳 Enter symbol of contract from /instruments
+
+🔳 Example:
+
+PI_XBTUSD
+`
+
+const PriceErrMessage = `
+⛔ Unable to continue further execution of price due to
+`
+
+const PriceSuccessMessage = `
+💹 Price:
+`
+
+const InstrumentsErrMessage = `
+⛔ Unable to continue further execution of instruments due to
+`
+
+const InstrumentsSuccessMessage = `
+📊 Tradeable contracts:
+`
+
+const PnLErrMessage = `
+⛔ Unable to continue further execution of pnl due to
+`
+
+const PnLSuccessMessage = `
+💰 PnL by day, symbol and strategy:
+`
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -14,15 +13,16 @@ import (
 )
 
 const (
-	accessUUIDTokenClaim = "access_UUID"
-	authorizedTokenClaim = "authorized"
-	userIDTokenClaim     = "user_id"
-	expiresTokenClaim    = "exp"
+	accessUUIDTokenClaim  = "access_UUID"
+	refreshUUIDTokenClaim = "refresh_UUID"
+	sessionIDTokenClaim   = "session_ID"
+	authorizedTokenClaim  = "authorized"
+	userIDTokenClaim      = "user_id"
+	expiresTokenClaim     = "exp"
 )
 
 const jwtHeaderAlgo = "alg"
 const authorizationHeader = "Authorization"
-const jwtAccessSigningKey = "JWT_ACCESS_SIGNING_KEY"
 
 var (
 	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
@@ -30,6 +30,8 @@ var (
 	ErrExtractTokenMetadata    = errors.New("extract token metadata")
 	ErrCantAssignToMapClaims   = errors.New("can't assign to map claims")
 	ErrInvalidAccessUUID       = errors.New("invalid access uuid")
+	ErrInvalidRefreshUUID      = errors.New("invalid refresh uuid")
+	ErrInvalidSessionID        = errors.New("invalid session id")
 	ErrInvalidUserID           = errors.New("invalid user id")
 	ErrInvalidToken            = errors.New("invalid token")
 	ErrEmptyAuthHeader         = errors.New("empty auth header")
@@ -37,17 +39,40 @@ var (
 	ErrEmptyBearerToken        = errors.New("empty bearer token")
 )
 
+// TokenDetails is a pair of access and refresh tokens of one session, expiration times are unix seconds
 type TokenDetails struct {
-	AccessToken string
-	AccessUUID  string
-	AtExpires   int64
+	AccessToken  string
+	AccessUUID   string
+	AtExpires    int64
+	RefreshToken string
+	RefreshUUID  string
+	RtExpires    int64
+	SessionID    string
 }
 
 type AccessDetails struct {
 	AccessUUID string
+	SessionID  string
 	UserID     int64
 }
 
+type RefreshDetails struct {
+	RefreshUUID string
+	SessionID   string
+	UserID      int64
+}
+
+// JWTManager signs and verifies tokens with HMAC key
+type JWTManager struct {
+	signingKey []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewJWTManager(signingKey string, accessTTL, refreshTTL time.Duration) *JWTManager {
+	return &JWTManager{signingKey: []byte(signingKey), accessTTL: accessTTL, refreshTTL: refreshTTL}
+}
+
 func GetBearerToken(r *http.Request) (string, error) {
 	authHeader := r.Header.Get(authorizationHeader)
 
@@ -71,38 +96,62 @@ func GetBearerToken(r *http.Request) (string, error) {
 	return headerParts[1], nil
 }
 
-func ExtractTokenMetadata(token string) (AccessDetails, error) {
-	verifiedToken, err := VerifyToken(token)
+func (m *JWTManager) ExtractTokenMetadata(token string) (AccessDetails, error) {
+	claims, err := m.verifiedClaims(token)
 	if err != nil {
 		return AccessDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, err)
 	}
 
-	claims, ok := verifiedToken.Claims.(jwt.MapClaims)
-	if !ok {
-		return AccessDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, ErrCantAssignToMapClaims)
-	}
-
 	accessUUID, ok := claims[accessUUIDTokenClaim].(string)
 	if !ok {
 		return AccessDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, ErrInvalidAccessUUID)
 	}
-	userID, err := strconv.ParseUint(fmt.Sprintf("%.f", claims[userIDTokenClaim]), 10, 64)
+	userID, err := userIDClaim(claims)
 	if err != nil {
-		return AccessDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, ErrInvalidUserID)
+		return AccessDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, err)
 	}
+	// tokens issued before sessions were introduced have no session id
+	sessionID, _ := claims[sessionIDTokenClaim].(string)
 
 	return AccessDetails{
 		AccessUUID: accessUUID,
-		UserID:     int64(userID),
+		SessionID:  sessionID,
+		UserID:     userID,
+	}, nil
+}
+
+func (m *JWTManager) ExtractRefreshTokenMetadata(token string) (RefreshDetails, error) {
+	claims, err := m.verifiedClaims(token)
+	if err != nil {
+		return RefreshDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, err)
+	}
+
+	refreshUUID, ok := claims[refreshUUIDTokenClaim].(string)
+	if !ok {
+		return RefreshDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, ErrInvalidRefreshUUID)
+	}
+	sessionID, ok := claims[sessionIDTokenClaim].(string)
+	if !ok || sessionID == "" {
+		return RefreshDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, ErrInvalidSessionID)
+	}
+	userID, err := userIDClaim(claims)
+	if err != nil {
+		return RefreshDetails{}, fmt.Errorf("%s: %w", ErrExtractTokenMetadata, err)
+	}
+
+	return RefreshDetails{
+		RefreshUUID: refreshUUID,
+		SessionID:   sessionID,
+		UserID:      userID,
 	}, nil
 }
 
-func VerifyToken(token string) (*jwt.Token, error) {
+func (m *JWTManager) VerifyToken(token string) (*jwt.Token, error) {
 	verified, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("%s: %v", ErrUnexpectedSigningMethod, token.Header[jwtHeaderAlgo])
 		}
-		return []byte(os.Getenv(jwtAccessSigningKey)), nil
+		return m.signingKey, nil
 	})
 	if err != nil {
 		return nil, err
@@ -114,10 +163,11 @@ func VerifyToken(token string) (*jwt.Token, error) {
 	return verified, nil
 }
 
-func GenerateJWTToken(userID int, d time.Duration) (TokenDetails, error) {
-	td := TokenDetails{}
+// GenerateTokens issues short-lived access token and refresh token of session
+func (m *JWTManager) GenerateTokens(userID int, sessionID string, now time.Time) (TokenDetails, error) {
+	td := TokenDetails{SessionID: sessionID}
 
-	td.AtExpires = time.Now().Add(d).Unix()
+	td.AtExpires = now.Add(m.accessTTL).Unix()
 	aUUID, err := uuid.NewV4()
 	if err != nil {
 		return TokenDetails{}, err
@@ -127,11 +177,51 @@ func GenerateJWTToken(userID int, d time.Duration) (TokenDetails, error) {
 	atClaims := jwt.MapClaims{}
 	atClaims[authorizedTokenClaim] = true
 	atClaims[accessUUIDTokenClaim] = td.AccessUUID
+	atClaims[sessionIDTokenClaim] = sessionID
 	atClaims[userIDTokenClaim] = userID
 	atClaims[expiresTokenClaim] = td.AtExpires
 
 	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
-	td.AccessToken, err = at.SignedString([]byte(os.Getenv(jwtAccessSigningKey)))
+	if td.AccessToken, err = at.SignedString(m.signingKey); err != nil {
+		return TokenDetails{}, err
+	}
+
+	td.RtExpires = now.Add(m.refreshTTL).Unix()
+	rUUID, err := uuid.NewV4()
+	if err != nil {
+		return TokenDetails{}, err
+	}
+	td.RefreshUUID = rUUID.String()
+
+	rtClaims := jwt.MapClaims{}
+	rtClaims[refreshUUIDTokenClaim] = td.RefreshUUID
+	rtClaims[sessionIDTokenClaim] = sessionID
+	rtClaims[userIDTokenClaim] = userID
+	rtClaims[expiresTokenClaim] = td.RtExpires
+
+	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, rtClaims)
+	td.RefreshToken, err = rt.SignedString(m.signingKey)
 
 	return td, err
 }
+
+func (m *JWTManager) verifiedClaims(token string) (jwt.MapClaims, error) {
+	verifiedToken, err := m.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := verifiedToken.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrCantAssignToMapClaims
+	}
+	return claims, nil
+}
+
+func userIDClaim(claims jwt.MapClaims) (int64, error) {
+	userID, err := strconv.ParseUint(fmt.Sprintf("%.f", claims[userIDTokenClaim]), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidUserID
+	}
+	return int64(userID), nil
+}
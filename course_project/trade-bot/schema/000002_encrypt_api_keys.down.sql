@@ -0,0 +1,15 @@
+-- encrypted api keys can not be read without their data keys, run decrypt-keys command before rollback
+DO
+$$
+    BEGIN
+        IF EXISTS(SELECT 1 FROM users WHERE api_keys_key_version <> 0) THEN
+            RAISE EXCEPTION 'api keys of users are encrypted, run decrypt-keys command before rollback';
+        END IF;
+    END
+$$;
+
+ALTER TABLE users
+    DROP COLUMN api_keys_key_version,
+    DROP COLUMN api_keys_data_key,
+    ALTER COLUMN private_api_key TYPE varchar(255),
+    ALTER COLUMN public_api_key TYPE varchar(255);
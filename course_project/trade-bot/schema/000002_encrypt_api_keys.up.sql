@@ -0,0 +1,5 @@
+ALTER TABLE users
+    ALTER COLUMN public_api_key TYPE text,
+    ALTER COLUMN private_api_key TYPE text,
+    ADD COLUMN api_keys_data_key    text    not null default '',
+    ADD COLUMN api_keys_key_version integer not null default 0;
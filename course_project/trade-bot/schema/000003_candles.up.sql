@@ -0,0 +1,12 @@
+CREATE TABLE candles
+(
+    symbol   varchar(255) not null,
+    interval varchar(16)  not null,
+    time     timestamptz  not null,
+    open     float8       not null,
+    high     float8       not null,
+    low      float8       not null,
+    close    float8       not null,
+    volume   bigint       not null default 0,
+    primary key (symbol, interval, time)
+);
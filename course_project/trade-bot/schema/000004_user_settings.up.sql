@@ -0,0 +1,5 @@
+CREATE TABLE user_settings
+(
+    user_id       int references users (id) on delete cascade not null unique,
+    paper_trading boolean                                     not null default false
+);
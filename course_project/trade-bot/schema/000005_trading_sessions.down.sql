@@ -0,0 +1 @@
+DROP TABLE trading_sessions;
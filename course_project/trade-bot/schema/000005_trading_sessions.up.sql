@@ -0,0 +1,18 @@
+CREATE TABLE trading_sessions
+(
+    id             serial                                      not null unique,
+    user_id        int references users (id) on delete cascade not null,
+    status         varchar(255)                                not null,
+    details        jsonb                                       not null,
+    entry_order_id varchar(255)                                not null default '',
+    entry_price    float8                                      not null default 0,
+    entry_time     timestamptz,
+    exit_order_id  varchar(255)                                not null default '',
+    exit_price     float8                                      not null default 0,
+    error          text                                        not null default '',
+    created_at     timestamptz                                 not null default now(),
+    updated_at     timestamptz                                 not null default now()
+);
+
+CREATE INDEX trading_sessions_user_id_idx ON trading_sessions (user_id);
+CREATE INDEX trading_sessions_status_idx ON trading_sessions (status);
@@ -0,0 +1,3 @@
+ALTER TABLE trading_sessions
+    DROP COLUMN stop_loss_order_id,
+    DROP COLUMN take_profit_order_id;
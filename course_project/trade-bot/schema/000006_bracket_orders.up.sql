@@ -0,0 +1,3 @@
+ALTER TABLE trading_sessions
+    ADD COLUMN stop_loss_order_id   varchar(255) not null default '',
+    ADD COLUMN take_profit_order_id varchar(255) not null default '';
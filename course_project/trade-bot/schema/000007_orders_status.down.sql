@@ -0,0 +1,5 @@
+DROP INDEX orders_user_id_status_idx;
+
+ALTER TABLE orders
+    DROP COLUMN status,
+    DROP COLUMN status_updated_at;
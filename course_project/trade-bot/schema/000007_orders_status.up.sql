@@ -0,0 +1,7 @@
+ALTER TABLE orders
+    ADD COLUMN status            varchar(255) not null default 'filled',
+    ADD COLUMN status_updated_at timestamptz  not null default now();
+
+UPDATE orders SET status='open' WHERE type='PLACE';
+
+CREATE INDEX orders_user_id_status_idx ON orders (user_id, status);
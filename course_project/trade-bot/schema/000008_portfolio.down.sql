@@ -0,0 +1,5 @@
+DROP TABLE portfolio_drifts;
+
+DROP TABLE positions;
+
+DROP TABLE fills;
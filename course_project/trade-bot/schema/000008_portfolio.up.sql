@@ -0,0 +1,40 @@
+CREATE TABLE fills
+(
+    fill_id      varchar(255)                                not null unique,
+    user_id      int references users (id) on delete cascade not null,
+    order_id     varchar(255)                                not null,
+    cli_order_id varchar(255)                                not null default '',
+    symbol       varchar(255)                                not null,
+    side         varchar(255)                                not null,
+    size         float8                                      not null,
+    price        float8                                      not null,
+    fill_type    varchar(255)                                not null,
+    fill_time    timestamptz                                 not null
+);
+
+CREATE INDEX fills_user_id_fill_time_idx ON fills (user_id, fill_time);
+
+CREATE TABLE positions
+(
+    user_id    int references users (id) on delete cascade not null,
+    symbol     varchar(255)                                not null,
+    side       varchar(255)                                not null,
+    size       float8                                      not null,
+    price      float8                                      not null,
+    fill_time  timestamptz                                 not null,
+    updated_at timestamptz                                 not null default now(),
+    unique (user_id, symbol)
+);
+
+CREATE TABLE portfolio_drifts
+(
+    id             serial                                      not null unique,
+    user_id        int references users (id) on delete cascade not null,
+    kind           varchar(255)                                not null,
+    reference      varchar(255)                                not null,
+    local_state    text                                        not null default '',
+    exchange_state text                                        not null default '',
+    detected_at    timestamptz                                 not null default now()
+);
+
+CREATE INDEX portfolio_drifts_user_id_idx ON portfolio_drifts (user_id);
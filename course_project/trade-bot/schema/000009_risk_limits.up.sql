@@ -0,0 +1,8 @@
+CREATE TABLE risk_limits
+(
+    user_id           int references users (id) on delete cascade not null unique,
+    max_order_size    int                                         not null default 0,
+    max_notional      float8                                      not null default 0,
+    max_open_sessions int                                         not null default 0,
+    daily_loss_limit  float8                                      not null default 0
+);
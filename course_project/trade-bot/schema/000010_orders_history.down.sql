@@ -0,0 +1,15 @@
+DROP INDEX orders_user_id_symbol_timestamp_idx;
+DROP INDEX orders_user_id_timestamp_idx;
+
+ALTER TABLE orders
+    ALTER COLUMN price DROP NOT NULL,
+    ALTER COLUMN price DROP DEFAULT;
+
+ALTER TABLE orders
+    ALTER COLUMN timestamp TYPE varchar(255)
+        USING to_char(timestamp AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+    ALTER COLUMN last_update_timestamp TYPE varchar(255)
+        USING to_char(last_update_timestamp AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+    ALTER COLUMN quantity TYPE float8 USING quantity::float8,
+    ALTER COLUMN filled TYPE float8 USING filled::float8,
+    ALTER COLUMN price TYPE float8 USING price::float8;
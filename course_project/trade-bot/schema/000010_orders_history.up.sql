@@ -0,0 +1,15 @@
+ALTER TABLE orders
+    ALTER COLUMN timestamp TYPE timestamptz
+        USING COALESCE(NULLIF(timestamp, '')::timestamptz, status_updated_at),
+    ALTER COLUMN last_update_timestamp TYPE timestamptz
+        USING COALESCE(NULLIF(last_update_timestamp, '')::timestamptz, status_updated_at),
+    ALTER COLUMN quantity TYPE numeric USING quantity::numeric,
+    ALTER COLUMN filled TYPE numeric USING filled::numeric,
+    ALTER COLUMN price TYPE numeric USING COALESCE(price, 0)::numeric;
+
+ALTER TABLE orders
+    ALTER COLUMN price SET DEFAULT 0,
+    ALTER COLUMN price SET NOT NULL;
+
+CREATE INDEX orders_user_id_timestamp_idx ON orders (user_id, timestamp, order_id);
+CREATE INDEX orders_user_id_symbol_timestamp_idx ON orders (user_id, symbol, timestamp, order_id);
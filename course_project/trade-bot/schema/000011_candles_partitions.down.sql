@@ -0,0 +1,21 @@
+CREATE TABLE candles_unpartitioned
+(
+    symbol   varchar(255) not null,
+    interval varchar(16)  not null,
+    time     timestamptz  not null,
+    open     float8       not null,
+    high     float8       not null,
+    low      float8       not null,
+    close    float8       not null,
+    volume   bigint       not null default 0,
+    primary key (symbol, interval, time)
+);
+
+INSERT INTO candles_unpartitioned (symbol, interval, time, open, high, low, close, volume)
+SELECT symbol, interval, time, open, high, low, close, volume
+FROM candles;
+
+DROP TABLE candles;
+
+ALTER TABLE candles_unpartitioned RENAME TO candles;
+ALTER INDEX candles_unpartitioned_pkey RENAME TO candles_pkey;
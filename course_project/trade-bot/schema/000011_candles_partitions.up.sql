@@ -0,0 +1,36 @@
+ALTER TABLE candles RENAME TO candles_unpartitioned;
+ALTER INDEX candles_pkey RENAME TO candles_unpartitioned_pkey;
+
+-- candles are partitioned by months of UTC, partitions are named candles_YYYY_MM
+CREATE TABLE candles
+(
+    symbol   varchar(255) not null,
+    interval varchar(16)  not null,
+    time     timestamptz  not null,
+    open     float8       not null,
+    high     float8       not null,
+    low      float8       not null,
+    close    float8       not null,
+    volume   bigint       not null default 0,
+    primary key (symbol, interval, time)
+) PARTITION BY RANGE (time);
+
+DO
+$$
+    DECLARE
+        month timestamp;
+    BEGIN
+        FOR month IN SELECT DISTINCT date_trunc('month', time AT TIME ZONE 'UTC') FROM candles_unpartitioned
+            LOOP
+                EXECUTE format('CREATE TABLE %I PARTITION OF candles FOR VALUES FROM (%L) TO (%L)',
+                               'candles_' || to_char(month, 'YYYY_MM'),
+                               month AT TIME ZONE 'UTC', (month + interval '1 month') AT TIME ZONE 'UTC');
+            END LOOP;
+    END
+$$;
+
+INSERT INTO candles (symbol, interval, time, open, high, low, close, volume)
+SELECT symbol, interval, time, open, high, low, close, volume
+FROM candles_unpartitioned;
+
+DROP TABLE candles_unpartitioned;
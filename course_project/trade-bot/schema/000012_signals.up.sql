@@ -0,0 +1,26 @@
+CREATE TABLE signal_webhooks
+(
+    user_id            int references users (id) on delete cascade not null unique,
+    token              varchar(255)                                not null unique,
+    secret             text                                        not null,
+    secret_data_key    text                                        not null default '',
+    secret_key_version integer                                     not null default 0,
+    created_at         timestamptz                                 not null default now()
+);
+
+-- signals are deduplicated by id among accepted and pending ones, rejected signal may be sent again
+CREATE TABLE signals
+(
+    id          serial                                      not null unique,
+    user_id     int references users (id) on delete cascade not null,
+    signal_id   varchar(255)                                not null default '',
+    status      varchar(255)                                not null,
+    reason      text                                        not null default '',
+    payload     text                                        not null default '',
+    order_id    varchar(255)                                not null default '',
+    session_id  int                                         not null default 0,
+    received_at timestamptz                                 not null default now()
+);
+
+CREATE UNIQUE INDEX signals_user_id_signal_id_idx ON signals (user_id, signal_id) WHERE status <> 'rejected';
+CREATE INDEX signals_user_id_received_at_idx ON signals (user_id, received_at);
@@ -0,0 +1,32 @@
+CREATE TABLE notification_preferences
+(
+    user_id          int references users (id) on delete cascade not null unique,
+    telegram_chat_id bigint                                      not null default 0,
+    email            varchar(255)                                not null default '',
+    webhook_url      text                                        not null default '',
+    events           text                                        not null default ''
+);
+
+-- outbox of notifications, dispatcher leases due pending notifications by moving next_attempt_at forward
+CREATE TABLE notifications
+(
+    id              serial                                      not null unique,
+    user_id         int references users (id) on delete cascade not null,
+    event           varchar(255)                                not null,
+    channel         varchar(255)                                not null,
+    address         text                                        not null,
+    dedup_key       varchar(255)                                not null default '',
+    subject         text                                        not null default '',
+    body            text                                        not null default '',
+    status          varchar(255)                                not null,
+    attempts        int                                         not null default 0,
+    last_error      text                                        not null default '',
+    next_attempt_at timestamptz                                 not null default now(),
+    created_at      timestamptz                                 not null default now(),
+    sent_at         timestamptz
+);
+
+CREATE UNIQUE INDEX notifications_user_id_channel_dedup_key_idx ON notifications (user_id, channel, dedup_key)
+    WHERE dedup_key <> '';
+CREATE INDEX notifications_pending_next_attempt_at_idx ON notifications (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX notifications_user_id_idx ON notifications (user_id);
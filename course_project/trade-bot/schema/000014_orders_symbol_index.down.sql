@@ -0,0 +1,2 @@
+DROP INDEX orders_user_id_lower_symbol_timestamp_idx;
+CREATE INDEX orders_user_id_symbol_timestamp_idx ON orders (user_id, symbol, timestamp, order_id);
@@ -0,0 +1,3 @@
+-- order history is filtered by symbol case insensitively
+DROP INDEX orders_user_id_symbol_timestamp_idx;
+CREATE INDEX orders_user_id_lower_symbol_timestamp_idx ON orders (user_id, lower(symbol), timestamp, order_id);
@@ -0,0 +1,7 @@
+-- replica watches trading session while it holds its lease, lease of stopped replica expires and is taken over
+CREATE TABLE trading_session_leases
+(
+    session_id  int references trading_sessions (id) on delete cascade not null unique,
+    owner       varchar(255)                                         not null,
+    lease_until timestamptz                                          not null
+);
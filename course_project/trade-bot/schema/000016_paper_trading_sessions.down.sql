@@ -0,0 +1 @@
+ALTER TABLE trading_sessions DROP COLUMN paper_trading;
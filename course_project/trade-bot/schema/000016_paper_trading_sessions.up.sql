@@ -0,0 +1,2 @@
+-- simulated exchange of paper trading is kept in memory only, so paper sessions are not resumed after restart
+ALTER TABLE trading_sessions ADD COLUMN paper_trading boolean not null default false;
@@ -0,0 +1,7 @@
+// Package schema embeds migrations of postgres database, they are applied by postgresRepo.Migrator
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var Migrations embed.FS